@@ -0,0 +1,126 @@
+package fileprep
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nao1215/fileparser"
+)
+
+// annotationColumn is the name of the column WriteAnnotated appends. A
+// number is added when the input already has a column of that name.
+const annotationColumn = "errors"
+
+// annotationSource is the input a ProcessResult covers. It keeps a
+// reference to the decompressed input so WriteAnnotated can reproduce the
+// values as they were read.
+type annotationSource struct {
+	processor *Processor
+	data      []byte
+	firstRow  int // 1-based data row number of the first covered row
+	rowCount  int // covered rows, including the ones dropped by WithFilter
+}
+
+// WriteAnnotated writes a copy of the input the result covers with an extra
+// last column, named errors, listing the errors of each row as
+// "column: message" separated by "; ". The column is empty for valid rows.
+// Values are written as they were read, before preprocessing, so a data
+// provider receives feedback in the file they sent.
+//
+// The copy has the input's format without compression: CSV (with the
+// WithDelimiter delimiter), TSV, LTSV, or XLSX. Other formats return an
+// error wrapping ErrUnsupportedFileType. It covers the rows of the result,
+// so rows skipped by WithStartRow are left out, and a ProcessChunks result
+// covers its chunk. Warnings are not listed. The Processor must be created
+// with WithAnnotations, which keeps the input in the result; otherwise
+// WriteAnnotated returns an error wrapping ErrInvalidOption.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithAnnotations())
+//	_, result, err := processor.Process(upload, &orders)
+//	if err != nil {
+//	    return err
+//	}
+//	f, _ := os.Create("orders_annotated.csv")
+//	defer f.Close()
+//	if err := result.WriteAnnotated(f); err != nil {
+//	    return err
+//	}
+func (r *ProcessResult) WriteAnnotated(w io.Writer) error {
+	src := r.source
+	if src == nil {
+		return fmt.Errorf("%w: result has no processed input; create the processor with WithAnnotations", ErrInvalidOption)
+	}
+	p := src.processor
+	baseType := fileparser.BaseFileType(p.fileType)
+	switch baseType {
+	case fileparser.CSV, fileparser.TSV, fileparser.LTSV, fileparser.XLSX:
+	default:
+		return fmt.Errorf("%w: annotated copies need CSV, TSV, LTSV, or XLSX input, got %s", ErrUnsupportedFileType, p.fileType)
+	}
+
+	tableData, _, err := p.parse(src.data)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrParse, err)
+	}
+	first := min(src.firstRow-1, len(tableData.Records))
+	records := tableData.Records[first:min(first+src.rowCount, len(tableData.Records))]
+
+	column := annotationColumn
+	for n := 2; slices.Contains(tableData.Headers, column); n++ {
+		column = annotationColumn + "_" + strconv.Itoa(n)
+	}
+	headers := append(slices.Clip(tableData.Headers), column)
+
+	notes := r.annotations()
+	rows := make([][]string, len(records))
+	for i, record := range records {
+		row := make([]string, max(len(record), len(tableData.Headers)), max(len(record), len(tableData.Headers))+1)
+		copy(row, record)
+		rows[i] = append(row, notes[src.firstRow+i])
+	}
+
+	switch baseType {
+	case fileparser.LTSV:
+		return p.writeLTSV(w, headers, rows)
+	case fileparser.XLSX:
+		return writeXLSX(w, headers, rows, nil)
+	default:
+		csvWriter := csv.NewWriter(w)
+		csvWriter.Comma = p.csvOpts.comma()
+		if baseType == fileparser.TSV {
+			csvWriter.Comma = '\t'
+		}
+		if err := csvWriter.Write(headers); err != nil {
+			return err
+		}
+		return csvWriter.WriteAll(rows)
+	}
+}
+
+// annotations returns the errors of each row as "column: message" joined
+// by "; ", by row number.
+func (r *ProcessResult) annotations() map[int]string {
+	byRow := make(map[int][]string)
+	for _, err := range r.Errors {
+		issue, ok := newReportIssue(err)
+		if !ok {
+			continue
+		}
+		note := issue.Message
+		if issue.Column != "" {
+			note = issue.Column + ": " + note
+		}
+		byRow[issue.Row] = append(byRow[issue.Row], note)
+	}
+	notes := make(map[int]string, len(byRow))
+	for row, list := range byRow {
+		notes[row] = strings.Join(list, "; ")
+	}
+	return notes
+}
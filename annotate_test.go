@@ -0,0 +1,169 @@
+package fileprep
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileparser"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestProcessResult_WriteAnnotated(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Name  string `prep:"trim" validate:"required"`
+		Email string `prep:"trim" validate:"email"`
+	}
+
+	tests := []struct {
+		name     string
+		fileType FileType
+		opts     []Option
+		input    string
+		want     string
+	}{
+		{
+			name:     "CSV keeps values as read",
+			fileType: fileparser.CSV,
+			input:    "name,email\n alice ,alice@example.com\n,bad\n",
+			want: "name,email,errors\n" +
+				"\" alice \",alice@example.com,\n" +
+				",bad,name: value is required; email: value must be a valid email address\n",
+		},
+		{
+			name:     "CSV with custom delimiter and existing errors column",
+			fileType: fileparser.CSV,
+			opts:     []Option{WithDelimiter(';')},
+			input:    "name;email;errors\nbob;bad;x\n",
+			want:     "name;email;errors;errors_2\nbob;bad;x;email: value must be a valid email address\n",
+		},
+		{
+			name:     "TSV after WithStartRow",
+			fileType: fileparser.TSV,
+			opts:     []Option{WithStartRow(1)},
+			input:    "name\temail\n\tskipped\ncarol\tbad\n",
+			want:     "name\temail\terrors\ncarol\tbad\temail: value must be a valid email address\n",
+		},
+		{
+			name:     "LTSV",
+			fileType: fileparser.LTSV,
+			input:    "name:dave\temail:bad\n",
+			want:     "name:dave\temail:bad\terrors:email: value must be a valid email address\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var users []user
+			_, result, err := NewProcessor(tt.fileType, append([]Option{WithAnnotations()}, tt.opts...)...).Process(strings.NewReader(tt.input), &users)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := result.WriteAnnotated(&buf); err != nil {
+				t.Fatalf("WriteAnnotated() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, buf.String()); diff != "" {
+				t.Errorf("WriteAnnotated() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("XLSX", func(t *testing.T) {
+		t.Parallel()
+
+		data := newTestXLSX(t, `<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c><c r="B1" t="inlineStr"><is><t>email</t></is></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><t>erin</t></is></c><c r="B2" t="inlineStr"><is><t>bad</t></is></c></row>`)
+		var users []user
+		_, result, err := NewProcessor(fileparser.XLSX, WithFillMergedCells(), WithAnnotations()).Process(bytes.NewReader(data), &users)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		var buf bytes.Buffer
+		if err := result.WriteAnnotated(&buf); err != nil {
+			t.Fatalf("WriteAnnotated() error = %v", err)
+		}
+		f, err := excelize.OpenReader(&buf)
+		if err != nil {
+			t.Fatalf("excelize.OpenReader() error = %v", err)
+		}
+		defer f.Close()
+		rows, err := f.GetRows(f.GetSheetList()[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := [][]string{
+			{"name", "email", "errors"},
+			{"erin", "bad", "email: value must be a valid email address"},
+		}
+		if diff := cmp.Diff(want, rows); diff != "" {
+			t.Errorf("rows mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("chunk covers its rows", func(t *testing.T) {
+		t.Parallel()
+
+		var users []user
+		var got []string
+		err := NewProcessor(fileparser.CSV, WithAnnotations()).ProcessChunks(strings.NewReader("name,email\na,a@example.com\nb,bad\n"), &users, 1,
+			func(_ Stream, res *ProcessResult) error {
+				var buf bytes.Buffer
+				if err := res.WriteAnnotated(&buf); err != nil {
+					return err
+				}
+				got = append(got, buf.String())
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("ProcessChunks() error = %v", err)
+		}
+		want := []string{
+			"name,email,errors\na,a@example.com,\n",
+			"name,email,errors\nb,bad,email: value must be a valid email address\n",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("chunks mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("JSONL is unsupported", func(t *testing.T) {
+		t.Parallel()
+
+		var rows []struct{}
+		_, result, err := NewProcessor(fileparser.JSONL, WithAnnotations()).Process(strings.NewReader("{\"a\":1}\n"), &rows)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if err := result.WriteAnnotated(&bytes.Buffer{}); !errors.Is(err, ErrUnsupportedFileType) {
+			t.Errorf("WriteAnnotated() error = %v, want ErrUnsupportedFileType", err)
+		}
+	})
+
+	t.Run("input not kept without WithAnnotations", func(t *testing.T) {
+		t.Parallel()
+
+		var users []user
+		_, result, err := NewProcessor(fileparser.CSV).Process(strings.NewReader("name,email\na,bad\n"), &users)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if err := result.WriteAnnotated(&bytes.Buffer{}); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("WriteAnnotated() error = %v, want ErrInvalidOption", err)
+		}
+	})
+
+	t.Run("empty result", func(t *testing.T) {
+		t.Parallel()
+
+		if err := (&ProcessResult{}).WriteAnnotated(&bytes.Buffer{}); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("WriteAnnotated() error = %v, want ErrInvalidOption", err)
+		}
+	})
+}
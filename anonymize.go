@@ -0,0 +1,166 @@
+package fileprep
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// AnonymizeKind selects the fake data WithAnonymizedColumn writes.
+type AnonymizeKind int
+
+const (
+	// AnonymizeName replaces a person name with a fake one with the same
+	// number of words, up to a first and last name.
+	AnonymizeName AnonymizeKind = iota
+	// AnonymizeEmail replaces an email address with a fake address at a
+	// reserved example domain.
+	AnonymizeEmail
+	// AnonymizePhone replaces every digit but the first with a fake digit,
+	// keeping separators, so the number keeps its format.
+	AnonymizePhone
+	// AnonymizeText replaces every letter with a fake letter of the same case
+	// and every digit with a fake digit, keeping the length and the other
+	// characters. It suits IDs, postal codes, and free text.
+	AnonymizeText
+)
+
+// anonymizedColumn is a column rewritten with fake data in the output.
+type anonymizedColumn struct {
+	column string
+	kind   AnonymizeKind
+}
+
+//nolint:gochecknoglobals // fake data tables
+var (
+	fakeFirstNames = []string{
+		"Alex", "Blake", "Casey", "Dana", "Eden", "Frankie", "Gray", "Harper",
+		"Indy", "Jordan", "Kai", "Logan", "Morgan", "Noel", "Oakley", "Parker",
+		"Quinn", "Riley", "Sage", "Taylor", "Val", "Wren", "Yael", "Zion",
+	}
+	fakeLastNames = []string{
+		"Abbott", "Brooks", "Carter", "Dalton", "Ellis", "Fisher", "Garner", "Hayes",
+		"Irving", "Jensen", "Keller", "Lambert", "Monroe", "Nolan", "Owens", "Porter",
+		"Reyes", "Sutton", "Tanner", "Upton", "Vaughn", "Walsh", "Young", "Zeller",
+	}
+	fakeEmailDomains = []string{"example.com", "example.net", "example.org"}
+)
+
+// anonymizer writes deterministic fake values: the same seed, kind, and
+// original value always give the same fake value, so anonymized files are
+// reproducible and values that join across files still join.
+type anonymizer struct {
+	seed string
+}
+
+// fake returns the fake value for value. Empty values stay empty.
+func (a anonymizer) fake(kind AnonymizeKind, value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s", a.seed, kind, value)))
+	r := rand.New(rand.NewPCG(binary.LittleEndian.Uint64(sum[:8]), binary.LittleEndian.Uint64(sum[8:16]))) //nolint:gosec // fake data, not secrets
+
+	switch kind {
+	case AnonymizeName:
+		return fakeName(r, value)
+	case AnonymizeEmail:
+		return fakeEmail(r)
+	case AnonymizePhone:
+		return fakeDigits(r, value, true)
+	default:
+		return fakeText(r, value)
+	}
+}
+
+// fakeName returns a fake name with as many words as value, up to two, and
+// in upper case when value is.
+func fakeName(r *rand.Rand, value string) string {
+	name := fakeFirstNames[r.IntN(len(fakeFirstNames))]
+	if len(strings.Fields(value)) > 1 {
+		name += " " + fakeLastNames[r.IntN(len(fakeLastNames))]
+	}
+	if strings.ToUpper(value) == value && strings.ToLower(value) != value {
+		return strings.ToUpper(name)
+	}
+	return name
+}
+
+// fakeEmail returns a fake address such as "casey.monroe42@example.org".
+func fakeEmail(r *rand.Rand) string {
+	first := fakeFirstNames[r.IntN(len(fakeFirstNames))]
+	last := fakeLastNames[r.IntN(len(fakeLastNames))]
+	domain := fakeEmailDomains[r.IntN(len(fakeEmailDomains))]
+	return fmt.Sprintf("%s.%s%d@%s", strings.ToLower(first), strings.ToLower(last), r.IntN(100), domain)
+}
+
+// fakeDigits replaces the digits of value with random digits. With
+// keepFirst, the first digit is kept, which preserves a trunk prefix such
+// as 0 or the start of a country code.
+func fakeDigits(r *rand.Rand, value string, keepFirst bool) string {
+	runes := []rune(value)
+	for i, c := range runes {
+		if c < '0' || c > '9' {
+			continue
+		}
+		if keepFirst {
+			keepFirst = false
+			continue
+		}
+		runes[i] = rune('0' + r.IntN(10))
+	}
+	return string(runes)
+}
+
+// fakeText replaces ASCII letters and digits with random ones of the same class.
+func fakeText(r *rand.Rand, value string) string {
+	runes := []rune(value)
+	for i, c := range runes {
+		switch {
+		case c >= '0' && c <= '9':
+			runes[i] = rune('0' + r.IntN(10))
+		case c < unicode.MaxASCII && unicode.IsUpper(c):
+			runes[i] = rune('A' + r.IntN(26))
+		case c < unicode.MaxASCII && unicode.IsLower(c):
+			runes[i] = rune('a' + r.IntN(26))
+		case unicode.IsLetter(c):
+			runes[i] = rune('x')
+		}
+	}
+	return string(runes)
+}
+
+// anonymizeRows returns records with the anonymized columns replaced by fake
+// values. Rows are copied, so the processed rows of ProcessResult keep their
+// real values.
+func (p *Processor) anonymizeRows(headers []string, records [][]string) ([][]string, error) {
+	if len(p.anonymize) == 0 {
+		return records, nil
+	}
+
+	colIdxs := make([]int, len(p.anonymize))
+	for i, a := range p.anonymize {
+		colIdx := slices.Index(headers, a.column)
+		if colIdx < 0 {
+			return nil, fmt.Errorf("anonymized column %q: %w", a.column, ErrColumnNotFound)
+		}
+		colIdxs[i] = colIdx
+	}
+
+	anon := anonymizer{seed: p.anonymizeSeed}
+	anonymized := make([][]string, len(records))
+	for rowIdx, record := range records {
+		row := slices.Clone(record)
+		for i, a := range p.anonymize {
+			if colIdx := colIdxs[i]; colIdx < len(row) {
+				row[colIdx] = anon.fake(a.kind, row[colIdx])
+			}
+		}
+		anonymized[rowIdx] = row
+	}
+	return anonymized, nil
+}
@@ -0,0 +1,55 @@
+package fileprep
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestAnonymizer_Fake(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		kind  AnonymizeKind
+		value string
+		want  *regexp.Regexp
+	}{
+		{name: "full name", kind: AnonymizeName, value: "John Smith", want: regexp.MustCompile(`^[A-Z][a-z]+ [A-Z][a-z]+$`)},
+		{name: "single name", kind: AnonymizeName, value: "Plato", want: regexp.MustCompile(`^[A-Z][a-z]+$`)},
+		{name: "upper case name", kind: AnonymizeName, value: "JOHN SMITH", want: regexp.MustCompile(`^[A-Z]+ [A-Z]+$`)},
+		{name: "email", kind: AnonymizeEmail, value: "john@corp.co.jp", want: regexp.MustCompile(`^[a-z]+\.[a-z]+[0-9]{1,2}@example\.(com|net|org)$`)},
+		{name: "phone keeps format", kind: AnonymizePhone, value: "090-1234-5678", want: regexp.MustCompile(`^0[0-9]{2}-[0-9]{4}-[0-9]{4}$`)},
+		{name: "international phone", kind: AnonymizePhone, value: "+1 (555) 010-9999", want: regexp.MustCompile(`^\+1 \([0-9]{3}\) [0-9]{3}-[0-9]{4}$`)},
+		{name: "text keeps classes", kind: AnonymizeText, value: "AB-12cd", want: regexp.MustCompile(`^[A-Z]{2}-[0-9]{2}[a-z]{2}$`)},
+		{name: "empty stays empty", kind: AnonymizeEmail, value: "", want: regexp.MustCompile(`^$`)},
+	}
+
+	anon := anonymizer{seed: "test"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := anon.fake(tt.kind, tt.value)
+			if !tt.want.MatchString(got) {
+				t.Errorf("fake(%q) = %q, want match for %s", tt.value, got, tt.want)
+			}
+			if again := anon.fake(tt.kind, tt.value); again != got {
+				t.Errorf("fake(%q) is not deterministic: %q then %q", tt.value, got, again)
+			}
+		})
+	}
+}
+
+func TestAnonymizer_Seed(t *testing.T) {
+	t.Parallel()
+
+	value := "alice@example.com"
+	a := anonymizer{seed: "a"}.fake(AnonymizeText, value)
+	b := anonymizer{seed: "b"}.fake(AnonymizeText, value)
+	if a == b {
+		t.Errorf("different seeds gave the same fake value %q", a)
+	}
+	if strings.Contains(a, "alice") {
+		t.Errorf("fake value %q contains the original", a)
+	}
+}
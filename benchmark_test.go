@@ -202,6 +202,36 @@ func BenchmarkPreprocessorsOnly(b *testing.B) {
 	}
 }
 
+// BenchmarkCaseAndTrim benchmarks the trim/lowercase/uppercase preprocessors
+// on already-clean and dirty ASCII values as well as non-ASCII input.
+func BenchmarkCaseAndTrim(b *testing.B) {
+	inputs := map[string][]string{
+		"clean":    {"hello", "world@example.com", "active", "technology"},
+		"dirty":    {"  HELLO  ", " World@Example.COM ", "  Active", "TECHNOLOGY  "},
+		"nonASCII": {"  ÄPFEL  ", " Ünïcödé ", "東京  ", " ÉCOLE"},
+	}
+	preps := map[string]Preprocessor{
+		"trim":      newTrimPreprocessor(),
+		"lowercase": newLowercasePreprocessor(),
+		"uppercase": newUppercasePreprocessor(),
+	}
+
+	for _, prepName := range []string{"trim", "lowercase", "uppercase"} {
+		for _, inputName := range []string{"clean", "dirty", "nonASCII"} {
+			prep := preps[prepName]
+			values := inputs[inputName]
+			b.Run(prepName+"/"+inputName, func(b *testing.B) {
+				b.ReportAllocs()
+				for range b.N {
+					for _, v := range values {
+						_ = prep.Process(v)
+					}
+				}
+			})
+		}
+	}
+}
+
 // BenchmarkValidatorsOnly benchmarks just the validation step
 func BenchmarkValidatorsOnly(b *testing.B) {
 	// Create validators chain
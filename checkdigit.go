@@ -0,0 +1,201 @@
+package fileprep
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CheckDigit verifies the check character of an identifier, such as the
+// Luhn digit of a card number or the mod-11 digit of a national ID number.
+// Register one with RegisterValidator to use it in validate tags.
+type CheckDigit interface {
+	// Valid reports whether value, check character included, is valid
+	Valid(value string) bool
+}
+
+// CheckDigitFunc adapts an ordinary function to the CheckDigit interface.
+type CheckDigitFunc func(value string) bool
+
+// Valid calls f(value)
+func (f CheckDigitFunc) Valid(value string) bool {
+	return f(value)
+}
+
+// luhnAlphabet holds the characters of LuhnModN in code point order. Mod N
+// uses the first N of them.
+const luhnAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// LuhnModN is the Luhn mod N algorithm over the first N characters of
+// 0-9 followed by A-Z, so LuhnModN(10) is the Luhn check of card numbers and
+// IMEIs and LuhnModN(36) covers alphanumeric codes. Letters match in either
+// case. N must be between 2 and 36; any other N accepts no value.
+type LuhnModN int
+
+// Valid reports whether the last character of value is the Luhn mod N check
+// character of the ones before it
+func (n LuhnModN) Valid(value string) bool {
+	if n < 2 || int(n) > len(luhnAlphabet) || len(value) < 2 {
+		return false
+	}
+	alphabet := luhnAlphabet[:n]
+	sum, factor := 0, 1
+	for i := len(value) - 1; i >= 0; i-- {
+		codePoint := strings.IndexByte(alphabet, upperASCII(value[i]))
+		if codePoint < 0 {
+			return false
+		}
+		addend := factor * codePoint
+		sum += addend/int(n) + addend%int(n)
+		factor = 3 - factor // alternate between 1 and 2
+	}
+	return sum%int(n) == 0
+}
+
+// upperASCII returns the uppercase form of an ASCII letter, or c itself.
+func upperASCII(c byte) byte {
+	if 'a' <= c && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+// checkDigitValidator validates values with a CheckDigit
+type checkDigitValidator struct {
+	name   string
+	check  CheckDigit
+	errMsg string
+}
+
+// newCheckDigitValidator creates a new check digit validator reported as name
+func newCheckDigitValidator(name string, check CheckDigit) *checkDigitValidator {
+	return &checkDigitValidator{
+		name:   name,
+		check:  check,
+		errMsg: "value must have a valid " + name + " check digit",
+	}
+}
+
+// Validate checks the check character of value
+func (v *checkDigitValidator) Validate(value string) string {
+	if !v.check.Valid(value) {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *checkDigitValidator) Name() string {
+	return v.name
+}
+
+// buildLuhnValidator builds a luhn validator: "luhn" checks digits with
+// Luhn mod 10, and "luhn=N" uses Luhn mod N.
+func buildLuhnValidator(value string, strict bool) (Validator, error) {
+	if value == "" {
+		return newCheckDigitValidator(luhnTagValue, LuhnModN(10)), nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 2 || n > len(luhnAlphabet) {
+		if strict {
+			return nil, fmt.Errorf("%w: luhn requires a modulus between 2 and %d, got %q", ErrInvalidTagFormat, len(luhnAlphabet), value)
+		}
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+	return newCheckDigitValidator(luhnTagValue, LuhnModN(n)), nil
+}
+
+// validatorTagNameRegex matches the tag names RegisterValidator accepts.
+var validatorTagNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// registeredValidators holds the validators added with RegisterValidator.
+//
+//nolint:gochecknoglobals // registry shared by every processor, like validatorRegistry
+var registeredValidators = struct {
+	sync.RWMutex
+	checks map[string]CheckDigit
+}{checks: make(map[string]CheckDigit)}
+
+// RegisterValidator makes tag usable in the validate tags of every
+// processor, checking values with check. fileprep takes care of the tag
+// syntax, validator groups, strict tag parsing, and error reporting, so a
+// check digit scheme only has to tell valid values from invalid ones. The
+// tag takes no parameter; register one tag per variant instead.
+//
+// Register validators from an init function or before processing starts.
+// RegisterValidator returns an error wrapping ErrInvalidOption when tag is
+// not a plain name, is a built-in validator, or is already registered.
+//
+// Example:
+//
+//	// Norwegian national identity numbers end with two mod-11 check digits
+//	err := fileprep.RegisterValidator("no_fnr", fileprep.CheckDigitFunc(validFNR))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	type Person struct {
+//	    NationalID string `validate:"required,no_fnr"`
+//	}
+func RegisterValidator(tag string, check CheckDigit) error {
+	if !validatorTagNameRegex.MatchString(tag) {
+		return fmt.Errorf("%w: validator tag %q must be a letter followed by letters, digits, or underscores", ErrInvalidOption, tag)
+	}
+	if check == nil {
+		return fmt.Errorf("%w: validator %q has no check", ErrInvalidOption, tag)
+	}
+	if isBuiltinValidateTag(tag) {
+		return fmt.Errorf("%w: validator tag %q is built in", ErrInvalidOption, tag)
+	}
+
+	registeredValidators.Lock()
+	defer registeredValidators.Unlock()
+	if _, ok := registeredValidators.checks[tag]; ok {
+		return fmt.Errorf("%w: validator tag %q is already registered", ErrInvalidOption, tag)
+	}
+	registeredValidators.checks[tag] = check
+	return nil
+}
+
+// isBuiltinValidateTag reports whether tag names a built-in validator or
+// validator group.
+func isBuiltinValidateTag(tag string) bool {
+	if _, ok := validatorRegistry[tag]; ok {
+		return true
+	}
+	if _, ok := crossFieldValidatorRegistry[tag]; ok {
+		return true
+	}
+	switch tag {
+	case requiredIfTagValue, requiredUnlessTagValue, uniqueTagValue, checksumTagValue, withinTagValue, sumOfTagValue, exprTagValue,
+		orTagValue, andTagValue, notTagValue:
+		return true
+	default:
+		return false
+	}
+}
+
+// lookupValidatorBuilder returns the builder of a built-in or registered
+// single-field validator.
+func lookupValidatorBuilder(tag string) (validatorBuilder, bool) {
+	if builder, ok := validatorRegistry[tag]; ok {
+		return builder, true
+	}
+	registeredValidators.RLock()
+	check, ok := registeredValidators.checks[tag]
+	registeredValidators.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return func(value string, strict bool) (Validator, error) {
+		if value != "" {
+			if strict {
+				return nil, fmt.Errorf("%w: %s takes no parameter, got %q", ErrInvalidTagFormat, tag, value)
+			}
+			return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+		}
+		return newCheckDigitValidator(tag, check), nil
+	}, true
+}
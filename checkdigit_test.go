@@ -0,0 +1,119 @@
+package fileprep
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLuhnModN(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		n     LuhnModN
+		value string
+		want  bool
+	}{
+		{name: "mod 10 valid", n: 10, value: "79927398713", want: true},
+		{name: "mod 10 wrong check digit", n: 10, value: "79927398710", want: false},
+		{name: "mod 10 transposed digits", n: 10, value: "79927398731", want: false},
+		{name: "mod 10 letter", n: 10, value: "7992739871A", want: false},
+		{name: "mod 36 valid", n: 36, value: "ABC12C", want: true},
+		{name: "mod 36 lowercase", n: 36, value: "abc12c", want: true},
+		{name: "mod 36 wrong check character", n: 36, value: "ABC12D", want: false},
+		{name: "mod 16 valid", n: 16, value: "A1B25", want: true},
+		{name: "mod 16 character outside alphabet", n: 16, value: "G1B25", want: false},
+		{name: "single character", n: 10, value: "0", want: false},
+		{name: "empty", n: 10, value: "", want: false},
+		{name: "modulus out of range", n: 37, value: "79927398713", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.n.Valid(tt.value); got != tt.want {
+				t.Errorf("LuhnModN(%d).Valid(%q) = %v, want %v", tt.n, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterValidator(t *testing.T) {
+	t.Parallel()
+
+	// mod11 accepts digits whose weighted sum, weights 1, 2, 3, ... from the
+	// left, is a multiple of 11
+	mod11 := CheckDigitFunc(func(value string) bool {
+		sum := 0
+		for i, c := range value {
+			if c < '0' || c > '9' {
+				return false
+			}
+			sum += (i + 1) * int(c-'0')
+		}
+		return value != "" && sum%11 == 0
+	})
+	if err := RegisterValidator("test_mod11", mod11); err != nil {
+		t.Fatalf("RegisterValidator() error = %v", err)
+	}
+
+	t.Run("registered tag validates values", func(t *testing.T) {
+		t.Parallel()
+
+		type Person struct {
+			ID   string `validate:"test_mod11"`
+			Alt  string `validate:"omitempty,or(test_mod11|luhn)"`
+			Name string
+		}
+		input := "id,alt,name\n15,,Kai\n16,79927398713,Sam\n"
+		var people []Person
+		_, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader(input), &people)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		errs := result.ValidationErrors()
+		if len(errs) != 1 {
+			t.Fatalf("expected 1 error, got %d: %v", len(errs), errs)
+		}
+		if errs[0].Row != 2 || errs[0].Tag != "test_mod11" {
+			t.Errorf("error = row %d tag %q, want row 2 tag test_mod11", errs[0].Row, errs[0].Tag)
+		}
+		if errs[0].Message() != "value must have a valid test_mod11 check digit" {
+			t.Errorf("Message() = %q", errs[0].Message())
+		}
+	})
+
+	t.Run("registered tag takes no parameter", func(t *testing.T) {
+		t.Parallel()
+
+		if _, _, err := parseValidateTag("test_mod11=3", true); !errors.Is(err, ErrInvalidTagFormat) {
+			t.Errorf("parseValidateTag() error = %v, want ErrInvalidTagFormat", err)
+		}
+	})
+
+	t.Run("invalid registrations", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name  string
+			tag   string
+			check CheckDigit
+		}{
+			{name: "already registered", tag: "test_mod11", check: mod11},
+			{name: "built-in validator", tag: "email", check: mod11},
+			{name: "built-in cross-field validator", tag: "eqfield", check: mod11},
+			{name: "validator group", tag: "or", check: mod11},
+			{name: "value list validator", tag: "not_in_list", check: mod11},
+			{name: "value list enumeration", tag: "oneof_file", check: mod11},
+			{name: "tag with parameter", tag: "mod=11", check: mod11},
+			{name: "empty tag", tag: "", check: mod11},
+			{name: "nil check", tag: "test_nil", check: nil},
+		}
+		for _, tt := range tests {
+			if err := RegisterValidator(tt.tag, tt.check); !errors.Is(err, ErrInvalidOption) {
+				t.Errorf("%s: RegisterValidator(%q) error = %v, want ErrInvalidOption", tt.name, tt.tag, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,212 @@
+package fileprep
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// columnKind selects what a ColumnType coerces values to.
+type columnKind int
+
+const (
+	columnKindInt columnKind = iota + 1
+	columnKindFloat
+	columnKindDecimal
+	columnKindBool
+)
+
+// ColumnType is the type of a column set with WithColumnTypes. The zero
+// value is not a valid type; use ColumnInt, ColumnFloat, ColumnDecimal, or
+// ColumnBool.
+type ColumnType struct {
+	kind  columnKind
+	scale int // digits after the decimal point, for ColumnDecimal
+}
+
+// ColumnInt is an integer column. Integral numbers such as "1.0" and
+// "+1" are rewritten as "1".
+func ColumnInt() ColumnType {
+	return ColumnType{kind: columnKindInt}
+}
+
+// ColumnFloat is a number column. Numbers are rewritten in plain decimal
+// notation, such as "1e3" as "1000".
+func ColumnFloat() ColumnType {
+	return ColumnType{kind: columnKindFloat}
+}
+
+// ColumnDecimal is a fixed-point column with scale digits after the
+// decimal point, such as amounts of money with ColumnDecimal(2). Values are
+// padded to scale digits, so "1.5" becomes "1.50"; values with more
+// non-zero digits are invalid rather than rounded.
+func ColumnDecimal(scale int) ColumnType {
+	return ColumnType{kind: columnKindDecimal, scale: scale}
+}
+
+// ColumnBool is a boolean column. true, 1, yes, and on are rewritten as
+// "true", and false, 0, no, and off as "false", ignoring case.
+func ColumnBool() ColumnType {
+	return ColumnType{kind: columnKindBool}
+}
+
+// String returns the name of the type, such as "int" or "decimal(2)"
+func (t ColumnType) String() string {
+	switch t.kind {
+	case columnKindInt:
+		return "int"
+	case columnKindFloat:
+		return "float"
+	case columnKindDecimal:
+		return "decimal(" + strconv.Itoa(t.scale) + ")"
+	case columnKindBool:
+		return "bool"
+	default:
+		return "invalid"
+	}
+}
+
+// name returns the name of the type without its scale, reported as the
+// tag of its validation errors.
+func (t ColumnType) name() string {
+	if t.kind == columnKindDecimal {
+		return "decimal"
+	}
+	return t.String()
+}
+
+// valid reports whether t was made by one of the ColumnType constructors.
+func (t ColumnType) valid() bool {
+	return t.kind != 0 && t.scale >= 0
+}
+
+// coerce returns value converted to the type's canonical form, and false
+// if value is not of the type. Surrounding spaces are ignored.
+func (t ColumnType) coerce(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	switch t.kind {
+	case columnKindInt:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return strconv.FormatInt(n, 10), true
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f != math.Trunc(f) || math.Abs(f) >= math.MaxInt64 {
+			return "", false
+		}
+		return strconv.FormatInt(int64(f), 10), true
+	case columnKindFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", false
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), true
+	case columnKindDecimal:
+		return normalizeDecimal(value, t.scale)
+	case columnKindBool:
+		switch strings.ToLower(value) {
+		case boolTrueValue, "1", "yes", "on":
+			return boolTrueValue, true
+		case boolFalseValue, "0", "no", "off":
+			return boolFalseValue, true
+		}
+	}
+	return "", false
+}
+
+// columnTypePreprocessor rewrites values of a ColumnType in its canonical
+// form. Values that are not of the type are left for the validator.
+type columnTypePreprocessor struct {
+	columnType ColumnType
+}
+
+// Process returns the canonical form of value, or value itself
+func (p *columnTypePreprocessor) Process(value string) string {
+	if coerced, ok := p.columnType.coerce(value); ok {
+		return coerced
+	}
+	return value
+}
+
+// Name returns the preprocessor name
+func (p *columnTypePreprocessor) Name() string {
+	return p.columnType.name()
+}
+
+// columnTypeValidator validates that non-empty values are of a ColumnType.
+type columnTypeValidator struct {
+	columnType ColumnType
+	errMsg     string
+}
+
+// newColumnTypeValidator creates a new validator for t
+func newColumnTypeValidator(t ColumnType) *columnTypeValidator {
+	errMsg := "value must be an integer"
+	switch t.kind {
+	case columnKindFloat:
+		errMsg = "value must be a number"
+	case columnKindDecimal:
+		errMsg = "value must be a number with at most " + strconv.Itoa(t.scale) + " decimal places"
+	case columnKindBool:
+		errMsg = "value must be a boolean"
+	}
+	return &columnTypeValidator{columnType: t, errMsg: errMsg}
+}
+
+// Validate checks that value is empty or of the column type
+func (v *columnTypeValidator) Validate(value string) string {
+	if value == "" {
+		return ""
+	}
+	if _, ok := v.columnType.coerce(value); !ok {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *columnTypeValidator) Name() string {
+	return v.columnType.name()
+}
+
+// withColumnTypes returns a copy of the struct info in which the columns of
+// types are coerced and validated before the rules of the fields bound to
+// them. Columns no field is bound to get a field of their own. It returns
+// an error wrapping ErrInvalidOption for a ColumnType not made by its
+// constructors.
+func (si *structInfo) withColumnTypes(types map[string]ColumnType) (*structInfo, error) {
+	fields := slices.Clone(si.Fields)
+	for _, column := range slices.Sorted(maps.Keys(types)) {
+		t := types[column]
+		if !t.valid() {
+			return nil, fmt.Errorf("%w: column %q has an invalid type %s", ErrInvalidOption, column, t)
+		}
+		var v Validator = newColumnTypeValidator(t)
+		if t.kind == columnKindDecimal {
+			v = &paramValidator{Validator: v, param: strconv.Itoa(t.scale)}
+		}
+		prep := &columnTypePreprocessor{columnType: t}
+
+		bound := false
+		for i, fi := range fields {
+			if fi.ColumnName != column {
+				continue
+			}
+			fields[i].Preprocessors = append(preprocessors{prep}, fi.Preprocessors...)
+			fields[i].Validators = append(validators{v}, fi.Validators...)
+			bound = true
+		}
+		if !bound {
+			fields = append(fields, fieldInfo{
+				ColumnName:    column,
+				Index:         -1,
+				ColumnIndex:   -1,
+				Preprocessors: preprocessors{prep},
+				Validators:    validators{v},
+			})
+		}
+	}
+	return &structInfo{Fields: fields}, nil
+}
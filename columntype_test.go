@@ -0,0 +1,69 @@
+package fileprep
+
+import (
+	"testing"
+)
+
+func TestColumnType_Coerce(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		columnType ColumnType
+		value      string
+		want       string
+		wantOK     bool
+	}{
+		{name: "int", columnType: ColumnInt(), value: "42", want: "42", wantOK: true},
+		{name: "int with sign and spaces", columnType: ColumnInt(), value: " +42 ", want: "42", wantOK: true},
+		{name: "int from integral float", columnType: ColumnInt(), value: "42.0", want: "42", wantOK: true},
+		{name: "int from fraction", columnType: ColumnInt(), value: "42.5", wantOK: false},
+		{name: "int from text", columnType: ColumnInt(), value: "forty", wantOK: false},
+		{name: "float", columnType: ColumnFloat(), value: "1.50", want: "1.5", wantOK: true},
+		{name: "float from exponent", columnType: ColumnFloat(), value: "1e3", want: "1000", wantOK: true},
+		{name: "float from NaN", columnType: ColumnFloat(), value: "NaN", wantOK: false},
+		{name: "decimal pads the fraction", columnType: ColumnDecimal(2), value: "1.5", want: "1.50", wantOK: true},
+		{name: "decimal from integer", columnType: ColumnDecimal(2), value: "12", want: "12.00", wantOK: true},
+		{name: "decimal trims zeros", columnType: ColumnDecimal(2), value: "+007.1000", want: "7.10", wantOK: true},
+		{name: "decimal without whole digits", columnType: ColumnDecimal(2), value: "-.5", want: "-0.50", wantOK: true},
+		{name: "decimal negative zero", columnType: ColumnDecimal(2), value: "-0.0", want: "0.00", wantOK: true},
+		{name: "decimal with scale 0", columnType: ColumnDecimal(0), value: "3.0", want: "3", wantOK: true},
+		{name: "decimal with too many digits", columnType: ColumnDecimal(2), value: "1.005", wantOK: false},
+		{name: "decimal from exponent", columnType: ColumnDecimal(2), value: "1e3", wantOK: false},
+		{name: "decimal from point only", columnType: ColumnDecimal(2), value: ".", wantOK: false},
+		{name: "bool true", columnType: ColumnBool(), value: "Yes", want: "true", wantOK: true},
+		{name: "bool false", columnType: ColumnBool(), value: "0", want: "false", wantOK: true},
+		{name: "bool from text", columnType: ColumnBool(), value: "maybe", wantOK: false},
+		{name: "empty", columnType: ColumnInt(), value: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := tt.columnType.coerce(tt.value)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("%s.coerce(%q) = %q, %v, want %q, %v", tt.columnType, tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestColumnType_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		columnType ColumnType
+		want       string
+	}{
+		{ColumnInt(), "int"},
+		{ColumnFloat(), "float"},
+		{ColumnDecimal(2), "decimal(2)"},
+		{ColumnBool(), "bool"},
+		{ColumnType{}, "invalid"},
+	}
+	for _, tt := range tests {
+		if got := tt.columnType.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package fileprep
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/nao1215/fileparser"
+)
+
+// Convert reads r as a file of type from and writes it to w as type to,
+// without prep or validate tags, such as XLSX to CSV or Parquet to TSV.
+// Compressed input is decompressed; the output is written uncompressed and
+// to must be FileTypeCSV, FileTypeTSV, FileTypeLTSV, FileTypeJSONL, or
+// FileTypeXLSX. Other output types return an error wrapping
+// ErrUnsupportedFileType. XLSX output is written like WithOutputFormat
+// writes it: one Data sheet with a bold header row and text cells.
+//
+// Tabular rows become one JSON object per line in JSONL, with the columns
+// as string members in header order. JSON and JSONL input keeps its single
+// "data" column, so it converts to JSONL unchanged and to the other types
+// as one column of raw JSON.
+//
+// Example:
+//
+//	var csv bytes.Buffer
+//	if err := fileprep.Convert(xlsxFile, fileprep.FileTypeXLSX, fileprep.FileTypeCSV, &csv); err != nil {
+//	    log.Fatal(err)
+//	}
+func Convert(r io.Reader, from, to FileType, w io.Writer) error {
+	switch to {
+	case fileparser.CSV, fileparser.TSV, fileparser.LTSV, fileparser.JSONL, fileparser.XLSX:
+	default:
+		return fmt.Errorf("%w: Convert writes CSV, TSV, LTSV, JSONL, or XLSX, got %s", ErrUnsupportedFileType, to)
+	}
+	if w == nil {
+		return fmt.Errorf("%w: writer cannot be nil", ErrInvalidOption)
+	}
+
+	src := NewProcessor(from)
+	data, err := readDecompressed(r, from, 0)
+	if err != nil {
+		return err
+	}
+	if err := checkEmptyInput(data, from); err != nil {
+		return err
+	}
+	tableData, _, err := src.parse(data)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrParse, err)
+	}
+
+	headers, records := tableData.Headers, tableData.Records
+	baseFrom := fileparser.BaseFileType(from)
+	if to == fileparser.JSONL && baseFrom != fileparser.JSON && baseFrom != fileparser.JSONL {
+		if records, err = jsonObjectRecords(headers, records); err != nil {
+			return err
+		}
+	}
+
+	if to == fileparser.XLSX {
+		if err := writeXLSX(w, headers, records, nil); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := NewProcessor(to).writeOutput(bw, headers, records); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return bw.Flush()
+}
+
+// jsonObjectRecords encodes each tabular record as a JSON object whose
+// members are the columns in header order, in the single-column layout
+// writeJSONL expects.
+func jsonObjectRecords(headers []string, records [][]string) ([][]string, error) {
+	keys := make([][]byte, len(headers))
+	for i, header := range headers {
+		key, err := json.Marshal(header)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+	}
+
+	objects := make([][]string, len(records))
+	var buf bytes.Buffer
+	for i, record := range records {
+		buf.Reset()
+		buf.WriteByte('{')
+		for col, key := range keys {
+			if col > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			value, err := json.Marshal(cell(record, col))
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(value)
+		}
+		buf.WriteByte('}')
+		objects[i] = []string{buf.String()}
+	}
+	return objects, nil
+}
@@ -0,0 +1,146 @@
+package fileprep
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileparser"
+)
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+
+	gzipped := func(t *testing.T, s string) *bytes.Buffer {
+		t.Helper()
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return &buf
+	}
+
+	tests := []struct {
+		name  string
+		input func(t *testing.T) *bytes.Buffer
+		from  FileType
+		to    FileType
+		want  string
+	}{
+		{
+			name:  "CSV to TSV",
+			input: func(*testing.T) *bytes.Buffer { return bytes.NewBufferString("name,city\nalice,\"Tokyo, JP\"\n") },
+			from:  fileparser.CSV,
+			to:    fileparser.TSV,
+			want:  "name\tcity\nalice\tTokyo, JP\n",
+		},
+		{
+			name:  "CSV to LTSV",
+			input: func(*testing.T) *bytes.Buffer { return bytes.NewBufferString("name,age\nalice,30\nbob,\n") },
+			from:  fileparser.CSV,
+			to:    fileparser.LTSV,
+			want:  "name:alice\tage:30\nname:bob\tage:\n",
+		},
+		{
+			name:  "CSV to JSONL",
+			input: func(*testing.T) *bytes.Buffer { return bytes.NewBufferString("name,note\nalice,\"say \"\"hi\"\"\"\n") },
+			from:  fileparser.CSV,
+			to:    fileparser.JSONL,
+			want:  `{"name":"alice","note":"say \"hi\""}` + "\n",
+		},
+		{
+			name:  "compressed TSV to CSV",
+			input: func(t *testing.T) *bytes.Buffer { return gzipped(t, "id\tname\n1\talice\n") },
+			from:  fileparser.TSVGZ,
+			to:    fileparser.CSV,
+			want:  "id,name\n1,alice\n",
+		},
+		{
+			name:  "JSONL to JSONL",
+			input: func(*testing.T) *bytes.Buffer { return bytes.NewBufferString("{\"a\": 1}\n{\"a\": 2}\n") },
+			from:  fileparser.JSONL,
+			to:    fileparser.JSONL,
+			want:  "{\"a\":1}\n{\"a\":2}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			if err := Convert(tt.input(t), tt.from, tt.to, &out); err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, out.String()); diff != "" {
+				t.Errorf("Convert() output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConvert_XLSXOutput(t *testing.T) {
+	t.Parallel()
+
+	input := "name,city\nalice,\"Tokyo, JP\"\nbob,\n"
+	var xlsx bytes.Buffer
+	if err := Convert(strings.NewReader(input), fileparser.CSV, fileparser.XLSX, &xlsx); err != nil {
+		t.Fatalf("Convert() to XLSX error = %v", err)
+	}
+
+	var csv bytes.Buffer
+	if err := Convert(&xlsx, fileparser.XLSX, fileparser.CSV, &csv); err != nil {
+		t.Fatalf("Convert() from XLSX error = %v", err)
+	}
+	if diff := cmp.Diff(input, csv.String()); diff != "" {
+		t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestConvert_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		from    FileType
+		to      FileType
+		wantErr error
+	}{
+		{name: "Parquet output", input: "a\n1\n", from: fileparser.CSV, to: fileparser.Parquet, wantErr: ErrUnsupportedFileType},
+		{name: "compressed output", input: "a\n1\n", from: fileparser.CSV, to: fileparser.CSVGZ, wantErr: ErrUnsupportedFileType},
+		{name: "empty input", input: "", from: fileparser.CSV, to: fileparser.TSV, wantErr: ErrNoHeader},
+		{name: "corrupt compressed input", input: "not gzip", from: fileparser.CSVGZ, to: fileparser.CSV, wantErr: ErrDecompression},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var out bytes.Buffer
+			err := Convert(strings.NewReader(tt.input), tt.from, tt.to, &out)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Convert() error = %v, want %v", err, tt.wantErr)
+			}
+			if out.Len() != 0 {
+				t.Errorf("Convert() wrote %q on error", out.String())
+			}
+		})
+	}
+
+	t.Run("nil reader", func(t *testing.T) {
+		t.Parallel()
+
+		var out bytes.Buffer
+		if err := Convert(nil, fileparser.CSV, fileparser.TSV, &out); !errors.Is(err, ErrNilReader) {
+			t.Errorf("Convert() error = %v, want ErrNilReader", err)
+		}
+	})
+}
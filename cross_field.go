@@ -1,6 +1,10 @@
 package fileprep
 
 import (
+	"encoding/hex"
+	"maps"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -22,6 +26,7 @@ type crossFieldValidators []CrossFieldValidator
 // baseCrossFieldValidator contains common fields for cross-field validators
 type baseCrossFieldValidator struct {
 	targetField string
+	errMsg      string // pre-built error message
 }
 
 // TargetField returns the name of the field to compare against
@@ -39,13 +44,16 @@ type eqFieldValidator struct {
 
 // newEqFieldValidator creates a new equal field validator
 func newEqFieldValidator(targetField string) *eqFieldValidator {
-	return &eqFieldValidator{baseCrossFieldValidator{targetField: targetField}}
+	return &eqFieldValidator{baseCrossFieldValidator{
+		targetField: targetField,
+		errMsg:      "value must equal field " + targetField,
+	}}
 }
 
 // Validate checks if the source value equals the target value
 func (v *eqFieldValidator) Validate(srcValue, targetValue string) string {
 	if srcValue != targetValue {
-		return "value must equal field " + v.targetField
+		return v.errMsg
 	}
 	return ""
 }
@@ -65,13 +73,16 @@ type neFieldValidator struct {
 
 // newNeFieldValidator creates a new not equal field validator
 func newNeFieldValidator(targetField string) *neFieldValidator {
-	return &neFieldValidator{baseCrossFieldValidator{targetField: targetField}}
+	return &neFieldValidator{baseCrossFieldValidator{
+		targetField: targetField,
+		errMsg:      "value must not equal field " + targetField,
+	}}
 }
 
 // Validate checks if the source value does not equal the target value
 func (v *neFieldValidator) Validate(srcValue, targetValue string) string {
 	if srcValue == targetValue {
-		return "value must not equal field " + v.targetField
+		return v.errMsg
 	}
 	return ""
 }
@@ -91,7 +102,10 @@ type gtFieldValidator struct {
 
 // newGtFieldValidator creates a new greater than field validator
 func newGtFieldValidator(targetField string) *gtFieldValidator {
-	return &gtFieldValidator{baseCrossFieldValidator{targetField: targetField}}
+	return &gtFieldValidator{baseCrossFieldValidator{
+		targetField: targetField,
+		errMsg:      "value must be greater than field " + targetField,
+	}}
 }
 
 // Validate checks if the source value is greater than the target value
@@ -99,18 +113,16 @@ func (v *gtFieldValidator) Validate(srcValue, targetValue string) string {
 	srcFloat, srcErr := strconv.ParseFloat(srcValue, 64)
 	targetFloat, targetErr := strconv.ParseFloat(targetValue, 64)
 
-	errMsg := "value must be greater than field " + v.targetField
-
 	if srcErr != nil || targetErr != nil {
 		// Fall back to string comparison
 		if srcValue <= targetValue {
-			return errMsg
+			return v.errMsg
 		}
 		return ""
 	}
 
 	if srcFloat <= targetFloat {
-		return errMsg
+		return v.errMsg
 	}
 	return ""
 }
@@ -130,7 +142,10 @@ type gteFieldValidator struct {
 
 // newGteFieldValidator creates a new greater than or equal field validator
 func newGteFieldValidator(targetField string) *gteFieldValidator {
-	return &gteFieldValidator{baseCrossFieldValidator{targetField: targetField}}
+	return &gteFieldValidator{baseCrossFieldValidator{
+		targetField: targetField,
+		errMsg:      "value must be greater than or equal to field " + targetField,
+	}}
 }
 
 // Validate checks if the source value is greater than or equal to the target value
@@ -138,18 +153,16 @@ func (v *gteFieldValidator) Validate(srcValue, targetValue string) string {
 	srcFloat, srcErr := strconv.ParseFloat(srcValue, 64)
 	targetFloat, targetErr := strconv.ParseFloat(targetValue, 64)
 
-	errMsg := "value must be greater than or equal to field " + v.targetField
-
 	if srcErr != nil || targetErr != nil {
 		// Fall back to string comparison
 		if srcValue < targetValue {
-			return errMsg
+			return v.errMsg
 		}
 		return ""
 	}
 
 	if srcFloat < targetFloat {
-		return errMsg
+		return v.errMsg
 	}
 	return ""
 }
@@ -169,7 +182,10 @@ type ltFieldValidator struct {
 
 // newLtFieldValidator creates a new less than field validator
 func newLtFieldValidator(targetField string) *ltFieldValidator {
-	return &ltFieldValidator{baseCrossFieldValidator{targetField: targetField}}
+	return &ltFieldValidator{baseCrossFieldValidator{
+		targetField: targetField,
+		errMsg:      "value must be less than field " + targetField,
+	}}
 }
 
 // Validate checks if the source value is less than the target value
@@ -177,18 +193,16 @@ func (v *ltFieldValidator) Validate(srcValue, targetValue string) string {
 	srcFloat, srcErr := strconv.ParseFloat(srcValue, 64)
 	targetFloat, targetErr := strconv.ParseFloat(targetValue, 64)
 
-	errMsg := "value must be less than field " + v.targetField
-
 	if srcErr != nil || targetErr != nil {
 		// Fall back to string comparison
 		if srcValue >= targetValue {
-			return errMsg
+			return v.errMsg
 		}
 		return ""
 	}
 
 	if srcFloat >= targetFloat {
-		return errMsg
+		return v.errMsg
 	}
 	return ""
 }
@@ -208,7 +222,10 @@ type lteFieldValidator struct {
 
 // newLteFieldValidator creates a new less than or equal field validator
 func newLteFieldValidator(targetField string) *lteFieldValidator {
-	return &lteFieldValidator{baseCrossFieldValidator{targetField: targetField}}
+	return &lteFieldValidator{baseCrossFieldValidator{
+		targetField: targetField,
+		errMsg:      "value must be less than or equal to field " + targetField,
+	}}
 }
 
 // Validate checks if the source value is less than or equal to the target value
@@ -216,18 +233,16 @@ func (v *lteFieldValidator) Validate(srcValue, targetValue string) string {
 	srcFloat, srcErr := strconv.ParseFloat(srcValue, 64)
 	targetFloat, targetErr := strconv.ParseFloat(targetValue, 64)
 
-	errMsg := "value must be less than or equal to field " + v.targetField
-
 	if srcErr != nil || targetErr != nil {
 		// Fall back to string comparison
 		if srcValue > targetValue {
-			return errMsg
+			return v.errMsg
 		}
 		return ""
 	}
 
 	if srcFloat > targetFloat {
-		return errMsg
+		return v.errMsg
 	}
 	return ""
 }
@@ -247,13 +262,16 @@ type fieldContainsValidator struct {
 
 // newFieldContainsValidator creates a new field contains validator
 func newFieldContainsValidator(targetField string) *fieldContainsValidator {
-	return &fieldContainsValidator{baseCrossFieldValidator{targetField: targetField}}
+	return &fieldContainsValidator{baseCrossFieldValidator{
+		targetField: targetField,
+		errMsg:      "value must contain field " + targetField + " value",
+	}}
 }
 
 // Validate checks if the source value contains the target value
 func (v *fieldContainsValidator) Validate(srcValue, targetValue string) string {
 	if !strings.Contains(srcValue, targetValue) {
-		return "value must contain field " + v.targetField + " value"
+		return v.errMsg
 	}
 	return ""
 }
@@ -273,13 +291,16 @@ type fieldExcludesValidator struct {
 
 // newFieldExcludesValidator creates a new field excludes validator
 func newFieldExcludesValidator(targetField string) *fieldExcludesValidator {
-	return &fieldExcludesValidator{baseCrossFieldValidator{targetField: targetField}}
+	return &fieldExcludesValidator{baseCrossFieldValidator{
+		targetField: targetField,
+		errMsg:      "value must not contain field " + targetField + " value",
+	}}
 }
 
 // Validate checks if the source value does not contain the target value
 func (v *fieldExcludesValidator) Validate(srcValue, targetValue string) string {
 	if strings.Contains(srcValue, targetValue) {
-		return "value must not contain field " + v.targetField + " value"
+		return v.errMsg
 	}
 	return ""
 }
@@ -289,6 +310,113 @@ func (v *fieldExcludesValidator) Name() string {
 	return fieldExcludesTagValue
 }
 
+// =====================================
+// withinValidator - Within a tolerance of another field
+// =====================================
+
+// toleranceEpsilon absorbs float rounding when a difference is compared to
+// a tolerance, so 10.05 is within 0.05 of 10.
+const toleranceEpsilon = 1e-9
+
+// withinTolerance reports whether diff does not exceed limit, allowing for
+// float rounding
+func withinTolerance(diff, limit float64) bool {
+	return diff-limit <= toleranceEpsilon*math.Max(1, math.Abs(limit))
+}
+
+// withinValidator validates that a numeric field is within an absolute or
+// relative tolerance of another field, such as a reported total and a
+// computed one. Empty values are not checked.
+type withinValidator struct {
+	baseCrossFieldValidator
+	tolerance float64
+	relative  bool // tolerance is a fraction of the target value
+}
+
+// newWithinValidator creates a new within validator. tolerance is the text
+// of the tag, such as "0.05" or "5%", and is used in the error message.
+func newWithinValidator(targetField string, tolerance float64, relative bool, toleranceText string) *withinValidator {
+	return &withinValidator{
+		baseCrossFieldValidator: baseCrossFieldValidator{
+			targetField: targetField,
+			errMsg:      "value must be within " + toleranceText + " of field " + targetField,
+		},
+		tolerance: tolerance,
+		relative:  relative,
+	}
+}
+
+// Validate checks if the source value is within the tolerance of the target
+// value. Non-numeric values fail.
+func (v *withinValidator) Validate(srcValue, targetValue string) string {
+	if srcValue == "" {
+		return ""
+	}
+	src, srcErr := strconv.ParseFloat(srcValue, 64)
+	target, targetErr := strconv.ParseFloat(targetValue, 64)
+	if srcErr != nil || targetErr != nil {
+		return v.errMsg
+	}
+	limit := v.tolerance
+	if v.relative {
+		limit *= math.Abs(target)
+	}
+	if !withinTolerance(math.Abs(src-target), limit) {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *withinValidator) Name() string {
+	return withinTagValue
+}
+
+// =====================================
+// checksumValidator - Hash digest of another field
+// =====================================
+
+// checksumValidator validates that a field holds the hex digest of another
+// field's value, such as a sha256 column shipped next to a payload column.
+// Empty values are not checked; combine with required to demand one.
+type checksumValidator struct {
+	baseCrossFieldValidator
+	algorithm HashAlgorithm
+}
+
+// newChecksumValidator creates a new checksum validator
+func newChecksumValidator(algorithm HashAlgorithm, targetField string) *checksumValidator {
+	return &checksumValidator{
+		baseCrossFieldValidator: baseCrossFieldValidator{
+			targetField: targetField,
+			errMsg:      "value must be the " + algorithm.String() + " checksum of field " + targetField,
+		},
+		algorithm: algorithm,
+	}
+}
+
+// Validate checks if the source value is the hex digest of the target value.
+// Hex digits are compared case-insensitively.
+func (v *checksumValidator) Validate(srcValue, targetValue string) string {
+	if srcValue == "" {
+		return ""
+	}
+	h, err := v.algorithm.newHash()
+	if err != nil {
+		return v.errMsg
+	}
+	h.Write([]byte(targetValue))
+	if !strings.EqualFold(srcValue, hex.EncodeToString(h.Sum(nil))) {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *checksumValidator) Name() string {
+	return checksumTagValue
+}
+
 // =====================================
 // requiredIfValidator - Required if another field equals a specific value
 // =====================================
@@ -303,8 +431,11 @@ type requiredIfValidator struct {
 // targetField is the field name, expectedValue is the value that triggers the requirement
 func newRequiredIfValidator(targetField, expectedValue string) *requiredIfValidator {
 	return &requiredIfValidator{
-		baseCrossFieldValidator: baseCrossFieldValidator{targetField: targetField},
-		expectedValue:           expectedValue,
+		baseCrossFieldValidator: baseCrossFieldValidator{
+			targetField: targetField,
+			errMsg:      "value is required when " + targetField + " is " + expectedValue,
+		},
+		expectedValue: expectedValue,
 	}
 }
 
@@ -312,7 +443,7 @@ func newRequiredIfValidator(targetField, expectedValue string) *requiredIfValida
 func (v *requiredIfValidator) Validate(srcValue, targetValue string) string {
 	// If target field equals expected value, source field is required
 	if targetValue == v.expectedValue && srcValue == "" {
-		return "value is required when " + v.targetField + " is " + v.expectedValue
+		return v.errMsg
 	}
 	return ""
 }
@@ -336,8 +467,11 @@ type requiredUnlessValidator struct {
 // targetField is the field name, exceptValue is the value that exempts the requirement
 func newRequiredUnlessValidator(targetField, exceptValue string) *requiredUnlessValidator {
 	return &requiredUnlessValidator{
-		baseCrossFieldValidator: baseCrossFieldValidator{targetField: targetField},
-		exceptValue:             exceptValue,
+		baseCrossFieldValidator: baseCrossFieldValidator{
+			targetField: targetField,
+			errMsg:      "value is required unless " + targetField + " is " + exceptValue,
+		},
+		exceptValue: exceptValue,
 	}
 }
 
@@ -345,7 +479,7 @@ func newRequiredUnlessValidator(targetField, exceptValue string) *requiredUnless
 func (v *requiredUnlessValidator) Validate(srcValue, targetValue string) string {
 	// If target field does NOT equal except value, source field is required
 	if targetValue != v.exceptValue && srcValue == "" {
-		return "value is required unless " + v.targetField + " is " + v.exceptValue
+		return v.errMsg
 	}
 	return ""
 }
@@ -366,14 +500,17 @@ type requiredWithValidator struct {
 
 // newRequiredWithValidator creates a new required_with validator
 func newRequiredWithValidator(targetField string) *requiredWithValidator {
-	return &requiredWithValidator{baseCrossFieldValidator{targetField: targetField}}
+	return &requiredWithValidator{baseCrossFieldValidator{
+		targetField: targetField,
+		errMsg:      "value is required when " + targetField + " is present",
+	}}
 }
 
 // Validate checks if the source value is present when target field is non-empty
 func (v *requiredWithValidator) Validate(srcValue, targetValue string) string {
 	// If target field is present (non-empty), source field is required
 	if targetValue != "" && srcValue == "" {
-		return "value is required when " + v.targetField + " is present"
+		return v.errMsg
 	}
 	return ""
 }
@@ -394,14 +531,17 @@ type requiredWithoutValidator struct {
 
 // newRequiredWithoutValidator creates a new required_without validator
 func newRequiredWithoutValidator(targetField string) *requiredWithoutValidator {
-	return &requiredWithoutValidator{baseCrossFieldValidator{targetField: targetField}}
+	return &requiredWithoutValidator{baseCrossFieldValidator{
+		targetField: targetField,
+		errMsg:      "value is required when " + targetField + " is absent",
+	}}
 }
 
 // Validate checks if the source value is present when target field is empty
 func (v *requiredWithoutValidator) Validate(srcValue, targetValue string) string {
 	// If target field is absent (empty), source field is required
 	if targetValue == "" && srcValue == "" {
-		return "value is required when " + v.targetField + " is absent"
+		return v.errMsg
 	}
 	return ""
 }
@@ -410,3 +550,384 @@ func (v *requiredWithoutValidator) Validate(srcValue, targetValue string) string
 func (v *requiredWithoutValidator) Name() string {
 	return requiredWithoutTagValue
 }
+
+// =====================================
+// Multi-field conditional validators
+// =====================================
+
+// multiFieldValidator is a cross-field validator that depends on several
+// target fields. The tag parameter is a space-separated field list, as in
+// go-playground/validator (e.g. required_with_all=Phone Email).
+// TargetField returns the list as written in the tag.
+type multiFieldValidator interface {
+	CrossFieldValidator
+	// TargetFields returns the names of the fields to inspect
+	TargetFields() []string
+	// ValidateFields checks the source value against the values of TargetFields, in order
+	ValidateFields(srcValue string, targetValues []string) string
+}
+
+// baseMultiFieldValidator contains common fields for multi-field validators
+type baseMultiFieldValidator struct {
+	baseCrossFieldValidator
+	targetFields []string
+}
+
+// newBaseMultiFieldValidator splits the field list and builds the error
+// message, e.g. "value is required when all of Phone, Email are present".
+// quantifier is "all" or "any"; state is "present" or "absent".
+func newBaseMultiFieldValidator(fieldList, msgPrefix, quantifier, state string) baseMultiFieldValidator {
+	fields := strings.Fields(fieldList)
+	condition := strings.Join(fields, ", ") + " is " + state
+	if len(fields) > 1 {
+		verb := " is "
+		if quantifier == "all" {
+			verb = " are "
+		}
+		condition = quantifier + " of " + strings.Join(fields, ", ") + verb + state
+	}
+	return baseMultiFieldValidator{
+		baseCrossFieldValidator: baseCrossFieldValidator{
+			targetField: strings.Join(fields, " "),
+			errMsg:      msgPrefix + " when " + condition,
+		},
+		targetFields: fields,
+	}
+}
+
+// TargetFields returns the names of the fields to inspect
+func (b *baseMultiFieldValidator) TargetFields() []string {
+	return b.targetFields
+}
+
+// countPresent returns the number of non-empty values
+func countPresent(values []string) int {
+	n := 0
+	for _, v := range values {
+		if v != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// requiredWithAllValidator validates that a field is required when all other fields are present
+type requiredWithAllValidator struct {
+	baseMultiFieldValidator
+}
+
+// newRequiredWithAllValidator creates a new required_with_all validator
+func newRequiredWithAllValidator(fieldList string) *requiredWithAllValidator {
+	return &requiredWithAllValidator{newBaseMultiFieldValidator(fieldList, "value is required", "all", "present")}
+}
+
+// Validate checks the source value against a single target value
+func (v *requiredWithAllValidator) Validate(srcValue, targetValue string) string {
+	return v.ValidateFields(srcValue, []string{targetValue})
+}
+
+// ValidateFields checks if the source value is present when every target is non-empty
+func (v *requiredWithAllValidator) ValidateFields(srcValue string, targetValues []string) string {
+	if srcValue == "" && countPresent(targetValues) == len(targetValues) {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *requiredWithAllValidator) Name() string {
+	return requiredWithAllTagValue
+}
+
+// requiredWithoutAllValidator validates that a field is required when all other fields are absent
+type requiredWithoutAllValidator struct {
+	baseMultiFieldValidator
+}
+
+// newRequiredWithoutAllValidator creates a new required_without_all validator
+func newRequiredWithoutAllValidator(fieldList string) *requiredWithoutAllValidator {
+	return &requiredWithoutAllValidator{newBaseMultiFieldValidator(fieldList, "value is required", "all", "absent")}
+}
+
+// Validate checks the source value against a single target value
+func (v *requiredWithoutAllValidator) Validate(srcValue, targetValue string) string {
+	return v.ValidateFields(srcValue, []string{targetValue})
+}
+
+// ValidateFields checks if the source value is present when every target is empty
+func (v *requiredWithoutAllValidator) ValidateFields(srcValue string, targetValues []string) string {
+	if srcValue == "" && countPresent(targetValues) == 0 {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *requiredWithoutAllValidator) Name() string {
+	return requiredWithoutAllTagValue
+}
+
+// excludedWithValidator validates that a field is empty when any other field is present
+type excludedWithValidator struct {
+	baseMultiFieldValidator
+}
+
+// newExcludedWithValidator creates a new excluded_with validator
+func newExcludedWithValidator(fieldList string) *excludedWithValidator {
+	return &excludedWithValidator{newBaseMultiFieldValidator(fieldList, "value must be empty", "any", "present")}
+}
+
+// Validate checks the source value against a single target value
+func (v *excludedWithValidator) Validate(srcValue, targetValue string) string {
+	return v.ValidateFields(srcValue, []string{targetValue})
+}
+
+// ValidateFields checks if the source value is empty when any target is non-empty
+func (v *excludedWithValidator) ValidateFields(srcValue string, targetValues []string) string {
+	if srcValue != "" && countPresent(targetValues) > 0 {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *excludedWithValidator) Name() string {
+	return excludedWithTagValue
+}
+
+// excludedWithoutValidator validates that a field is empty when any other field is absent
+type excludedWithoutValidator struct {
+	baseMultiFieldValidator
+}
+
+// newExcludedWithoutValidator creates a new excluded_without validator
+func newExcludedWithoutValidator(fieldList string) *excludedWithoutValidator {
+	return &excludedWithoutValidator{newBaseMultiFieldValidator(fieldList, "value must be empty", "any", "absent")}
+}
+
+// Validate checks the source value against a single target value
+func (v *excludedWithoutValidator) Validate(srcValue, targetValue string) string {
+	return v.ValidateFields(srcValue, []string{targetValue})
+}
+
+// ValidateFields checks if the source value is empty when any target is empty
+func (v *excludedWithoutValidator) ValidateFields(srcValue string, targetValues []string) string {
+	if srcValue != "" && countPresent(targetValues) < len(targetValues) {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *excludedWithoutValidator) Name() string {
+	return excludedWithoutTagValue
+}
+
+// =====================================
+// sumOfValidator - Sum of other fields
+// =====================================
+
+// sumOfValidator validates that a numeric field equals the sum of its
+// target fields, such as a total and its detail amounts or percent splits
+// and 100. Empty targets count as zero; an empty source is not checked.
+type sumOfValidator struct {
+	baseMultiFieldValidator
+	epsilon float64
+}
+
+// newSumOfValidator creates a new sumof validator. epsilonText is the
+// epsilon as written in the tag, or empty for an exact sum.
+func newSumOfValidator(fieldList string, epsilon float64, epsilonText string) *sumOfValidator {
+	fields := strings.Fields(fieldList)
+	errMsg := "value must equal the sum of " + strings.Join(fields, ", ")
+	if epsilonText != "" {
+		errMsg += " within " + epsilonText
+	}
+	return &sumOfValidator{
+		baseMultiFieldValidator: baseMultiFieldValidator{
+			baseCrossFieldValidator: baseCrossFieldValidator{targetField: strings.Join(fields, " "), errMsg: errMsg},
+			targetFields:            fields,
+		},
+		epsilon: epsilon,
+	}
+}
+
+// Validate checks the source value against a single target value
+func (v *sumOfValidator) Validate(srcValue, targetValue string) string {
+	return v.ValidateFields(srcValue, []string{targetValue})
+}
+
+// ValidateFields checks if the source value is the sum of the target
+// values within epsilon. Non-numeric values fail.
+func (v *sumOfValidator) ValidateFields(srcValue string, targetValues []string) string {
+	if srcValue == "" {
+		return ""
+	}
+	total, err := strconv.ParseFloat(srcValue, 64)
+	if err != nil {
+		return v.errMsg
+	}
+	sum := 0.0
+	for i, value := range targetValues {
+		if value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "field " + v.targetFields[i] + " must be a number to sum"
+		}
+		sum += f
+	}
+	if !withinTolerance(math.Abs(total-sum), v.epsilon) {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *sumOfValidator) Name() string {
+	return sumOfTagValue
+}
+
+// =====================================
+// exprValidator - Expression over the row
+// =====================================
+
+// exprValidator validates that an expression over the value and the fields
+// it names with other('Field') holds. An empty value is not checked.
+type exprValidator struct {
+	baseMultiFieldValidator
+	cond exprCond
+}
+
+// newExprValidator compiles expr. value refers to the validated value and
+// other('Field') to the value of another field. Syntax errors wrap
+// ErrInvalidTagFormat.
+func newExprValidator(expr string) (*exprValidator, error) {
+	var fields []string
+	c := &exprCompiler{expr: expr, label: exprTagValue, errSyn: ErrInvalidTagFormat}
+	c.name = func(name string, bracketed bool) (exprValue, error) {
+		if bracketed || name != "value" {
+			return nil, c.errorf("unknown name %q, use value or other('Field')", name)
+		}
+		return func(env []string) string { return env[0] }, nil
+	}
+	c.call = func(name, arg string) (exprValue, error) {
+		if name != "other" || arg == "" {
+			return nil, c.errorf("unknown function %s('%s'), use other('Field')", name, arg)
+		}
+		i := slices.Index(fields, arg)
+		if i < 0 {
+			i = len(fields)
+			fields = append(fields, arg)
+		}
+		envIdx := i + 1
+		return func(env []string) string { return env[envIdx] }, nil
+	}
+	cond, err := c.compile()
+	if err != nil {
+		return nil, err
+	}
+	return &exprValidator{
+		baseMultiFieldValidator: baseMultiFieldValidator{
+			baseCrossFieldValidator: baseCrossFieldValidator{
+				targetField: strings.Join(fields, " "),
+				errMsg:      "value must satisfy " + expr,
+			},
+			targetFields: fields,
+		},
+		cond: cond,
+	}, nil
+}
+
+// Validate checks the source value against a single target value
+func (v *exprValidator) Validate(srcValue, targetValue string) string {
+	return v.ValidateFields(srcValue, []string{targetValue})
+}
+
+// ValidateFields evaluates the expression with the source value and the
+// values of the fields it names
+func (v *exprValidator) ValidateFields(srcValue string, targetValues []string) string {
+	if srcValue == "" {
+		return ""
+	}
+	env := make([]string, 0, len(targetValues)+1)
+	env = append(env, srcValue)
+	env = append(env, targetValues...)
+	if !v.cond(env) {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *exprValidator) Name() string {
+	return exprTagValue
+}
+
+// =====================================
+// uniqueValidator - Unique across the whole file
+// =====================================
+
+// uniqueValidator validates that the combination of the field and its target
+// fields does not repeat in any other row. Rows where any key value is empty
+// are not compared, as with SQL UNIQUE constraints and NULL.
+// It must be bound to the file with withKeys; an unbound validator accepts
+// every value.
+type uniqueValidator struct {
+	baseMultiFieldValidator
+	rowsByKey map[string][]int // key -> rows sharing it, only keys that repeat
+}
+
+// newUniqueValidator creates a new unique validator. fieldList names the
+// other key fields and may be empty.
+func newUniqueValidator(fieldList string) *uniqueValidator {
+	fields := strings.Fields(fieldList)
+	return &uniqueValidator{baseMultiFieldValidator: baseMultiFieldValidator{
+		baseCrossFieldValidator: baseCrossFieldValidator{targetField: strings.Join(fields, " ")},
+		targetFields:            fields,
+	}}
+}
+
+// withKeys returns a copy of the validator bound to the key values of each
+// row. keys[i] holds the field's value followed by the target values for
+// row firstRow+i.
+func (v *uniqueValidator) withKeys(keys [][]string, firstRow int) *uniqueValidator {
+	rowsByKey := make(map[string][]int)
+	for i, key := range keys {
+		if slices.Contains(key, "") {
+			continue
+		}
+		k := strings.Join(key, duplicateKeySeparator)
+		rowsByKey[k] = append(rowsByKey[k], firstRow+i)
+	}
+	maps.DeleteFunc(rowsByKey, func(_ string, rows []int) bool { return len(rows) < 2 })
+	return &uniqueValidator{baseMultiFieldValidator: v.baseMultiFieldValidator, rowsByKey: rowsByKey}
+}
+
+// Validate checks the source value against a single target value
+func (v *uniqueValidator) Validate(srcValue, targetValue string) string {
+	return v.ValidateFields(srcValue, []string{targetValue})
+}
+
+// ValidateFields checks if the key formed by the source and target values
+// appears in more than one row, and lists every row of the group.
+func (v *uniqueValidator) ValidateFields(srcValue string, targetValues []string) string {
+	if srcValue == "" || slices.Contains(targetValues, "") {
+		return ""
+	}
+	rows := v.rowsByKey[strings.Join(append([]string{srcValue}, targetValues...), duplicateKeySeparator)]
+	if len(rows) < 2 {
+		return ""
+	}
+	rowList := make([]string, len(rows))
+	for i, row := range rows {
+		rowList[i] = strconv.Itoa(row)
+	}
+	return "value is not unique: rows " + strings.Join(rowList, ", ") + " share the same key"
+}
+
+// Name returns the validator name
+func (v *uniqueValidator) Name() string {
+	return uniqueTagValue
+}
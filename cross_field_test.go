@@ -441,6 +441,144 @@ func TestFieldExcludesValidator(t *testing.T) {
 	}
 }
 
+func TestWithinValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		tag         string
+		srcValue    string
+		targetValue string
+		wantErr     bool
+	}{
+		{name: "absolute equal", tag: "within=Total:0.05", srcValue: "10", targetValue: "10"},
+		{name: "absolute at the limit", tag: "within=Total:0.05", srcValue: "10.05", targetValue: "10"},
+		{name: "absolute below", tag: "within=Total:0.05", srcValue: "9.95", targetValue: "10"},
+		{name: "absolute beyond", tag: "within=Total:0.05", srcValue: "10.06", targetValue: "10", wantErr: true},
+		{name: "relative within", tag: "within=Total:5%", srcValue: "104", targetValue: "100"},
+		{name: "relative at the limit", tag: "within=Total:5%", srcValue: "95", targetValue: "100"},
+		{name: "relative beyond", tag: "within=Total:5%", srcValue: "106", targetValue: "100", wantErr: true},
+		{name: "relative to zero needs equality", tag: "within=Total:5%", srcValue: "0.01", targetValue: "0", wantErr: true},
+		{name: "zero tolerance", tag: "within=Total:0", srcValue: "1.5", targetValue: "1.50"},
+		{name: "non-numeric source", tag: "within=Total:1", srcValue: "abc", targetValue: "10", wantErr: true},
+		{name: "non-numeric target", tag: "within=Total:1", srcValue: "10", targetValue: "", wantErr: true},
+		{name: "empty source is not checked", tag: "within=Total:1", srcValue: "", targetValue: "10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, crossVals, err := parseValidateTag(tt.tag, true)
+			if err != nil || len(crossVals) != 1 {
+				t.Fatalf("parseValidateTag(%q) = %v, %v", tt.tag, crossVals, err)
+			}
+			v := crossVals[0]
+			got := v.Validate(tt.srcValue, tt.targetValue)
+			if (got != "") != tt.wantErr {
+				t.Errorf("withinValidator.Validate(%q, %q) = %q, wantErr %v", tt.srcValue, tt.targetValue, got, tt.wantErr)
+			}
+			if v.Name() != withinTagValue || v.TargetField() != "Total" {
+				t.Errorf("Name() = %q, TargetField() = %q", v.Name(), v.TargetField())
+			}
+		})
+	}
+}
+
+func TestChecksumValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		algorithm   HashAlgorithm
+		srcValue    string
+		targetValue string
+		wantErr     bool
+	}{
+		{
+			name:        "sha256 digest passes",
+			algorithm:   SHA256,
+			srcValue:    "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+			targetValue: "hello",
+		},
+		{
+			name:        "uppercase hex passes",
+			algorithm:   SHA256,
+			srcValue:    "2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824",
+			targetValue: "hello",
+		},
+		{
+			name:        "md5 digest passes",
+			algorithm:   MD5,
+			srcValue:    "5d41402abc4b2a76b9719d911017c592",
+			targetValue: "hello",
+		},
+		{
+			name:        "digest of another value fails",
+			algorithm:   SHA256,
+			srcValue:    "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+			targetValue: "hello!",
+			wantErr:     true,
+		},
+		{
+			name:        "digest of another algorithm fails",
+			algorithm:   SHA1,
+			srcValue:    "5d41402abc4b2a76b9719d911017c592",
+			targetValue: "hello",
+			wantErr:     true,
+		},
+		{
+			name:        "empty checksum is not checked",
+			algorithm:   SHA256,
+			srcValue:    "",
+			targetValue: "hello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			v := newChecksumValidator(tt.algorithm, "Payload")
+			got := v.Validate(tt.srcValue, tt.targetValue)
+			if (got != "") != tt.wantErr {
+				t.Errorf("checksumValidator.Validate() = %q, wantErr %v", got, tt.wantErr)
+			}
+			if v.Name() != checksumTagValue {
+				t.Errorf("checksumValidator.Name() = %q, want %q", v.Name(), checksumTagValue)
+			}
+		})
+	}
+}
+
+func TestChecksumValidation_Processor(t *testing.T) {
+	t.Parallel()
+
+	type Export struct {
+		Payload string
+		Digest  string `validate:"required,checksum=fnv64a:Payload"`
+	}
+
+	csvData := "payload,digest\nhello,a430d84680aabd0b\nhello,0000000000000000\nbye,\n"
+	var records []Export
+	_, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader(csvData), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	errs := result.ValidationErrors()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), errs)
+	}
+	if errs[0].Row != 2 || errs[0].Tag != checksumTagValue {
+		t.Errorf("first error = row %d tag %q, want row 2 tag %q", errs[0].Row, errs[0].Tag, checksumTagValue)
+	}
+	if errs[0].Message() != "value must be the fnv64a checksum of field Payload" {
+		t.Errorf("Message() = %q", errs[0].Message())
+	}
+	if errs[1].Row != 3 || errs[1].Tag != requiredTagValue {
+		t.Errorf("second error = row %d tag %q, want row 3 tag %q", errs[1].Row, errs[1].Tag, requiredTagValue)
+	}
+}
+
 func TestRequiredIfValidator(t *testing.T) {
 	t.Parallel()
 
@@ -661,6 +799,366 @@ func TestRequiredWithoutValidator(t *testing.T) {
 	}
 }
 
+func TestMultiFieldConditionalValidators(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		validator    multiFieldValidator
+		srcValue     string
+		targetValues []string
+		want         string
+	}{
+		{
+			name:         "required_with_all fails when all targets present",
+			validator:    newRequiredWithAllValidator("Phone Email"),
+			targetValues: []string{"555-1234", "a@example.com"},
+			want:         "value is required when all of Phone, Email are present",
+		},
+		{
+			name:         "required_with_all passes when one target absent",
+			validator:    newRequiredWithAllValidator("Phone Email"),
+			targetValues: []string{"555-1234", ""},
+		},
+		{
+			name:         "required_with_all passes when source present",
+			validator:    newRequiredWithAllValidator("Phone Email"),
+			srcValue:     "John",
+			targetValues: []string{"555-1234", "a@example.com"},
+		},
+		{
+			name:         "required_without_all fails when all targets absent",
+			validator:    newRequiredWithoutAllValidator("Phone Email"),
+			targetValues: []string{"", ""},
+			want:         "value is required when all of Phone, Email are absent",
+		},
+		{
+			name:         "required_without_all passes when one target present",
+			validator:    newRequiredWithoutAllValidator("Phone Email"),
+			targetValues: []string{"", "a@example.com"},
+		},
+		{
+			name:         "excluded_with fails when any target present",
+			validator:    newExcludedWithValidator("Phone Email"),
+			srcValue:     "x",
+			targetValues: []string{"", "a@example.com"},
+			want:         "value must be empty when any of Phone, Email is present",
+		},
+		{
+			name:         "excluded_with passes when source empty",
+			validator:    newExcludedWithValidator("Phone Email"),
+			targetValues: []string{"555-1234", "a@example.com"},
+		},
+		{
+			name:         "excluded_with passes when all targets absent",
+			validator:    newExcludedWithValidator("Phone"),
+			srcValue:     "x",
+			targetValues: []string{""},
+		},
+		{
+			name:         "excluded_without fails when single target absent",
+			validator:    newExcludedWithoutValidator("Phone"),
+			srcValue:     "x",
+			targetValues: []string{""},
+			want:         "value must be empty when Phone is absent",
+		},
+		{
+			name:         "excluded_without passes when all targets present",
+			validator:    newExcludedWithoutValidator("Phone Email"),
+			srcValue:     "x",
+			targetValues: []string{"555-1234", "a@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.validator.ValidateFields(tt.srcValue, tt.targetValues); got != tt.want {
+				t.Errorf("ValidateFields() = %q, want %q", got, tt.want)
+			}
+			if len(tt.validator.TargetFields()) != len(tt.targetValues) {
+				t.Errorf("TargetFields() = %v, want %d fields", tt.validator.TargetFields(), len(tt.targetValues))
+			}
+			if tt.validator.TargetField() != strings.Join(tt.validator.TargetFields(), " ") {
+				t.Errorf("TargetField() = %q, want space-separated TargetFields()", tt.validator.TargetField())
+			}
+		})
+	}
+}
+
+func TestMultiFieldConditionalValidation_Processor(t *testing.T) {
+	t.Parallel()
+
+	type Contact struct {
+		Phone    string
+		Email    string
+		Name     string `validate:"required_with_all=Phone Email"`
+		Fallback string `validate:"required_without_all=Phone Email"`
+		Fax      string `validate:"excluded_with=Phone Email"`
+		Pager    string `validate:"excluded_without=Phone"`
+	}
+
+	tests := []struct {
+		name     string
+		csvData  string
+		wantTags []string
+	}{
+		{
+			name:    "all conditions satisfied",
+			csvData: "phone,email,name,fallback,fax,pager\n555-1234,a@example.com,John,,,p1\n",
+		},
+		{
+			name:     "required_with_all and excluded_with fail",
+			csvData:  "phone,email,name,fallback,fax,pager\n555-1234,a@example.com,,,f1,p1\n",
+			wantTags: []string{requiredWithAllTagValue, excludedWithTagValue},
+		},
+		{
+			name:     "required_without_all and excluded_without fail",
+			csvData:  "phone,email,name,fallback,fax,pager\n,,,,,p1\n",
+			wantTags: []string{requiredWithoutAllTagValue, excludedWithoutTagValue},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var records []Contact
+			_, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader(tt.csvData), &records)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			var gotTags []string
+			for _, e := range result.ValidationErrors() {
+				gotTags = append(gotTags, e.Tag)
+			}
+			if strings.Join(gotTags, ",") != strings.Join(tt.wantTags, ",") {
+				t.Errorf("error tags = %v, want %v", gotTags, tt.wantTags)
+			}
+		})
+	}
+
+	t.Run("missing target field is reported", func(t *testing.T) {
+		t.Parallel()
+		type Record struct {
+			Phone string
+			Name  string `validate:"required_with_all=Phone Mobile"`
+		}
+		var records []Record
+		_, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader("phone,name\n555-1234,John\n"), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		errs := result.ValidationErrors()
+		if len(errs) != 1 {
+			t.Fatalf("expected 1 error, got %d: %v", len(errs), errs)
+		}
+		if errs[0].Code() != CodeTargetFieldNotFound {
+			t.Errorf("Code() = %q, want %q", errs[0].Code(), CodeTargetFieldNotFound)
+		}
+		if errs[0].Message() != "target field Mobile not found" {
+			t.Errorf("Message() = %q", errs[0].Message())
+		}
+	})
+}
+
+func TestSumOfValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		tag          string
+		srcValue     string
+		targetValues []string
+		wantMsg      string
+	}{
+		{name: "exact sum", tag: "sumof=A B C", srcValue: "100", targetValues: []string{"20", "30", "50"}},
+		{name: "float sum", tag: "sumof=A B C", srcValue: "0.6", targetValues: []string{"0.1", "0.2", "0.3"}},
+		{name: "empty target counts as zero", tag: "sumof=A B", srcValue: "20", targetValues: []string{"20", ""}},
+		{name: "wrong sum", tag: "sumof=A B C", srcValue: "100", targetValues: []string{"20", "30", "49"}, wantMsg: "value must equal the sum of A, B, C"},
+		{name: "within epsilon", tag: "sumof=A B:0.01", srcValue: "10.00", targetValues: []string{"3.33", "6.66"}},
+		{name: "beyond epsilon", tag: "sumof=A B:0.01", srcValue: "10.00", targetValues: []string{"3.33", "6.65"}, wantMsg: "value must equal the sum of A, B within 0.01"},
+		{name: "non-numeric target", tag: "sumof=A B", srcValue: "10", targetValues: []string{"5", "x"}, wantMsg: "field B must be a number to sum"},
+		{name: "non-numeric source", tag: "sumof=A B", srcValue: "ten", targetValues: []string{"5", "5"}, wantMsg: "value must equal the sum of A, B"},
+		{name: "empty source is not checked", tag: "sumof=A B", srcValue: "", targetValues: []string{"5", "5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, crossVals, err := parseValidateTag(tt.tag, true)
+			if err != nil || len(crossVals) != 1 {
+				t.Fatalf("parseValidateTag(%q) = %v, %v", tt.tag, crossVals, err)
+			}
+			v, ok := crossVals[0].(multiFieldValidator)
+			if !ok {
+				t.Fatalf("sumof validator does not implement multiFieldValidator")
+			}
+			if got := v.ValidateFields(tt.srcValue, tt.targetValues); got != tt.wantMsg {
+				t.Errorf("ValidateFields(%q, %v) = %q, want %q", tt.srcValue, tt.targetValues, got, tt.wantMsg)
+			}
+			if v.Name() != sumOfTagValue {
+				t.Errorf("Name() = %q, want %q", v.Name(), sumOfTagValue)
+			}
+		})
+	}
+}
+
+func TestSumOfValidation_Processor(t *testing.T) {
+	t.Parallel()
+
+	type Split struct {
+		Alice string
+		Bob   string
+		Carol string
+		Total string `validate:"required,sumof=Alice Bob Carol:0.5"`
+	}
+
+	input := "alice,bob,carol,total\n50,30,20,100\n33.3,33.3,33.3,100\n50,50,50,100\n"
+	var splits []Split
+	_, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader(input), &splits)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	errs := result.ValidationErrors()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errs), errs)
+	}
+	if errs[0].Row != 3 || errs[0].Tag != sumOfTagValue || errs[0].Column != "total" {
+		t.Errorf("error = row %d column %q tag %q, want row 3 column total tag sumof", errs[0].Row, errs[0].Column, errs[0].Tag)
+	}
+}
+
+func TestExprValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		tag          string
+		srcValue     string
+		targetValues []string
+		wantFields   []string
+		wantMsg      string
+	}{
+		{name: "arithmetic on another field", tag: "expr=value > other('MinPrice') * 0.9", srcValue: "95", targetValues: []string{"100"}, wantFields: []string{"MinPrice"}},
+		{name: "arithmetic fails", tag: "expr=value > other('MinPrice') * 0.9", srcValue: "85", targetValues: []string{"100"}, wantFields: []string{"MinPrice"}, wantMsg: "value must satisfy value > other('MinPrice') * 0.9"},
+		{name: "precedence", tag: "expr=value == 2 + 3 * 4", srcValue: "14"},
+		{name: "parentheses", tag: "expr=(value - 1) / 2 == 3", srcValue: "7"},
+		{name: "negation", tag: "expr=value == -other('Refund')", srcValue: "-5", targetValues: []string{"5"}, wantFields: []string{"Refund"}},
+		{name: "field named twice is read once", tag: "expr=other('Min') <= value && value <= other('Min') + 10", srcValue: "15", targetValues: []string{"10"}, wantFields: []string{"Min"}},
+		{name: "two fields", tag: "expr=value <= other('A') + other('B')", srcValue: "15", targetValues: []string{"10", "4"}, wantFields: []string{"A", "B"}, wantMsg: "value must satisfy value <= other('A') + other('B')"},
+		{name: "conditions on other fields", tag: "expr=other('Status') != 'closed' || value == '0'", srcValue: "3", targetValues: []string{"closed"}, wantFields: []string{"Status"}, wantMsg: "value must satisfy other('Status') != 'closed' || value == '0'"},
+		{name: "regex", tag: "expr=value =~ '^[A-Z]{3}$'", srcValue: "JPY"},
+		{name: "non-numeric in arithmetic fails", tag: "expr=value > other('Min') + 1", srcValue: "5", targetValues: []string{"n/a"}, wantFields: []string{"Min"}, wantMsg: "value must satisfy value > other('Min') + 1"},
+		{name: "empty source is not checked", tag: "expr=value > 1", srcValue: ""},
+		{name: "in list keeps its commas", tag: "expr=value in ('a', 'b')", srcValue: "c", wantMsg: "value must satisfy value in ('a', 'b')"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, crossVals, err := parseValidateTag(tt.tag, true)
+			if err != nil || len(crossVals) != 1 {
+				t.Fatalf("parseValidateTag(%q) = %v, %v", tt.tag, crossVals, err)
+			}
+			v, ok := crossVals[0].(multiFieldValidator)
+			if !ok {
+				t.Fatalf("expr validator does not implement multiFieldValidator")
+			}
+			if strings.Join(v.TargetFields(), ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("TargetFields() = %v, want %v", v.TargetFields(), tt.wantFields)
+			}
+			if got := v.ValidateFields(tt.srcValue, tt.targetValues); got != tt.wantMsg {
+				t.Errorf("ValidateFields(%q, %v) = %q, want %q", tt.srcValue, tt.targetValues, got, tt.wantMsg)
+			}
+			if v.Name() != exprTagValue {
+				t.Errorf("Name() = %q, want %q", v.Name(), exprTagValue)
+			}
+		})
+	}
+}
+
+func TestExprValidation_Processor(t *testing.T) {
+	t.Parallel()
+
+	type Product struct {
+		MinPrice string
+		Price    string `validate:"required,expr=value >= other('MinPrice') * 0.9"`
+	}
+
+	input := "min_price,price\n100,95\n100,89.99\n,10\n"
+	var products []Product
+	_, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader(input), &products)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	errs := result.ValidationErrors()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), errs)
+	}
+	for i, wantRow := range []int{2, 3} {
+		if errs[i].Row != wantRow || errs[i].Tag != exprTagValue || errs[i].Column != "price" {
+			t.Errorf("error %d = row %d column %q tag %q, want row %d column price tag expr", i, errs[i].Row, errs[i].Column, errs[i].Tag, wantRow)
+		}
+	}
+}
+
+func TestUniqueValidator(t *testing.T) {
+	t.Parallel()
+
+	v := newUniqueValidator("CompanyID")
+	if got := v.ValidateFields("a@example.com", []string{"1"}); got != "" {
+		t.Errorf("unbound ValidateFields() = %q, want pass", got)
+	}
+
+	bound := v.withKeys([][]string{
+		{"a@example.com", "1"},
+		{"b@example.com", "1"},
+		{"a@example.com", "1"},
+		{"a@example.com", ""},
+		{"a@example.com", ""},
+	}, 11)
+
+	tests := []struct {
+		name         string
+		srcValue     string
+		targetValues []string
+		want         string
+	}{
+		{
+			name:         "repeated key lists the whole group",
+			srcValue:     "a@example.com",
+			targetValues: []string{"1"},
+			want:         "value is not unique: rows 11, 13 share the same key",
+		},
+		{
+			name:         "key seen once passes",
+			srcValue:     "b@example.com",
+			targetValues: []string{"1"},
+		},
+		{
+			name:         "empty key value is not compared",
+			srcValue:     "a@example.com",
+			targetValues: []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := bound.ValidateFields(tt.srcValue, tt.targetValues); got != tt.want {
+				t.Errorf("ValidateFields() = %q, want %q", got, tt.want)
+			}
+			if bound.Name() != uniqueTagValue {
+				t.Errorf("Name() = %q, want %q", bound.Name(), uniqueTagValue)
+			}
+		})
+	}
+}
+
 func TestConditionalCrossFieldValidation_Processor(t *testing.T) {
 	t.Parallel()
 
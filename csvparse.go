@@ -0,0 +1,162 @@
+package fileprep
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nao1215/fileparser"
+)
+
+// csvParseOptions controls how CSV and TSV input is parsed.
+// The zero value matches fileparser's strict RFC 4180 parsing.
+type csvParseOptions struct {
+	lazyQuotes             bool // allow bare and unescaped quotes (csv.Reader.LazyQuotes)
+	backslashEscapes       bool // treat \" and \\ inside quoted fields as escapes
+	disallowQuotedNewlines bool // reject line breaks inside quoted fields
+	allowDuplicateHeaders  bool // accept repeated column names for renaming
+	raggedRows             bool // accept rows whose cell count differs from the header
+	delimiter              rune // CSV field delimiter; 0 means a comma
+}
+
+// comma returns the CSV field delimiter.
+func (o csvParseOptions) comma() rune {
+	if o.delimiter == 0 {
+		return ','
+	}
+	return o.delimiter
+}
+
+// isDefault reports whether no parse option was set.
+func (o csvParseOptions) isDefault() bool {
+	return o == csvParseOptions{}
+}
+
+// parseDelimited parses CSV or TSV data with the given options.
+// Errors carry the 1-based data row number (excluding header) so they can be
+// matched with ValidationError.Row. Column types are not inferred because
+// Process does not use them.
+func parseDelimited(data []byte, comma rune, fileTypeName string, opts csvParseOptions) (*fileparser.TableData, error) {
+	if opts.backslashEscapes {
+		data = convertBackslashEscapes(data, byte(comma))
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = comma
+	r.LazyQuotes = opts.lazyQuotes
+	if opts.raggedRows {
+		r.FieldsPerRecord = -1
+	}
+
+	headers, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: empty %s data", ErrNoHeader, fileTypeName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s header: %w", fileTypeName, err)
+	}
+	if !opts.allowDuplicateHeaders {
+		if err := validateHeaderNames(headers); err != nil {
+			return nil, err
+		}
+	}
+	if opts.disallowQuotedNewlines {
+		if colIdx := indexOfNewline(headers); colIdx >= 0 {
+			return nil, fmt.Errorf("header, column %d: %w", colIdx+1, ErrQuotedNewline)
+		}
+	}
+
+	var records [][]string
+	for row := 1; ; row++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at row %d: %w", fileTypeName, row, err)
+		}
+		if opts.disallowQuotedNewlines {
+			if colIdx := indexOfNewline(record); colIdx >= 0 {
+				return nil, fmt.Errorf("row %d, column %q: %w", row, headers[colIdx], ErrQuotedNewline)
+			}
+		}
+		records = append(records, record)
+	}
+
+	return &fileparser.TableData{
+		Headers: headers,
+		Records: records,
+	}, nil
+}
+
+// validateHeaderNames rejects duplicate column names, as fileparser does.
+func validateHeaderNames(headers []string) error {
+	seen := make(map[string]bool, len(headers))
+	for _, h := range headers {
+		if seen[h] {
+			return fmt.Errorf("%w: %s", ErrDuplicateColumn, h)
+		}
+		seen[h] = true
+	}
+	return nil
+}
+
+// indexOfNewline returns the index of the first field containing a line
+// break, or -1. csv.Reader only keeps line breaks that were inside quotes.
+func indexOfNewline(record []string) int {
+	for i, field := range record {
+		if strings.ContainsAny(field, "\r\n") {
+			return i
+		}
+	}
+	return -1
+}
+
+// convertBackslashEscapes rewrites backslash escapes inside quoted fields to
+// RFC 4180 form: \" becomes "" and \\ becomes \. Bytes outside quoted fields
+// are copied unchanged. A quote opens a quoted field only at the start of a
+// field, so bare quotes in unquoted fields (allowed by lazy quotes) do not
+// confuse the scan.
+func convertBackslashEscapes(data []byte, comma byte) []byte {
+	if !bytes.Contains(data, []byte{'\\'}) {
+		return data
+	}
+
+	out := make([]byte, 0, len(data)+len(data)/16)
+	inQuotes := false
+	fieldStart := true
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if !inQuotes {
+			if c == '"' && fieldStart {
+				inQuotes = true
+			}
+			fieldStart = c == comma || c == '\n' || c == '\r'
+			out = append(out, c)
+			continue
+		}
+
+		switch {
+		case c == '\\' && i+1 < len(data) && data[i+1] == '"':
+			out = append(out, '"', '"')
+			i++
+		case c == '\\' && i+1 < len(data) && data[i+1] == '\\':
+			out = append(out, '\\')
+			i++
+		case c == '"' && i+1 < len(data) && data[i+1] == '"':
+			// RFC 4180 doubled quote stays inside the field
+			out = append(out, '"', '"')
+			i++
+		case c == '"':
+			inQuotes = false
+			fieldStart = false
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package fileprep
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseDelimited(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		data        string
+		comma       rune
+		opts        csvParseOptions
+		wantHeaders []string
+		wantRecords [][]string
+		wantErr     error
+		wantErrText string
+	}{
+		{
+			name:        "strict quoting",
+			data:        "a,b\n\"x,y\",\"say \"\"hi\"\"\"\n",
+			comma:       ',',
+			wantHeaders: []string{"a", "b"},
+			wantRecords: [][]string{{"x,y", `say "hi"`}},
+		},
+		{
+			name:        "bare quote fails with row number",
+			data:        "a,b\n1,2\n3,x\"y\n",
+			comma:       ',',
+			wantErrText: "at row 2",
+		},
+		{
+			name:        "lazy quotes accept bare quote",
+			data:        "a,b\n1,2\n3,x\"y\n",
+			comma:       ',',
+			opts:        csvParseOptions{lazyQuotes: true},
+			wantHeaders: []string{"a", "b"},
+			wantRecords: [][]string{{"1", "2"}, {"3", `x"y`}},
+		},
+		{
+			name:        "backslash escapes",
+			data:        "a\tb\n\"say \\\"hi\\\"\"\t\"C:\\\\tmp\"\n",
+			comma:       '\t',
+			opts:        csvParseOptions{backslashEscapes: true},
+			wantHeaders: []string{"a", "b"},
+			wantRecords: [][]string{{`say "hi"`, `C:\tmp`}},
+		},
+		{
+			name:        "backslash outside quotes is literal",
+			data:        "a\nC:\\tmp\n",
+			comma:       ',',
+			opts:        csvParseOptions{backslashEscapes: true},
+			wantHeaders: []string{"a"},
+			wantRecords: [][]string{{`C:\tmp`}},
+		},
+		{
+			name:        "quoted newline allowed unless disallowed",
+			data:        "a,b\n1,\"x\ny\"\n",
+			comma:       ',',
+			opts:        csvParseOptions{lazyQuotes: true},
+			wantHeaders: []string{"a", "b"},
+			wantRecords: [][]string{{"1", "x\ny"}},
+		},
+		{
+			name:    "quoted newline rejected",
+			data:    "a,b\n1,2\n3,\"x\r\ny\"\n",
+			comma:   ',',
+			opts:    csvParseOptions{disallowQuotedNewlines: true},
+			wantErr: ErrQuotedNewline,
+			// Row 2 is the data row, not the third physical line
+			wantErrText: `row 2, column "b"`,
+		},
+		{
+			name:    "empty input",
+			data:    "",
+			comma:   ',',
+			opts:    csvParseOptions{lazyQuotes: true},
+			wantErr: ErrEmptyFile,
+		},
+		{
+			name:        "duplicate header",
+			data:        "a,a\n1,2\n",
+			comma:       ',',
+			opts:        csvParseOptions{lazyQuotes: true},
+			wantErrText: "duplicate column name: a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseDelimited([]byte(tt.data), tt.comma, "CSV", tt.opts)
+			if tt.wantErr != nil || tt.wantErrText != "" {
+				if err == nil {
+					t.Fatal("parseDelimited() expected error, got nil")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("parseDelimited() error = %v, want %v", err, tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.wantErrText) {
+					t.Errorf("parseDelimited() error = %q, want it to contain %q", err, tt.wantErrText)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDelimited() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantHeaders, got.Headers); diff != "" {
+				t.Errorf("headers mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantRecords, got.Records); diff != "" {
+				t.Errorf("records mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package fileprep
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// decimalRegex matches a decimal number: sign, integer digits, and
+// fraction digits.
+var decimalRegex = regexp.MustCompile(`^([+-]?)(\d*)(?:\.(\d*))?$`)
+
+// parseDecimal splits a decimal number such as "-012.340" into its sign,
+// its integer digits without leading zeros ("12"), and its fraction digits
+// without trailing zeros ("34"). Working on the digits rather than on a
+// float keeps every digit exact.
+func parseDecimal(value string) (sign, whole, fraction string, ok bool) {
+	m := decimalRegex.FindStringSubmatch(value)
+	if m == nil || m[2] == "" && m[3] == "" {
+		return "", "", "", false
+	}
+	return m[1], strings.TrimLeft(m[2], "0"), strings.TrimRight(m[3], "0"), true
+}
+
+// normalizeDecimal rewrites a decimal number with scale digits after the
+// decimal point, such as "1.5" as "1.50" for scale 2, or without trailing
+// zeros when scale is negative. It returns false if value is not a decimal
+// number or has more non-zero fraction digits than scale.
+func normalizeDecimal(value string, scale int) (string, bool) {
+	sign, whole, fraction, ok := parseDecimal(value)
+	if !ok || scale >= 0 && len(fraction) > scale {
+		return "", false
+	}
+	if sign == "+" || whole == "" && fraction == "" {
+		sign = ""
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	if scale > 0 {
+		fraction += strings.Repeat("0", scale-len(fraction))
+	}
+	if fraction == "" {
+		return sign + whole, true
+	}
+	return sign + whole + "." + fraction, true
+}
+
+// decimalPreprocessor normalizes decimal numbers. Values that are not
+// decimal numbers, or that have more digits than the scale, are left as
+// they are.
+type decimalPreprocessor struct {
+	scale int // negative to strip trailing zeros
+}
+
+// newDecimalPreprocessor creates a new decimal preprocessor
+func newDecimalPreprocessor(scale int) *decimalPreprocessor {
+	return &decimalPreprocessor{scale: scale}
+}
+
+// Process pads the fraction to the scale or strips its trailing zeros
+func (p *decimalPreprocessor) Process(value string) string {
+	if normalized, ok := normalizeDecimal(strings.TrimSpace(value), p.scale); ok {
+		return normalized
+	}
+	return value
+}
+
+// Name returns the preprocessor name
+func (p *decimalPreprocessor) Name() string {
+	return decimalTagValue
+}
+
+// roundPreprocessor rounds decimal numbers to a number of fraction digits.
+// The digits are rounded as text, so no float error is introduced. Values
+// with no more fraction digits than places, or that are not decimal
+// numbers, are left as they are.
+type roundPreprocessor struct {
+	places   int
+	halfEven bool // round ties to the even digit rather than away from zero
+}
+
+// newRoundPreprocessor creates a new round preprocessor
+func newRoundPreprocessor(places int, halfEven bool) *roundPreprocessor {
+	return &roundPreprocessor{places: places, halfEven: halfEven}
+}
+
+// Process rounds the value to the number of places, keeping trailing zeros
+// so that every rounded value has the same number of fraction digits
+func (p *roundPreprocessor) Process(value string) string {
+	sign, whole, fraction, ok := parseDecimal(strings.TrimSpace(value))
+	if !ok || len(fraction) <= p.places {
+		return value
+	}
+	digits := whole + fraction[:p.places]
+	rest := fraction[p.places:] // without trailing zeros, so never "5" followed by zeros
+	up := rest[0] > '5' || rest[0] == '5' && len(rest) > 1
+	if rest == "5" {
+		up = !p.halfEven || digits != "" && (digits[len(digits)-1]-'0')%2 == 1
+	}
+	if up {
+		digits = incrementDigits(digits)
+	}
+	digits = strings.Repeat("0", max(p.places+1-len(digits), 0)) + digits
+	if strings.Trim(digits, "0") == "" || sign == "+" {
+		sign = ""
+	}
+	point := len(digits) - p.places
+	whole = strings.TrimLeft(digits[:point], "0")
+	if whole == "" {
+		whole = "0"
+	}
+	if p.places == 0 {
+		return sign + whole
+	}
+	return sign + whole + "." + digits[point:]
+}
+
+// Name returns the preprocessor name
+func (p *roundPreprocessor) Name() string {
+	return roundTagValue
+}
+
+// incrementDigits adds one to a string of decimal digits, such as "199" to
+// "200" or "99" to "100"
+func incrementDigits(digits string) string {
+	b := []byte(digits)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < '9' {
+			b[i]++
+			return string(b)
+		}
+		b[i] = '0'
+	}
+	return "1" + string(b)
+}
+
+// clampPreprocessor clips numbers into a range, replacing values below the
+// minimum with the minimum and values above the maximum with the maximum,
+// as written in the tag. Values in range, or that are not numbers, are
+// left as they are.
+type clampPreprocessor struct {
+	minText, maxText string // empty for no bound
+	minimum, maximum float64
+}
+
+// newClampPreprocessor creates a new clamp preprocessor. An empty bound is
+// not checked.
+func newClampPreprocessor(minText, maxText string) (*clampPreprocessor, error) {
+	p := &clampPreprocessor{minText: minText, maxText: maxText, minimum: math.Inf(-1), maximum: math.Inf(1)}
+	var err error
+	if minText != "" {
+		if p.minimum, err = strconv.ParseFloat(minText, 64); err != nil {
+			return nil, err
+		}
+	}
+	if maxText != "" {
+		if p.maximum, err = strconv.ParseFloat(maxText, 64); err != nil {
+			return nil, err
+		}
+	}
+	if !(p.minimum <= p.maximum) {
+		return nil, fmt.Errorf("minimum %s is above maximum %s", minText, maxText)
+	}
+	return p, nil
+}
+
+// Process clips the value into the range
+func (p *clampPreprocessor) Process(value string) string {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	switch {
+	case err != nil || math.IsNaN(f):
+		return value
+	case f < p.minimum:
+		return p.minText
+	case f > p.maximum:
+		return p.maxText
+	}
+	return value
+}
+
+// Name returns the preprocessor name
+func (p *clampPreprocessor) Name() string {
+	return clampTagValue
+}
+
+// columnStatsPreprocessor is a Preprocessor whose result depends on the
+// whole column. Before any row is processed, the column's values after the
+// preceding prep rules are passed to withColumnValues, and rows are
+// processed with the returned preprocessor.
+type columnStatsPreprocessor interface {
+	Preprocessor
+	withColumnValues(values []string) Preprocessor
+}
+
+// winsorizePreprocessor replaces numbers beyond the low and high
+// percentiles of the column with the values at those percentiles, so a
+// single bad reading does not skew statistics computed after loading.
+type winsorizePreprocessor struct {
+	low, high        float64 // percentiles (0-100)
+	minText, maxText string  // the column's values at low and high
+	minVal, maxVal   float64
+	ready            bool // true once column statistics are set
+}
+
+// newWinsorizePreprocessor creates a new winsorize preprocessor
+func newWinsorizePreprocessor(low, high float64) *winsorizePreprocessor {
+	return &winsorizePreprocessor{low: low, high: high}
+}
+
+// withColumnValues returns a preprocessor bound to the column's values at
+// the low and high percentiles, using linear interpolation between ranks
+// as the percentile validator does
+func (p *winsorizePreprocessor) withColumnValues(values []string) Preprocessor {
+	nums := columnFloats(values)
+	if len(nums) == 0 {
+		return &winsorizePreprocessor{low: p.low, high: p.high}
+	}
+	slices.Sort(nums)
+
+	minVal := percentileOf(nums, p.low)
+	maxVal := percentileOf(nums, p.high)
+	return &winsorizePreprocessor{
+		low:     p.low,
+		high:    p.high,
+		minText: strconv.FormatFloat(minVal, 'f', -1, 64),
+		maxText: strconv.FormatFloat(maxVal, 'f', -1, 64),
+		minVal:  minVal,
+		maxVal:  maxVal,
+		ready:   true,
+	}
+}
+
+// Process replaces a number beyond the percentiles with the bound
+func (p *winsorizePreprocessor) Process(value string) string {
+	if !p.ready {
+		return value
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	switch {
+	case err != nil:
+		return value
+	case f < p.minVal:
+		return p.minText
+	case f > p.maxVal:
+		return p.maxText
+	}
+	return value
+}
+
+// Name returns the preprocessor name
+func (p *winsorizePreprocessor) Name() string {
+	return winsorizeTagValue
+}
+
+// scaledUnitPreprocessor converts numbers written in a unit such as
+// percent or basis points, marked by a suffix, to plain decimal numbers.
+// The decimal point is moved on the digits, so no rounding occurs. Values
+// without a suffix, or that are not numbers, are left as they are.
+type scaledUnitPreprocessor struct {
+	name     string
+	suffixes []string // lowercase
+	places   int      // digits to move the decimal point left
+}
+
+// newPercentToDecimalPreprocessor creates a preprocessor that converts
+// "12.5%" to "0.125"
+func newPercentToDecimalPreprocessor() *scaledUnitPreprocessor {
+	return &scaledUnitPreprocessor{name: percentToDecimalTagValue, suffixes: []string{"%"}, places: 2}
+}
+
+// newBPToDecimalPreprocessor creates a preprocessor that converts basis
+// points such as "125bp" or "125 bps" to "0.0125"
+func newBPToDecimalPreprocessor() *scaledUnitPreprocessor {
+	return &scaledUnitPreprocessor{name: bpToDecimalTagValue, suffixes: []string{"bps", "bp", "‱"}, places: 4}
+}
+
+// Process converts a value with one of the suffixes
+func (p *scaledUnitPreprocessor) Process(value string) string {
+	trimmed := strings.TrimSpace(value)
+	lower := strings.ToLower(trimmed)
+	for _, suffix := range p.suffixes {
+		if !strings.HasSuffix(lower, suffix) {
+			continue
+		}
+		number := strings.TrimSpace(trimmed[:len(trimmed)-len(suffix)])
+		sign, whole, fraction, ok := parseDecimal(number)
+		if !ok {
+			return value
+		}
+		digits := strings.Repeat("0", max(p.places-len(whole), 0)) + whole + fraction
+		point := len(digits) - len(fraction) - p.places
+		normalized, _ := normalizeDecimal(sign+digits[:point]+"."+digits[point:], -1)
+		return normalized
+	}
+	return value
+}
+
+// Name returns the preprocessor name
+func (p *scaledUnitPreprocessor) Name() string {
+	return p.name
+}
+
+// decimalValidator validates decimal numbers against a SQL-style precision
+// and scale, as in DECIMAL(10,2)
+type decimalValidator struct {
+	precision int
+	scale     int
+}
+
+// newDecimalValidator creates a new decimal validator
+func newDecimalValidator(precision, scale int) *decimalValidator {
+	return &decimalValidator{precision: precision, scale: scale}
+}
+
+// Validate checks that the value is a decimal number with at most scale
+// digits after the decimal point and precision digits in total. Leading
+// zeros and trailing fraction zeros are not counted.
+func (v *decimalValidator) Validate(value string) string {
+	_, whole, fraction, ok := parseDecimal(value)
+	switch {
+	case !ok:
+		return "value must be a decimal number"
+	case len(fraction) > v.scale:
+		return "value must have at most " + strconv.Itoa(v.scale) + " digits after the decimal point"
+	case len(whole) > v.precision-v.scale:
+		return "value must have at most " + strconv.Itoa(v.precision-v.scale) + " digits before the decimal point"
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *decimalValidator) Name() string {
+	return decimalTagValue
+}
+
+// buildDecimalValidator builds a decimal validator from "precision" or
+// "precision:scale", such as "10:2" for DECIMAL(10,2). The scale defaults
+// to 0, as in SQL.
+func buildDecimalValidator(value string, strict bool) (Validator, error) {
+	precisionText, scaleText, hasScale := strings.Cut(value, ":")
+	precision, err := strconv.Atoi(precisionText)
+	scale := 0
+	if err == nil && hasScale {
+		scale, err = strconv.Atoi(scaleText)
+	}
+	if err != nil || precision < 1 || scale < 0 || scale > precision {
+		if strict {
+			return nil, fmt.Errorf("%w: decimal requires precision or precision:scale with 0 <= scale <= precision, got %q", ErrInvalidTagFormat, value)
+		}
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+	return newDecimalValidator(precision, scale), nil
+}
@@ -0,0 +1,297 @@
+package fileprep
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNormalizeDecimal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		value  string
+		scale  int
+		want   string
+		wantOK bool
+	}{
+		{name: "pad scale", value: "1.5", scale: 2, want: "1.50", wantOK: true},
+		{name: "pad integer", value: "12", scale: 2, want: "12.00", wantOK: true},
+		{name: "trim zeros beyond scale", value: "1.500", scale: 2, want: "1.50", wantOK: true},
+		{name: "too many digits", value: "1.005", scale: 2, wantOK: false},
+		{name: "strip trailing zeros", value: "1.500", scale: -1, want: "1.5", wantOK: true},
+		{name: "strip to integer", value: "2.00", scale: -1, want: "2", wantOK: true},
+		{name: "strip leading zeros", value: "+007.10", scale: -1, want: "7.1", wantOK: true},
+		{name: "negative", value: "-.5", scale: 2, want: "-0.50", wantOK: true},
+		{name: "negative zero", value: "-0.00", scale: -1, want: "0", wantOK: true},
+		{name: "more digits than a float holds", value: "12345678901234567.891", scale: 3, want: "12345678901234567.891", wantOK: true},
+		{name: "exponent", value: "1e3", scale: 2, wantOK: false},
+		{name: "text", value: "abc", scale: 2, wantOK: false},
+		{name: "empty", value: "", scale: 2, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := normalizeDecimal(tt.value, tt.scale)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("normalizeDecimal(%q, %d) = %q, %v, want %q, %v", tt.value, tt.scale, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDecimalValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   string
+		wantMsg string
+	}{
+		{name: "fits", value: "12345678.90"},
+		{name: "fewer fraction digits", value: "-1.5"},
+		{name: "trailing zeros beyond scale", value: "1.2300"},
+		{name: "leading zeros", value: "0012345678"},
+		{name: "too many fraction digits", value: "1.234", wantMsg: "value must have at most 2 digits after the decimal point"},
+		{name: "too many integer digits", value: "123456789", wantMsg: "value must have at most 8 digits before the decimal point"},
+		{name: "not a decimal", value: "1,000.00", wantMsg: "value must be a decimal number"},
+		{name: "empty", value: "", wantMsg: "value must be a decimal number"},
+	}
+
+	v := newDecimalValidator(10, 2)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := v.Validate(tt.value); got != tt.wantMsg {
+				t.Errorf("Validate(%q) = %q, want %q", tt.value, got, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestDecimal_Processor(t *testing.T) {
+	t.Parallel()
+
+	type Payment struct {
+		Amount string `prep:"decimal=2" validate:"decimal=10:2"`
+		Rate   string `prep:"decimal"`
+	}
+
+	input := "amount,rate\n1.5,0.0800\n1.005,1.0\n"
+	var payments []Payment
+	output, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader(input), &payments)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if errs := result.ValidationErrors(); len(errs) != 1 || errs[0].Row != 2 || errs[0].Tag != decimalTagValue || errs[0].Param != "10:2" {
+		t.Errorf("ValidationErrors() = %v, want one decimal=10:2 error on row 2", errs)
+	}
+	data, err := io.ReadAll(output)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+	if want := "amount,rate\n1.50,0.08\n1.005,1\n"; string(data) != want {
+		t.Errorf("output = %q, want %q", string(data), want)
+	}
+}
+
+func TestRoundPreprocessor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		value    string
+		places   int
+		halfEven bool
+		want     string
+	}{
+		{name: "round down", value: "1.234", places: 2, want: "1.23"},
+		{name: "round up", value: "1.236", places: 2, want: "1.24"},
+		{name: "tie half up", value: "1.225", places: 2, want: "1.23"},
+		{name: "tie half even down", value: "1.225", places: 2, halfEven: true, want: "1.22"},
+		{name: "tie half even up", value: "1.235", places: 2, halfEven: true, want: "1.24"},
+		{name: "above tie half even", value: "1.2251", places: 2, halfEven: true, want: "1.23"},
+		{name: "tie with trailing zeros", value: "1.22500", places: 2, halfEven: true, want: "1.22"},
+		{name: "carry", value: "9.995", places: 2, want: "10.00"},
+		{name: "keeps trailing zeros", value: "1.001", places: 2, want: "1.00"},
+		{name: "negative away from zero", value: "-1.225", places: 2, want: "-1.23"},
+		{name: "negative to zero", value: "-0.001", places: 2, want: "0.00"},
+		{name: "integer places", value: "2.5", places: 0, want: "3"},
+		{name: "integer places half even", value: "2.5", places: 0, halfEven: true, want: "2"},
+		{name: "leading point", value: ".5", places: 0, halfEven: true, want: "0"},
+		{name: "plus sign", value: "+1.005", places: 2, want: "1.01"},
+		{name: "more digits than a float holds", value: "12345678901234567.8915", places: 3, want: "12345678901234567.892"},
+		{name: "few enough digits", value: "1.5", places: 2, want: "1.5"},
+		{name: "integer", value: "12", places: 2, want: "12"},
+		{name: "text", value: "abc", places: 2, want: "abc"},
+		{name: "empty", value: "", places: 2, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := newRoundPreprocessor(tt.places, tt.halfEven).Process(tt.value); got != tt.want {
+				t.Errorf("round=%d (halfEven=%v) Process(%q) = %q, want %q", tt.places, tt.halfEven, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClampPreprocessor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		minText string
+		maxText string
+		value   string
+		want    string
+	}{
+		{name: "in range", minText: "0", maxText: "100", value: "42.50", want: "42.50"},
+		{name: "below minimum", minText: "0", maxText: "100", value: "-3", want: "0"},
+		{name: "above maximum", minText: "0", maxText: "100", value: "100.01", want: "100"},
+		{name: "on bound", minText: "0", maxText: "100", value: "100", want: "100"},
+		{name: "exponent", minText: "0", maxText: "100", value: "1e3", want: "100"},
+		{name: "surrounding spaces", minText: "0", maxText: "100", value: " 150 ", want: "100"},
+		{name: "decimal bounds", minText: "-40.5", maxText: "85.25", value: "-41", want: "-40.5"},
+		{name: "minimum only", minText: "0", value: "1e9", want: "1e9"},
+		{name: "minimum only below", minText: "0", value: "-1", want: "0"},
+		{name: "maximum only", maxText: "1", value: "-1e9", want: "-1e9"},
+		{name: "maximum only above", maxText: "1", value: "2", want: "1"},
+		{name: "not a number", minText: "0", maxText: "100", value: "n/a", want: "n/a"},
+		{name: "NaN", minText: "0", maxText: "100", value: "NaN", want: "NaN"},
+		{name: "empty", minText: "0", maxText: "100", value: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p, err := newClampPreprocessor(tt.minText, tt.maxText)
+			if err != nil {
+				t.Fatalf("newClampPreprocessor(%q, %q) error = %v", tt.minText, tt.maxText, err)
+			}
+			if got := p.Process(tt.value); got != tt.want {
+				t.Errorf("clamp=%s:%s Process(%q) = %q, want %q", tt.minText, tt.maxText, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClamp_Processor(t *testing.T) {
+	t.Parallel()
+
+	type Reading struct {
+		Humidity    string `prep:"clamp=0:100"`
+		Temperature string `prep:"clamp=-40:85" validate:"number"`
+	}
+
+	input := "humidity,temperature\n-2,20.5\n101.5,90\n55,-40\n"
+	var readings []Reading
+	output, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader(input), &readings)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	want := map[string]ColumnRepairs{
+		"humidity":    {Clamped: 2},
+		"temperature": {Clamped: 1},
+	}
+	if diff := cmp.Diff(want, result.Repairs); diff != "" {
+		t.Errorf("Repairs mismatch (-want +got):\n%s", diff)
+	}
+	data, err := io.ReadAll(output)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+	if want := "humidity,temperature\n0,20.5\n100,85\n55,-40\n"; string(data) != want {
+		t.Errorf("output = %q, want %q", string(data), want)
+	}
+}
+
+func TestWinsorizePreprocessor(t *testing.T) {
+	t.Parallel()
+
+	column := []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "1000", "", "n/a"}
+	p := newWinsorizePreprocessor(10, 90).withColumnValues(column)
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "below low percentile", value: "1", want: "2"},
+		{name: "above high percentile", value: "1000", want: "10"},
+		{name: "on bound", value: "10", want: "10"},
+		{name: "in range", value: "5.50", want: "5.50"},
+		{name: "not a number", value: "n/a", want: "n/a"},
+		{name: "empty", value: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := p.Process(tt.value); got != tt.want {
+				t.Errorf("Process(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+
+	t.Run("interpolated bounds", func(t *testing.T) {
+		t.Parallel()
+		p := newWinsorizePreprocessor(25, 75).withColumnValues([]string{"0", "10"})
+		if got := p.Process("-1") + " " + p.Process("11"); got != "2.5 7.5" {
+			t.Errorf("Process() = %q, want %q", got, "2.5 7.5")
+		}
+	})
+
+	t.Run("unbound or without numbers", func(t *testing.T) {
+		t.Parallel()
+		for _, p := range []Preprocessor{
+			newWinsorizePreprocessor(10, 90),
+			newWinsorizePreprocessor(10, 90).withColumnValues([]string{"a", ""}),
+		} {
+			if got := p.Process("1000"); got != "1000" {
+				t.Errorf("Process() = %q, want the value unchanged", got)
+			}
+		}
+	})
+}
+
+func TestWinsorize_Processor(t *testing.T) {
+	t.Parallel()
+
+	type Reading struct {
+		Sensor string
+		Value  string `prep:"trim,winsorize=10:90" validate:"percentile=0:100"`
+	}
+
+	var b strings.Builder
+	b.WriteString("sensor,value\n")
+	for i, value := range []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", " 1000 "} {
+		fmt.Fprintf(&b, "s%d,%s\n", i, value)
+	}
+
+	var readings []Reading
+	output, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader(b.String()), &readings)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.HasErrors() {
+		t.Errorf("Errors = %v, want none", result.Errors)
+	}
+	if diff := cmp.Diff(map[string]ColumnRepairs{"value": {Clamped: 2}}, result.Repairs); diff != "" {
+		t.Errorf("Repairs mismatch (-want +got):\n%s", diff)
+	}
+	data, err := io.ReadAll(output)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if got := lines[1] + " " + lines[11]; got != "s0,2 s10,10" {
+		t.Errorf("first and last rows = %q, want %q", got, "s0,2 s10,10")
+	}
+}
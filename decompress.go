@@ -0,0 +1,176 @@
+package fileprep
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/nao1215/fileparser"
+	"github.com/pierrec/lz4/v4"
+	"github.com/ulikunitz/xz"
+)
+
+// newDecompressReader wraps the reader with the decompressor matching the file type.
+// The returned close function is nil when the decompressor does not need closing.
+// Uncompressed file types return the reader unchanged.
+func newDecompressReader(reader io.Reader, fileType fileparser.FileType) (io.Reader, func() error, error) {
+	switch fileType {
+	case fileparser.CSVGZ, fileparser.TSVGZ, fileparser.LTSVGZ, fileparser.XLSXGZ,
+		fileparser.ParquetGZ, fileparser.JSONGZ, fileparser.JSONLGZ:
+		gzReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		return gzReader, gzReader.Close, nil
+
+	case fileparser.CSVBZ2, fileparser.TSVBZ2, fileparser.LTSVBZ2, fileparser.XLSXBZ2,
+		fileparser.ParquetBZ2, fileparser.JSONBZ2, fileparser.JSONLBZ2:
+		return bzip2.NewReader(reader), nil, nil
+
+	case fileparser.CSVXZ, fileparser.TSVXZ, fileparser.LTSVXZ, fileparser.XLSXXZ,
+		fileparser.ParquetXZ, fileparser.JSONXZ, fileparser.JSONLXZ:
+		xzReader, err := xz.NewReader(reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create xz reader: %w", err)
+		}
+		return xzReader, nil, nil
+
+	case fileparser.CSVZSTD, fileparser.TSVZSTD, fileparser.LTSVZSTD, fileparser.XLSXZSTD,
+		fileparser.ParquetZSTD, fileparser.JSONZSTD, fileparser.JSONLZSTD:
+		decoder, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create zstd reader: %w", err)
+		}
+		return decoder, func() error { decoder.Close(); return nil }, nil
+
+	case fileparser.CSVZLIB, fileparser.TSVZLIB, fileparser.LTSVZLIB, fileparser.XLSXZLIB,
+		fileparser.ParquetZLIB, fileparser.JSONZLIB, fileparser.JSONLZLIB:
+		zlibReader, err := zlib.NewReader(reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create zlib reader: %w", err)
+		}
+		return zlibReader, zlibReader.Close, nil
+
+	case fileparser.CSVSNAPPY, fileparser.TSVSNAPPY, fileparser.LTSVSNAPPY, fileparser.XLSXSNAPPY,
+		fileparser.ParquetSNAPPY, fileparser.JSONSNAPPY, fileparser.JSONLSNAPPY:
+		return snappy.NewReader(reader), nil, nil
+
+	case fileparser.CSVS2, fileparser.TSVS2, fileparser.LTSVS2, fileparser.XLSXS2,
+		fileparser.ParquetS2, fileparser.JSONS2, fileparser.JSONLS2:
+		return s2.NewReader(reader), nil, nil
+
+	case fileparser.CSVLZ4, fileparser.TSVLZ4, fileparser.LTSVLZ4, fileparser.XLSXLZ4,
+		fileparser.ParquetLZ4, fileparser.JSONLZ4, fileparser.JSONLLZ4:
+		return lz4.NewReader(reader), nil, nil
+
+	default:
+		// No compression
+		return reader, nil, nil
+	}
+}
+
+// Decompress wraps r with the decompressor for ft and returns it together
+// with the file type of the decompressed data, such as FileTypeCSV for
+// FileTypeCSVGZ. Uncompressed file types return r unchanged. It supports the
+// same codecs as Process, so tools that read fileprep's file types can reuse
+// them without their own decompression code.
+//
+// Read errors other than io.EOF wrap ErrDecompression. Close releases the
+// decompressor; it does not close r.
+//
+// Example:
+//
+//	rc, ft, err := fileprep.Decompress(file, fileprep.DetectFileType("users.csv.zst"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer rc.Close()
+//	// ft is fileprep.FileTypeCSV and rc reads the plain CSV
+func Decompress(r io.Reader, ft FileType) (io.ReadCloser, FileType, error) {
+	if r == nil {
+		return nil, fileparser.Unsupported, ErrNilReader
+	}
+	decompressed, closeFunc, err := newDecompressReader(r, ft)
+	if err != nil {
+		return nil, fileparser.Unsupported, fmt.Errorf("%w: %w", ErrDecompression, err)
+	}
+	if !fileparser.IsCompressed(ft) {
+		return io.NopCloser(r), ft, nil
+	}
+	return &decompressReader{reader: decompressed, closeFunc: closeFunc}, fileparser.BaseFileType(ft), nil
+}
+
+// decompressReader is the io.ReadCloser returned by Decompress.
+type decompressReader struct {
+	reader    io.Reader
+	closeFunc func() error // nil when the decompressor does not need closing
+	closed    bool
+}
+
+// Read reads decompressed data
+func (d *decompressReader) Read(p []byte) (int, error) {
+	if d.closed {
+		return 0, fmt.Errorf("%w: reader is closed", ErrDecompression)
+	}
+	n, err := d.reader.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = fmt.Errorf("%w: %w", ErrDecompression, err)
+	}
+	return n, err
+}
+
+// Close releases the decompressor. Closing twice is a no-op.
+func (d *decompressReader) Close() error {
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	if d.closeFunc == nil {
+		return nil
+	}
+	if err := d.closeFunc(); err != nil {
+		return fmt.Errorf("%w: failed to close decompressor: %w", ErrDecompression, err)
+	}
+	return nil
+}
+
+// readDecompressed reads the whole input and returns the decompressed bytes.
+// The returned buffer is kept by Process so that it can be handed out as the
+// output stream when preprocessing leaves the data untouched.
+// If maxBytes is positive, reading stops with ErrInputTooLarge once the
+// decompressed data exceeds it.
+func readDecompressed(reader io.Reader, fileType fileparser.FileType, maxBytes int64) (data []byte, err error) {
+	if reader == nil {
+		return nil, ErrNilReader
+	}
+
+	decompressed, closeFunc, err := newDecompressReader(reader, fileType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecompression, err)
+	}
+	if closeFunc != nil {
+		defer func() {
+			if closeErr := closeFunc(); closeErr != nil && err == nil {
+				err = fmt.Errorf("%w: failed to close decompressor: %w", ErrDecompression, closeErr)
+			}
+		}()
+	}
+
+	if maxBytes > 0 {
+		decompressed = io.LimitReader(decompressed, maxBytes+1)
+	}
+	data, err = io.ReadAll(decompressed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrDecompression, err)
+	}
+	if maxBytes > 0 && int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("%w: input is larger than %d bytes", ErrInputTooLarge, maxBytes)
+	}
+	return data, nil
+}
@@ -0,0 +1,162 @@
+package fileprep
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/nao1215/fileparser"
+	"github.com/ulikunitz/xz"
+)
+
+func TestDecompress(t *testing.T) {
+	t.Parallel()
+
+	const input = "name,age\nalice,30\n"
+
+	compress := func(t *testing.T, newWriter func(io.Writer) (io.WriteCloser, error)) *bytes.Buffer {
+		t.Helper()
+		var buf bytes.Buffer
+		w, err := newWriter(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(input)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return &buf
+	}
+
+	tests := []struct {
+		name      string
+		fileType  FileType
+		newWriter func(io.Writer) (io.WriteCloser, error)
+		wantType  FileType
+	}{
+		{
+			name:      "gzip",
+			fileType:  fileparser.CSVGZ,
+			newWriter: func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil },
+			wantType:  fileparser.CSV,
+		},
+		{
+			name:      "zlib",
+			fileType:  fileparser.TSVZLIB,
+			newWriter: func(w io.Writer) (io.WriteCloser, error) { return zlib.NewWriter(w), nil },
+			wantType:  fileparser.TSV,
+		},
+		{
+			name:      "zstd",
+			fileType:  fileparser.JSONLZSTD,
+			newWriter: func(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) },
+			wantType:  fileparser.JSONL,
+		},
+		{
+			name:      "xz",
+			fileType:  fileparser.LTSVXZ,
+			newWriter: func(w io.Writer) (io.WriteCloser, error) { return xz.NewWriter(w) },
+			wantType:  fileparser.LTSV,
+		},
+		{
+			name:      "snappy",
+			fileType:  fileparser.CSVSNAPPY,
+			newWriter: func(w io.Writer) (io.WriteCloser, error) { return snappy.NewBufferedWriter(w), nil },
+			wantType:  fileparser.CSV,
+		},
+		{
+			name:      "s2",
+			fileType:  fileparser.CSVS2,
+			newWriter: func(w io.Writer) (io.WriteCloser, error) { return s2.NewWriter(w), nil },
+			wantType:  fileparser.CSV,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rc, ft, err := Decompress(compress(t, tt.newWriter), tt.fileType)
+			if err != nil {
+				t.Fatalf("Decompress() error = %v", err)
+			}
+			if ft != tt.wantType {
+				t.Errorf("Decompress() file type = %v, want %v", ft, tt.wantType)
+			}
+			data, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatalf("io.ReadAll() error = %v", err)
+			}
+			if string(data) != input {
+				t.Errorf("decompressed data = %q, want %q", data, input)
+			}
+			if err := rc.Close(); err != nil {
+				t.Errorf("Close() error = %v", err)
+			}
+			if err := rc.Close(); err != nil {
+				t.Errorf("second Close() error = %v", err)
+			}
+			if _, err := rc.Read(make([]byte, 1)); !errors.Is(err, ErrDecompression) {
+				t.Errorf("Read() after Close() error = %v, want ErrDecompression", err)
+			}
+		})
+	}
+
+	t.Run("uncompressed input is returned unchanged", func(t *testing.T) {
+		t.Parallel()
+
+		rc, ft, err := Decompress(strings.NewReader(input), fileparser.CSV)
+		if err != nil {
+			t.Fatalf("Decompress() error = %v", err)
+		}
+		defer rc.Close()
+		if ft != fileparser.CSV {
+			t.Errorf("Decompress() file type = %v, want CSV", ft)
+		}
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("io.ReadAll() error = %v", err)
+		}
+		if string(data) != input {
+			t.Errorf("data = %q, want %q", data, input)
+		}
+	})
+
+	t.Run("nil reader", func(t *testing.T) {
+		t.Parallel()
+
+		if _, _, err := Decompress(nil, fileparser.CSVGZ); !errors.Is(err, ErrNilReader) {
+			t.Errorf("Decompress() error = %v, want ErrNilReader", err)
+		}
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		t.Parallel()
+
+		if _, _, err := Decompress(strings.NewReader("not gzip"), fileparser.CSVGZ); !errors.Is(err, ErrDecompression) {
+			t.Errorf("Decompress() error = %v, want ErrDecompression", err)
+		}
+	})
+
+	t.Run("corrupt data", func(t *testing.T) {
+		t.Parallel()
+
+		rc, _, err := Decompress(strings.NewReader("not bzip2"), fileparser.CSVBZ2)
+		if err != nil {
+			t.Fatalf("Decompress() error = %v", err)
+		}
+		defer rc.Close()
+		if _, err := io.ReadAll(rc); !errors.Is(err, ErrDecompression) {
+			t.Errorf("io.ReadAll() error = %v, want ErrDecompression", err)
+		}
+	})
+}
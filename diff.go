@@ -0,0 +1,221 @@
+package fileprep
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// FileDiff holds the differences between two versions of a file found by
+// Compare or CompareFiles. Row values follow Columns, the header of the new
+// file; columns only in the old file are left out, and columns only in the
+// new file are empty in old rows.
+type FileDiff struct {
+	// Columns is the header of the new file
+	Columns []string
+	// Added lists the rows whose key is only in the new file, in new file order
+	Added []DiffRow
+	// Removed lists the rows whose key is only in the old file, in old file order
+	Removed []DiffRow
+	// Changed lists the rows whose key is in both files with different
+	// values, in new file order
+	Changed []RowChange
+}
+
+// DiffRow is a row that is only in one of the compared files.
+type DiffRow struct {
+	// Key contains the key column values, in key order
+	Key []string
+	// Values contains the processed row
+	Values []string
+}
+
+// RowChange is a row whose key is in both compared files with different values.
+type RowChange struct {
+	// Key contains the key column values, in key order
+	Key []string
+	// Old and New contain the processed row of each file
+	Old []string
+	New []string
+	// Columns lists the columns whose values differ, in header order
+	Columns []string
+}
+
+// HasChanges reports whether the files differ.
+func (d *FileDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// CompareFiles compares two versions of a file of type ft by the key
+// columns and returns the added, removed, and changed rows, for loading a
+// delta instead of reloading the whole file. Both files are parsed like
+// Process parses them. Use Processor.Compare to apply options and the prep
+// tags of a struct before comparing.
+//
+// Example:
+//
+//	diff, err := fileprep.CompareFiles(oldFile, newFile, fileprep.FileTypeCSV, "id")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, c := range diff.Changed {
+//	    fmt.Printf("id %s changed: %v\n", c.Key[0], c.Columns)
+//	}
+func CompareFiles(oldInput, newInput io.Reader, ft FileType, key ...string) (*FileDiff, error) {
+	var rows []struct{}
+	return NewProcessor(ft).Compare(oldInput, newInput, &rows, key...)
+}
+
+// Compare processes two versions of a file like Process and compares the
+// processed rows by the key columns, so values are compared after column
+// transforms and prep tags; two values that only differ in what a prep tag
+// normalizes, such as surrounding spaces with trim, are equal. With
+// WithValidRowsOnly, invalid rows are left out of the comparison.
+// structSlicePointer receives the structs of newInput, replacing its
+// contents as ProcessInto does.
+//
+// Without key columns, the whole row is the key, so the diff has only added
+// and removed rows. When a key repeats in a file, its rows are paired in
+// file order. Compare returns an error wrapping ErrColumnNotFound if a key
+// column is missing from either header, and the error of Process for either
+// file, including the *MultiError of WithStrictValidation.
+//
+// Example:
+//
+//	var users []User
+//	diff, err := processor.Compare(yesterday, today, &users, "email")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	log.Printf("%d added, %d removed, %d changed", len(diff.Added), len(diff.Removed), len(diff.Changed))
+func (p *Processor) Compare(oldInput, newInput io.Reader, structSlicePointer any, key ...string) (*FileDiff, error) {
+	if _, err := getStructType(structSlicePointer); err != nil {
+		return nil, err
+	}
+
+	// The comparison reads the processed rows, so keep them in the results
+	// whether or not p was created with WithResultRows
+	rp := *p
+	rp.keepRows = true
+
+	oldRows := reflect.New(reflect.TypeOf(structSlicePointer).Elem()).Interface()
+	var oldResult, newResult ProcessResult
+	if _, err := rp.ProcessInto(oldInput, oldRows, &oldResult); err != nil {
+		return nil, fmt.Errorf("old file: %w", err)
+	}
+	if _, err := rp.ProcessInto(newInput, structSlicePointer, &newResult); err != nil {
+		return nil, fmt.Errorf("new file: %w", err)
+	}
+	return diffRows(&oldResult, &newResult, key)
+}
+
+// diffRows compares the processed rows of two results by the key columns.
+func diffRows(oldResult, newResult *ProcessResult, key []string) (*FileDiff, error) {
+	columns := newResult.Columns
+	newColIdx := columnIndexes(columns)
+	oldColIdx := columnIndexes(oldResult.Columns)
+
+	if len(key) == 0 {
+		key = columns
+	}
+	keyIdxs := make([]int, 0, len(key))
+	for _, column := range key {
+		colIdx, ok := newColIdx[column]
+		if !ok {
+			return nil, fmt.Errorf("compare key column %q in new file: %w", column, ErrColumnNotFound)
+		}
+		if _, ok := oldColIdx[column]; !ok {
+			return nil, fmt.Errorf("compare key column %q in old file: %w", column, ErrColumnNotFound)
+		}
+		keyIdxs = append(keyIdxs, colIdx)
+	}
+
+	// Align old rows to the new header so rows compare cell by cell
+	oldIdxs := make([]int, len(columns))
+	for i, column := range columns {
+		colIdx, ok := oldColIdx[column]
+		if !ok {
+			colIdx = -1
+		}
+		oldIdxs[i] = colIdx
+	}
+	oldRows := make([][]string, len(oldResult.rows))
+	oldByKey := make(map[string][]int, len(oldResult.rows))
+	for i, record := range oldResult.rows {
+		row := make([]string, len(columns))
+		for j, colIdx := range oldIdxs {
+			if colIdx >= 0 {
+				row[j] = cell(record, colIdx)
+			}
+		}
+		oldRows[i] = row
+		k := diffKey(row, keyIdxs)
+		oldByKey[k] = append(oldByKey[k], i)
+	}
+
+	diff := &FileDiff{Columns: columns}
+	matched := make([]bool, len(oldRows))
+	for _, record := range newResult.rows {
+		row := make([]string, len(columns))
+		copy(row, record)
+
+		k := diffKey(row, keyIdxs)
+		queue := oldByKey[k]
+		if len(queue) == 0 {
+			diff.Added = append(diff.Added, DiffRow{Key: keyValues(row, keyIdxs), Values: row})
+			continue
+		}
+		oldIdx := queue[0]
+		oldByKey[k] = queue[1:]
+		matched[oldIdx] = true
+
+		var changed []string
+		for i, column := range columns {
+			if oldRows[oldIdx][i] != row[i] {
+				changed = append(changed, column)
+			}
+		}
+		if len(changed) > 0 {
+			diff.Changed = append(diff.Changed, RowChange{
+				Key:     keyValues(row, keyIdxs),
+				Old:     oldRows[oldIdx],
+				New:     row,
+				Columns: changed,
+			})
+		}
+	}
+
+	for i, row := range oldRows {
+		if !matched[i] {
+			diff.Removed = append(diff.Removed, DiffRow{Key: keyValues(row, keyIdxs), Values: row})
+		}
+	}
+	return diff, nil
+}
+
+// columnIndexes maps each column name to its index. When a name repeats,
+// the first column wins.
+func columnIndexes(columns []string) map[string]int {
+	idxs := make(map[string]int, len(columns))
+	for i, column := range columns {
+		if _, seen := idxs[column]; !seen {
+			idxs[column] = i
+		}
+	}
+	return idxs
+}
+
+// diffKey joins the key column values of row into a single map key.
+func diffKey(row []string, keyIdxs []int) string {
+	return strings.Join(keyValues(row, keyIdxs), duplicateKeySeparator)
+}
+
+// keyValues returns the key column values of row.
+func keyValues(row []string, keyIdxs []int) []string {
+	values := make([]string, len(keyIdxs))
+	for i, colIdx := range keyIdxs {
+		values[i] = row[colIdx]
+	}
+	return values
+}
@@ -0,0 +1,202 @@
+package fileprep
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileparser"
+)
+
+func TestCompareFiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		oldInput string
+		newInput string
+		key      []string
+		want     *FileDiff
+	}{
+		{
+			name:     "added, removed, and changed rows",
+			oldInput: "id,name,city\n1,Alice,Tokyo\n2,Bob,Osaka\n3,Carol,Kyoto\n",
+			newInput: "id,name,city\n3,Carol,Nara\n1,Alice,Tokyo\n4,Dave,Kobe\n",
+			key:      []string{"id"},
+			want: &FileDiff{
+				Columns: []string{"id", "name", "city"},
+				Added:   []DiffRow{{Key: []string{"4"}, Values: []string{"4", "Dave", "Kobe"}}},
+				Removed: []DiffRow{{Key: []string{"2"}, Values: []string{"2", "Bob", "Osaka"}}},
+				Changed: []RowChange{{
+					Key:     []string{"3"},
+					Old:     []string{"3", "Carol", "Kyoto"},
+					New:     []string{"3", "Carol", "Nara"},
+					Columns: []string{"city"},
+				}},
+			},
+		},
+		{
+			name:     "composite key",
+			oldInput: "region,id,total\neast,1,10\nwest,1,20\n",
+			newInput: "region,id,total\neast,1,10\nwest,1,25\n",
+			key:      []string{"region", "id"},
+			want: &FileDiff{
+				Columns: []string{"region", "id", "total"},
+				Changed: []RowChange{{
+					Key:     []string{"west", "1"},
+					Old:     []string{"west", "1", "20"},
+					New:     []string{"west", "1", "25"},
+					Columns: []string{"total"},
+				}},
+			},
+		},
+		{
+			name:     "whole row is the key without key columns",
+			oldInput: "id,name\n1,Alice\n2,Bob\n",
+			newInput: "id,name\n1,Alice\n2,Robert\n",
+			want: &FileDiff{
+				Columns: []string{"id", "name"},
+				Added:   []DiffRow{{Key: []string{"2", "Robert"}, Values: []string{"2", "Robert"}}},
+				Removed: []DiffRow{{Key: []string{"2", "Bob"}, Values: []string{"2", "Bob"}}},
+			},
+		},
+		{
+			name:     "repeated keys are paired in order",
+			oldInput: "id,name\n1,a\n1,b\n",
+			newInput: "id,name\n1,a\n1,c\n1,d\n",
+			key:      []string{"id"},
+			want: &FileDiff{
+				Columns: []string{"id", "name"},
+				Added:   []DiffRow{{Key: []string{"1"}, Values: []string{"1", "d"}}},
+				Changed: []RowChange{{
+					Key:     []string{"1"},
+					Old:     []string{"1", "b"},
+					New:     []string{"1", "c"},
+					Columns: []string{"name"},
+				}},
+			},
+		},
+		{
+			name:     "old rows follow the new header",
+			oldInput: "name,id,legacy\nAlice,1,x\n",
+			newInput: "id,name,email\n1,Alice,a@example.com\n",
+			key:      []string{"id"},
+			want: &FileDiff{
+				Columns: []string{"id", "name", "email"},
+				Changed: []RowChange{{
+					Key:     []string{"1"},
+					Old:     []string{"1", "Alice", ""},
+					New:     []string{"1", "Alice", "a@example.com"},
+					Columns: []string{"email"},
+				}},
+			},
+		},
+		{
+			name:     "identical files",
+			oldInput: "id,name\n1,Alice\n",
+			newInput: "id,name\n1,Alice\n",
+			key:      []string{"id"},
+			want:     &FileDiff{Columns: []string{"id", "name"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := CompareFiles(strings.NewReader(tt.oldInput), strings.NewReader(tt.newInput), FileTypeCSV, tt.key...)
+			if err != nil {
+				t.Fatalf("CompareFiles() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("CompareFiles() mismatch (-want +got):\n%s", diff)
+			}
+			if got.HasChanges() != (len(tt.want.Added)+len(tt.want.Removed)+len(tt.want.Changed) > 0) {
+				t.Errorf("HasChanges() = %v", got.HasChanges())
+			}
+		})
+	}
+}
+
+func TestCompareFiles_MissingKeyColumn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		oldInput string
+		newInput string
+	}{
+		{name: "missing in new file", oldInput: "id,name\n1,a\n", newInput: "name\na\n"},
+		{name: "missing in old file", oldInput: "name\na\n", newInput: "id,name\n1,a\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := CompareFiles(strings.NewReader(tt.oldInput), strings.NewReader(tt.newInput), FileTypeCSV, "id")
+			if !errors.Is(err, ErrColumnNotFound) {
+				t.Errorf("CompareFiles() error = %v, want ErrColumnNotFound", err)
+			}
+		})
+	}
+}
+
+func TestProcessor_Compare(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Email string `prep:"trim,lowercase" validate:"email"`
+		Name  string `prep:"trim"`
+	}
+
+	oldInput := "email,name\nALICE@EXAMPLE.COM, Alice \nbob@example.com,Bob\n"
+	newInput := "email,name\nalice@example.com,Alice\nbob@example.com,Robert\nnot-an-email,Eve\n"
+
+	t.Run("values are compared after prep", func(t *testing.T) {
+		t.Parallel()
+
+		var users []user
+		got, err := NewProcessor(fileparser.CSV, WithValidRowsOnly()).
+			Compare(strings.NewReader(oldInput), strings.NewReader(newInput), &users, "email")
+		if err != nil {
+			t.Fatalf("Compare() error = %v", err)
+		}
+		want := &FileDiff{
+			Columns: []string{"email", "name"},
+			Changed: []RowChange{{
+				Key:     []string{"bob@example.com"},
+				Old:     []string{"bob@example.com", "Bob"},
+				New:     []string{"bob@example.com", "Robert"},
+				Columns: []string{"name"},
+			}},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Compare() mismatch (-want +got):\n%s", diff)
+		}
+		wantUsers := []user{{Email: "alice@example.com", Name: "Alice"}, {Email: "bob@example.com", Name: "Robert"}}
+		if diff := cmp.Diff(wantUsers, users); diff != "" {
+			t.Errorf("users mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("strict validation error", func(t *testing.T) {
+		t.Parallel()
+
+		var users []user
+		_, err := NewProcessor(fileparser.CSV, WithStrictValidation()).
+			Compare(strings.NewReader(oldInput), strings.NewReader(newInput), &users, "email")
+		var multiErr *MultiError
+		if !errors.As(err, &multiErr) {
+			t.Errorf("Compare() error = %v, want *MultiError", err)
+		}
+	})
+
+	t.Run("invalid struct slice pointer", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewProcessor(fileparser.CSV).Compare(strings.NewReader(oldInput), strings.NewReader(newInput), nil, "email")
+		if !errors.Is(err, ErrStructSlicePointer) {
+			t.Errorf("Compare() error = %v, want ErrStructSlicePointer", err)
+		}
+	})
+}
@@ -34,6 +34,10 @@
 // usage scales with file size. For large files, ensure sufficient memory
 // is available.
 //
+// For CSV and TSV input, when preprocessing leaves every value unchanged
+// (for example, validate-only runs), the returned stream reuses the
+// decompressed input buffer instead of re-encoding the records.
+//
 // Format-specific limitations:
 //   - XLSX: Only the first sheet is processed
 //   - LTSV: Maximum line size is 10MB
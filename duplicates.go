@@ -0,0 +1,110 @@
+package fileprep
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DuplicateGroup describes rows that share the same key column values.
+// Row numbers are 1-based and exclude the header, like ValidationError.Row.
+type DuplicateGroup struct {
+	// Key contains the key column values, in WithDuplicateReport order
+	Key []string
+	// FirstRow is the row where the key first appears
+	FirstRow int
+	// DuplicateRows lists the later rows with the same key
+	DuplicateRows []int
+}
+
+// duplicateKeySeparator joins key column values into a single map key.
+// The unit separator does not appear in ordinary text data.
+const duplicateKeySeparator = "\x1f"
+
+// duplicateTracker collects duplicate key groups while rows are processed.
+type duplicateTracker struct {
+	colIdxs  []int
+	firstRow map[string]int // key -> row of first appearance
+	groupIdx map[string]int // key -> index into dupes, once repeated
+	dupes    []DuplicateGroup
+}
+
+// newDuplicateTracker resolves the key columns against the header.
+func newDuplicateTracker(keyColumns []string, headerToColIdx map[string]int) (*duplicateTracker, error) {
+	colIdxs := make([]int, 0, len(keyColumns))
+	for _, name := range keyColumns {
+		colIdx, ok := headerToColIdx[name]
+		if !ok {
+			return nil, fmt.Errorf("duplicate key column %q: %w", name, ErrColumnNotFound)
+		}
+		colIdxs = append(colIdxs, colIdx)
+	}
+	return &duplicateTracker{
+		colIdxs:  colIdxs,
+		firstRow: make(map[string]int),
+		groupIdx: make(map[string]int),
+	}, nil
+}
+
+// add records the key of a processed row.
+func (d *duplicateTracker) add(record []string, rowNum int) {
+	key := d.key(record)
+	first, seen := d.firstRow[key]
+	if !seen {
+		d.firstRow[key] = rowNum
+		return
+	}
+
+	idx, grouped := d.groupIdx[key]
+	if !grouped {
+		idx = len(d.dupes)
+		d.groupIdx[key] = idx
+		d.dupes = append(d.dupes, DuplicateGroup{
+			Key:      d.keyValues(record),
+			FirstRow: first,
+		})
+	}
+	d.dupes[idx].DuplicateRows = append(d.dupes[idx].DuplicateRows, rowNum)
+}
+
+// startChunk clears the reported groups so the next groups call only covers
+// rows added since. Keys seen earlier are kept, so repeats across chunks are
+// still detected.
+func (d *duplicateTracker) startChunk() {
+	d.dupes = nil
+	clear(d.groupIdx)
+}
+
+// groups returns the duplicate groups ordered by first appearance.
+func (d *duplicateTracker) groups() []DuplicateGroup {
+	// Groups are created when a key repeats, so sort them by FirstRow
+	slices.SortFunc(d.dupes, func(a, b DuplicateGroup) int {
+		return cmp.Compare(a.FirstRow, b.FirstRow)
+	})
+	return d.dupes
+}
+
+// key returns the map key for the row's key column values.
+func (d *duplicateTracker) key(record []string) string {
+	if len(d.colIdxs) == 1 {
+		return record[d.colIdxs[0]]
+	}
+	var b strings.Builder
+	for i, colIdx := range d.colIdxs {
+		if i > 0 {
+			b.WriteString(duplicateKeySeparator)
+		}
+		b.WriteString(record[colIdx])
+	}
+	return b.String()
+}
+
+// keyValues returns a copy of the row's key column values.
+func (d *duplicateTracker) keyValues(record []string) []string {
+	values := make([]string, len(d.colIdxs))
+	for i, colIdx := range d.colIdxs {
+		values[i] = record[colIdx]
+	}
+	return values
+}
@@ -0,0 +1,91 @@
+package fileprep
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileparser"
+)
+
+func TestProcessor_WithDuplicateReport(t *testing.T) {
+	t.Parallel()
+
+	type order struct {
+		Customer string `prep:"trim,lowercase"`
+		Date     string
+		Amount   string `validate:"numeric"`
+	}
+
+	input := "customer,date,amount\n" +
+		"alice,2024-01-01,10\n" +
+		"bob,2024-01-01,20\n" +
+		"carol,2024-01-02,x\n" +
+		" Bob ,2024-01-01,30\n" +
+		"carol,2024-01-02,40\n" +
+		"BOB,2024-01-01,50\n" +
+		"alice,2024-01-02,60\n"
+
+	tests := []struct {
+		name string
+		keys []string
+		want []DuplicateGroup
+	}{
+		{
+			name: "single key column compares preprocessed values",
+			keys: []string{"customer"},
+			want: []DuplicateGroup{
+				{Key: []string{"alice"}, FirstRow: 1, DuplicateRows: []int{7}},
+				{Key: []string{"bob"}, FirstRow: 2, DuplicateRows: []int{4, 6}},
+				{Key: []string{"carol"}, FirstRow: 3, DuplicateRows: []int{5}},
+			},
+		},
+		{
+			name: "composite key",
+			keys: []string{"customer", "date"},
+			want: []DuplicateGroup{
+				{Key: []string{"bob", "2024-01-01"}, FirstRow: 2, DuplicateRows: []int{4, 6}},
+				{Key: []string{"carol", "2024-01-02"}, FirstRow: 3, DuplicateRows: []int{5}},
+			},
+		},
+		{
+			name: "no duplicates",
+			keys: []string{"amount"},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var records []order
+			processor := NewProcessor(fileparser.CSV, WithDuplicateReport(tt.keys...))
+			_, result, err := processor.Process(strings.NewReader(input), &records)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, result.Duplicates); diff != "" {
+				t.Errorf("Duplicates mismatch (-want +got):\n%s", diff)
+			}
+			if result.HasDuplicates() != (len(tt.want) > 0) {
+				t.Errorf("HasDuplicates() = %v, want %v", result.HasDuplicates(), len(tt.want) > 0)
+			}
+			// Duplicates do not affect validity or output
+			if result.ValidRowCount != 6 || len(records) != 7 {
+				t.Errorf("ValidRowCount = %d, len(records) = %d, want 6 and 7", result.ValidRowCount, len(records))
+			}
+		})
+	}
+
+	t.Run("unknown key column", func(t *testing.T) {
+		t.Parallel()
+
+		var records []order
+		processor := NewProcessor(fileparser.CSV, WithDuplicateReport("missing"))
+		_, _, err := processor.Process(strings.NewReader(input), &records)
+		if err == nil || !strings.Contains(err.Error(), `"missing"`) {
+			t.Errorf("Process() error = %v, want key column not found", err)
+		}
+	})
+}
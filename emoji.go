@@ -0,0 +1,77 @@
+package fileprep
+
+import "unicode"
+
+// emojiTable holds the code points shown as emoji: pictographs, emoticons,
+// transport and map symbols, regional indicators, skin tone modifiers, and
+// the BMP symbols and dingbats that have an emoji presentation. Characters
+// that are only emoji when followed by U+FE0F, such as © and digits, are
+// not included.
+//
+//nolint:gochecknoglobals // lookup table
+var emojiTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x231a, Hi: 0x231b, Stride: 1},
+		{Lo: 0x23e9, Hi: 0x23f3, Stride: 1},
+		{Lo: 0x23f8, Hi: 0x23fa, Stride: 1},
+		{Lo: 0x2600, Hi: 0x27bf, Stride: 1},
+		{Lo: 0x2b1b, Hi: 0x2b1c, Stride: 1},
+		{Lo: 0x2b50, Hi: 0x2b55, Stride: 5},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f000, Hi: 0x1f0ff, Stride: 1},
+		{Lo: 0x1f170, Hi: 0x1f1ff, Stride: 1},
+		{Lo: 0x1f300, Hi: 0x1f6ff, Stride: 1},
+		{Lo: 0x1f7e0, Hi: 0x1f7f0, Stride: 1},
+		{Lo: 0x1f900, Hi: 0x1f9ff, Stride: 1},
+		{Lo: 0x1fa70, Hi: 0x1faff, Stride: 1},
+	},
+}
+
+// Code points that only join or modify emoji
+const (
+	zeroWidthJoiner   = '\u200d'
+	variationSelector = '\ufe0f' // VS16, requests emoji presentation
+	combiningKeycap   = '\u20e3'
+)
+
+// isEmoji reports whether r is an emoji code point.
+func isEmoji(r rune) bool {
+	return r >= 0x231a && unicode.Is(emojiTable, r)
+}
+
+// isEmojiComponent reports whether r only has a meaning as part of an emoji
+// sequence: VS16, the combining keycap, and tag characters for flags.
+func isEmojiComponent(r rune) bool {
+	return r == variationSelector || r == combiningKeycap || (r >= 0xe0020 && r <= 0xe007f)
+}
+
+// stripEmoji removes emoji and the characters that join or modify them.
+// A zero width joiner is removed only next to an emoji, since scripts such
+// as Devanagari use it between letters.
+func stripEmoji(value string) string {
+	runes := []rune(value)
+	kept := runes[:0]
+	for i, r := range runes {
+		switch {
+		case isEmoji(r), isEmojiComponent(r):
+			continue
+		case r == zeroWidthJoiner:
+			if (i > 0 && isEmoji(runes[i-1])) || (i+1 < len(runes) && isEmoji(runes[i+1])) {
+				continue
+			}
+		}
+		kept = append(kept, r)
+	}
+	return string(kept)
+}
+
+// containsEmoji reports whether value contains an emoji code point.
+func containsEmoji(value string) bool {
+	for _, r := range value {
+		if isEmoji(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,20 +3,81 @@ package fileprep
 import (
 	"errors"
 	"fmt"
+	"iter"
+	"reflect"
+	"strings"
 
 	"github.com/nao1215/fileparser"
 )
 
-// Sentinel errors for fileprep
+// Sentinel errors for fileprep. Every error returned by fileprep for one of
+// these conditions wraps the matching sentinel, so errors.Is keeps working
+// when the message text changes. The sentinels fall into these groups:
+//
+//   - Configuration: ErrStructSlicePointer, ErrInvalidTagFormat (and the more
+//     specific ErrUnknownPrepTag and ErrUnknownValidateTag),
+//     ErrUnsupportedFieldType, ErrUnsupportedFileType, ErrInvalidOption,
+//     ErrInvalidSchema
+//   - Input: ErrNilReader, ErrDecompression, ErrParse, ErrEmptyFile (and the
+//     more specific ErrNoHeader, ErrWhitespaceOnly, and ErrHeaderOnly),
+//     ErrDuplicateColumn, ErrColumnNotFound, ErrSchemaMismatch,
+//     ErrQuotedNewline, ErrInputTooLarge, ErrTooManyRows, ErrDownloadTooLarge,
+//     ErrDuplicateKey
+//   - Output: ErrInvalidJSONAfterPrep, ErrEmptyJSONOutput
+//   - Per-row results: ErrValidation and ErrPrep, matched by the entries of
+//     ProcessResult.Errors rather than returned by Process
+//
+// An error may match more than one sentinel: a parse failure caused by an
+// empty file matches both ErrParse and ErrNoHeader.
 var (
 	// ErrStructSlicePointer is returned when the value is not a pointer to a struct slice
 	ErrStructSlicePointer = errors.New("value must be a pointer to a struct slice")
+	// ErrUnsupportedFieldType is wrapped by the PrepError of a value bound to
+	// a struct field whose kind fileprep cannot set, such as a map or a slice
+	ErrUnsupportedFieldType = errors.New("unsupported field type")
 	// ErrUnsupportedFileType is returned when the file type is not supported
 	ErrUnsupportedFileType = errors.New("unsupported file type")
-	// ErrEmptyFile is returned when the file is empty
+	// ErrEmptyFile is returned when the file is empty. ErrNoHeader,
+	// ErrWhitespaceOnly, and ErrHeaderOnly tell the cases apart and all match
+	// ErrEmptyFile with errors.Is.
 	ErrEmptyFile = errors.New("file is empty")
+	// ErrNoHeader is returned when the input has no header row: it has no
+	// bytes, only a byte order mark, or an XLSX sheet without rows.
+	ErrNoHeader = fmt.Errorf("%w: no header row", ErrEmptyFile)
+	// ErrWhitespaceOnly is returned when a text input holds only whitespace
+	// and line breaks.
+	ErrWhitespaceOnly = fmt.Errorf("%w: only whitespace", ErrEmptyFile)
+	// ErrHeaderOnly is returned when the input has a header but no data rows
+	// and WithRejectHeaderOnly is enabled.
+	ErrHeaderOnly = fmt.Errorf("%w: header but no data rows", ErrEmptyFile)
 	// ErrInvalidTagFormat is returned when the tag format is invalid
 	ErrInvalidTagFormat = errors.New("invalid tag format")
+	// ErrUnknownPrepTag is returned when a prep tag has an unknown name. It is
+	// wrapped together with ErrInvalidTagFormat.
+	ErrUnknownPrepTag = errors.New("unknown prep tag")
+	// ErrUnknownValidateTag is returned when a validate tag has an unknown
+	// name. It is wrapped together with ErrInvalidTagFormat.
+	ErrUnknownValidateTag = errors.New("unknown validate tag")
+	// ErrInvalidOption is returned when an option was given an unusable
+	// value, such as an unknown header rule or a malformed JSON record path.
+	ErrInvalidOption = errors.New("invalid option")
+	// ErrInvalidSchema is returned by NewProcessorFromJSONSchema and
+	// NewProcessorFromOpenAPI when the schema is not valid JSON, cannot be
+	// found, or uses a keyword fileprep cannot compile.
+	ErrInvalidSchema = errors.New("invalid or unsupported JSON Schema")
+	// ErrNilReader is returned when the input reader is nil
+	ErrNilReader = errors.New("reader cannot be nil")
+	// ErrDecompression is returned when the compressed input cannot be read
+	ErrDecompression = errors.New("failed to decompress")
+	// ErrParse is returned when the decompressed input cannot be parsed as
+	// the configured file type
+	ErrParse = errors.New("failed to parse input")
+	// ErrDuplicateColumn is returned when the header holds the same column
+	// name twice, or a column transform adds a column that already exists.
+	ErrDuplicateColumn = errors.New("duplicate column name")
+	// ErrColumnNotFound is returned when an option names a column that is not
+	// in the header.
+	ErrColumnNotFound = errors.New("column not found in header")
 	// ErrInvalidJSONAfterPrep is returned when preprocessing destroys JSON structure
 	// in the "data" column of a JSON/JSONL file. This is a hard error because
 	// invalid JSON lines in JSONL output cause downstream parsers to fail.
@@ -25,63 +86,190 @@ var (
 	// after preprocessing, resulting in no output lines. An empty JSONL output is
 	// unparseable by downstream consumers.
 	ErrEmptyJSONOutput = errors.New("JSON/JSONL output has no valid rows after preprocessing")
+	// ErrSchemaMismatch is returned (wrapped in a SchemaError) when the file's
+	// header does not match the columns configured with WithExpectedColumns,
+	// or lacks a struct field column under ProfileStrict.
+	ErrSchemaMismatch = errors.New("header does not match expected columns")
+	// ErrQuotedNewline is returned when a quoted CSV/TSV field contains a line
+	// break and WithDisallowQuotedNewlines is enabled.
+	ErrQuotedNewline = errors.New("quoted field contains a line break")
+	// ErrValidation matches every *ValidationError with errors.Is
+	ErrValidation = errors.New("validation failed")
+	// ErrPrep matches every *PrepError with errors.Is
+	ErrPrep = errors.New("preprocessing failed")
+	// ErrDownloadTooLarge is returned by ProcessURL when the remote object is
+	// larger than the WithMaxDownloadBytes limit.
+	ErrDownloadTooLarge = errors.New("download exceeds size limit")
+	// ErrInputTooLarge is returned when the decompressed input is larger than
+	// the WithMaxBytes limit.
+	ErrInputTooLarge = errors.New("input exceeds size limit")
+	// ErrTooManyRows is returned when the input has more data rows than the
+	// WithMaxRows limit.
+	ErrTooManyRows = errors.New("input exceeds row limit")
+	// ErrDuplicateKey is returned by MergeFiles when two rows share a key
+	// under MergeError.
+	ErrDuplicateKey = errors.New("duplicate key")
+)
+
+// typeConversionTag is the PrepError tag used when a value cannot be
+// converted to the struct field type.
+const typeConversionTag = "type_conversion"
+
+// typeValidationTag is the ValidationError tag used instead of
+// typeConversionTag with WithConversionErrorsAsValidation.
+const typeValidationTag = "type"
+
+// Severity classifies a ValidationError.
+type Severity int
+
+const (
+	// SeverityError marks a failed validate rule; the row is invalid.
+	SeverityError Severity = iota
+	// SeverityWarning marks a failed warning-level rule; the row stays valid.
+	SeverityWarning
+)
+
+// String returns the lower-case severity name
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "unknown"
+	}
+}
+
+// Stable ValidationError codes that are not derived from the tag name.
+const (
+	// CodeRequiredMissing is the code for a failed required rule
+	CodeRequiredMissing = "REQUIRED_MISSING"
+	// CodeTargetFieldNotFound is the code for a cross-field rule whose target
+	// field does not exist in the struct or file
+	CodeTargetFieldNotFound = "TARGET_FIELD_NOT_FOUND"
+	// CodeNonIdempotentPrep is the code for a warning from
+	// WithPrepIdempotencyCheck: a second pass of the prep chain changed the value
+	CodeNonIdempotentPrep = "NON_IDEMPOTENT_PREP"
 )
 
 // ValidationError represents a validation error with row and column information.
+// The human-readable message is reported by the failed rule; most rules
+// build it once when their tag is parsed rather than for each failed row.
 //
 // Example:
 //
 //	for _, ve := range result.ValidationErrors() {
 //	    fmt.Printf("Row %d, Column %q: %s (value=%q)\n",
-//	        ve.Row, ve.Column, ve.Message, ve.Value)
+//	        ve.Row, ve.Column, ve.Message(), ve.Value)
 //	}
 type ValidationError struct {
-	Row     int    // 1-based row number (excluding header)
-	Column  string // Column name
-	Field   string // Struct field name
-	Value   string // The value that failed validation
-	Tag     string // The validation tag that failed
-	Message string // Human-readable error message
+	Row      int      // 1-based row number (excluding header)
+	Column   string   // Column name
+	Field    string   // Struct field name
+	Value    string   // The offending value, as validated (after preprocessing)
+	Tag      string   // The validation tag that failed
+	Param    string   // The tag parameter (e.g. "0" for min=0), empty if the tag has none
+	Severity Severity // SeverityError, or SeverityWarning for warning-level rules
+
+	message string // message reported by the failed rule
+	code    string // fixed code; empty when derived from Tag
+}
+
+// Code returns a stable, machine-readable error code so that callers can
+// branch on the failure without parsing Message. The code is the upper-cased
+// tag with an "_INVALID" suffix (e.g. "EMAIL_INVALID", "MIN_INVALID"), except
+// for CodeRequiredMissing, CodeTargetFieldNotFound, and CodeNonIdempotentPrep.
+func (e *ValidationError) Code() string {
+	if e.code != "" {
+		return e.code
+	}
+	if e.Tag == requiredTagValue {
+		return CodeRequiredMissing
+	}
+	return strings.ToUpper(e.Tag) + "_INVALID"
+}
+
+// Message returns the human-readable error message
+func (e *ValidationError) Message() string {
+	return e.message
 }
 
 // Error implements the error interface
 func (e *ValidationError) Error() string {
 	return fmt.Sprintf("row %d, column %q (field %s): %s (value=%q, tag=%s)",
-		e.Row, e.Column, e.Field, e.Message, e.Value, e.Tag)
+		e.Row, e.Column, e.Field, e.Message(), e.Value, e.Tag)
+}
+
+// Is reports whether target is ErrValidation, so that
+// errors.Is(err, ErrValidation) matches any validation failure
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
 }
 
 // newValidationError creates a new ValidationError
-func newValidationError(row int, column, field, value, tag, message string) *ValidationError {
+func newValidationError(row int, column, field, value, tag, param, message string) *ValidationError {
 	return &ValidationError{
 		Row:     row,
 		Column:  column,
 		Field:   field,
 		Value:   value,
 		Tag:     tag,
-		Message: message,
+		Param:   param,
+		message: message,
 	}
 }
 
+// asWarning marks the error as warning-level and returns it
+func (e *ValidationError) asWarning() *ValidationError {
+	e.Severity = SeverityWarning
+	return e
+}
+
 // PrepError represents a preprocessing error.
+// Type-conversion messages are rendered on demand by Message or Error.
 //
 // Example:
 //
 //	for _, pe := range result.PrepErrors() {
 //	    fmt.Printf("Row %d, Column %q: %s (tag=%q)\n",
-//	        pe.Row, pe.Column, pe.Message, pe.Tag)
+//	        pe.Row, pe.Column, pe.Message(), pe.Tag)
 //	}
 type PrepError struct {
-	Row     int    // 1-based row number
-	Column  string // Column name
-	Field   string // Struct field name
-	Tag     string // The prep tag that failed
-	Message string // Human-readable error message
+	Row    int    // 1-based row number
+	Column string // Column name
+	Field  string // Struct field name
+	Tag    string // The prep tag that failed
+
+	message string // fixed message; empty when rendered from value and cause
+	value   string // value that could not be processed
+	cause   error  // underlying error (e.g. strconv failure on type conversion)
+}
+
+// Message returns the human-readable error message
+func (e *PrepError) Message() string {
+	if e.cause != nil {
+		return fmt.Sprintf("failed to convert value %q: %v", e.value, e.cause)
+	}
+	return e.message
 }
 
 // Error implements the error interface
 func (e *PrepError) Error() string {
 	return fmt.Sprintf("row %d, column %q (field %s): prep error - %s (tag=%s)",
-		e.Row, e.Column, e.Field, e.Message, e.Tag)
+		e.Row, e.Column, e.Field, e.Message(), e.Tag)
+}
+
+// Is reports whether target is ErrPrep, so that
+// errors.Is(err, ErrPrep) matches any preprocessing failure
+func (e *PrepError) Is(target error) bool {
+	return target == ErrPrep
+}
+
+// Unwrap returns the underlying cause, such as the strconv error of a failed
+// type conversion, or nil
+func (e *PrepError) Unwrap() error {
+	return e.cause
 }
 
 // newPrepError creates a new PrepError
@@ -91,10 +279,116 @@ func newPrepError(row int, column, field, tag, message string) *PrepError {
 		Column:  column,
 		Field:   field,
 		Tag:     tag,
-		Message: message,
+		message: message,
 	}
 }
 
+// newTypeValidationError creates a ValidationError for a value that could
+// not be converted to the struct field type, for WithConversionErrorsAsValidation.
+func newTypeValidationError(row int, column, field, value string, typ reflect.Type) *ValidationError {
+	return newValidationError(row, column, field, value, typeValidationTag, typ.String(),
+		"value cannot be converted to "+typ.String())
+}
+
+// newConversionError creates a PrepError for a value that could not be
+// converted to the struct field type. The message is built lazily from
+// value and cause.
+func newConversionError(row int, column, field, value string, cause error) *PrepError {
+	return &PrepError{
+		Row:    row,
+		Column: column,
+		Field:  field,
+		Tag:    typeConversionTag,
+		value:  value,
+		cause:  cause,
+	}
+}
+
+// SchemaError reports how a file's header differs from the columns configured
+// with WithExpectedColumns, or, under ProfileStrict, which struct field
+// columns it lacks. It is returned by Process before any row is processed,
+// and matches ErrSchemaMismatch with errors.Is.
+//
+// Example:
+//
+//	_, _, err := processor.Process(input, &records)
+//	var se *fileprep.SchemaError
+//	if errors.As(err, &se) {
+//	    fmt.Println("missing:", se.Missing, "extra:", se.Extra, "reordered:", se.Reordered)
+//	}
+type SchemaError struct {
+	Missing   []string // Expected columns absent from the header
+	Extra     []string // Header columns that were not expected
+	Reordered []string // Columns present in both but in a different relative order
+}
+
+// Error implements the error interface
+func (e *SchemaError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, fmt.Sprintf("missing %q", e.Missing))
+	}
+	if len(e.Extra) > 0 {
+		parts = append(parts, fmt.Sprintf("extra %q", e.Extra))
+	}
+	if len(e.Reordered) > 0 {
+		parts = append(parts, fmt.Sprintf("reordered %q", e.Reordered))
+	}
+	return ErrSchemaMismatch.Error() + ": " + strings.Join(parts, ", ")
+}
+
+// Unwrap returns ErrSchemaMismatch so that errors.Is works
+func (e *SchemaError) Unwrap() error {
+	return ErrSchemaMismatch
+}
+
+// maxMultiErrorLines is the number of errors listed in MultiError.Error
+const maxMultiErrorLines = 10
+
+// MultiError combines the validation and preprocessing errors of a run.
+// It is returned by Process when WithStrictValidation is enabled and any
+// row has errors. Each error can be reached with errors.Is and errors.As.
+//
+// Example:
+//
+//	_, result, err := processor.Process(input, &records)
+//	var me *fileprep.MultiError
+//	if errors.As(err, &me) {
+//	    fmt.Printf("%d errors\n", len(me.Errors))
+//	}
+//	var ve *fileprep.ValidationError
+//	if errors.As(err, &ve) {
+//	    fmt.Println("first validation error:", ve.Code())
+//	}
+type MultiError struct {
+	Errors []error
+}
+
+// Error implements the error interface.
+// At most the first 10 errors are listed.
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d errors occurred:", len(e.Errors))
+	for i, err := range e.Errors {
+		if i == maxMultiErrorLines {
+			fmt.Fprintf(&b, "\n\t... and %d more", len(e.Errors)-maxMultiErrorLines)
+			break
+		}
+		b.WriteString("\n\t* ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap returns the combined errors for errors.Is and errors.As
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
 // ProcessResult contains the results of processing a file.
 //
 // Example:
@@ -102,7 +396,7 @@ func newPrepError(row int, column, field, tag, message string) *PrepError {
 //	reader, result, err := processor.Process(input, &records)
 //	if result.HasErrors() {
 //	    for _, ve := range result.ValidationErrors() {
-//	        fmt.Printf("Row %d: %s\n", ve.Row, ve.Message)
+//	        fmt.Printf("Row %d: %s\n", ve.Row, ve.Message())
 //	    }
 //	}
 //	fmt.Printf("Valid: %d/%d rows\n", result.ValidRowCount, result.RowCount)
@@ -113,10 +407,164 @@ type ProcessResult struct {
 	RowCount int
 	// ValidRowCount is the number of rows that passed all validations
 	ValidRowCount int
+	// FilteredRowCount is the number of data rows dropped by WithFilter.
+	// They are not processed and not counted in RowCount.
+	FilteredRowCount int
+	// Warnings contains failures of warning-level rules (warn tag or
+	// WithWarningValidators). Warnings do not affect ValidRowCount.
+	Warnings []*ValidationError
+	// Duplicates lists groups of rows sharing the same key columns, in
+	// order of first appearance. It is only set with WithDuplicateReport.
+	Duplicates []DuplicateGroup
+	// HeaderIssues lists column names that are unsafe as SQLite column
+	// names or break the naming rules. It is only set with
+	// WithSQLHeaderCheck or WithHeaderRules.
+	HeaderIssues []HeaderIssue
+	// JSONTypes maps each top-level key of JSON/JSONL object records to the
+	// type of its values in the output, so loaders can create typed columns
+	// even though the data column holds raw JSON. It is only set for JSON
+	// and JSONL input.
+	JSONTypes map[string]JSONType
+	// Repairs counts, per column, the cells that were repaired rather than
+	// read as they were: padded, truncated, clamped, cleared, or bound with
+	// a conversion fallback. Columns without repairs are absent; the map is nil
+	// when no cell was repaired.
+	Repairs map[string]ColumnRepairs
+	// Suggestions lists corrected values for failed validations, in row
+	// order. It is only set with WithFixSuggestions.
+	Suggestions []FixSuggestion
+	// Perf reports the input size, buffer size, and per-phase durations of
+	// the run
+	Perf PerfStats
 	// Columns contains the column names from the header
 	Columns []string
 	// OriginalFormat is the file type that was processed
 	OriginalFormat fileparser.FileType
+
+	// changes is the audit trail recorded with WithChangeTracking
+	changes []CellChange
+	// rows holds the processed rows returned by Rows, with WithResultRows
+	rows [][]string
+	// source is the input the result covers for WriteAnnotated, with
+	// WithAnnotations
+	source *annotationSource
+}
+
+// ColumnRepairs counts the repaired cells of one column in ProcessResult.Repairs.
+type ColumnRepairs struct {
+	// Padded is the number of cells missing from short rows and filled with
+	// an empty string
+	Padded int
+	// Truncated is the number of values shortened by the truncate preprocessor
+	Truncated int
+	// Clamped is the number of values clipped into range by the clamp or
+	// winsorize preprocessor
+	Clamped int
+	// Cleared is the number of invalid values replaced with an empty string
+	// because the field's validate tag has orclear
+	Cleared int
+	// ConversionFallbacks is the number of values that could not be converted
+	// to the field type and were handled by the WithOnConversionError fallback
+	ConversionFallbacks int
+}
+
+// Reset clears the result for reuse with ProcessInto. The Errors, Warnings,
+// and change tracking slices keep their capacity, so a service that
+// processes many inputs with one result allocates less. Values read from the
+// result before Reset, such as the Errors slice, must not be used afterwards.
+func (r *ProcessResult) Reset() {
+	clear(r.Errors)
+	clear(r.Warnings)
+	clear(r.changes)
+	*r = ProcessResult{
+		Errors:   r.Errors[:0],
+		Warnings: r.Warnings[:0],
+		changes:  r.changes[:0],
+	}
+}
+
+// repair applies fn to the repair counts of column.
+func (r *ProcessResult) repair(column string, fn func(*ColumnRepairs)) {
+	if r.Repairs == nil {
+		r.Repairs = make(map[string]ColumnRepairs)
+	}
+	counts := r.Repairs[column]
+	fn(&counts)
+	r.Repairs[column] = counts
+}
+
+// CellChange records a value modified by preprocessing, or cleared by
+// orclear.
+type CellChange struct {
+	// Row is the 1-based data row number (excluding header)
+	Row int
+	// Column is the column name
+	Column string
+	// Field is the struct field name
+	Field string
+	// Before is the value as read from the input
+	Before string
+	// After is the value after preprocessing
+	After string
+	// Applied lists the preprocessors that changed the value, in order,
+	// followed by orclear if the value was cleared
+	Applied []string
+}
+
+// Changes returns the cells modified by preprocessing or cleared by
+// orclear, in row order.
+// It is empty unless the Processor was created with WithChangeTracking.
+func (r *ProcessResult) Changes() []CellChange {
+	return r.changes
+}
+
+// Rows returns the processed rows, with cells in Columns order. These are
+// the rows written to the output stream, before any output column order is
+// applied: every row, or only the valid rows with WithValidRowsOnly. Use it
+// to work with the cleaned data in memory without parsing the output stream.
+// The slices are shared with the result, so modify copies.
+// It is empty unless the Processor was created with WithResultRows, since
+// the result then keeps every row in memory.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithResultRows())
+//	_, result, err := processor.Process(input, &records)
+//	for _, row := range result.Rows() {
+//	    fmt.Println(strings.Join(row, "|"))
+//	}
+func (r *ProcessResult) Rows() [][]string {
+	return r.rows
+}
+
+// RowMaps returns an iterator over the processed rows of Rows as maps from
+// column name to value. Each map is newly allocated. When a column name
+// repeats in the header, the first column wins.
+//
+// Example:
+//
+//	for row := range result.RowMaps() {
+//	    fmt.Println(row["email"])
+//	}
+func (r *ProcessResult) RowMaps() iter.Seq[map[string]string] {
+	return func(yield func(map[string]string) bool) {
+		for _, row := range r.rows {
+			m := make(map[string]string, len(r.Columns))
+			for i, column := range r.Columns {
+				if _, seen := m[column]; seen {
+					continue
+				}
+				value := ""
+				if i < len(row) {
+					value = row[i]
+				}
+				m[column] = value
+			}
+			if !yield(m) {
+				return
+			}
+		}
+	}
 }
 
 // InvalidRowCount returns the number of rows that failed validation
@@ -129,6 +577,16 @@ func (r *ProcessResult) HasErrors() bool {
 	return len(r.Errors) > 0
 }
 
+// HasWarnings returns true if any warning-level rule failed
+func (r *ProcessResult) HasWarnings() bool {
+	return len(r.Warnings) > 0
+}
+
+// HasDuplicates returns true if WithDuplicateReport found duplicate keys
+func (r *ProcessResult) HasDuplicates() bool {
+	return len(r.Duplicates) > 0
+}
+
 // ValidationErrors returns only validation errors
 func (r *ProcessResult) ValidationErrors() []*ValidationError {
 	var errs []*ValidationError
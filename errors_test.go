@@ -1,8 +1,14 @@
 package fileprep
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 func TestValidationError_Error(t *testing.T) {
@@ -22,7 +28,7 @@ func TestValidationError_Error(t *testing.T) {
 				Field:   "Email",
 				Value:   "invalid",
 				Tag:     "email",
-				Message: "must be a valid email",
+				message: "must be a valid email",
 			},
 			wantRow: 1,
 			wantCol: "email",
@@ -35,7 +41,7 @@ func TestValidationError_Error(t *testing.T) {
 				Field:   "Name",
 				Value:   "",
 				Tag:     "required",
-				Message: "field is required",
+				message: "field is required",
 			},
 			wantRow: 5,
 			wantCol: "name",
@@ -59,7 +65,7 @@ func TestValidationError_Error(t *testing.T) {
 func Test_newValidationError(t *testing.T) {
 	t.Parallel()
 
-	err := newValidationError(1, "email", "Email", "invalid", "email", "must be a valid email")
+	err := newValidationError(1, "email", "Email", "invalid", "email", "", "must be a valid email")
 
 	if err.Row != 1 {
 		t.Errorf("Row = %d, want 1", err.Row)
@@ -76,8 +82,8 @@ func Test_newValidationError(t *testing.T) {
 	if err.Tag != "email" {
 		t.Errorf("Tag = %q, want %q", err.Tag, "email")
 	}
-	if err.Message != "must be a valid email" {
-		t.Errorf("Message = %q, want %q", err.Message, "must be a valid email")
+	if err.Message() != "must be a valid email" {
+		t.Errorf("Message() = %q, want %q", err.Message(), "must be a valid email")
 	}
 }
 
@@ -89,7 +95,7 @@ func TestPrepError_Error(t *testing.T) {
 		Column:  "value",
 		Field:   "Value",
 		Tag:     "truncate",
-		Message: "truncation failed",
+		message: "truncation failed",
 	}
 
 	errStr := err.Error()
@@ -121,8 +127,8 @@ func Test_newPrepError(t *testing.T) {
 	if err.Tag != "regex_replace" {
 		t.Errorf("Tag = %q, want %q", err.Tag, "regex_replace")
 	}
-	if err.Message != "invalid regex pattern" {
-		t.Errorf("Message = %q, want %q", err.Message, "invalid regex pattern")
+	if err.Message() != "invalid regex pattern" {
+		t.Errorf("Message() = %q, want %q", err.Message(), "invalid regex pattern")
 	}
 }
 
@@ -155,6 +161,35 @@ func TestProcessResult_InvalidRowCount(t *testing.T) {
 	}
 }
 
+func TestProcessResult_Reset(t *testing.T) {
+	t.Parallel()
+
+	errs := make([]error, 0, 8)
+	result := &ProcessResult{
+		Errors:        append(errs, newValidationError(1, "name", "Name", "", "required", "", "value is required")),
+		Warnings:      []*ValidationError{newValidationError(1, "name", "Name", "", "email", "", "bad")},
+		RowCount:      3,
+		ValidRowCount: 2,
+		Duplicates:    []DuplicateGroup{{Key: []string{"1"}, FirstRow: 1, DuplicateRows: []int{2}}},
+		Repairs:       map[string]ColumnRepairs{"name": {Padded: 1}},
+		Columns:       []string{"name"},
+		changes:       []CellChange{{Row: 1, Column: "name"}},
+		rows:          [][]string{{"a"}},
+	}
+
+	result.Reset()
+
+	if diff := cmp.Diff(ProcessResult{}, *result, cmp.AllowUnexported(ProcessResult{}), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Reset() left values behind (-want +got):\n%s", diff)
+	}
+	if cap(result.Errors) != 8 {
+		t.Errorf("cap(Errors) = %d, want the backing array of capacity 8 to be kept", cap(result.Errors))
+	}
+	if errs[:1][0] != nil {
+		t.Error("Reset() kept a reference to the old error in the backing array")
+	}
+}
+
 func TestProcessResult_HasErrors(t *testing.T) {
 	t.Parallel()
 
@@ -169,7 +204,7 @@ func TestProcessResult_HasErrors(t *testing.T) {
 	t.Run("with errors", func(t *testing.T) {
 		t.Parallel()
 		r := &ProcessResult{
-			Errors: []error{newValidationError(1, "col", "Field", "val", "tag", "msg")},
+			Errors: []error{newValidationError(1, "col", "Field", "val", "tag", "", "msg")},
 		}
 		if !r.HasErrors() {
 			t.Error("HasErrors() should return true when errors exist")
@@ -180,8 +215,8 @@ func TestProcessResult_HasErrors(t *testing.T) {
 func TestProcessResult_ValidationErrors(t *testing.T) {
 	t.Parallel()
 
-	ve1 := newValidationError(1, "col1", "Field1", "val1", "tag1", "msg1")
-	ve2 := newValidationError(2, "col2", "Field2", "val2", "tag2", "msg2")
+	ve1 := newValidationError(1, "col1", "Field1", "val1", "tag1", "", "msg1")
+	ve2 := newValidationError(2, "col2", "Field2", "val2", "tag2", "", "msg2")
 	pe1 := newPrepError(3, "col3", "Field3", "tag3", "msg3")
 
 	r := &ProcessResult{
@@ -197,7 +232,7 @@ func TestProcessResult_ValidationErrors(t *testing.T) {
 func TestProcessResult_PrepErrors(t *testing.T) {
 	t.Parallel()
 
-	ve1 := newValidationError(1, "col1", "Field1", "val1", "tag1", "msg1")
+	ve1 := newValidationError(1, "col1", "Field1", "val1", "tag1", "", "msg1")
 	pe1 := newPrepError(2, "col2", "Field2", "tag2", "msg2")
 	pe2 := newPrepError(3, "col3", "Field3", "tag3", "msg3")
 
@@ -210,3 +245,239 @@ func TestProcessResult_PrepErrors(t *testing.T) {
 		t.Errorf("PrepErrors() returned %d errors, want 2", len(prepErrors))
 	}
 }
+
+func Test_newConversionError(t *testing.T) {
+	t.Parallel()
+
+	cause := errors.New("invalid syntax")
+	err := newConversionError(4, "age", "Age", "abc", cause)
+
+	if err.Tag != "type_conversion" {
+		t.Errorf("Tag = %q, want %q", err.Tag, "type_conversion")
+	}
+	want := `failed to convert value "abc": invalid syntax`
+	if got := err.Message(); got != want {
+		t.Errorf("Message() = %q, want %q", got, want)
+	}
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("Error() = %q, want it to contain %q", err.Error(), want)
+	}
+}
+
+func TestValidationError_Param(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		Age   string `validate:"min=18"`
+		Email string `validate:"email"`
+	}
+
+	var records []record
+	_, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader("age,email\n10,bad\n"), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	errs := result.ValidationErrors()
+	if len(errs) != 2 {
+		t.Fatalf("len(ValidationErrors()) = %d, want 2", len(errs))
+	}
+	if errs[0].Tag != "min" || errs[0].Param != "18" {
+		t.Errorf("Tag, Param = %q, %q, want %q, %q", errs[0].Tag, errs[0].Param, "min", "18")
+	}
+	if errs[0].Message() != "value must be at least 18" {
+		t.Errorf("Message() = %q, want %q", errs[0].Message(), "value must be at least 18")
+	}
+	if errs[1].Tag != "email" || errs[1].Param != "" {
+		t.Errorf("Tag, Param = %q, %q, want %q, %q", errs[1].Tag, errs[1].Param, "email", "")
+	}
+}
+
+func TestSchemaError_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  *SchemaError
+		want string
+	}{
+		{
+			name: "missing only",
+			err:  &SchemaError{Missing: []string{"email"}},
+			want: `header does not match expected columns: missing ["email"]`,
+		},
+		{
+			name: "all differences",
+			err: &SchemaError{
+				Missing:   []string{"email"},
+				Extra:     []string{"phone"},
+				Reordered: []string{"name", "id"},
+			},
+			want: `header does not match expected columns: missing ["email"], extra ["phone"], reordered ["name" "id"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+			if !errors.Is(tt.err, ErrSchemaMismatch) {
+				t.Error("errors.Is(err, ErrSchemaMismatch) = false, want true")
+			}
+		})
+	}
+}
+
+func TestValidationError_Code(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  *ValidationError
+		want string
+	}{
+		{"format validator", newValidationError(1, "email", "Email", "x", "email", "", "msg"), "EMAIL_INVALID"},
+		{"parameterized validator", newValidationError(1, "age", "Age", "5", "min", "18", "msg"), "MIN_INVALID"},
+		{"cross-field validator", newValidationError(1, "a", "A", "x", "eqfield", "B", "msg"), "EQFIELD_INVALID"},
+		{"required", newValidationError(1, "name", "Name", "", "required", "", "msg"), CodeRequiredMissing},
+		{"fixed code", &ValidationError{Tag: "eqfield", code: CodeTargetFieldNotFound}, CodeTargetFieldNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.err.Code(); got != tt.want {
+				t.Errorf("Code() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeverity_String(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		severity Severity
+		want     string
+	}{
+		{SeverityError, "error"},
+		{SeverityWarning, "warning"},
+		{Severity(99), "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.severity.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorTypes_IsAndUnwrap(t *testing.T) {
+	t.Parallel()
+
+	ve := newValidationError(1, "email", "Email", "x", "email", "", "invalid")
+	pe := newPrepError(2, "data", "Data", "empty_json_data", "empty")
+	_, convErr := strconv.Atoi("abc")
+	ce := newConversionError(3, "age", "Age", "abc", convErr)
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{"validation error matches ErrValidation", ve, ErrValidation, true},
+		{"validation error does not match ErrPrep", ve, ErrPrep, false},
+		{"prep error matches ErrPrep", pe, ErrPrep, true},
+		{"prep error does not match ErrValidation", pe, ErrValidation, false},
+		{"conversion error matches ErrPrep", ce, ErrPrep, true},
+		{"conversion error unwraps to strconv cause", ce, strconv.ErrSyntax, true},
+		{"wrapped validation error", fmt.Errorf("load: %w", ve), ErrValidation, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiError(t *testing.T) {
+	t.Parallel()
+
+	ve := newValidationError(1, "email", "Email", "x", "email", "", "invalid")
+	pe := newPrepError(2, "data", "Data", "empty_json_data", "empty")
+
+	t.Run("single error uses its message", func(t *testing.T) {
+		t.Parallel()
+		me := &MultiError{Errors: []error{ve}}
+		if got := me.Error(); got != ve.Error() {
+			t.Errorf("Error() = %q, want %q", got, ve.Error())
+		}
+	})
+
+	t.Run("lists errors", func(t *testing.T) {
+		t.Parallel()
+		me := &MultiError{Errors: []error{ve, pe}}
+		want := "2 errors occurred:\n\t* " + ve.Error() + "\n\t* " + pe.Error()
+		if got := me.Error(); got != want {
+			t.Errorf("Error() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("long lists are truncated", func(t *testing.T) {
+		t.Parallel()
+		errs := make([]error, 12)
+		for i := range errs {
+			errs[i] = ve
+		}
+		got := (&MultiError{Errors: errs}).Error()
+		if !strings.HasSuffix(got, "\n\t... and 2 more") {
+			t.Errorf("Error() = %q, want suffix %q", got, "\n\t... and 2 more")
+		}
+		if n := strings.Count(got, "\n\t* "); n != maxMultiErrorLines {
+			t.Errorf("Error() lists %d errors, want %d", n, maxMultiErrorLines)
+		}
+	})
+
+	t.Run("errors.Is and errors.As reach the combined errors", func(t *testing.T) {
+		t.Parallel()
+		var err error = &MultiError{Errors: []error{pe, ve}}
+		if !errors.Is(err, ErrValidation) || !errors.Is(err, ErrPrep) {
+			t.Error("errors.Is() did not match both ErrValidation and ErrPrep")
+		}
+		var gotVE *ValidationError
+		if !errors.As(err, &gotVE) || gotVE != ve {
+			t.Errorf("errors.As() = %v, want %v", gotVE, ve)
+		}
+	})
+}
+
+func TestProcessResult_RowMaps(t *testing.T) {
+	t.Parallel()
+
+	r := &ProcessResult{
+		Columns: []string{"id", "name", "id"},
+		rows:    [][]string{{"1", "a", "x"}, {"2"}, {"3", "c", "z"}},
+	}
+
+	var got []map[string]string
+	for m := range r.RowMaps() {
+		got = append(got, m)
+		if len(got) == 2 {
+			break
+		}
+	}
+	want := []map[string]string{{"id": "1", "name": "a"}, {"id": "2", "name": ""}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("RowMaps() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -82,7 +82,7 @@ Jane,jane@example.com,25
 	if result.HasErrors() {
 		fmt.Printf("Found %d validation errors:\n", len(result.Errors))
 		for _, e := range result.ValidationErrors() {
-			fmt.Printf("  Row %d, Column %q: %s\n", e.Row, e.Column, e.Message)
+			fmt.Printf("  Row %d, Column %q: %s\n", e.Row, e.Column, e.Message())
 		}
 	}
 
@@ -143,7 +143,7 @@ func Example_complexPrepAndValidation() {
 	if result.HasErrors() {
 		fmt.Printf("\nValidation Errors:\n")
 		for _, ve := range result.ValidationErrors() {
-			fmt.Printf("  Row %d, Field %q: %s\n", ve.Row, ve.Field, ve.Message)
+			fmt.Printf("  Row %d, Field %q: %s\n", ve.Row, ve.Field, ve.Message())
 		}
 	}
 
@@ -216,7 +216,7 @@ Bob,password1,wrongpass
 	if result.HasErrors() {
 		fmt.Printf("Errors:\n")
 		for _, ve := range result.ValidationErrors() {
-			fmt.Printf("  Row %d, Field %q: %s\n", ve.Row, ve.Field, ve.Message)
+			fmt.Printf("  Row %d, Field %q: %s\n", ve.Row, ve.Field, ve.Message())
 		}
 	}
 
@@ -452,7 +452,7 @@ invalid-uuid,abc,not-an-email,-100,US,USA,2024/01/15,2024-01-10,999.999.999.999,
 	if result.HasErrors() {
 		fmt.Println("=== Error Details ===")
 		for _, e := range result.ValidationErrors() {
-			fmt.Printf("Row %d, Column '%s': %s\n", e.Row, e.Column, e.Message)
+			fmt.Printf("Row %d, Column '%s': %s\n", e.Row, e.Column, e.Message())
 		}
 	}
 
@@ -488,3 +488,26 @@ invalid-uuid,abc,not-an-email,-100,US,USA,2024/01/15,2024-01-10,999.999.999.999,
 	// Row 3, Column 'ship_date': value must be greater than field OrderDate
 	// Row 4, Column 'ship_date': value must be greater than field OrderDate
 }
+
+func Example_generateStruct() {
+	csvData := `user_id,Email Address,score,active
+1,alice@example.com,9.5,true
+2,bob@example.com,7,false
+`
+
+	src, err := fileprep.GenerateStruct(strings.NewReader(csvData), fileprep.FileTypeCSV, "Member")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	fmt.Print(src)
+
+	// Output:
+	// // Member was generated by fileprep.GenerateStruct from a sample file.
+	// type Member struct {
+	// 	UserID       int64   `prep:"trim" validate:"required"`
+	// 	EmailAddress string  `name:"Email Address" prep:"trim" validate:"required,email"`
+	// 	Score        float64 `prep:"trim" validate:"required"`
+	// 	Active       bool    `prep:"trim" validate:"required"`
+	// }
+}
@@ -0,0 +1,493 @@
+package fileprep
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// This file holds the small expression language shared by WithFilter and
+// the expr validator. Expressions are compiled once into closures over an
+// environment of string values, such as a record or a source value and its
+// target fields.
+
+// exprValue yields a value of an expression from its environment.
+type exprValue func(env []string) string
+
+// exprCond yields a condition of an expression from its environment.
+type exprCond func(env []string) bool
+
+// exprNode is a compiled subexpression: a value or a condition.
+type exprNode struct {
+	value exprValue
+	cond  exprCond
+}
+
+// Operators of expressions, longest first so "<=" is not read as "<".
+//
+//nolint:gochecknoglobals // operator tables
+var (
+	exprComparisons = []string{"==", "!=", "<=", ">=", "=~", "!~", "<", ">"}
+	exprOperators   = append([]string{"&&", "||"}, exprComparisons...)
+)
+
+// exprTokenKind classifies the tokens of an expression.
+type exprTokenKind int
+
+const (
+	exprIdent    exprTokenKind = iota // name, keyword, or function
+	exprColumn                        // [column name]
+	exprString                        // 'text' or "text"
+	exprNumber                        // 18, 1.5
+	exprOperator                      // operator or punctuation
+)
+
+// exprToken is a token of an expression.
+type exprToken struct {
+	kind exprTokenKind
+	text string // unquoted for strings and bracketed columns
+}
+
+// exprCompiler compiles one expression by recursive descent. The user of
+// the language decides what names and function calls refer to.
+type exprCompiler struct {
+	expr   string
+	label  string // "filter" or the tag name, for error messages
+	errSyn error  // wrapped by syntax errors
+	// name resolves a bare or bracketed name
+	name func(name string, bracketed bool) (exprValue, error)
+	// call resolves a function call with a quoted argument, such as other('Price')
+	call   func(name, arg string) (exprValue, error)
+	tokens []exprToken
+	pos    int
+}
+
+// compile compiles the whole expression, which must be a condition.
+func (c *exprCompiler) compile() (exprCond, error) {
+	if err := c.tokenize(); err != nil {
+		return nil, err
+	}
+	n, err := c.or()
+	if err != nil {
+		return nil, err
+	}
+	if c.pos < len(c.tokens) {
+		return nil, c.errorf("unexpected %q", c.tokens[c.pos].text)
+	}
+	if n.cond == nil {
+		return nil, c.errorf("expression must be a condition, such as a comparison")
+	}
+	return n.cond, nil
+}
+
+// errorf returns a syntax error for the expression.
+func (c *exprCompiler) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s %q: %s", c.errSyn, c.label, c.expr, fmt.Sprintf(format, args...))
+}
+
+// tokenize splits the expression into tokens.
+func (c *exprCompiler) tokenize() error {
+	s := c.expr
+	for i := 0; i < len(s); {
+		ch := rune(s[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '\'' || ch == '"':
+			end := strings.IndexByte(s[i+1:], s[i])
+			if end < 0 {
+				return c.errorf("unterminated string")
+			}
+			c.tokens = append(c.tokens, exprToken{kind: exprString, text: s[i+1 : i+1+end]})
+			i += end + 2
+		case ch == '[':
+			end := strings.IndexByte(s[i+1:], ']')
+			if end < 0 {
+				return c.errorf("unterminated column name")
+			}
+			c.tokens = append(c.tokens, exprToken{kind: exprColumn, text: s[i+1 : i+1+end]})
+			i += end + 2
+		case isDigitByte(s[i]):
+			j := i + 1
+			for j < len(s) && (isDigitByte(s[j]) || s[j] == '.') {
+				j++
+			}
+			c.tokens = append(c.tokens, exprToken{kind: exprNumber, text: s[i:j]})
+			i = j
+		case isExprIdentByte(s[i]):
+			j := i + 1
+			for j < len(s) && (isExprIdentByte(s[j]) || isDigitByte(s[j]) || s[j] == '.') {
+				j++
+			}
+			c.tokens = append(c.tokens, exprToken{kind: exprIdent, text: s[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, candidate := range exprOperators {
+				if strings.HasPrefix(s[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" && strings.ContainsRune("!(),+-*/", ch) {
+				op = string(ch)
+			}
+			if op == "" {
+				r, _ := utf8.DecodeRuneInString(s[i:])
+				return c.errorf("unexpected character %q", r)
+			}
+			c.tokens = append(c.tokens, exprToken{kind: exprOperator, text: op})
+			i += len(op)
+		}
+	}
+	if len(c.tokens) == 0 {
+		return c.errorf("empty expression")
+	}
+	return nil
+}
+
+// isDigitByte reports whether c is an ASCII digit.
+func isDigitByte(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// isExprIdentByte reports whether c can start a bare name. Other names are
+// written in brackets, such as [unit price].
+func isExprIdentByte(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// peekOperator reports whether the next token is the operator op.
+func (c *exprCompiler) peekOperator(op string) bool {
+	return c.pos < len(c.tokens) && c.tokens[c.pos].kind == exprOperator && c.tokens[c.pos].text == op
+}
+
+// expect consumes the operator op or returns an error.
+func (c *exprCompiler) expect(op string) error {
+	if !c.peekOperator(op) {
+		return c.errorf("expected %q", op)
+	}
+	c.pos++
+	return nil
+}
+
+// or compiles a || b || ...
+func (c *exprCompiler) or() (exprNode, error) {
+	left, err := c.and()
+	if err != nil {
+		return exprNode{}, err
+	}
+	for c.peekOperator("||") {
+		c.pos++
+		right, err := c.and()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if left.cond == nil || right.cond == nil {
+			return exprNode{}, c.errorf("|| needs conditions on both sides")
+		}
+		l, r := left.cond, right.cond
+		left = exprNode{cond: func(env []string) bool { return l(env) || r(env) }}
+	}
+	return left, nil
+}
+
+// and compiles a && b && ...
+func (c *exprCompiler) and() (exprNode, error) {
+	left, err := c.not()
+	if err != nil {
+		return exprNode{}, err
+	}
+	for c.peekOperator("&&") {
+		c.pos++
+		right, err := c.not()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if left.cond == nil || right.cond == nil {
+			return exprNode{}, c.errorf("&& needs conditions on both sides")
+		}
+		l, r := left.cond, right.cond
+		left = exprNode{cond: func(env []string) bool { return l(env) && r(env) }}
+	}
+	return left, nil
+}
+
+// not compiles !a.
+func (c *exprCompiler) not() (exprNode, error) {
+	if !c.peekOperator("!") {
+		return c.comparison()
+	}
+	c.pos++
+	inner, err := c.not()
+	if err != nil {
+		return exprNode{}, err
+	}
+	if inner.cond == nil {
+		return exprNode{}, c.errorf("! needs a condition")
+	}
+	cond := inner.cond
+	return exprNode{cond: func(env []string) bool { return !cond(env) }}, nil
+}
+
+// comparison compiles "a op b", "a =~ 'regexp'", and "a in (b, c, ...)".
+func (c *exprCompiler) comparison() (exprNode, error) {
+	left, err := c.additive()
+	if err != nil {
+		return exprNode{}, err
+	}
+	if c.pos >= len(c.tokens) {
+		return left, nil
+	}
+	tok := c.tokens[c.pos]
+	isIn := tok.kind == exprIdent && strings.EqualFold(tok.text, "in")
+	if !isIn && (tok.kind != exprOperator || !slices.Contains(exprComparisons, tok.text)) {
+		return left, nil
+	}
+	c.pos++
+	if left.value == nil {
+		return exprNode{}, c.errorf("%s needs a value on its left", tok.text)
+	}
+	if isIn {
+		return c.inList(left.value)
+	}
+
+	if tok.text == "=~" || tok.text == "!~" {
+		if c.pos >= len(c.tokens) || c.tokens[c.pos].kind != exprString {
+			return exprNode{}, c.errorf("%s needs a quoted regular expression", tok.text)
+		}
+		re, err := regexp.Compile(c.tokens[c.pos].text)
+		if err != nil {
+			return exprNode{}, c.errorf("invalid regular expression: %v", err)
+		}
+		c.pos++
+		value, negate := left.value, tok.text == "!~"
+		return exprNode{cond: func(env []string) bool { return re.MatchString(value(env)) != negate }}, nil
+	}
+
+	right, err := c.operandValue()
+	if err != nil {
+		return exprNode{}, err
+	}
+	l, op := left.value, tok.text
+	return exprNode{cond: func(env []string) bool {
+		cmp, ok := compareExprValues(l(env), right(env))
+		if !ok {
+			return op == "!="
+		}
+		switch op {
+		case "==":
+			return cmp == 0
+		case "!=":
+			return cmp != 0
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		case ">":
+			return cmp > 0
+		default:
+			return cmp >= 0
+		}
+	}}, nil
+}
+
+// inList compiles the parenthesized list after "in".
+func (c *exprCompiler) inList(left exprValue) (exprNode, error) {
+	if err := c.expect("("); err != nil {
+		return exprNode{}, err
+	}
+	var items []exprValue
+	for {
+		item, err := c.operandValue()
+		if err != nil {
+			return exprNode{}, err
+		}
+		items = append(items, item)
+		if !c.peekOperator(",") {
+			break
+		}
+		c.pos++
+	}
+	if err := c.expect(")"); err != nil {
+		return exprNode{}, err
+	}
+	return exprNode{cond: func(env []string) bool {
+		value := left(env)
+		for _, item := range items {
+			if cmp, ok := compareExprValues(value, item(env)); ok && cmp == 0 {
+				return true
+			}
+		}
+		return false
+	}}, nil
+}
+
+// operandValue compiles the right side of a comparison, which must be a value.
+func (c *exprCompiler) operandValue() (exprValue, error) {
+	n, err := c.additive()
+	if err != nil {
+		return nil, err
+	}
+	if n.value == nil {
+		return nil, c.errorf("expected a value, got a condition")
+	}
+	return n.value, nil
+}
+
+// additive compiles a + b - c ...
+func (c *exprCompiler) additive() (exprNode, error) {
+	return c.arithmetic(c.multiplicative, "+", "-")
+}
+
+// multiplicative compiles a * b / c ...
+func (c *exprCompiler) multiplicative() (exprNode, error) {
+	return c.arithmetic(c.negation, "*", "/")
+}
+
+// arithmetic compiles a left-associative chain of the operators ops
+// between operands compiled by next.
+func (c *exprCompiler) arithmetic(next func() (exprNode, error), ops ...string) (exprNode, error) {
+	left, err := next()
+	if err != nil {
+		return exprNode{}, err
+	}
+	for c.pos < len(c.tokens) && c.tokens[c.pos].kind == exprOperator && slices.Contains(ops, c.tokens[c.pos].text) {
+		op := c.tokens[c.pos].text
+		c.pos++
+		right, err := next()
+		if err != nil {
+			return exprNode{}, err
+		}
+		if left.value == nil || right.value == nil {
+			return exprNode{}, c.errorf("%s needs values on both sides", op)
+		}
+		l, r := left.value, right.value
+		left = exprNode{value: func(env []string) string {
+			return arithmeticExprValues(op, l(env), r(env))
+		}}
+	}
+	return left, nil
+}
+
+// negation compiles -a.
+func (c *exprCompiler) negation() (exprNode, error) {
+	if !c.peekOperator("-") {
+		return c.primary()
+	}
+	c.pos++
+	inner, err := c.negation()
+	if err != nil {
+		return exprNode{}, err
+	}
+	if inner.value == nil {
+		return exprNode{}, c.errorf("- needs a value")
+	}
+	value := inner.value
+	return exprNode{value: func(env []string) string { return arithmeticExprValues("-", "0", value(env)) }}, nil
+}
+
+// primary compiles a literal, a name, a function call, or a parenthesized
+// expression.
+func (c *exprCompiler) primary() (exprNode, error) {
+	if c.pos >= len(c.tokens) {
+		return exprNode{}, c.errorf("expected a value at end of expression")
+	}
+	tok := c.tokens[c.pos]
+	c.pos++
+	switch tok.kind {
+	case exprString, exprNumber:
+		text := tok.text
+		return exprNode{value: func([]string) string { return text }}, nil
+	case exprColumn:
+		value, err := c.name(tok.text, true)
+		return exprNode{value: value}, err
+	case exprIdent:
+		if tok.text == "true" || tok.text == "false" {
+			text := tok.text
+			return exprNode{value: func([]string) string { return text }}, nil
+		}
+		if !c.peekOperator("(") {
+			value, err := c.name(tok.text, false)
+			return exprNode{value: value}, err
+		}
+		c.pos++
+		if c.pos >= len(c.tokens) || c.tokens[c.pos].kind != exprString {
+			return exprNode{}, c.errorf("%s() needs a quoted argument", tok.text)
+		}
+		arg := c.tokens[c.pos].text
+		c.pos++
+		if err := c.expect(")"); err != nil {
+			return exprNode{}, err
+		}
+		value, err := c.call(tok.text, arg)
+		return exprNode{value: value}, err
+	default:
+		if tok.text == "(" {
+			inner, err := c.or()
+			if err != nil {
+				return exprNode{}, err
+			}
+			if err := c.expect(")"); err != nil {
+				return exprNode{}, err
+			}
+			return inner, nil
+		}
+		return exprNode{}, c.errorf("expected a value, got %q", tok.text)
+	}
+}
+
+// parseExprNumber parses a value as a number, ignoring surrounding spaces.
+func parseExprNumber(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f, err == nil
+}
+
+// arithmeticExprValues applies op to two numbers. The result is NaN, which
+// no comparison matches except !=, when either value is not a number.
+func arithmeticExprValues(op, a, b string) string {
+	x, okX := parseExprNumber(a)
+	y, okY := parseExprNumber(b)
+	if !okX || !okY {
+		return strconv.FormatFloat(math.NaN(), 'f', -1, 64)
+	}
+	var r float64
+	switch op {
+	case "+":
+		r = x + y
+	case "-":
+		r = x - y
+	case "*":
+		r = x * y
+	default:
+		r = x / y
+	}
+	return strconv.FormatFloat(r, 'f', -1, 64)
+}
+
+// compareExprValues compares two values as numbers when both are numbers
+// and as strings otherwise. It returns -1, 0, or 1, and false when either
+// number is NaN.
+func compareExprValues(a, b string) (int, bool) {
+	x, okX := parseExprNumber(a)
+	y, okY := parseExprNumber(b)
+	if !okX || !okY {
+		return strings.Compare(a, b), true
+	}
+	if math.IsNaN(x) || math.IsNaN(y) {
+		return 0, false
+	}
+	switch {
+	case x < y:
+		return -1, true
+	case x > y:
+		return 1, true
+	default:
+		return 0, true
+	}
+}
@@ -0,0 +1,300 @@
+package fileprep
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Default retry settings for ProcessURL.
+const (
+	defaultFetchAttempts = 3
+	defaultFetchBackoff  = 500 * time.Millisecond
+)
+
+// Fetcher downloads the object at a URL for ProcessURL. Register one per URL
+// scheme with WithFetcher, e.g. an S3 client for "s3://bucket/key" URLs.
+//
+// Fetch should return a *FetchError with a StatusCode for responses from the
+// remote service, so that ProcessURL can tell permanent failures (such as
+// 404) from transient ones. Other errors are retried.
+type Fetcher interface {
+	Fetch(ctx context.Context, u *url.URL) (io.ReadCloser, error)
+}
+
+// FetcherFunc adapts an ordinary function to the Fetcher interface.
+type FetcherFunc func(ctx context.Context, u *url.URL) (io.ReadCloser, error)
+
+// Fetch calls f(ctx, u)
+func (f FetcherFunc) Fetch(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+	return f(ctx, u)
+}
+
+// FetchError reports a failed download.
+type FetchError struct {
+	// URL is the requested URL without user info, query, and fragment
+	URL string
+	// StatusCode is the HTTP-style status code returned by the remote
+	// service, or 0 when no response was received
+	StatusCode int
+	// Err is the underlying error, if any
+	Err error
+}
+
+// Error implements the error interface
+func (e *FetchError) Error() string {
+	msg := "failed to fetch " + e.URL
+	if e.StatusCode != 0 {
+		msg += ": status " + strconv.Itoa(e.StatusCode)
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// retryable reports whether the download may succeed if attempted again.
+// Client errors other than timeouts and rate limiting are permanent.
+func (e *FetchError) retryable() bool {
+	switch {
+	case e.StatusCode == http.StatusRequestTimeout, e.StatusCode == http.StatusTooManyRequests:
+		return true
+	case e.StatusCode >= 400 && e.StatusCode < 500:
+		return false
+	default:
+		return true
+	}
+}
+
+// fetchConfig holds the ProcessURL settings of a Processor.
+type fetchConfig struct {
+	fetchers map[string]Fetcher
+	attempts int
+	backoff  time.Duration
+	maxBytes int64 // 0 means unlimited
+}
+
+// WithFetcher registers the Fetcher used by ProcessURL for URLs with the
+// given scheme. It overrides the built-in fetcher for "http" and "https".
+//
+// Example:
+//
+//	s3Fetcher := fileprep.FetcherFunc(func(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+//	    out, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+//	        Bucket: aws.String(u.Host),
+//	        Key:    aws.String(strings.TrimPrefix(u.Path, "/")),
+//	    })
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return out.Body, nil
+//	})
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithFetcher("s3", s3Fetcher))
+func WithFetcher(scheme string, f Fetcher) Option {
+	return func(p *Processor) {
+		if p.fetch.fetchers == nil {
+			p.fetch.fetchers = make(map[string]Fetcher)
+		}
+		p.fetch.fetchers[scheme] = f
+	}
+}
+
+// WithFetchRetry configures how ProcessURL retries failed downloads:
+// up to attempts tries in total, waiting backoff before the second try and
+// doubling the wait after each further failure. The default is 3 attempts
+// with a 500ms initial backoff.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithFetchRetry(5, time.Second))
+func WithFetchRetry(attempts int, backoff time.Duration) Option {
+	return func(p *Processor) {
+		p.fetch.attempts = max(attempts, 1)
+		p.fetch.backoff = max(backoff, 0)
+	}
+}
+
+// WithMaxDownloadBytes limits the number of bytes ProcessURL downloads.
+// Larger objects fail with ErrDownloadTooLarge. The limit applies to the
+// bytes as stored, before decompression. By default there is no limit.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithMaxDownloadBytes(100<<20))
+func WithMaxDownloadBytes(n int64) Option {
+	return func(p *Processor) {
+		p.fetch.maxBytes = max(n, 0)
+	}
+}
+
+// ProcessURL downloads the file at rawURL and processes it like Process.
+// http and https URLs are fetched with http.DefaultClient; other schemes,
+// such as s3, need a Fetcher registered with WithFetcher.
+//
+// Failed downloads are retried with exponential backoff (see
+// WithFetchRetry), except for permanent failures such as HTTP 404.
+// The stream's TableName is derived from the last path element of the URL.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSVGZ, fileprep.WithMaxDownloadBytes(1<<30))
+//	var orders []Order
+//	reader, result, err := processor.ProcessURL(ctx, "https://example.com/exports/orders.csv.gz", &orders)
+func (p *Processor) ProcessURL(ctx context.Context, rawURL string, structSlicePointer any) (io.Reader, *ProcessResult, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// *url.Error repeats the URL, which may hold credentials
+		if urlErr := (*url.Error)(nil); errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, nil, fmt.Errorf("invalid URL: %w", err)
+	}
+
+	fetcher, err := p.fetcherFor(u.Scheme)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := p.download(ctx, fetcher, u)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.Process(&namedReader{Reader: bytes.NewReader(data), name: u.Path, source: redactedURL(u)}, structSlicePointer)
+}
+
+// redactedURL returns u without user info, query, and fragment, the form
+// used in errors and by WithSourceColumn, so neither leaks credentials or
+// signed query parameters.
+func redactedURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	clean.RawQuery = ""
+	clean.ForceQuery = false
+	clean.Fragment = ""
+	clean.RawFragment = ""
+	return clean.String()
+}
+
+// redactURLError replaces the URL that net/http repeats in a *url.Error
+// with its redacted form.
+func redactURLError(err error, u *url.URL) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return &url.Error{Op: urlErr.Op, URL: redactedURL(u), Err: urlErr.Err}
+	}
+	return err
+}
+
+// fetcherFor returns the Fetcher for a URL scheme.
+func (p *Processor) fetcherFor(scheme string) (Fetcher, error) {
+	if f, ok := p.fetch.fetchers[scheme]; ok {
+		return f, nil
+	}
+	switch scheme {
+	case "http", "https":
+		return FetcherFunc(fetchHTTP), nil
+	default:
+		return nil, fmt.Errorf("no fetcher registered for URL scheme %q", scheme)
+	}
+}
+
+// download fetches u, retrying transient failures.
+func (p *Processor) download(ctx context.Context, fetcher Fetcher, u *url.URL) ([]byte, error) {
+	attempts := p.fetch.attempts
+	backoff := p.fetch.backoff
+	if attempts == 0 {
+		attempts, backoff = defaultFetchAttempts, defaultFetchBackoff
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, errors.Join(ctx.Err(), lastErr)
+			case <-timer.C:
+			}
+			backoff *= 2
+		}
+
+		data, err := p.fetchOnce(ctx, fetcher, u)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, errors.Join(ctxErr, err)
+		}
+		var fetchErr *FetchError
+		if errors.Is(err, ErrDownloadTooLarge) || (errors.As(err, &fetchErr) && !fetchErr.retryable()) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("giving up after %d attempts: %w", attempts, lastErr)
+}
+
+// fetchOnce downloads u once, enforcing the size limit.
+func (p *Processor) fetchOnce(ctx context.Context, fetcher Fetcher, u *url.URL) ([]byte, error) {
+	body, err := fetcher.Fetch(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var r io.Reader = body
+	if p.fetch.maxBytes > 0 {
+		r = io.LimitReader(body, p.fetch.maxBytes+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &FetchError{URL: redactedURL(u), Err: redactURLError(err, u)}
+	}
+	if p.fetch.maxBytes > 0 && int64(len(data)) > p.fetch.maxBytes {
+		return nil, fmt.Errorf("%s: %w (%d bytes)", redactedURL(u), ErrDownloadTooLarge, p.fetch.maxBytes)
+	}
+	return data, nil
+}
+
+// fetchHTTP is the built-in Fetcher for http and https URLs.
+func fetchHTTP(ctx context.Context, u *url.URL) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, &FetchError{URL: redactedURL(u), Err: err}
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, &FetchError{URL: redactedURL(u), Err: redactURLError(err, u)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &FetchError{URL: redactedURL(u), StatusCode: resp.StatusCode}
+	}
+	return resp.Body, nil
+}
+
+// namedReader is an io.Reader with a file name, used to pass the table
+// name hint of a downloaded file to Process.
+type namedReader struct {
+	io.Reader
+	name   string
+	source string // URL reported by WithSourceColumn
+}
+
+// Name returns the file name
+func (r *namedReader) Name() string {
+	return r.name
+}
@@ -0,0 +1,237 @@
+// Package fileprepsql loads fileprep output into a SQL database.
+//
+// It removes the glue code between fileprep.Processor and a database you
+// already have open, such as one opened by filesql (or any database/sql
+// driver with SQLite-compatible syntax): process the input, create a table
+// from the output header, and insert the rows in one transaction. It uses
+// only database/sql, so it adds no SQL driver to your dependencies.
+//
+//	// db is an open *sql.DB, for example from filesql.Open("orders.csv")
+//	processor := fileprep.NewProcessor(fileprep.FileTypeCSV, fileprep.WithValidRowsOnly())
+//	var customers []Customer
+//	res, err := fileprepsql.LoadInto(ctx, db, "customers", processor, file,
+//	    fileprepsql.WithRecords(&customers))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("loaded %d of %d rows\n", res.LoadedRows, res.RowCount)
+//	// The customers table can now be joined with the orders table
+package fileprepsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nao1215/fileparser"
+	"github.com/nao1215/fileprep"
+)
+
+// LoadResult combines the validation result of fileprep.Processor.Process
+// with the outcome of the load.
+type LoadResult struct {
+	*fileprep.ProcessResult
+	// Table is the name of the created table
+	Table string
+	// LoadedRows is the number of rows inserted into Table
+	LoadedRows int
+}
+
+// LoadOption configures LoadInto.
+type LoadOption func(*loadConfig)
+
+// loadConfig holds the settings of one LoadInto call.
+type loadConfig struct {
+	records     any  // struct slice pointer passed to Process
+	emptyAsNull bool // store empty values as NULL
+}
+
+// WithRecords processes the input with the struct tags of the element type
+// of structSlicePointer and fills it with the processed records, as
+// fileprep.Processor.Process does. Without it, the input is processed with
+// the rules of the processor alone, such as those of
+// fileprep.NewProcessorFromJSONSchema or fileprep.WithColumnTypes.
+//
+// Example:
+//
+//	var users []User
+//	res, err := fileprepsql.LoadInto(ctx, db, "users", processor, file,
+//	    fileprepsql.WithRecords(&users))
+func WithRecords(structSlicePointer any) LoadOption {
+	return func(c *loadConfig) {
+		c.records = structSlicePointer
+	}
+}
+
+// WithEmptyAsNull stores empty values as NULL. By default, empty values are
+// stored as empty strings.
+//
+// Example:
+//
+//	res, err := fileprepsql.LoadInto(ctx, db, "users", processor, file,
+//	    fileprepsql.WithEmptyAsNull())
+func WithEmptyAsNull() LoadOption {
+	return func(c *loadConfig) {
+		c.emptyAsNull = true
+	}
+}
+
+// LoadInto processes r with processor and loads the output stream into a new
+// table in db. Column types follow the types fileparser infers from the
+// output (INTEGER, REAL, or TEXT).
+//
+// Rows that fail validation are loaded too unless the processor was created
+// with fileprep.WithValidRowsOnly. The table is created and filled in one
+// transaction, so a failed load leaves db unchanged. LoadInto returns an
+// error if the table already exists.
+//
+// When processing fails with validation details (fileprep.WithStrictValidation),
+// the returned LoadResult still holds the ProcessResult.
+func LoadInto(
+	ctx context.Context,
+	db *sql.DB,
+	table string,
+	processor *fileprep.Processor,
+	r io.Reader,
+	opts ...LoadOption,
+) (*LoadResult, error) {
+	if table == "" {
+		return nil, errors.New("table name must not be empty")
+	}
+	cfg := loadConfig{records: &[]struct{}{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	reader, result, err := processor.Process(r, cfg.records)
+	if err != nil {
+		if result != nil {
+			return &LoadResult{ProcessResult: result, Table: table}, err
+		}
+		return nil, err
+	}
+	res := &LoadResult{ProcessResult: result, Table: table}
+
+	// The stream is in memory, so fileparser reads it without another copy
+	format := fileparser.CSV
+	if stream, ok := reader.(fileprep.Stream); ok {
+		format = stream.Format()
+	}
+	tableData, err := fileparser.Parse(reader, format)
+	if err != nil {
+		return res, fmt.Errorf("failed to parse processed output: %w", err)
+	}
+
+	loaded, err := insertTable(ctx, db, table, tableData, cfg.emptyAsNull)
+	if err != nil {
+		return res, err
+	}
+	res.LoadedRows = loaded
+	return res, nil
+}
+
+// insertTable creates table and inserts every record in one transaction.
+func insertTable(ctx context.Context, db *sql.DB, table string, tableData *fileparser.TableData, emptyAsNull bool) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	loaded, err := insertRecords(ctx, tx, table, tableData, emptyAsNull)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return 0, errors.Join(err, fmt.Errorf("failed to roll back: %w", rbErr))
+		}
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit table %q: %w", table, err)
+	}
+	return loaded, nil
+}
+
+// insertRecords runs CREATE TABLE and the INSERT statements within tx.
+func insertRecords(ctx context.Context, tx *sql.Tx, table string, tableData *fileparser.TableData, emptyAsNull bool) (int, error) {
+	if _, err := tx.ExecContext(ctx, createTableSQL(table, tableData)); err != nil {
+		return 0, fmt.Errorf("failed to create table %q: %w", table, err)
+	}
+	if len(tableData.Records) == 0 {
+		return 0, nil
+	}
+
+	stmt, err := tx.PrepareContext(ctx, insertSQL(table, tableData.Headers))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare insert into %q: %w", table, err)
+	}
+	defer stmt.Close()
+
+	args := make([]any, len(tableData.Headers))
+	for i, record := range tableData.Records {
+		for j := range args {
+			value := ""
+			if j < len(record) {
+				value = record[j]
+			}
+			args[j] = value
+			if value == "" && emptyAsNull {
+				args[j] = nil
+			}
+		}
+		if _, err := stmt.ExecContext(ctx, args...); err != nil {
+			return 0, fmt.Errorf("failed to insert row %d into %q: %w", i+1, table, err)
+		}
+	}
+	return len(tableData.Records), nil
+}
+
+// createTableSQL returns the CREATE TABLE statement for the output header.
+func createTableSQL(table string, tableData *fileparser.TableData) string {
+	var b strings.Builder
+	b.WriteString("CREATE TABLE ")
+	b.WriteString(quoteIdentifier(table))
+	b.WriteString(" (")
+	for i, header := range tableData.Headers {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(quoteIdentifier(header))
+		b.WriteByte(' ')
+		b.WriteString(columnSQLType(tableData.ColumnTypes, i))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// insertSQL returns a parameterized INSERT statement for the given columns.
+func insertSQL(table string, headers []string) string {
+	columns := make([]string, len(headers))
+	for i, header := range headers {
+		columns[i] = quoteIdentifier(header)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(headers)), ", ")
+	return "INSERT INTO " + quoteIdentifier(table) +
+		" (" + strings.Join(columns, ", ") + ") VALUES (" + placeholders + ")"
+}
+
+// columnSQLType maps an inferred column type to a SQLite type name.
+func columnSQLType(columnTypes []fileparser.ColumnType, i int) string {
+	if i >= len(columnTypes) {
+		return "TEXT"
+	}
+	switch columnTypes[i] {
+	case fileparser.TypeInteger:
+		return "INTEGER"
+	case fileparser.TypeReal:
+		return "REAL"
+	default:
+		return "TEXT"
+	}
+}
+
+// quoteIdentifier quotes a table or column name for SQL.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,228 @@
+package fileprepsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileprep"
+)
+
+// recordingConnector is a database/sql connector that records executed
+// statements instead of running them.
+type recordingConnector struct {
+	mu         sync.Mutex
+	execs      []string // statements with their arguments, in order
+	committed  bool
+	rolledBack bool
+	failOn     string // fail any statement containing this text
+}
+
+func (c *recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{c: c}, nil
+}
+func (c *recordingConnector) Driver() driver.Driver { return nil }
+
+func (c *recordingConnector) exec(query string, args []driver.NamedValue) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failOn != "" && strings.Contains(query, c.failOn) {
+		return errors.New("forced failure")
+	}
+	var b strings.Builder
+	b.WriteString(query)
+	for _, arg := range args {
+		if arg.Value == nil {
+			b.WriteString(" | NULL")
+			continue
+		}
+		fmt.Fprintf(&b, " | %v", arg.Value)
+	}
+	c.execs = append(c.execs, b.String())
+	return nil
+}
+
+type recordingConn struct{ c *recordingConnector }
+
+func (cn *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{c: cn.c, query: query}, nil
+}
+func (cn *recordingConn) Close() error { return nil }
+func (cn *recordingConn) Begin() (driver.Tx, error) {
+	return &recordingTx{c: cn.c}, nil
+}
+func (cn *recordingConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), cn.c.exec(query, args)
+}
+
+type recordingStmt struct {
+	c     *recordingConnector
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	named := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return driver.RowsAffected(1), s.c.exec(s.query, named)
+}
+func (s *recordingStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+type recordingTx struct{ c *recordingConnector }
+
+func (tx *recordingTx) Commit() error {
+	tx.c.mu.Lock()
+	defer tx.c.mu.Unlock()
+	tx.c.committed = true
+	return nil
+}
+
+func (tx *recordingTx) Rollback() error {
+	tx.c.mu.Lock()
+	defer tx.c.mu.Unlock()
+	tx.c.rolledBack = true
+	return nil
+}
+
+type user struct {
+	ID    string `validate:"required"`
+	Name  string `prep:"trim"`
+	Score string
+}
+
+func TestLoadInto(t *testing.T) {
+	t.Parallel()
+
+	input := "id,name,score\n1, alice ,9.5\n2,bob,\n,carol,7\n"
+
+	t.Run("creates table and inserts processed rows", func(t *testing.T) {
+		t.Parallel()
+
+		conn := &recordingConnector{}
+		db := sql.OpenDB(conn)
+		defer db.Close()
+
+		var users []user
+		processor := fileprep.NewProcessor(fileprep.FileTypeCSV, fileprep.WithValidRowsOnly())
+		res, err := LoadInto(context.Background(), db, "users", processor, strings.NewReader(input),
+			WithRecords(&users), WithEmptyAsNull())
+		if err != nil {
+			t.Fatalf("LoadInto() error = %v", err)
+		}
+
+		want := []string{
+			`CREATE TABLE "users" ("id" INTEGER, "name" TEXT, "score" REAL)`,
+			`INSERT INTO "users" ("id", "name", "score") VALUES (?, ?, ?) | 1 | alice | 9.5`,
+			`INSERT INTO "users" ("id", "name", "score") VALUES (?, ?, ?) | 2 | bob | NULL`,
+		}
+		if diff := cmp.Diff(want, conn.execs); diff != "" {
+			t.Errorf("statements mismatch (-want +got):\n%s", diff)
+		}
+		if !conn.committed {
+			t.Error("transaction was not committed")
+		}
+		if res.LoadedRows != 2 || res.RowCount != 3 || len(res.ValidationErrors()) != 1 || res.Table != "users" {
+			t.Errorf("LoadResult = %+v, want 2 loaded of 3 rows with 1 validation error", res)
+		}
+		if len(users) == 0 || users[0].Name != "alice" {
+			t.Errorf("records = %+v, want processed records", users)
+		}
+	})
+
+	t.Run("without records uses processor rules only and keeps empty strings", func(t *testing.T) {
+		t.Parallel()
+
+		conn := &recordingConnector{}
+		db := sql.OpenDB(conn)
+		defer db.Close()
+
+		processor := fileprep.NewProcessor(fileprep.FileTypeCSV, fileprep.WithValidRowsOnly())
+		res, err := LoadInto(context.Background(), db, "users", processor,
+			strings.NewReader("id,name\n1, alice \n,carol\n"))
+		if err != nil {
+			t.Fatalf("LoadInto() error = %v", err)
+		}
+
+		want := []string{
+			`CREATE TABLE "users" ("id" INTEGER, "name" TEXT)`,
+			`INSERT INTO "users" ("id", "name") VALUES (?, ?) | 1 |  alice `,
+			`INSERT INTO "users" ("id", "name") VALUES (?, ?) |  | carol`,
+		}
+		if diff := cmp.Diff(want, conn.execs); diff != "" {
+			t.Errorf("statements mismatch (-want +got):\n%s", diff)
+		}
+		if res.LoadedRows != 2 || len(res.ValidationErrors()) != 0 {
+			t.Errorf("LoadResult = %+v, want 2 loaded rows without validation errors", res)
+		}
+	})
+
+	t.Run("rolls back on insert failure", func(t *testing.T) {
+		t.Parallel()
+
+		conn := &recordingConnector{failOn: "INSERT"}
+		db := sql.OpenDB(conn)
+		defer db.Close()
+
+		processor := fileprep.NewProcessor(fileprep.FileTypeCSV)
+		res, err := LoadInto(context.Background(), db, "users", processor, strings.NewReader(input),
+			WithRecords(&[]user{}))
+		if err == nil {
+			t.Fatal("LoadInto() error = nil, want insert failure")
+		}
+		if !conn.rolledBack || conn.committed {
+			t.Errorf("rolledBack = %v, committed = %v, want rollback only", conn.rolledBack, conn.committed)
+		}
+		if res == nil || res.LoadedRows != 0 || res.RowCount != 3 {
+			t.Errorf("LoadResult = %+v, want validation result with no loaded rows", res)
+		}
+	})
+
+	t.Run("strict validation failure keeps the result", func(t *testing.T) {
+		t.Parallel()
+
+		conn := &recordingConnector{}
+		db := sql.OpenDB(conn)
+		defer db.Close()
+
+		processor := fileprep.NewProcessor(fileprep.FileTypeCSV, fileprep.WithStrictValidation())
+		res, err := LoadInto(context.Background(), db, "users", processor, strings.NewReader(input),
+			WithRecords(&[]user{}))
+		if !errors.Is(err, fileprep.ErrValidation) {
+			t.Errorf("LoadInto() error = %v, want ErrValidation", err)
+		}
+		if res == nil || res.RowCount != 3 {
+			t.Errorf("LoadResult = %+v, want process result", res)
+		}
+		if len(conn.execs) != 0 {
+			t.Errorf("executed %v, want nothing", conn.execs)
+		}
+	})
+
+	t.Run("empty table name", func(t *testing.T) {
+		t.Parallel()
+
+		processor := fileprep.NewProcessor(fileprep.FileTypeCSV)
+		if _, err := LoadInto(context.Background(), nil, "", processor, strings.NewReader(input)); err == nil {
+			t.Error("LoadInto() error = nil, want error for empty table name")
+		}
+	})
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	t.Parallel()
+
+	if got, want := quoteIdentifier(`my "table"`), `"my ""table"""`; got != want {
+		t.Errorf("quoteIdentifier() = %s, want %s", got, want)
+	}
+}
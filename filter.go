@@ -0,0 +1,48 @@
+package fileprep
+
+import (
+	"fmt"
+)
+
+// rowFilter reports whether a raw record is kept by WithFilter.
+type rowFilter func(record []string) bool
+
+// compileFilters compiles the WithFilter expressions against the header into
+// one filter that keeps the rows every expression keeps. Names refer to
+// columns. It returns an error wrapping ErrInvalidOption for a malformed
+// expression, and one wrapping ErrColumnNotFound for a column that is not in
+// headerToColIdx.
+func compileFilters(exprs []string, headerToColIdx map[string]int) (rowFilter, error) {
+	filters := make([]exprCond, 0, len(exprs))
+	for _, expr := range exprs {
+		c := &exprCompiler{expr: expr, label: "filter", errSyn: ErrInvalidOption}
+		c.name = func(name string, _ bool) (exprValue, error) {
+			colIdx, ok := headerToColIdx[name]
+			if !ok {
+				return nil, fmt.Errorf("filter %q: column %q: %w", expr, name, ErrColumnNotFound)
+			}
+			return func(record []string) string {
+				if colIdx < len(record) {
+					return record[colIdx]
+				}
+				return ""
+			}, nil
+		}
+		c.call = func(name, _ string) (exprValue, error) {
+			return nil, c.errorf("unknown function %s", name)
+		}
+		f, err := c.compile()
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return func(record []string) bool {
+		for _, f := range filters {
+			if !f(record) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
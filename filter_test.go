@@ -0,0 +1,83 @@
+package fileprep
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompileFilters(t *testing.T) {
+	t.Parallel()
+
+	headerToColIdx := map[string]int{"name": 0, "age": 1, "country": 2, "unit price": 3}
+	tests := []struct {
+		name   string
+		exprs  []string
+		record []string
+		want   bool
+	}{
+		{name: "numeric comparison", exprs: []string{"age >= 18"}, record: []string{"Kai", "18", "JP", ""}, want: true},
+		{name: "numbers compare by value", exprs: []string{"age < 100"}, record: []string{"Kai", "9", "JP", ""}, want: true},
+		{name: "strings compare as text", exprs: []string{"name < 'L'"}, record: []string{"Kai", "9", "JP", ""}, want: true},
+		{name: "and", exprs: []string{"age >= 18 && country == 'JP'"}, record: []string{"Kai", "30", "US", ""}, want: false},
+		{name: "or", exprs: []string{`country == "US" || country == 'JP'`}, record: []string{"Kai", "30", "JP", ""}, want: true},
+		{name: "and binds tighter than or", exprs: []string{"age > 60 || age > 18 && country == 'JP'"}, record: []string{"Kai", "30", "US", ""}, want: false},
+		{name: "parentheses", exprs: []string{"(age > 60 || age > 18) && country == 'JP'"}, record: []string{"Kai", "70", "US", ""}, want: false},
+		{name: "not", exprs: []string{"!(country == 'JP')"}, record: []string{"Kai", "30", "US", ""}, want: true},
+		{name: "in", exprs: []string{"country in ('JP', 'KR')"}, record: []string{"Kai", "30", "KR", ""}, want: true},
+		{name: "not in", exprs: []string{"!(country in ('JP', 'KR'))"}, record: []string{"Kai", "30", "KR", ""}, want: false},
+		{name: "regex", exprs: []string{"name =~ '^K'"}, record: []string{"Kai", "30", "JP", ""}, want: true},
+		{name: "negated regex", exprs: []string{"name !~ '^K'"}, record: []string{"Kai", "30", "JP", ""}, want: false},
+		{name: "bracketed column", exprs: []string{"[unit price] > 9.5"}, record: []string{"Kai", "30", "JP", "10"}, want: true},
+		{name: "negative number", exprs: []string{"age > -1"}, record: []string{"Kai", "0", "JP", ""}, want: true},
+		{name: "empty cell", exprs: []string{"[unit price] == ''"}, record: []string{"Kai", "30", "JP", ""}, want: true},
+		{name: "short record", exprs: []string{"[unit price] == ''"}, record: []string{"Kai"}, want: true},
+		{name: "every expression must match", exprs: []string{"age >= 18", "country == 'JP'"}, record: []string{"Kai", "30", "US", ""}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			filter, err := compileFilters(tt.exprs, headerToColIdx)
+			if err != nil {
+				t.Fatalf("compileFilters(%q) error = %v", tt.exprs, err)
+			}
+			if got := filter(tt.record); got != tt.want {
+				t.Errorf("filter(%q) = %v, want %v", tt.record, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileFilters_Errors(t *testing.T) {
+	t.Parallel()
+
+	headerToColIdx := map[string]int{"age": 0, "country": 1}
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr error
+	}{
+		{name: "empty", expr: " ", wantErr: ErrInvalidOption},
+		{name: "unknown column", expr: "city == 'Tokyo'", wantErr: ErrColumnNotFound},
+		{name: "missing operator", expr: "age 18", wantErr: ErrInvalidOption},
+		{name: "missing operand", expr: "age >=", wantErr: ErrInvalidOption},
+		{name: "bare column", expr: "age", wantErr: ErrInvalidOption},
+		{name: "unbalanced parenthesis", expr: "(age > 1", wantErr: ErrInvalidOption},
+		{name: "trailing token", expr: "age > 1)", wantErr: ErrInvalidOption},
+		{name: "unterminated string", expr: "country == 'JP", wantErr: ErrInvalidOption},
+		{name: "single equals", expr: "country = 'JP'", wantErr: ErrInvalidOption},
+		{name: "unquoted regex", expr: "country =~ JP", wantErr: ErrInvalidOption},
+		{name: "invalid regex", expr: "country =~ '('", wantErr: ErrInvalidOption},
+		{name: "non-ASCII character", expr: "age ≥ 18", wantErr: ErrInvalidOption},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := compileFilters([]string{tt.expr}, headerToColIdx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("compileFilters(%q) error = %v, want %v", tt.expr, err, tt.wantErr)
+			}
+		})
+	}
+}
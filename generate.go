@@ -0,0 +1,230 @@
+package fileprep
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nao1215/fileparser"
+)
+
+// generateSampleSize is the number of data rows inspected when inferring
+// field types and tag stubs.
+const generateSampleSize = 100
+
+// commonInitialisms are header words rendered in upper case in field names,
+// following Go naming conventions. toSnakeCase maps them back to lower case,
+// so no name tag is needed for headers such as "user_id".
+//
+//nolint:gochecknoglobals // lookup table
+var commonInitialisms = map[string]bool{
+	"api": true, "csv": true, "db": true, "html": true, "http": true,
+	"https": true, "id": true, "ip": true, "json": true, "sql": true,
+	"tsv": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// GenerateStruct reads a sample file and returns Go source for a struct whose
+// fields map to the file's columns. Field types and tag stubs are inferred from
+// the header and the first rows of data:
+//   - integer and real columns become int64 and float64; true/false columns become bool
+//   - every field gets prep:"trim"
+//   - columns without blanks in the sample get validate:"required"
+//   - string columns whose sampled values are all emails, UUIDs, or URLs get
+//     the matching validator
+//   - a name tag is emitted when the header does not match the field's snake_case name
+//
+// The result is a starting point for a new import job; review the tags before use.
+//
+// Example:
+//
+//	src, err := fileprep.GenerateStruct(file, fileprep.FileTypeCSV, "User")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(src)
+//	// // User was generated by fileprep.GenerateStruct from a sample file.
+//	// type User struct {
+//	//     ID    int64  `prep:"trim" validate:"required"`
+//	//     Email string `prep:"trim" validate:"required,email"`
+//	// }
+func GenerateStruct(r io.Reader, ft FileType, structName string) (string, error) {
+	if !token.IsIdentifier(structName) || !token.IsExported(structName) {
+		return "", fmt.Errorf("invalid struct name %q: must be an exported Go identifier", structName)
+	}
+
+	rawData, err := readDecompressed(r, ft, 0)
+	if err != nil {
+		return "", err
+	}
+	tableData, err := fileparser.Parse(bytes.NewReader(rawData), fileparser.BaseFileType(ft))
+	if err != nil {
+		return "", err
+	}
+
+	sample := tableData.Records
+	if len(sample) > generateSampleSize {
+		sample = sample[:generateSampleSize]
+	}
+
+	var src strings.Builder
+	fmt.Fprintf(&src, "// %s was generated by fileprep.GenerateStruct from a sample file.\n", structName)
+	fmt.Fprintf(&src, "type %s struct {\n", structName)
+
+	usedNames := make(map[string]bool, len(tableData.Headers))
+	for i, header := range tableData.Headers {
+		fieldName := uniqueFieldName(headerToFieldName(header, i), usedNames)
+		values := columnSample(sample, i)
+
+		goType := "string"
+		if i < len(tableData.ColumnTypes) {
+			goType = inferGoType(tableData.ColumnTypes[i], values)
+		}
+
+		var tags []string
+		if toSnakeCase(fieldName) != header {
+			tags = append(tags, "name:"+strconv.Quote(header))
+		}
+		tags = append(tags, `prep:"trim"`)
+		if rules := inferValidateRules(goType, values, len(sample)); len(rules) > 0 {
+			tags = append(tags, "validate:"+strconv.Quote(strings.Join(rules, ",")))
+		}
+
+		fmt.Fprintf(&src, "\t%s %s %s\n", fieldName, goType, structTagLiteral(strings.Join(tags, " ")))
+	}
+	src.WriteString("}\n")
+
+	formatted, err := format.Source([]byte(src.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated struct: %w", err)
+	}
+	return string(formatted), nil
+}
+
+// headerToFieldName converts a column header to an exported Go field name.
+// Non-alphanumeric characters separate words, and each word is capitalized.
+// Headers that yield no usable identifier fall back to ColumnN.
+func headerToFieldName(header string, index int) string {
+	words := strings.FieldsFunc(header, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, word := range words {
+		if commonInitialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		first, size := utf8.DecodeRuneInString(word)
+		b.WriteRune(unicode.ToUpper(first))
+		b.WriteString(word[size:])
+	}
+
+	name := b.String()
+	if name == "" {
+		return "Column" + strconv.Itoa(index+1)
+	}
+	if !token.IsExported(name) {
+		// Leading digits or scripts without case cannot start an exported name
+		return "Field" + name
+	}
+	return name
+}
+
+// uniqueFieldName appends a numeric suffix when name is already taken.
+func uniqueFieldName(name string, used map[string]bool) string {
+	candidate := name
+	for n := 2; used[candidate]; n++ {
+		candidate = name + strconv.Itoa(n)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// columnSample returns the trimmed values of one column across the sample rows.
+func columnSample(records [][]string, colIdx int) []string {
+	values := make([]string, 0, len(records))
+	for _, record := range records {
+		value := ""
+		if colIdx < len(record) {
+			value = strings.TrimSpace(record[colIdx])
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// inferGoType picks the field type for a column from the parser's inferred
+// column type, with an extra check for boolean columns.
+func inferGoType(colType fileparser.ColumnType, values []string) string {
+	switch colType {
+	case fileparser.TypeInteger:
+		return "int64"
+	case fileparser.TypeReal:
+		return "float64"
+	default:
+		if allNonEmptyMatch(values, func(v string) bool { return v == boolTrueValue || v == boolFalseValue }) {
+			return "bool"
+		}
+		return "string"
+	}
+}
+
+// inferValidateRules returns validate tag stubs for a column.
+func inferValidateRules(goType string, values []string, sampleRows int) []string {
+	var rules []string
+	if sampleRows > 0 && !containsEmpty(values) {
+		rules = append(rules, requiredTagValue)
+	}
+	if goType != "string" {
+		return rules
+	}
+
+	candidates := []Validator{newEmailValidator(), newUUIDValidator(), newURLValidator()}
+	for _, v := range candidates {
+		if allNonEmptyMatch(values, func(s string) bool { return v.Validate(s) == "" }) {
+			rules = append(rules, v.Name())
+			break
+		}
+	}
+	return rules
+}
+
+// allNonEmptyMatch reports whether values has at least one non-empty entry
+// and every non-empty entry satisfies match.
+func allNonEmptyMatch(values []string, match func(string) bool) bool {
+	seen := false
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		if !match(v) {
+			return false
+		}
+		seen = true
+	}
+	return seen
+}
+
+// containsEmpty reports whether any value is empty.
+func containsEmpty(values []string) bool {
+	for _, v := range values {
+		if v == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// structTagLiteral returns tag as a Go string literal, preferring a raw string.
+func structTagLiteral(tag string) string {
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
@@ -0,0 +1,179 @@
+package fileprep
+
+import (
+	"bytes"
+	"compress/gzip"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/nao1215/fileparser"
+)
+
+func TestGenerateStruct(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		data       string
+		fileType   fileparser.FileType
+		structName string
+		wantLines  []string
+	}{
+		{
+			name:       "inferred types and validators",
+			data:       "id,email,price,enabled,token,site\n1,a@example.com,1.5,true,550e8400-e29b-41d4-a716-446655440000,https://example.com\n2,b@example.com,2,false,6ba7b810-9dad-11d1-80b4-00c04fd430c8,https://example.org\n",
+			fileType:   fileparser.CSV,
+			structName: "Item",
+			wantLines: []string{
+				"type Item struct {",
+				"ID      int64   `prep:\"trim\" validate:\"required\"`",
+				"Email   string  `prep:\"trim\" validate:\"required,email\"`",
+				"Price   float64 `prep:\"trim\" validate:\"required\"`",
+				"Enabled bool    `prep:\"trim\" validate:\"required\"`",
+				"Token   string  `prep:\"trim\" validate:\"required,uuid\"`",
+				"Site    string  `prep:\"trim\" validate:\"required,url\"`",
+			},
+		},
+		{
+			name:       "blank values drop required",
+			data:       "name\tnote\nAlice\t\nBob\thello\n",
+			fileType:   fileparser.TSV,
+			structName: "Row",
+			wantLines: []string{
+				"Name string `prep:\"trim\" validate:\"required\"`",
+				"Note string `prep:\"trim\"`",
+			},
+		},
+		{
+			name:       "headers needing name tags",
+			data:       "userId,first name,2nd,,first_name\nx,y,z,w,v\n",
+			fileType:   fileparser.CSV,
+			structName: "Person",
+			wantLines: []string{
+				"UserId    string `name:\"userId\" prep:\"trim\" validate:\"required\"`",
+				"FirstName string `name:\"first name\" prep:\"trim\" validate:\"required\"`",
+				"Field2nd  string `name:\"2nd\" prep:\"trim\" validate:\"required\"`",
+				"Column4   string `name:\"\" prep:\"trim\" validate:\"required\"`",
+				"FirstName2 string `name:\"first_name\" prep:\"trim\" validate:\"required\"`",
+			},
+		},
+		{
+			name:       "LTSV input",
+			data:       "host:127.0.0.1\tstatus:200\nhost:10.0.0.1\tstatus:404\n",
+			fileType:   fileparser.LTSV,
+			structName: "Access",
+			wantLines: []string{
+				"Host   string `prep:\"trim\" validate:\"required\"`",
+				"Status int64  `prep:\"trim\" validate:\"required\"`",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := GenerateStruct(strings.NewReader(tt.data), tt.fileType, tt.structName)
+			if err != nil {
+				t.Fatalf("GenerateStruct() error = %v", err)
+			}
+
+			for _, want := range tt.wantLines {
+				if !containsNormalizedLine(src, want) {
+					t.Errorf("GenerateStruct() output missing line %q\ngot:\n%s", want, src)
+				}
+			}
+
+			// The generated source must be a valid Go declaration
+			if _, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+src, 0); err != nil {
+				t.Errorf("generated source does not parse: %v\n%s", err, src)
+			}
+		})
+	}
+}
+
+func TestGenerateStruct_CompressedInput(t *testing.T) {
+	t.Parallel()
+
+	var compressed bytes.Buffer
+	gw := gzip.NewWriter(&compressed)
+	if _, err := gw.Write([]byte("name,age\nAlice,30\n")); err != nil {
+		t.Fatalf("gzip write error: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("gzip close error: %v", err)
+	}
+
+	src, err := GenerateStruct(&compressed, fileparser.CSVGZ, "Person")
+	if err != nil {
+		t.Fatalf("GenerateStruct() error = %v", err)
+	}
+	if !containsNormalizedLine(src, "Age int64 `prep:\"trim\" validate:\"required\"`") {
+		t.Errorf("GenerateStruct() output missing age field:\n%s", src)
+	}
+}
+
+func TestGenerateStruct_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		data       string
+		structName string
+	}{
+		{"empty struct name", "a\n1\n", ""},
+		{"unexported struct name", "a\n1\n", "row"},
+		{"invalid identifier", "a\n1\n", "My Row"},
+		{"empty input", "", "Row"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if _, err := GenerateStruct(strings.NewReader(tt.data), fileparser.CSV, tt.structName); err == nil {
+				t.Error("GenerateStruct() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestHeaderToFieldName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"name", "Name"},
+		{"user_id", "UserID"},
+		{"API-Key", "APIKey"},
+		{"created at", "CreatedAt"},
+		{"userName", "UserName"},
+		{"3d", "Field3d"},
+		{"名前", "Field名前"},
+		{"---", "Column1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			t.Parallel()
+			if got := headerToFieldName(tt.header, 0); got != tt.want {
+				t.Errorf("headerToFieldName(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+// containsNormalizedLine reports whether src has a line equal to want
+// after collapsing runs of whitespace, so gofmt alignment does not matter.
+func containsNormalizedLine(src, want string) bool {
+	want = strings.Join(strings.Fields(want), " ")
+	for line := range strings.SplitSeq(src, "\n") {
+		if strings.Join(strings.Fields(line), " ") == want {
+			return true
+		}
+	}
+	return false
+}
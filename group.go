@@ -0,0 +1,107 @@
+package fileprep
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// groupTagValue is the tag of the validation errors reported by
+// WithGroupValidator.
+const groupTagValue = "group"
+
+// groupValidator is a check added with WithGroupValidator.
+type groupValidator struct {
+	keyColumn string
+	validate  func(rows []map[string]string) []error
+}
+
+// groupCheck is a groupValidator resolved against the header of a run.
+type groupCheck struct {
+	groupValidator
+	colIdx int
+	field  string // struct field bound to the key column, if any
+}
+
+// groupChecks resolves the key columns of the group validators. It returns
+// an error wrapping ErrColumnNotFound for a key column that is not in the
+// header, and one wrapping ErrInvalidOption for a nil function.
+func (p *Processor) groupChecks(headerToColIdx map[string]int, si *structInfo) ([]groupCheck, error) {
+	checks := make([]groupCheck, 0, len(p.groupValidators))
+	for _, gv := range p.groupValidators {
+		if gv.validate == nil {
+			return nil, fmt.Errorf("%w: group validator for column %q has no function", ErrInvalidOption, gv.keyColumn)
+		}
+		colIdx, ok := headerToColIdx[gv.keyColumn]
+		if !ok {
+			return nil, fmt.Errorf("group key column %q: %w", gv.keyColumn, ErrColumnNotFound)
+		}
+		check := groupCheck{groupValidator: gv, colIdx: colIdx}
+		for _, fi := range si.Fields {
+			if fi.ColumnName == gv.keyColumn && fi.Name != "" {
+				check.field = fi.Name
+				break
+			}
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// runGroupChecks groups the processed rows by the key column of each check
+// and reports every error a group validator returns on each row of the
+// group. It returns the row numbers that got an error.
+func runGroupChecks(checks []groupCheck, headers []string, pending []pendingRow, result *ProcessResult) map[int]bool {
+	invalid := make(map[int]bool)
+	for _, check := range checks {
+		var keys []string // in order of first appearance
+		rowsByKey := make(map[string][]int)
+		for i, row := range pending {
+			key := row.record[check.colIdx]
+			if key == "" {
+				continue
+			}
+			if _, seen := rowsByKey[key]; !seen {
+				keys = append(keys, key)
+			}
+			rowsByKey[key] = append(rowsByKey[key], i)
+		}
+
+		for _, key := range keys {
+			indexes := rowsByKey[key]
+			rows := make([]map[string]string, len(indexes))
+			rowList := make([]string, len(indexes))
+			for i, idx := range indexes {
+				rows[i] = rowMap(headers, pending[idx].record)
+				rowList[i] = strconv.Itoa(pending[idx].rowNum)
+			}
+
+			for _, err := range check.validate(rows) {
+				if err == nil {
+					continue
+				}
+				msg := err.Error() + " (rows " + strings.Join(rowList, ", ") + ")"
+				for _, idx := range indexes {
+					rowNum := pending[idx].rowNum
+					result.Errors = append(result.Errors, newValidationError(
+						rowNum, check.keyColumn, check.field, key, groupTagValue, "", msg,
+					))
+					invalid[rowNum] = true
+				}
+			}
+		}
+	}
+	return invalid
+}
+
+// rowMap returns a record as a map from column name to value. The first of
+// duplicate column names wins.
+func rowMap(headers, record []string) map[string]string {
+	row := make(map[string]string, len(headers))
+	for i, h := range headers {
+		if _, exists := row[h]; !exists && i < len(record) {
+			row[h] = record[i]
+		}
+	}
+	return row
+}
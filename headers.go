@@ -0,0 +1,321 @@
+package fileprep
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Header issue reasons reported in HeaderIssue.Reason
+const (
+	// HeaderReservedWord marks a column name that is an SQLite keyword
+	HeaderReservedWord = "RESERVED_WORD"
+	// HeaderNeedsQuoting marks a column name that is not a plain identifier
+	// (letters, digits, and underscores, not starting with a digit)
+	HeaderNeedsQuoting = "NEEDS_QUOTING"
+	// HeaderCaseCollision marks a column name that equals an earlier one
+	// when case is ignored, as SQLite compares column names
+	HeaderCaseCollision = "CASE_COLLISION"
+
+	// HeaderNotLowercase marks a column name that breaks the lowercase rule
+	HeaderNotLowercase = "NOT_LOWERCASE"
+	// HeaderNotUppercase marks a column name that breaks the uppercase rule
+	HeaderNotUppercase = "NOT_UPPERCASE"
+	// HeaderNotSnakeCase marks a column name that breaks the snakecase rule
+	HeaderNotSnakeCase = "NOT_SNAKE_CASE"
+	// HeaderNotKebabCase marks a column name that breaks the kebabcase rule
+	HeaderNotKebabCase = "NOT_KEBAB_CASE"
+	// HeaderNotCamelCase marks a column name that breaks the camelcase rule
+	HeaderNotCamelCase = "NOT_CAMEL_CASE"
+	// HeaderNotASCII marks a column name that breaks the ascii rule
+	HeaderNotASCII = "NOT_ASCII"
+	// HeaderTooLong marks a column name that breaks the maxlen rule
+	HeaderTooLong = "TOO_LONG"
+)
+
+// HeaderIssue describes a column name that is unsafe to use as an SQLite
+// column name without quoting, reported with WithSQLHeaderCheck, or that
+// breaks a naming rule set with WithHeaderRules.
+type HeaderIssue struct {
+	// Index is the 0-based position of the column in the header
+	Index int
+	// Column is the column name as it appears in the file
+	Column string
+	// Reason is HeaderReservedWord, HeaderNeedsQuoting, HeaderCaseCollision,
+	// or one of the Header* reasons of WithHeaderRules
+	Reason string
+	// Suggestion is the name WithSanitizeHeaders would use for SQL issues,
+	// or the name rewritten to follow the broken rule; it is empty when no
+	// rewrite applies (ascii, maxlen)
+	Suggestion string
+}
+
+// sqliteKeywords lists the SQLite keywords (https://sqlite.org/lang_keywords.html)
+// in upper case.
+//
+//nolint:gochecknoglobals // lookup table
+var sqliteKeywords = map[string]bool{
+	"ABORT": true, "ACTION": true, "ADD": true, "AFTER": true, "ALL": true, "ALTER": true,
+	"ALWAYS": true, "ANALYZE": true, "AND": true, "AS": true, "ASC": true, "ATTACH": true,
+	"AUTOINCREMENT": true, "BEFORE": true, "BEGIN": true, "BETWEEN": true, "BY": true,
+	"CASCADE": true, "CASE": true, "CAST": true, "CHECK": true, "COLLATE": true, "COLUMN": true,
+	"COMMIT": true, "CONFLICT": true, "CONSTRAINT": true, "CREATE": true, "CROSS": true,
+	"CURRENT": true, "CURRENT_DATE": true, "CURRENT_TIME": true, "CURRENT_TIMESTAMP": true,
+	"DATABASE": true, "DEFAULT": true, "DEFERRABLE": true, "DEFERRED": true, "DELETE": true,
+	"DESC": true, "DETACH": true, "DISTINCT": true, "DO": true, "DROP": true, "EACH": true,
+	"ELSE": true, "END": true, "ESCAPE": true, "EXCEPT": true, "EXCLUDE": true, "EXCLUSIVE": true,
+	"EXISTS": true, "EXPLAIN": true, "FAIL": true, "FILTER": true, "FIRST": true,
+	"FOLLOWING": true, "FOR": true, "FOREIGN": true, "FROM": true, "FULL": true,
+	"GENERATED": true, "GLOB": true, "GROUP": true, "GROUPS": true, "HAVING": true, "IF": true,
+	"IGNORE": true, "IMMEDIATE": true, "IN": true, "INDEX": true, "INDEXED": true,
+	"INITIALLY": true, "INNER": true, "INSERT": true, "INSTEAD": true, "INTERSECT": true,
+	"INTO": true, "IS": true, "ISNULL": true, "JOIN": true, "KEY": true, "LAST": true,
+	"LEFT": true, "LIKE": true, "LIMIT": true, "MATCH": true, "MATERIALIZED": true,
+	"NATURAL": true, "NO": true, "NOT": true, "NOTHING": true, "NOTNULL": true, "NULL": true,
+	"NULLS": true, "OF": true, "OFFSET": true, "ON": true, "OR": true, "ORDER": true,
+	"OTHERS": true, "OUTER": true, "OVER": true, "PARTITION": true, "PLAN": true,
+	"PRAGMA": true, "PRECEDING": true, "PRIMARY": true, "QUERY": true, "RAISE": true,
+	"RANGE": true, "RECURSIVE": true, "REFERENCES": true, "REGEXP": true, "REINDEX": true,
+	"RELEASE": true, "RENAME": true, "REPLACE": true, "RESTRICT": true, "RETURNING": true,
+	"RIGHT": true, "ROLLBACK": true, "ROW": true, "ROWS": true, "SAVEPOINT": true,
+	"SELECT": true, "SET": true, "TABLE": true, "TEMP": true, "TEMPORARY": true, "THEN": true,
+	"TIES": true, "TO": true, "TRANSACTION": true, "TRIGGER": true, "UNBOUNDED": true,
+	"UNION": true, "UNIQUE": true, "UPDATE": true, "USING": true, "VACUUM": true,
+	"VALUES": true, "VIEW": true, "VIRTUAL": true, "WHEN": true, "WHERE": true,
+	"WINDOW": true, "WITH": true, "WITHOUT": true,
+}
+
+// isSQLKeyword reports whether name is an SQLite keyword, ignoring case.
+func isSQLKeyword(name string) bool {
+	return sqliteKeywords[strings.ToUpper(name)]
+}
+
+// isPlainIdentifier reports whether name can be used unquoted: letters,
+// digits, and underscores, not starting with a digit.
+func isPlainIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// checkSQLHeaders returns an issue for every column name that is an SQLite
+// keyword, needs quoting, or collides with an earlier name when case is
+// ignored. A column has at most one issue; the first matching reason wins.
+func checkSQLHeaders(headers []string) []HeaderIssue {
+	suggestions := sanitizeHeaders(headers)
+	seen := make(map[string]bool, len(headers))
+	var issues []HeaderIssue
+	for i, h := range headers {
+		folded := strings.ToLower(h)
+		reason := ""
+		switch {
+		case !isPlainIdentifier(h):
+			reason = HeaderNeedsQuoting
+		case isSQLKeyword(h):
+			reason = HeaderReservedWord
+		case seen[folded]:
+			reason = HeaderCaseCollision
+		}
+		seen[folded] = true
+		if reason != "" {
+			issues = append(issues, HeaderIssue{Index: i, Column: h, Reason: reason, Suggestion: suggestions[i]})
+		}
+	}
+	return issues
+}
+
+// dedupHeaders renames repeated column names by appending a numeric suffix
+// to every occurrence after the first (id, id, name becomes id, id_2, name).
+// Suffixes skip names that already appear in the header.
+func dedupHeaders(headers []string) []string {
+	taken := make(map[string]bool, len(headers))
+	for _, h := range headers {
+		taken[h] = true
+	}
+	out := make([]string, len(headers))
+	seen := make(map[string]bool, len(headers))
+	for i, h := range headers {
+		if !seen[h] {
+			seen[h] = true
+			out[i] = h
+			continue
+		}
+		candidate := h
+		for n := 2; taken[candidate]; n++ {
+			candidate = h + "_" + strconv.Itoa(n)
+		}
+		taken[candidate] = true
+		out[i] = candidate
+	}
+	return out
+}
+
+// sanitizeHeaders rewrites column names into plain identifiers that are not
+// SQLite keywords:
+//   - surrounding spaces are trimmed and other characters that are not letters,
+//     digits, or underscores become underscores
+//   - a leading digit gets an underscore prefix, and an empty name becomes column_N
+//   - keywords get an underscore suffix (order becomes order_)
+//   - names that collide, ignoring case, get a numeric suffix (id, id_2)
+func sanitizeHeaders(headers []string) []string {
+	out := make([]string, len(headers))
+	used := make(map[string]bool, len(headers))
+	for i, h := range headers {
+		name := sanitizeHeader(h, i)
+		candidate := name
+		for n := 2; used[strings.ToLower(candidate)]; n++ {
+			candidate = name + "_" + strconv.Itoa(n)
+		}
+		used[strings.ToLower(candidate)] = true
+		out[i] = candidate
+	}
+	return out
+}
+
+// sanitizeHeader rewrites a single column name; index is its 0-based position.
+func sanitizeHeader(header string, index int) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(header) {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	name := b.String()
+	switch {
+	case name == "":
+		return "column_" + strconv.Itoa(index+1)
+	case unicode.IsDigit([]rune(name)[0]):
+		return "_" + name
+	case isSQLKeyword(name):
+		return name + "_"
+	default:
+		return name
+	}
+}
+
+// headerRule is a naming rule for column names set with WithHeaderRules.
+type headerRule struct {
+	reason  string
+	ok      func(name string) bool
+	suggest func(name string) string // nil when no rewrite applies
+}
+
+// parseHeaderRules returns the header rules with the given names. A rule is
+// lowercase, uppercase, snakecase, kebabcase, camelcase, ascii, or maxlen=N.
+func parseHeaderRules(names []string) ([]headerRule, error) {
+	rules := make([]headerRule, 0, len(names))
+	for _, name := range names {
+		key, value := splitTagKeyValue(name)
+		var rule headerRule
+		switch key {
+		case "lowercase":
+			rule = headerRule{reason: HeaderNotLowercase, ok: func(h string) bool { return h == strings.ToLower(h) }, suggest: strings.ToLower}
+		case "uppercase":
+			rule = headerRule{reason: HeaderNotUppercase, ok: func(h string) bool { return h == strings.ToUpper(h) }, suggest: strings.ToUpper}
+		case "snakecase":
+			rule = headerRule{reason: HeaderNotSnakeCase, ok: func(h string) bool { return isDelimitedLower(h, '_') }, suggest: func(h string) string {
+				return strings.Join(headerWords(h), "_")
+			}}
+		case "kebabcase":
+			rule = headerRule{reason: HeaderNotKebabCase, ok: func(h string) bool { return isDelimitedLower(h, '-') }, suggest: func(h string) string {
+				return strings.Join(headerWords(h), "-")
+			}}
+		case "camelcase":
+			rule = headerRule{reason: HeaderNotCamelCase, ok: isCamelCase, suggest: toCamelCase}
+		case "ascii":
+			rule = headerRule{reason: HeaderNotASCII, ok: func(h string) bool {
+				return !strings.ContainsFunc(h, func(r rune) bool { return r > unicode.MaxASCII })
+			}}
+		case "maxlen":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("%w: header rule %q: maxlen needs a positive length", ErrInvalidOption, name)
+			}
+			rule = headerRule{reason: HeaderTooLong, ok: func(h string) bool { return utf8.RuneCountInString(h) <= n }}
+		default:
+			return nil, fmt.Errorf("%w: unknown header rule %q", ErrInvalidOption, name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// checkHeaderRules returns an issue for every rule a column name breaks.
+func checkHeaderRules(headers []string, rules []headerRule) []HeaderIssue {
+	var issues []HeaderIssue
+	for i, h := range headers {
+		for _, rule := range rules {
+			if rule.ok(h) {
+				continue
+			}
+			issue := HeaderIssue{Index: i, Column: h, Reason: rule.reason}
+			if rule.suggest != nil {
+				issue.Suggestion = rule.suggest(h)
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// isDelimitedLower reports whether name is lower-case ASCII words of letters
+// and digits joined by single sep characters, starting with a letter.
+func isDelimitedLower(name string, sep byte) bool {
+	if name == "" || name[0] < 'a' || name[0] > 'z' || name[len(name)-1] == sep {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		case c == sep && name[i-1] != sep:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isCamelCase reports whether name is ASCII letters and digits starting with
+// a lower-case letter.
+func isCamelCase(name string) bool {
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// headerWords splits a column name into lower-case words at spaces,
+// punctuation, and case changes: "Order ID" and "orderId" both give
+// [order id].
+func headerWords(name string) []string {
+	return strings.FieldsFunc(toSnakeCase(strings.TrimSpace(name)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// toCamelCase rewrites a column name in camelCase: "Order ID" becomes orderId.
+func toCamelCase(name string) string {
+	words := headerWords(name)
+	for i := 1; i < len(words); i++ {
+		words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+	}
+	return strings.Join(words, "")
+}
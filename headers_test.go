@@ -0,0 +1,171 @@
+package fileprep
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSanitizeHeaders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		headers []string
+		want    []string
+	}{
+		{
+			name:    "plain identifiers are kept",
+			headers: []string{"id", "user_name", "Email", "名前"},
+			want:    []string{"id", "user_name", "Email", "名前"},
+		},
+		{
+			name:    "spaces and punctuation become underscores",
+			headers: []string{" Order ID ", "unit-price", "a.b"},
+			want:    []string{"Order_ID", "unit_price", "a_b"},
+		},
+		{
+			name:    "keywords get an underscore suffix",
+			headers: []string{"order", "Group", "select"},
+			want:    []string{"order_", "Group_", "select_"},
+		},
+		{
+			name:    "leading digit and empty names",
+			headers: []string{"2024", "", "  "},
+			want:    []string{"_2024", "column_2", "column_3"},
+		},
+		{
+			name:    "collisions ignoring case get numeric suffixes",
+			headers: []string{"id", "ID", "first name", "first_name", "id_2"},
+			want:    []string{"id", "ID_2", "first_name", "first_name_2", "id_2_2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(tt.want, sanitizeHeaders(tt.headers)); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckSQLHeaders(t *testing.T) {
+	t.Parallel()
+
+	got := checkSQLHeaders([]string{"id", "order", "unit price", "ID", "2024", "名前"})
+	want := []HeaderIssue{
+		{Index: 1, Column: "order", Reason: HeaderReservedWord, Suggestion: "order_"},
+		{Index: 2, Column: "unit price", Reason: HeaderNeedsQuoting, Suggestion: "unit_price"},
+		{Index: 3, Column: "ID", Reason: HeaderCaseCollision, Suggestion: "ID_2"},
+		{Index: 4, Column: "2024", Reason: HeaderNeedsQuoting, Suggestion: "_2024"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDedupHeaders(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		headers []string
+		want    []string
+	}{
+		{
+			name:    "unique names are kept",
+			headers: []string{"id", "ID", "name"},
+			want:    []string{"id", "ID", "name"},
+		},
+		{
+			name:    "repeats get numeric suffixes",
+			headers: []string{"id", "id", "name", "id"},
+			want:    []string{"id", "id_2", "name", "id_3"},
+		},
+		{
+			name:    "suffixes skip existing names",
+			headers: []string{"id", "id", "id_2"},
+			want:    []string{"id", "id_3", "id_2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(tt.want, dedupHeaders(tt.headers)); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCheckHeaderRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		rules   []string
+		headers []string
+		want    []HeaderIssue
+		wantErr bool
+	}{
+		{
+			name:    "snakecase",
+			rules:   []string{"snakecase"},
+			headers: []string{"user_id", "Order ID", "unitPrice", "a__b", "2nd"},
+			want: []HeaderIssue{
+				{Index: 1, Column: "Order ID", Reason: HeaderNotSnakeCase, Suggestion: "order_id"},
+				{Index: 2, Column: "unitPrice", Reason: HeaderNotSnakeCase, Suggestion: "unit_price"},
+				{Index: 3, Column: "a__b", Reason: HeaderNotSnakeCase, Suggestion: "a_b"},
+				{Index: 4, Column: "2nd", Reason: HeaderNotSnakeCase, Suggestion: "2nd"},
+			},
+		},
+		{
+			name:    "kebabcase and camelcase",
+			rules:   []string{"kebabcase", "camelcase"},
+			headers: []string{"order-id", "orderId"},
+			want: []HeaderIssue{
+				{Index: 0, Column: "order-id", Reason: HeaderNotCamelCase, Suggestion: "orderId"},
+				{Index: 1, Column: "orderId", Reason: HeaderNotKebabCase, Suggestion: "order-id"},
+			},
+		},
+		{
+			name:    "lowercase, ascii, and maxlen",
+			rules:   []string{"lowercase", "ascii", "maxlen=5"},
+			headers: []string{"Name", "名前", "address"},
+			want: []HeaderIssue{
+				{Index: 0, Column: "Name", Reason: HeaderNotLowercase, Suggestion: "name"},
+				{Index: 1, Column: "名前", Reason: HeaderNotASCII},
+				{Index: 2, Column: "address", Reason: HeaderTooLong},
+			},
+		},
+		{
+			name:    "unknown rule",
+			rules:   []string{"titlecase"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid maxlen",
+			rules:   []string{"maxlen=x"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rules, err := parseHeaderRules(tt.rules)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHeaderRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.want, checkHeaderRules(tt.headers, rules)); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package fileprep
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseJSONRecordPath splits a record path into its keys and array indexes.
+// Two forms are accepted: a JSONPath subset rooted at $ ($.items[*],
+// $.data['records'], $.pages[0].items) and a JSON Pointer (/data/items).
+// A trailing [*] selects the elements of the array and may be omitted.
+func parseJSONRecordPath(path string) ([]string, error) {
+	switch {
+	case strings.HasPrefix(path, "$"):
+		return parseJSONPath(path)
+	case strings.HasPrefix(path, "/"):
+		return parseJSONPointer(path), nil
+	default:
+		return nil, fmt.Errorf("%w: JSON record path %q must start with $ or /", ErrInvalidOption, path)
+	}
+}
+
+// parseJSONPath parses the dot and bracket steps of a JSONPath expression.
+func parseJSONPath(path string) ([]string, error) {
+	var tokens []string
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case rest == "[*]":
+			return tokens, nil
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" || key == "*" {
+				return nil, fmt.Errorf("%w: JSON record path %q: empty or wildcard key", ErrInvalidOption, path)
+			}
+			tokens = append(tokens, key)
+			rest = rest[end+1:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: JSON record path %q: unclosed bracket", ErrInvalidOption, path)
+			}
+			inner := rest[1:end]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				tokens = append(tokens, inner[1:len(inner)-1])
+			} else if _, err := strconv.Atoi(inner); err == nil {
+				tokens = append(tokens, inner)
+			} else {
+				return nil, fmt.Errorf("%w: JSON record path %q: unsupported step [%s]", ErrInvalidOption, path, inner)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("%w: JSON record path %q: unexpected %q", ErrInvalidOption, path, rest)
+		}
+	}
+	return tokens, nil
+}
+
+// parseJSONPointer parses an RFC 6901 JSON Pointer.
+func parseJSONPointer(path string) []string {
+	if path == "/" {
+		return nil
+	}
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+// extractJSONRecords returns the JSON array at path inside data, so that
+// wrapped API responses such as {"meta":{...},"items":[...]} parse like a
+// top-level array. The array is returned as it appears in the input.
+func extractJSONRecords(data []byte, path string) ([]byte, error) {
+	tokens, err := parseJSONRecordPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := json.RawMessage(data)
+	for i, token := range tokens {
+		next, err := jsonChild(current, token)
+		if err != nil {
+			return nil, fmt.Errorf("JSON record path %q: %s: %w", path, strings.Join(tokens[:i+1], "/"), err)
+		}
+		current = next
+	}
+
+	var records []json.RawMessage
+	if err := json.Unmarshal(current, &records); err != nil {
+		return nil, fmt.Errorf("JSON record path %q does not point to an array", path)
+	}
+	return current, nil
+}
+
+// jsonChild returns the member named token of a JSON object, or the element
+// at index token of a JSON array.
+func jsonChild(value json.RawMessage, token string) (json.RawMessage, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(value, &object); err == nil {
+		child, ok := object[token]
+		if !ok {
+			return nil, errors.New("key not found")
+		}
+		return child, nil
+	}
+
+	var array []json.RawMessage
+	if err := json.Unmarshal(value, &array); err != nil {
+		return nil, errors.New("not an object or array")
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index >= len(array) {
+		return nil, errors.New("array index out of range")
+	}
+	return array[index], nil
+}
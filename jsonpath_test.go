@@ -0,0 +1,47 @@
+package fileprep
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExtractJSONRecords(t *testing.T) {
+	t.Parallel()
+
+	doc := `{"meta":{"page":1},"data":{"items":[{"id":1},{"id":2}],"a/b":[3]},"pages":[{"items":[4]}]}`
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{name: "JSONPath with wildcard", path: "$.data.items[*]", want: `[{"id":1},{"id":2}]`},
+		{name: "JSONPath without wildcard", path: "$.data.items", want: `[{"id":1},{"id":2}]`},
+		{name: "JSONPath bracket key", path: "$['data']['a/b']", want: `[3]`},
+		{name: "JSONPath array index", path: "$.pages[0].items", want: `[4]`},
+		{name: "JSON Pointer", path: "/data/items", want: `[{"id":1},{"id":2}]`},
+		{name: "JSON Pointer escape", path: "/data/a~1b", want: `[3]`},
+		{name: "JSON Pointer array index", path: "/pages/0/items", want: `[4]`},
+		{name: "missing key", path: "$.data.records", wantErr: true},
+		{name: "not an array", path: "$.meta", wantErr: true},
+		{name: "index out of range", path: "$.pages[1]", wantErr: true},
+		{name: "no root", path: "data.items", wantErr: true},
+		{name: "unsupported step", path: "$..items", wantErr: true},
+		{name: "unclosed bracket", path: "$.pages[0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := extractJSONRecords([]byte(doc), tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractJSONRecords() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, string(got)); diff != "" {
+				t.Errorf("records mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
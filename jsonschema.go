@@ -0,0 +1,523 @@
+package fileprep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nao1215/fileparser"
+)
+
+// NewProcessorFromJSONSchema returns a processor that checks columns against
+// the rules of a JSON Schema describing one row as an object keyed by column
+// name, so existing schemas can be reused without writing struct tags. The
+// schema rules apply in addition to the tags of the struct passed to
+// Process; pass &[]struct{}{} to validate with the schema alone.
+//
+// Each property becomes the rules of the column with its name:
+//   - type: integer, number, and boolean check the value like the numeric,
+//     number, and boolean tags; string and null add no check
+//   - required: listed columns reject empty values; other columns skip their
+//     checks when empty, since an empty cell is a missing value
+//   - enum and const: like oneof, matching the values as text
+//   - minimum, maximum, exclusiveMinimum, exclusiveMaximum: like min, max,
+//     gt, and lt
+//   - minLength, maxLength: character counts
+//   - pattern: a regular expression in Go syntax that must match somewhere
+//     in the value
+//   - format: email, uri, uuid, ipv4, ipv6, hostname, date-time, date, and
+//     time; other formats are annotations and add no check
+//   - default: fills empty values before they are checked, like the default
+//     prep tag
+//
+// Annotations such as title and description are ignored. Any other keyword,
+// such as $ref, allOf, or a nested object, returns an error wrapping
+// ErrInvalidSchema rather than silently skipping a rule. Errors from schema
+// rules have an empty Field, and their Tag is the name of the rule, such as
+// "required", "pattern", or "maxLength".
+//
+// Example:
+//
+//	schema := []byte(`{
+//	    "type": "object",
+//	    "required": ["id", "email"],
+//	    "properties": {
+//	        "id":    {"type": "integer", "minimum": 1},
+//	        "email": {"type": "string", "format": "email"},
+//	        "plan":  {"enum": ["free", "pro"]}
+//	    }
+//	}`)
+//	processor, err := fileprep.NewProcessorFromJSONSchema(schema, fileparser.CSV)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	var rows []struct{}
+//	_, result, err := processor.Process(input, &rows)
+func NewProcessorFromJSONSchema(schema []byte, fileType fileparser.FileType, opts ...Option) (*Processor, error) {
+	fields, err := compileJSONSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	p := NewProcessor(fileType, opts...)
+	p.schemaFields = fields
+	return p, nil
+}
+
+// jsonSchemaAnnotations are keywords that describe a schema without
+// constraining values.
+//
+//nolint:gochecknoglobals // keyword table
+var jsonSchemaAnnotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "title": true, "description": true,
+	"examples": true, "deprecated": true, "readOnly": true, "writeOnly": true,
+}
+
+// jsonSchemaRootKeywords are the keywords compiled at the root of a schema.
+// additionalProperties is accepted but not enforced: extra columns are
+// always allowed, as with struct tags.
+//
+//nolint:gochecknoglobals // keyword table
+var jsonSchemaRootKeywords = map[string]bool{
+	"type": true, "properties": true, "required": true, "additionalProperties": true,
+}
+
+// jsonSchemaPropertyRules holds the keywords compiled for one property.
+type jsonSchemaPropertyRules struct {
+	Type             json.RawMessage   `json:"type"`
+	Enum             []json.RawMessage `json:"enum"`
+	Const            json.RawMessage   `json:"const"`
+	Minimum          *float64          `json:"minimum"`
+	Maximum          *float64          `json:"maximum"`
+	ExclusiveMinimum json.RawMessage   `json:"exclusiveMinimum"`
+	ExclusiveMaximum json.RawMessage   `json:"exclusiveMaximum"`
+	MinLength        *int              `json:"minLength"`
+	MaxLength        *int              `json:"maxLength"`
+	Pattern          *string           `json:"pattern"`
+	Format           string            `json:"format"`
+	Default          json.RawMessage   `json:"default"`
+}
+
+// jsonSchemaPropertyKeywords are the keywords compiled for a property.
+//
+//nolint:gochecknoglobals // keyword table
+var jsonSchemaPropertyKeywords = map[string]bool{
+	"type": true, "enum": true, "const": true, "minimum": true, "maximum": true,
+	"exclusiveMinimum": true, "exclusiveMaximum": true, "minLength": true,
+	"maxLength": true, "pattern": true, "format": true, "default": true,
+}
+
+// jsonSchemaFormats maps JSON Schema formats to validate tags and their
+// parameters.
+//
+//nolint:gochecknoglobals // format table
+var jsonSchemaFormats = map[string][2]string{
+	"email":     {emailTagValue, ""},
+	"uri":       {uriTagValue, ""},
+	"uuid":      {uuidTagValue, ""},
+	"ipv4":      {ip4AddrTagValue, ""},
+	"ipv6":      {ip6AddrTagValue, ""},
+	"hostname":  {hostnameTagValue, ""},
+	"date-time": {datetimeTagValue, time.RFC3339},
+	"date":      {datetimeTagValue, time.DateOnly},
+	"time":      {datetimeTagValue, time.TimeOnly},
+}
+
+// compileJSONSchema returns one field per property of schema, in document
+// order, with ColumnIndex -1 and no struct field (Index -1).
+func compileJSONSchema(schema []byte) ([]fieldInfo, error) {
+	return compileSchemaObject(schema, nil)
+}
+
+// compileSchemaObject compiles an object schema. prepare, when not nil,
+// rewrites each property schema before it is compiled.
+func compileSchemaObject(schema []byte, prepare func(json.RawMessage) (json.RawMessage, error)) ([]fieldInfo, error) {
+	var root map[string]json.RawMessage
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+	if err := checkJSONSchemaKeywords(root, jsonSchemaRootKeywords); err != nil {
+		return nil, err
+	}
+	if raw, ok := root["type"]; ok {
+		var typ string
+		if err := json.Unmarshal(raw, &typ); err != nil || typ != "object" {
+			return nil, fmt.Errorf("%w: the root type must be \"object\", got %s", ErrInvalidSchema, raw)
+		}
+	}
+
+	var required []string
+	if raw, ok := root["required"]; ok {
+		if err := json.Unmarshal(raw, &required); err != nil {
+			return nil, fmt.Errorf("%w: required: %w", ErrInvalidSchema, err)
+		}
+	}
+
+	names, err := jsonObjectKeys(root["properties"])
+	if err != nil {
+		return nil, fmt.Errorf("%w: properties: %w", ErrInvalidSchema, err)
+	}
+	var properties map[string]json.RawMessage
+	if len(names) > 0 {
+		if err := json.Unmarshal(root["properties"], &properties); err != nil {
+			return nil, fmt.Errorf("%w: properties: %w", ErrInvalidSchema, err)
+		}
+	}
+	if prepare != nil {
+		for name, raw := range properties {
+			if properties[name], err = prepare(raw); err != nil {
+				return nil, fmt.Errorf("property %q: %w", name, err)
+			}
+		}
+	}
+	return compileSchemaColumns(names, properties, required)
+}
+
+// compileSchemaColumns compiles the property schemas of the named columns.
+func compileSchemaColumns(names []string, properties map[string]json.RawMessage, required []string) ([]fieldInfo, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: the schema has no properties", ErrInvalidSchema)
+	}
+	for _, name := range required {
+		if _, ok := properties[name]; !ok {
+			return nil, fmt.Errorf("%w: required property %q is not in properties", ErrInvalidSchema, name)
+		}
+	}
+
+	fields := make([]fieldInfo, 0, len(names))
+	for _, name := range names {
+		fi, err := compileJSONSchemaProperty(name, properties[name], slices.Contains(required, name))
+		if err != nil {
+			return nil, fmt.Errorf("property %q: %w", name, err)
+		}
+		fields = append(fields, fi)
+	}
+	return fields, nil
+}
+
+// compileJSONSchemaProperty compiles the rules of one column.
+func compileJSONSchemaProperty(name string, raw json.RawMessage, required bool) (fieldInfo, error) {
+	fi := fieldInfo{ColumnName: name, Index: -1, ColumnIndex: -1}
+
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &keywords); err != nil {
+		return fi, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+	if err := checkJSONSchemaKeywords(keywords, jsonSchemaPropertyKeywords); err != nil {
+		return fi, err
+	}
+	var rules jsonSchemaPropertyRules
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return fi, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+
+	if rules.Default != nil {
+		value, err := jsonScalarText(rules.Default)
+		if err != nil {
+			return fi, fmt.Errorf("default: %w", err)
+		}
+		fi.Preprocessors = preprocessors{newDefaultPreprocessor(value)}
+	}
+
+	var vals validators
+	add := func(tag, param string) error {
+		v, err := validatorRegistry[tag](param, true)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+		}
+		if param != "" {
+			v = &paramValidator{Validator: v, param: param}
+		}
+		vals = append(vals, v)
+		return nil
+	}
+
+	if required {
+		vals = append(vals, newRequiredValidator())
+	} else {
+		vals = append(vals, &omitemptyValidator{})
+	}
+
+	typeTag, err := jsonSchemaTypeTag(rules.Type)
+	if err != nil {
+		return fi, err
+	}
+	if typeTag != "" {
+		if err := add(typeTag, ""); err != nil {
+			return fi, err
+		}
+	}
+
+	enum := rules.Enum
+	if rules.Const != nil {
+		enum = []json.RawMessage{rules.Const}
+	}
+	if len(enum) > 0 {
+		allowed := make([]string, len(enum))
+		for i, raw := range enum {
+			if allowed[i], err = jsonScalarText(raw); err != nil {
+				return fi, fmt.Errorf("enum: %w", err)
+			}
+		}
+		vals = append(vals, &paramValidator{Validator: newOneOfValidator(allowed), param: strings.Join(allowed, " ")})
+	}
+
+	minTag, maxTag := minTagValue, maxTagValue
+	exclusiveMin, err := jsonSchemaExclusiveBound(rules.ExclusiveMinimum, &minTag, greaterThanTagValue)
+	if err != nil {
+		return fi, err
+	}
+	exclusiveMax, err := jsonSchemaExclusiveBound(rules.ExclusiveMaximum, &maxTag, lessThanTagValue)
+	if err != nil {
+		return fi, err
+	}
+	for _, bound := range []struct {
+		tag   string
+		limit *float64
+	}{
+		{greaterThanTagValue, exclusiveMin},
+		{lessThanTagValue, exclusiveMax},
+		{minTag, rules.Minimum},
+		{maxTag, rules.Maximum},
+	} {
+		if bound.limit == nil {
+			continue
+		}
+		if err := add(bound.tag, strconv.FormatFloat(*bound.limit, 'f', -1, 64)); err != nil {
+			return fi, err
+		}
+	}
+
+	switch {
+	case rules.MinLength != nil && rules.MaxLength != nil && *rules.MinLength == *rules.MaxLength:
+		if err := add(lengthTagValue, strconv.Itoa(*rules.MinLength)); err != nil {
+			return fi, err
+		}
+	default:
+		if rules.MinLength != nil && *rules.MinLength > 0 {
+			vals = append(vals, &paramValidator{Validator: newMinLengthValidator(*rules.MinLength), param: strconv.Itoa(*rules.MinLength)})
+		}
+		if rules.MaxLength != nil {
+			vals = append(vals, &paramValidator{Validator: newMaxLengthValidator(*rules.MaxLength), param: strconv.Itoa(*rules.MaxLength)})
+		}
+	}
+
+	if rules.Pattern != nil {
+		v, err := newPatternValidator(*rules.Pattern)
+		if err != nil {
+			return fi, fmt.Errorf("%w: pattern: %w", ErrInvalidSchema, err)
+		}
+		vals = append(vals, &paramValidator{Validator: v, param: *rules.Pattern})
+	}
+
+	if format, ok := jsonSchemaFormats[rules.Format]; ok {
+		if err := add(format[0], format[1]); err != nil {
+			return fi, err
+		}
+	}
+
+	fi.Validators = vals
+	return fi, nil
+}
+
+// checkJSONSchemaKeywords returns an error for the first keyword of object
+// that is neither in allowed nor an annotation.
+func checkJSONSchemaKeywords(object map[string]json.RawMessage, allowed map[string]bool) error {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if !allowed[key] && !jsonSchemaAnnotations[key] {
+			return fmt.Errorf("%w: unsupported keyword %q", ErrInvalidSchema, key)
+		}
+	}
+	return nil
+}
+
+// jsonSchemaTypeTag returns the validate tag checking a JSON Schema type,
+// which is a type name or a list of them. null is allowed next to one other
+// type, since an empty cell is a missing value anyway.
+func jsonSchemaTypeTag(raw json.RawMessage) (string, error) {
+	if raw == nil {
+		return "", nil
+	}
+	var types []string
+	var single string
+	if json.Unmarshal(raw, &single) == nil {
+		types = []string{single}
+	} else if err := json.Unmarshal(raw, &types); err != nil {
+		return "", fmt.Errorf("%w: type must be a string or a list of strings, got %s", ErrInvalidSchema, raw)
+	}
+	types = slices.DeleteFunc(types, func(t string) bool { return t == "null" })
+	if len(types) > 1 {
+		return "", fmt.Errorf("%w: type lists are supported only with null, got %s", ErrInvalidSchema, raw)
+	}
+	if len(types) == 0 {
+		return "", nil
+	}
+
+	switch types[0] {
+	case "string":
+		return "", nil
+	case "integer":
+		return numericTagValue, nil
+	case "number":
+		return numberTagValue, nil
+	case "boolean":
+		return booleanTagValue, nil
+	default:
+		return "", fmt.Errorf("%w: type %q does not describe a cell value", ErrInvalidSchema, types[0])
+	}
+}
+
+// jsonScalarText returns the text of a JSON string, number, or boolean as
+// it would appear in a cell. null is the empty string.
+func jsonScalarText(raw json.RawMessage) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("%w: expected a string, number, or boolean, got %s", ErrInvalidSchema, raw)
+	}
+}
+
+// jsonObjectKeys returns the keys of a JSON object in document order.
+func jsonObjectKeys(raw json.RawMessage) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected an object, got %s", raw)
+	}
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if key, _ := tok.(string); !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// jsonSchemaExclusiveBound returns the limit of an exclusiveMinimum or
+// exclusiveMaximum keyword. Draft 4 writes the keyword as a boolean that
+// makes minimum or maximum exclusive instead; then tag is set to
+// exclusiveTag and no limit is returned.
+func jsonSchemaExclusiveBound(raw json.RawMessage, tag *string, exclusiveTag string) (*float64, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var exclusive bool
+	if json.Unmarshal(raw, &exclusive) == nil {
+		if exclusive {
+			*tag = exclusiveTag
+		}
+		return nil, nil
+	}
+	var limit float64
+	if err := json.Unmarshal(raw, &limit); err != nil {
+		return nil, fmt.Errorf("%w: exclusive bound must be a number, got %s", ErrInvalidSchema, raw)
+	}
+	return &limit, nil
+}
+
+// patternValidator validates that a value matches a JSON Schema pattern.
+// Like JSON Schema, the pattern is not anchored.
+type patternValidator struct {
+	re *regexp.Regexp
+}
+
+// newPatternValidator creates a new pattern validator
+func newPatternValidator(pattern string) (*patternValidator, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &patternValidator{re: re}, nil
+}
+
+// Validate checks if the value matches the pattern
+func (v *patternValidator) Validate(value string) string {
+	if !v.re.MatchString(value) {
+		return "value must match the pattern " + v.re.String()
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *patternValidator) Name() string {
+	return "pattern"
+}
+
+// minLengthValidator validates that a value has at least a number of characters
+type minLengthValidator struct {
+	length int
+}
+
+// newMinLengthValidator creates a new minLength validator
+func newMinLengthValidator(length int) *minLengthValidator {
+	return &minLengthValidator{length: length}
+}
+
+// Validate checks if the value has at least the minimum number of characters
+func (v *minLengthValidator) Validate(value string) string {
+	if utf8.RuneCountInString(value) < v.length {
+		return "value must have at least " + strconv.Itoa(v.length) + " characters"
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *minLengthValidator) Name() string {
+	return "minLength"
+}
+
+// maxLengthValidator validates that a value has at most a number of characters
+type maxLengthValidator struct {
+	length int
+}
+
+// newMaxLengthValidator creates a new maxLength validator
+func newMaxLengthValidator(length int) *maxLengthValidator {
+	return &maxLengthValidator{length: length}
+}
+
+// Validate checks if the value has at most the maximum number of characters
+func (v *maxLengthValidator) Validate(value string) string {
+	if utf8.RuneCountInString(value) > v.length {
+		return "value must have at most " + strconv.Itoa(v.length) + " characters"
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *maxLengthValidator) Name() string {
+	return "maxLength"
+}
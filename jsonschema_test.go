@@ -0,0 +1,226 @@
+package fileprep
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileparser"
+)
+
+// schemaIssue is the part of a ValidationError checked by the JSON Schema tests.
+type schemaIssue struct {
+	Row    int
+	Column string
+	Tag    string
+}
+
+func processWithJSONSchema(t *testing.T, schema, input string) ([]schemaIssue, *ProcessResult) {
+	t.Helper()
+	processor, err := NewProcessorFromJSONSchema([]byte(schema), fileparser.CSV)
+	if err != nil {
+		t.Fatalf("NewProcessorFromJSONSchema() error = %v", err)
+	}
+	var rows []struct{}
+	_, result, err := processor.Process(strings.NewReader(input), &rows)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	var issues []schemaIssue
+	for _, ve := range result.ValidationErrors() {
+		issues = append(issues, schemaIssue{Row: ve.Row, Column: ve.Column, Tag: ve.Tag})
+	}
+	return issues, result
+}
+
+func TestNewProcessorFromJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		schema string
+		input  string
+		want   []schemaIssue
+	}{
+		{
+			name:   "types and required",
+			schema: `{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}, "score": {"type": ["number", "null"]}, "ok": {"type": "boolean"}}}`,
+			input:  "id,score,ok\n1,2.5,true\nx,abc,yes\n,,\n",
+			want: []schemaIssue{
+				{Row: 2, Column: "id", Tag: "numeric"},
+				{Row: 2, Column: "score", Tag: "number"},
+				{Row: 2, Column: "ok", Tag: "boolean"},
+				{Row: 3, Column: "id", Tag: "required"},
+			},
+		},
+		{
+			name:   "enum and const",
+			schema: `{"properties": {"plan": {"enum": ["free", "pro plus", 3]}, "kind": {"const": "user"}}}`,
+			input:  "plan,kind\nfree,user\npro plus,user\n3,admin\npro,\n",
+			want: []schemaIssue{
+				{Row: 3, Column: "kind", Tag: "oneof"},
+				{Row: 4, Column: "plan", Tag: "oneof"},
+			},
+		},
+		{
+			name:   "numeric bounds",
+			schema: `{"properties": {"age": {"minimum": 0, "maximum": 150}, "rate": {"exclusiveMinimum": 0, "exclusiveMaximum": 1}, "old": {"minimum": 0, "exclusiveMinimum": true}}}`,
+			input:  "age,rate,old\n0,0.5,1\n151,1,0\n-1,0,\n",
+			want: []schemaIssue{
+				{Row: 2, Column: "age", Tag: "max"},
+				{Row: 2, Column: "rate", Tag: "lt"},
+				{Row: 2, Column: "old", Tag: "gt"},
+				{Row: 3, Column: "age", Tag: "min"},
+				{Row: 3, Column: "rate", Tag: "gt"},
+			},
+		},
+		{
+			name:   "lengths and pattern",
+			schema: `{"properties": {"code": {"minLength": 4, "maxLength": 4}, "name": {"minLength": 2, "maxLength": 5}, "sku": {"pattern": "^[A-Z]{3}-\\d+$"}}}`,
+			input:  "code,name,sku\nAB12,Kai,ABC-1\nAB1,K,abc-1\nAB123,Kenneth,ABC-\n",
+			want: []schemaIssue{
+				{Row: 2, Column: "code", Tag: "len"},
+				{Row: 2, Column: "name", Tag: "minLength"},
+				{Row: 2, Column: "sku", Tag: "pattern"},
+				{Row: 3, Column: "code", Tag: "len"},
+				{Row: 3, Column: "name", Tag: "maxLength"},
+				{Row: 3, Column: "sku", Tag: "pattern"},
+			},
+		},
+		{
+			name:   "formats",
+			schema: `{"properties": {"email": {"format": "email"}, "at": {"format": "date-time"}, "day": {"format": "date"}, "note": {"format": "markdown"}}}`,
+			input:  "email,at,day,note\na@example.com,2024-01-02T03:04:05Z,2024-01-02,*hi*\nbad,2024-01-02,01/02/2024,x\n",
+			want: []schemaIssue{
+				{Row: 2, Column: "email", Tag: "email"},
+				{Row: 2, Column: "at", Tag: "datetime"},
+				{Row: 2, Column: "day", Tag: "datetime"},
+			},
+		},
+		{
+			name:   "default fills empty values before validation",
+			schema: `{"required": ["status"], "properties": {"status": {"default": "active", "enum": ["active", "closed"]}}}`,
+			input:  "status\n\nclosed\n",
+			want:   nil,
+		},
+		{
+			name:   "missing column",
+			schema: `{"required": ["id"], "properties": {"id": {}, "note": {"type": "integer"}}}`,
+			input:  "name\nKai\n",
+			want:   []schemaIssue{{Row: 1, Column: "id", Tag: "required"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, _ := processWithJSONSchema(t, tt.schema, tt.input)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("errors mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewProcessorFromJSONSchema_Output(t *testing.T) {
+	t.Parallel()
+
+	schema := `{"properties": {"status": {"default": "active"}, "id": {"type": "integer"}}}`
+	processor, err := NewProcessorFromJSONSchema([]byte(schema), fileparser.CSV, WithValidRowsOnly())
+	if err != nil {
+		t.Fatalf("NewProcessorFromJSONSchema() error = %v", err)
+	}
+
+	type row struct {
+		Name string `validate:"required"`
+	}
+	var rows []row
+	output, result, err := processor.Process(strings.NewReader("name,id,status\nKai,1,\nSam,x,closed\n,3,closed\n"), &rows)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	// Struct tags still apply next to the schema rules
+	if diff := cmp.Diff([]row{{Name: "Kai"}}, rows); diff != "" {
+		t.Errorf("rows mismatch (-want +got):\n%s", diff)
+	}
+	if result.ValidRowCount != 1 {
+		t.Errorf("ValidRowCount = %d, want 1", result.ValidRowCount)
+	}
+	got, err := io.ReadAll(output)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+	if string(got) != "name,id,status\nKai,1,active\n" {
+		t.Errorf("output = %q", got)
+	}
+}
+
+func TestNewProcessorFromJSONSchema_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	schema, err := ExportJSONSchema([]schemaCustomer{})
+	if err != nil {
+		t.Fatalf("ExportJSONSchema() error = %v", err)
+	}
+	input := "id,email,company,score,plan,level,code,website,host,active,joined_at,note\n" +
+		"1,a@example.com,acme,50,pro,2,C123,https://example.com,example.com,true,2024-01-02T03:04:05Z,\n" +
+		"0,bad,,0,gold,4,C1,,-,maybe,,\n"
+	got, _ := processWithJSONSchema(t, string(schema), input)
+
+	want := []schemaIssue{
+		{Row: 2, Column: "id", Tag: "min"},
+		{Row: 2, Column: "email", Tag: "email"},
+		{Row: 2, Column: "score", Tag: "gt"},
+		{Row: 2, Column: "plan", Tag: "oneof"},
+		{Row: 2, Column: "level", Tag: "oneof"},
+		{Row: 2, Column: "code", Tag: "len"},
+		{Row: 2, Column: "host", Tag: "hostname"},
+		{Row: 2, Column: "active", Tag: "boolean"},
+		{Row: 2, Column: "joined_at", Tag: "required"},
+	}
+	// Properties are compiled in document order, which ExportJSONSchema
+	// sorts by name
+	sortIssues := cmp.Transformer("sort", func(in []schemaIssue) map[string]schemaIssue {
+		out := make(map[string]schemaIssue, len(in))
+		for _, issue := range in {
+			out[issue.Column] = issue
+		}
+		return out
+	})
+	if diff := cmp.Diff(want, got, sortIssues); diff != "" {
+		t.Errorf("errors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewProcessorFromJSONSchema_InvalidSchema(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{name: "not JSON", schema: `{"properties": `},
+		{name: "root type", schema: `{"type": "array", "properties": {"id": {}}}`},
+		{name: "no properties", schema: `{"type": "object"}`},
+		{name: "unknown root keyword", schema: `{"allOf": [], "properties": {"id": {}}}`},
+		{name: "unknown property keyword", schema: `{"properties": {"id": {"$ref": "#/$defs/id"}}}`},
+		{name: "object property", schema: `{"properties": {"id": {"type": "object"}}}`},
+		{name: "type list", schema: `{"properties": {"id": {"type": ["integer", "string"]}}}`},
+		{name: "bad pattern", schema: `{"properties": {"id": {"pattern": "(?<=a)b"}}}`},
+		{name: "required not in properties", schema: `{"required": ["id"], "properties": {"name": {}}}`},
+		{name: "enum of objects", schema: `{"properties": {"id": {"enum": [{"a": 1}]}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewProcessorFromJSONSchema([]byte(tt.schema), fileparser.CSV)
+			if !errors.Is(err, ErrInvalidSchema) {
+				t.Errorf("NewProcessorFromJSONSchema() error = %v, want ErrInvalidSchema", err)
+			}
+		})
+	}
+}
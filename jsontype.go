@@ -0,0 +1,87 @@
+package fileprep
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// JSONType is the type of the values of a top-level key in JSON/JSONL
+// records, as reported in ProcessResult.JSONTypes.
+type JSONType string
+
+const (
+	// JSONString is a key whose values are strings
+	JSONString JSONType = "string"
+	// JSONInteger is a key whose values are numbers without a fraction or exponent
+	JSONInteger JSONType = "integer"
+	// JSONNumber is a key whose values are numbers, at least one with a fraction or exponent
+	JSONNumber JSONType = "number"
+	// JSONBoolean is a key whose values are true or false
+	JSONBoolean JSONType = "boolean"
+	// JSONObject is a key whose values are objects
+	JSONObject JSONType = "object"
+	// JSONArray is a key whose values are arrays
+	JSONArray JSONType = "array"
+	// JSONNull is a key whose values are all null
+	JSONNull JSONType = "null"
+	// JSONMixed is a key whose values have different types
+	JSONMixed JSONType = "mixed"
+)
+
+// jsonRecordTypes infers the type of each top-level key from the JSON
+// values in column col of records. Null values do not decide a key's type,
+// integers widen to numbers, and any other disagreement makes the key mixed.
+// Values that are not JSON objects are skipped.
+func jsonRecordTypes(records [][]string, col int) map[string]JSONType {
+	types := make(map[string]JSONType)
+	for _, record := range records {
+		var object map[string]json.RawMessage
+		if json.Unmarshal([]byte(cell(record, col)), &object) != nil {
+			continue
+		}
+		for key, raw := range object {
+			types[key] = mergeJSONTypes(types[key], jsonTypeOf(raw))
+		}
+	}
+	return types
+}
+
+// jsonTypeOf returns the type of a single JSON value.
+func jsonTypeOf(raw json.RawMessage) JSONType {
+	value := bytes.TrimSpace(raw)
+	if len(value) == 0 {
+		return JSONNull
+	}
+	switch value[0] {
+	case '"':
+		return JSONString
+	case '{':
+		return JSONObject
+	case '[':
+		return JSONArray
+	case 't', 'f':
+		return JSONBoolean
+	case 'n':
+		return JSONNull
+	}
+	if strings.ContainsAny(string(value), ".eE") {
+		return JSONNumber
+	}
+	return JSONInteger
+}
+
+// mergeJSONTypes combines the type seen so far for a key with the type of
+// another of its values.
+func mergeJSONTypes(seen, next JSONType) JSONType {
+	switch {
+	case seen == "" || seen == JSONNull:
+		return next
+	case next == JSONNull || seen == next:
+		return seen
+	case (seen == JSONInteger && next == JSONNumber) || (seen == JSONNumber && next == JSONInteger):
+		return JSONNumber
+	default:
+		return JSONMixed
+	}
+}
@@ -0,0 +1,31 @@
+package fileprep
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJSONRecordTypes(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{`{"id":1,"price":10,"name":"a","active":true,"tags":["x"],"meta":{"k":1},"note":null,"code":"A1"}`},
+		{`{"id":2,"price":9.5,"name":null,"active":false,"tags":[],"meta":{},"note":null,"code":7}`},
+		{`[1,2]`},
+		{``},
+	}
+	want := map[string]JSONType{
+		"id":     JSONInteger,
+		"price":  JSONNumber,
+		"name":   JSONString,
+		"active": JSONBoolean,
+		"tags":   JSONArray,
+		"meta":   JSONObject,
+		"note":   JSONNull,
+		"code":   JSONMixed,
+	}
+	if diff := cmp.Diff(want, jsonRecordTypes(records, 0)); diff != "" {
+		t.Errorf("jsonRecordTypes() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,264 @@
+package fileprep
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// minLatinLetters is the fewest letters a Latin-script text needs before its
+// language is guessed from trigrams. Shorter values, such as names and
+// single words, are undetermined.
+const minLatinLetters = 12
+
+// minTrigramHits is the fewest profile trigrams the best Latin-script
+// language must match, and minTrigramMargin how many more than any other
+// language, so that loanwords and names do not decide the language.
+const (
+	minTrigramHits   = 4
+	minTrigramMargin = 2
+)
+
+// scriptLanguages maps Unicode scripts written in mostly one language to
+// that language.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var scriptLanguages = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// ukrainianLetters are Cyrillic letters used in Ukrainian but not Russian.
+const ukrainianLetters = "іїєґІЇЄҐ"
+
+// trigramProfiles are the most frequent letter trigrams of Latin-script
+// languages, with "_" for a word boundary.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var trigramProfiles = func() map[string]map[string]bool {
+	raw := map[string]string{
+		"en": "_th the he_ nd_ _an and _of of_ ed_ _to to_ _in ing ng_ ion er_ tio is_ in_ re_ at_ on_ _is es_ " +
+			"ent her for _fo or_ _be hat tha ter was ly_ _wa you _yo ou_ his _it it_ _wi wit ith th_ _a_ ll_ " +
+			"are _ar _we we_ _wh ve_ ave hav _ha _so ght ous",
+		"fr": "_de de_ es_ ent _le le_ _la la_ nt_ ion _et et_ les _co on_ _pa que ue_ _qu re_ e_d des _un une " +
+			"our ous _po pou ur_ ais ait _vo vou est _es men eme ne_ _je je_ ns_ _ce ce_ _il il_ eau aux _du du_ " +
+			"_au _pr lle _l_ _d_ _ne pas _se tre",
+		"de": "en_ er_ ich der _de die _di ie_ ch_ ein _ei sch und _un nd_ den cht ine in_ ung te_ gen ter _da " +
+			"das ist _is st_ _zu zu_ nic ber _ge es_ ten eit che auf _au mit _mi ht_ _ic sie _si ach " +
+			"_we ere _wi ges ßen _fü für",
+		"es": "_de de_ os_ _la la_ el_ _el es_ _qu que ue_ en_ _en as_ ión ón_ _co ado ent los _lo _se ar_ aci " +
+			"ció con par _pa ra_ _es est nte del _po por or_ una _un o_d e_l mos _y_ y_ _ya ero ien ada _me " +
+			"ndo _su su_ ran _al",
+		"it": "_di di_ la_ _la che _ch he_ to_ re_ one ne_ ell lla _il il_ per _pe er_ ent zio _co con del _de " +
+			"no_ ato i_d a_d _in in_ ono non _no o_d e_d gli _gl _un all ere _è_ _so son are _ma _al _pr " +
+			"ett tto _qu sta",
+		"pt": "_de de_ os_ ão_ ção do_ _do da_ _da que _qu ue_ _co com es_ as_ _a_ o_d ent nte par _pa um_ _um " +
+			"uma em_ _em não _nã ado mos ar_ est _se por dos ões ra_ _po ma_ _ma _o_ _e_ _ao ao_ nha " +
+			"ele _el ica",
+		"nl": "en_ de_ _de an_ et_ het _he van _va _en een _ee ijk ij_ sch ver _ve oor nde den aar ing ng_ _in " +
+			"in_ te_ _te ie_ zij jn_ cht ten ter er_ voo _vo _ge gen aan _da dat ik_ _ik _ni nie iet _wa " +
+			"_zi _ee _me met _op op_",
+	}
+	profiles := make(map[string]map[string]bool, len(raw))
+	for lang, trigrams := range raw {
+		profile := make(map[string]bool)
+		for _, t := range strings.Fields(trigrams) {
+			profile[strings.ReplaceAll(t, "_", " ")] = true
+		}
+		profiles[lang] = profile
+	}
+	return profiles
+}()
+
+// supportedLanguages are the language codes detectLanguage can report.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var supportedLanguages = map[string]bool{
+	"ja": true, "zh": true, "ko": true, "ru": true, "uk": true, "el": true, "ar": true, "he": true,
+	"th": true, "hi": true, "en": true, "fr": true, "de": true, "es": true, "it": true, "pt": true, "nl": true,
+}
+
+// detectLanguage guesses the language of text from the scripts of its
+// letters and, for Latin-script text, from letter trigrams. It returns the
+// ISO 639-1 codes the text may be in: none when the language cannot be
+// told, such as for short Latin-script values, and both "ja" and "zh" for
+// text written only in Han characters.
+func detectLanguage(text string) []string {
+	var latin, han, kana int
+	scripts := make(map[string]int)
+	for _, r := range text {
+		switch {
+		case !unicode.IsLetter(r):
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana) || r == 'ー':
+			kana++
+		default:
+			for _, s := range scriptLanguages {
+				if unicode.Is(s.table, r) {
+					scripts[s.lang]++
+					break
+				}
+			}
+		}
+	}
+
+	// Japanese mixes Han and kana, so they are counted together
+	best, count := "", han+kana
+	if count > 0 {
+		best = "ja"
+	}
+	for _, s := range scriptLanguages {
+		if scripts[s.lang] > count {
+			best, count = s.lang, scripts[s.lang]
+		}
+	}
+	if latin > count {
+		return detectLatinLanguage(text, latin)
+	}
+
+	switch {
+	case count == 0:
+		return nil
+	case best == "ja" && kana == 0:
+		return []string{"ja", "zh"}
+	case best == "ru" && strings.ContainsAny(text, ukrainianLetters):
+		return []string{"uk"}
+	default:
+		return []string{best}
+	}
+}
+
+// detectLatinLanguage guesses the language of Latin-script text with letters
+// letters by counting the trigrams of each language profile in it. It
+// returns no language when the text is short or no language matches
+// clearly more trigrams than the others.
+func detectLatinLanguage(text string, letters int) []string {
+	if letters < minLatinLetters {
+		return nil
+	}
+
+	// Lowercase and turn every run of other characters into one space
+	runes := []rune{' '}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r):
+			runes = append(runes, r)
+		case runes[len(runes)-1] != ' ':
+			runes = append(runes, ' ')
+		}
+	}
+	if runes[len(runes)-1] != ' ' {
+		runes = append(runes, ' ')
+	}
+
+	hits := make(map[string]int, len(trigramProfiles))
+	for i := 0; i+3 <= len(runes); i++ {
+		trigram := string(runes[i : i+3])
+		for lang, profile := range trigramProfiles {
+			if profile[trigram] {
+				hits[lang]++
+			}
+		}
+	}
+
+	best, bestHits, secondHits := "", 0, 0
+	for _, lang := range slices.Sorted(maps.Keys(hits)) {
+		switch n := hits[lang]; {
+		case n > bestHits:
+			best, bestHits, secondHits = lang, n, bestHits
+		case n > secondHits:
+			secondHits = n
+		}
+	}
+	if bestHits < minTrigramHits || bestHits-secondHits < minTrigramMargin {
+		return nil
+	}
+	return []string{best}
+}
+
+// languageValidator validates that free text is written in one of the
+// allowed languages. Values whose language cannot be told pass.
+type languageValidator struct {
+	langs  []string
+	errMsg string // pre-built error message
+}
+
+// newLanguageValidator creates a new language validator
+func newLanguageValidator(langs []string) *languageValidator {
+	return &languageValidator{
+		langs:  langs,
+		errMsg: "value must be written in " + strings.Join(langs, " or "),
+	}
+}
+
+// Validate checks the detected language of the value
+func (v *languageValidator) Validate(value string) string {
+	detected := detectLanguage(value)
+	if len(detected) == 0 {
+		return ""
+	}
+	for _, lang := range detected {
+		if slices.Contains(v.langs, lang) {
+			return ""
+		}
+	}
+	return v.errMsg + ", detected " + strings.Join(detected, " or ")
+}
+
+// Name returns the validator name
+func (v *languageValidator) Name() string {
+	return langTagValue
+}
+
+// buildLanguageValidator builds a language validator from a space-separated
+// list of language codes, such as "en fr".
+func buildLanguageValidator(value string, strict bool) (Validator, error) {
+	langs := strings.Fields(strings.ToLower(value))
+	valid := len(langs) > 0
+	for _, lang := range langs {
+		if !supportedLanguages[lang] {
+			valid = false
+		}
+	}
+	if !valid {
+		if strict {
+			return nil, fmt.Errorf("%w: lang requires a space-separated list of supported language codes such as \"en fr\", got %q", ErrInvalidTagFormat, value)
+		}
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+	return newLanguageValidator(slices.Clip(langs)), nil
+}
+
+// languageColumn appends the detected language of a source column.
+type languageColumn struct {
+	name   string
+	source string
+}
+
+// apply appends the language column. The cell is empty when the language
+// cannot be told or is ambiguous.
+func (l *languageColumn) apply(headers []string, records [][]string) ([]string, [][]string, error) {
+	colIdx := slices.Index(headers, l.source)
+	if colIdx < 0 {
+		return nil, nil, fmt.Errorf("language column source %q: %w", l.source, ErrColumnNotFound)
+	}
+	return addColumns(headers, records, []string{l.name}, func(_ int, record []string) []string {
+		if detected := detectLanguage(cell(record, colIdx)); len(detected) == 1 {
+			return []string{detected[0]}
+		}
+		return []string{""}
+	})
+}
@@ -0,0 +1,138 @@
+package fileprep
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileparser"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"english", "Thank you for your order, it will be shipped within two days.", []string{"en"}},
+		{"french", "Le produit est arrivé cassé et je voudrais un remboursement.", []string{"fr"}},
+		{"german", "Das Produkt ist kaputt angekommen und ich möchte eine Rückerstattung.", []string{"de"}},
+		{"spanish", "Gracias por su pedido, se enviará en dos días.", []string{"es"}},
+		{"italian", "Il prodotto è arrivato rotto e vorrei un rimborso.", []string{"it"}},
+		{"portuguese", "Obrigado pelo seu pedido, ele será enviado em dois dias.", []string{"pt"}},
+		{"dutch", "Het product is kapot aangekomen en ik wil graag mijn geld terug.", []string{"nl"}},
+		{"japanese", "ご注文ありがとうございます", []string{"ja"}},
+		{"japanese with a latin word", "iPhoneを購入しました", []string{"ja"}},
+		{"han only", "東京都渋谷区", []string{"ja", "zh"}},
+		{"korean", "감사합니다", []string{"ko"}},
+		{"russian", "Спасибо за заказ", []string{"ru"}},
+		{"ukrainian", "Дякуємо за замовлення", []string{"uk"}},
+		{"greek", "Ευχαριστώ", []string{"el"}},
+		{"short latin text", "John Smith", nil},
+		{"latin text without a clear language", "Very good quality, fast delivery", nil},
+		{"no letters", "12345", nil},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if diff := cmp.Diff(tt.want, detectLanguage(tt.text)); diff != "" {
+				t.Errorf("detectLanguage(%q) mismatch (-want +got):\n%s", tt.text, diff)
+			}
+		})
+	}
+}
+
+func TestLanguageValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		tag     string
+		input   string
+		wantMsg string
+	}{
+		{"expected language", "lang=ja", "ご注文ありがとうございます", ""},
+		{"other language", "lang=ja", "Thank you for your order, it will be shipped within two days.", "value must be written in ja, detected en"},
+		{"one of several languages", "lang=en fr", "Le produit est arrivé cassé et je voudrais un remboursement.", ""},
+		{"codes are case-insensitive", "lang=JA", "ご注文ありがとうございます", ""},
+		{"han only passes ja", "lang=ja", "東京都渋谷区", ""},
+		{"han only fails ko", "lang=ko", "東京都渋谷区", "value must be written in ko, detected ja or zh"},
+		{"undetermined passes", "lang=ja", "John Smith", ""},
+		{"empty passes", "lang=ja", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			vals, _, err := parseValidateTag(tt.tag, true)
+			if err != nil {
+				t.Fatalf("parseValidateTag(%q) error = %v", tt.tag, err)
+			}
+			if _, msg := vals.Validate(tt.input); msg != tt.wantMsg {
+				t.Errorf("Validate(%q) = %q, want %q", tt.input, msg, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestWithLanguageColumn(t *testing.T) {
+	t.Parallel()
+
+	type ticket struct {
+		ID      string
+		Comment string
+	}
+
+	t.Run("appends detected languages", func(t *testing.T) {
+		t.Parallel()
+
+		input := "id,comment\n" +
+			"1,\"Thank you for your order, it will be shipped within two days.\"\n" +
+			"2,ご注文ありがとうございます\n" +
+			"3,東京都渋谷区\n" +
+			"4,OK\n"
+		var tickets []ticket
+		output, _, err := NewProcessor(fileparser.CSV, WithLanguageColumn("comment_lang", "comment")).Process(strings.NewReader(input), &tickets)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		data, err := io.ReadAll(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "id,comment,comment_lang\n" +
+			"1,\"Thank you for your order, it will be shipped within two days.\",en\n" +
+			"2,ご注文ありがとうございます,ja\n" +
+			"3,東京都渋谷区,\n" +
+			"4,OK,\n"
+		if diff := cmp.Diff(want, string(data)); diff != "" {
+			t.Errorf("output mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("missing source column", func(t *testing.T) {
+		t.Parallel()
+
+		var tickets []ticket
+		_, _, err := NewProcessor(fileparser.CSV, WithLanguageColumn("lang", "note")).Process(strings.NewReader("id,comment\n1,x\n"), &tickets)
+		if !errors.Is(err, ErrColumnNotFound) {
+			t.Errorf("Process() error = %v, want ErrColumnNotFound", err)
+		}
+	})
+
+	t.Run("existing column", func(t *testing.T) {
+		t.Parallel()
+
+		var tickets []ticket
+		_, _, err := NewProcessor(fileparser.CSV, WithLanguageColumn("id", "comment")).Process(strings.NewReader("id,comment\n1,x\n"), &tickets)
+		if !errors.Is(err, ErrDuplicateColumn) {
+			t.Errorf("Process() error = %v, want ErrDuplicateColumn", err)
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package fileprep
+
+import (
+	"fmt"
+	"slices"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// columnLocale is a locale set with WithLocale for some or all columns.
+type columnLocale struct {
+	locale  string
+	columns []string // empty means every column
+}
+
+// localeCasePreprocessor converts case with the rules of a language, such
+// as the dotted and dotless i of Turkish. It replaces the lowercase and
+// uppercase preprocessors of columns with a locale.
+type localeCasePreprocessor struct {
+	tag   language.Tag
+	upper bool
+}
+
+// Process converts value to lowercase or uppercase. Casers keep state, so
+// each call uses its own, and concurrent runs never share one.
+func (p *localeCasePreprocessor) Process(value string) string {
+	if p.upper {
+		return cases.Upper(p.tag).String(value)
+	}
+	return cases.Lower(p.tag).String(value)
+}
+
+// Name returns the preprocessor name
+func (p *localeCasePreprocessor) Name() string {
+	if p.upper {
+		return uppercaseTagValue
+	}
+	return lowercaseTagValue
+}
+
+// localeEqualIgnoreCaseValidator compares values case-insensitively with the
+// case rules of a language. It replaces the eq_ignore_case and
+// ne_ignore_case validators of columns with a locale.
+type localeEqualIgnoreCaseValidator struct {
+	tag      language.Tag
+	expected string // lowercased with the rules of tag
+	negate   bool
+	errMsg   string
+}
+
+// newLocaleEqualIgnoreCaseValidator creates a validator with the expected
+// value and error message of an eq_ignore_case or ne_ignore_case validator
+func newLocaleEqualIgnoreCaseValidator(tag language.Tag, expected, errMsg string, negate bool) *localeEqualIgnoreCaseValidator {
+	return &localeEqualIgnoreCaseValidator{
+		tag:      tag,
+		expected: cases.Lower(tag).String(expected),
+		negate:   negate,
+		errMsg:   errMsg,
+	}
+}
+
+// Validate checks if the value equals, or with negate does not equal, the
+// expected value when both are lowercased
+func (v *localeEqualIgnoreCaseValidator) Validate(value string) string {
+	if (cases.Lower(v.tag).String(value) == v.expected) == v.negate {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *localeEqualIgnoreCaseValidator) Name() string {
+	if v.negate {
+		return notEqualIgnoreCaseTagValue
+	}
+	return equalIgnoreCaseTagValue
+}
+
+// columnLocales resolves the locales set with WithLocale to a function
+// returning the locale of a column. Later calls override earlier ones. It
+// returns an error wrapping ErrInvalidOption for a malformed locale, and one
+// wrapping ErrColumnNotFound for a column that is not in headers.
+func (p *Processor) columnLocales(headers []string) (func(column string) (language.Tag, bool), error) {
+	var (
+		all       language.Tag
+		hasAll    bool
+		perColumn = make(map[string]language.Tag)
+	)
+	for _, l := range p.locales {
+		tag, err := language.Parse(l.locale)
+		if err != nil {
+			return nil, fmt.Errorf("%w: locale %q: %w", ErrInvalidOption, l.locale, err)
+		}
+		if len(l.columns) == 0 {
+			all, hasAll = tag, true
+			clear(perColumn)
+			continue
+		}
+		for _, column := range l.columns {
+			if !slices.Contains(headers, column) {
+				return nil, fmt.Errorf("locale column %q: %w", column, ErrColumnNotFound)
+			}
+			perColumn[column] = tag
+		}
+	}
+	return func(column string) (language.Tag, bool) {
+		if tag, ok := perColumn[column]; ok {
+			return tag, true
+		}
+		return all, hasAll
+	}, nil
+}
+
+// withLocales returns a copy of the struct info in which the case
+// conversion, case-insensitive comparison, and number format of each field
+// follow the locale localeOf returns for its column. Validators inside or, and, and
+// not groups keep the default rules.
+func (si *structInfo) withLocales(localeOf func(column string) (language.Tag, bool)) *structInfo {
+	fields := make([]fieldInfo, len(si.Fields))
+	for i, fi := range si.Fields {
+		if tag, ok := localeOf(fi.ColumnName); ok {
+			fi.Preprocessors = localePreprocessors(fi.Preprocessors, tag)
+			fi.Validators = localeValidators(fi.Validators, tag)
+			fi.WarnValidators = localeValidators(fi.WarnValidators, tag)
+		}
+		fields[i] = fi
+	}
+	return &structInfo{Fields: fields}
+}
+
+// localePreprocessors returns a copy of ps with the case preprocessors
+// following the rules of tag, and unit preprocessors reading numbers in its
+// format.
+func localePreprocessors(ps preprocessors, tag language.Tag) preprocessors {
+	out := slices.Clone(ps)
+	for i, p := range out {
+		switch p := p.(type) {
+		case *lowercasePreprocessor:
+			out[i] = &localeCasePreprocessor{tag: tag}
+		case *uppercasePreprocessor:
+			out[i] = &localeCasePreprocessor{tag: tag, upper: true}
+		case *unitPreprocessor:
+			out[i] = p.withFormat(numberFormatFor(tag))
+		}
+	}
+	return out
+}
+
+// localeValidators returns a copy of vs with the case-insensitive
+// comparisons following the rules of tag, formatted_number validators
+// without a locale of their own accepting the number format of tag, and unit
+// validators reading numbers in that format.
+func localeValidators(vs validators, tag language.Tag) validators {
+	out := slices.Clone(vs)
+	for i, v := range out {
+		param := validatorParam(v)
+		if pv, ok := v.(*paramValidator); ok {
+			v = pv.Validator
+		}
+		var replaced Validator
+		switch v := v.(type) {
+		case *equalIgnoreCaseValidator:
+			replaced = newLocaleEqualIgnoreCaseValidator(tag, v.expected, v.errMsg, false)
+		case *notEqualIgnoreCaseValidator:
+			replaced = newLocaleEqualIgnoreCaseValidator(tag, v.expected, v.errMsg, true)
+		case *formattedNumberValidator:
+			if v.explicit {
+				continue
+			}
+			replaced = newFormattedNumberValidator(tag, false)
+		case *unitValidator:
+			replaced = v.withFormat(numberFormatFor(tag))
+		default:
+			continue
+		}
+		out[i] = &paramValidator{Validator: replaced, param: param}
+	}
+	return out
+}
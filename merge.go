@@ -0,0 +1,135 @@
+package fileprep
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// MergePolicy selects which row MergeFiles keeps when rows of the merged
+// inputs share a key.
+type MergePolicy int
+
+const (
+	// MergeFirstWins keeps the first row with a key and drops the later ones.
+	MergeFirstWins MergePolicy = iota
+	// MergeLastWins keeps the values of the last row with a key, at the
+	// position of the first one.
+	MergeLastWins
+	// MergeError fails the merge with ErrDuplicateKey at the first repeated
+	// key whose row differs from the first one. Identical rows are dropped,
+	// since overlapping files repeat them.
+	MergeError
+)
+
+// MergeFiles concatenates inputs of type ft into one stream, resolving rows
+// that share the key columns with policy. This is typical for vendors that
+// send overlapping daily files. Without key columns, the whole row is the
+// key, so only exact duplicates are resolved.
+//
+// The header of the first input is the output header. Later inputs may
+// order their columns differently and may lack columns, which are empty;
+// a column that is not in the first header is an error wrapping
+// ErrColumnNotFound. Keys are compared as read, so pass the merged stream
+// to Process to apply prep and validate tags. The output has the format
+// Process would produce for ft, and its table name comes from the first
+// input.
+//
+// Example:
+//
+//	merged, err := fileprep.MergeFiles([]io.Reader{monday, tuesday}, fileprep.FileTypeCSV,
+//	    []string{"order_id"}, fileprep.MergeLastWins)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	var orders []Order
+//	_, result, err := processor.Process(merged, &orders)
+func MergeFiles(inputs []io.Reader, ft FileType, key []string, policy MergePolicy) (io.Reader, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("%w: no inputs to merge", ErrInvalidOption)
+	}
+	if policy < MergeFirstWins || policy > MergeError {
+		return nil, fmt.Errorf("%w: unknown merge policy %d", ErrInvalidOption, int(policy))
+	}
+
+	p := NewProcessor(ft, WithResultRows())
+	var (
+		columns  []string
+		colIdx   map[string]int
+		keyIdxs  []int
+		records  [][]string
+		firstRow = make(map[string]mergedRow)
+	)
+	for inputIdx, input := range inputs {
+		var rows []struct{}
+		var result ProcessResult
+		if _, err := p.ProcessInto(input, &rows, &result); err != nil {
+			return nil, fmt.Errorf("input %d: %w", inputIdx+1, err)
+		}
+
+		if inputIdx == 0 {
+			columns = result.Columns
+			colIdx = columnIndexes(columns)
+			if len(key) == 0 {
+				key = columns
+			}
+			for _, column := range key {
+				idx, ok := colIdx[column]
+				if !ok {
+					return nil, fmt.Errorf("merge key column %q: %w", column, ErrColumnNotFound)
+				}
+				keyIdxs = append(keyIdxs, idx)
+			}
+		}
+
+		// Align the rows to the first header
+		targets := make([]int, len(result.Columns))
+		for i, column := range result.Columns {
+			idx, ok := colIdx[column]
+			if !ok {
+				return nil, fmt.Errorf("column %q of input %d is not in the header of input 1: %w", column, inputIdx+1, ErrColumnNotFound)
+			}
+			targets[i] = idx
+		}
+
+		for rowIdx, record := range result.rows {
+			row := make([]string, len(columns))
+			for i, target := range targets {
+				row[target] = cell(record, i)
+			}
+
+			k := diffKey(row, keyIdxs)
+			first, seen := firstRow[k]
+			if !seen {
+				firstRow[k] = mergedRow{input: inputIdx + 1, row: rowIdx + 1, index: len(records)}
+				records = append(records, row)
+				continue
+			}
+			switch policy {
+			case MergeLastWins:
+				records[first.index] = row
+			case MergeError:
+				if slices.Equal(records[first.index], row) {
+					continue
+				}
+				return nil, fmt.Errorf("%w %v: input %d row %d repeats input %d row %d",
+					ErrDuplicateKey, keyValues(row, keyIdxs), inputIdx+1, rowIdx+1, first.input, first.row)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(p.estimateOutputSize(columns, records))
+	if err := p.writeOutput(&buf, columns, records); err != nil {
+		return nil, fmt.Errorf("failed to write output: %w", err)
+	}
+	return newStream(buf.Bytes(), p.outputFormat(), ft).withTableName(p.tableNameFor(inputs[0])), nil
+}
+
+// mergedRow locates the first row with a key in MergeFiles.
+type mergedRow struct {
+	input int // 1-based input number
+	row   int // 1-based data row number within the input
+	index int // index into the merged records
+}
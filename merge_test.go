@@ -0,0 +1,148 @@
+package fileprep
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMergeFiles(t *testing.T) {
+	t.Parallel()
+
+	monday := "id,name,qty\n1,apple,3\n2,banana,5\n"
+	tuesday := "id,name,qty\n2,banana,7\n3,cherry,1\n"
+
+	tests := []struct {
+		name   string
+		inputs []string
+		ft     FileType
+		key    []string
+		policy MergePolicy
+		want   string
+	}{
+		{
+			name:   "first wins",
+			inputs: []string{monday, tuesday},
+			ft:     FileTypeCSV,
+			key:    []string{"id"},
+			policy: MergeFirstWins,
+			want:   "id,name,qty\n1,apple,3\n2,banana,5\n3,cherry,1\n",
+		},
+		{
+			name:   "last wins keeps the first position",
+			inputs: []string{monday, tuesday},
+			ft:     FileTypeCSV,
+			key:    []string{"id"},
+			policy: MergeLastWins,
+			want:   "id,name,qty\n1,apple,3\n2,banana,7\n3,cherry,1\n",
+		},
+		{
+			name:   "whole row key drops exact duplicates only",
+			inputs: []string{monday, "id,name,qty\n1,apple,3\n2,banana,7\n"},
+			ft:     FileTypeCSV,
+			policy: MergeError,
+			want:   "id,name,qty\n1,apple,3\n2,banana,5\n2,banana,7\n",
+		},
+		{
+			name:   "later inputs are aligned to the first header",
+			inputs: []string{monday, "qty,id\n9,4\n"},
+			ft:     FileTypeCSV,
+			key:    []string{"id"},
+			policy: MergeFirstWins,
+			want:   "id,name,qty\n1,apple,3\n2,banana,5\n4,,9\n",
+		},
+		{
+			name:   "TSV output",
+			inputs: []string{"id\tname\n1\ta\n", "id\tname\n1\tb\n"},
+			ft:     FileTypeTSV,
+			key:    []string{"id"},
+			policy: MergeLastWins,
+			want:   "id\tname\n1\tb\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			inputs := make([]io.Reader, len(tt.inputs))
+			for i, input := range tt.inputs {
+				inputs[i] = strings.NewReader(input)
+			}
+			reader, err := MergeFiles(inputs, tt.ft, tt.key, tt.policy)
+			if err != nil {
+				t.Fatalf("MergeFiles() error = %v", err)
+			}
+			output, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, string(output)); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMergeFiles_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		inputs  []string
+		key     []string
+		policy  MergePolicy
+		wantErr error
+	}{
+		{
+			name:    "duplicate key under MergeError",
+			inputs:  []string{"id,name\n1,a\n", "id,name\n1,b\n"},
+			key:     []string{"id"},
+			policy:  MergeError,
+			wantErr: ErrDuplicateKey,
+		},
+		{
+			name:    "missing key column",
+			inputs:  []string{"id,name\n1,a\n"},
+			key:     []string{"email"},
+			wantErr: ErrColumnNotFound,
+		},
+		{
+			name:    "column not in the first header",
+			inputs:  []string{"id,name\n1,a\n", "id,email\n2,b@example.com\n"},
+			key:     []string{"id"},
+			wantErr: ErrColumnNotFound,
+		},
+		{
+			name:    "no inputs",
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "unknown policy",
+			inputs:  []string{"id\n1\n"},
+			policy:  MergePolicy(9),
+			wantErr: ErrInvalidOption,
+		},
+		{
+			name:    "empty input",
+			inputs:  []string{"id\n1\n", ""},
+			wantErr: ErrEmptyFile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			inputs := make([]io.Reader, len(tt.inputs))
+			for i, input := range tt.inputs {
+				inputs[i] = strings.NewReader(input)
+			}
+			_, err := MergeFiles(inputs, FileTypeCSV, tt.key, tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("MergeFiles() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
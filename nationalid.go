@@ -0,0 +1,115 @@
+package fileprep
+
+import (
+	"regexp"
+	"strings"
+)
+
+// National identifier patterns. Structure is checked by the patterns, and
+// the numbering rules that do not fit a pattern by the validators.
+//
+//nolint:gochecknoglobals // compiled once, read-only
+var (
+	usSSNRegex      = regexp.MustCompile(`^(\d{3})-?(\d{2})-?(\d{4})$`)
+	jpMyNumberRegex = regexp.MustCompile(`^\d{12}$`)
+	ukNINORegex     = regexp.MustCompile(`^[A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z]\d{6}[A-D]$`)
+)
+
+// ukNINOInvalidPrefixes lists the NINO prefixes HMRC never allocates.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var ukNINOInvalidPrefixes = map[string]bool{
+	"BG": true, "GB": true, "KN": true, "NK": true, "NT": true, "TN": true, "ZZ": true,
+}
+
+// usSSNValidator validates US Social Security Numbers
+type usSSNValidator struct{}
+
+// newUSSSNValidator creates a new US SSN validator
+func newUSSSNValidator() *usSSNValidator {
+	return &usSSNValidator{}
+}
+
+// Validate checks if the value is a US Social Security Number, written as
+// 123-45-6789 or 123456789. Numbers the SSA never issues are rejected: area
+// 000, 666, or 900-999, group 00, and serial 0000.
+func (v *usSSNValidator) Validate(value string) string {
+	m := usSSNRegex.FindStringSubmatch(value)
+	// Dashes are all or nothing
+	if m == nil || (len(value) != 9 && len(value) != 11) {
+		return "value must be a valid US Social Security Number"
+	}
+	area, group, serial := m[1], m[2], m[3]
+	if area == "000" || area == "666" || area[0] == '9' || group == "00" || serial == "0000" {
+		return "value must be a valid US Social Security Number"
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *usSSNValidator) Name() string {
+	return usSSNTagValue
+}
+
+// jpMyNumberValidator validates Japanese Individual Numbers (My Number)
+type jpMyNumberValidator struct{}
+
+// newJPMyNumberValidator creates a new My Number validator
+func newJPMyNumberValidator() *jpMyNumberValidator {
+	return &jpMyNumberValidator{}
+}
+
+// Validate checks if the value is 12 digits ending with the check digit
+// defined by the My Number Act ordinance
+func (v *jpMyNumberValidator) Validate(value string) string {
+	if !jpMyNumberRegex.MatchString(value) {
+		return "value must be a 12-digit My Number"
+	}
+	// Digit n counts from the right of the 11 digits before the check
+	// digit and weighs n+1 for n <= 6, n-5 otherwise
+	sum := 0
+	for n := 1; n <= 11; n++ {
+		weight := n + 1
+		if n > 6 {
+			weight = n - 5
+		}
+		sum += int(value[11-n]-'0') * weight
+	}
+	check := 0
+	if r := sum % 11; r > 1 {
+		check = 11 - r
+	}
+	if int(value[11]-'0') != check {
+		return "value must have a valid My Number check digit"
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *jpMyNumberValidator) Name() string {
+	return jpMyNumberTagValue
+}
+
+// ukNINOValidator validates UK National Insurance numbers
+type ukNINOValidator struct{}
+
+// newUKNINOValidator creates a new NINO validator
+func newUKNINOValidator() *ukNINOValidator {
+	return &ukNINOValidator{}
+}
+
+// Validate checks if the value is a National Insurance number such as
+// AB123456C, with or without spaces. Prefix letters HMRC does not use, and
+// the prefixes it never allocates, are rejected.
+func (v *ukNINOValidator) Validate(value string) string {
+	nino := strings.ReplaceAll(value, " ", "")
+	if !ukNINORegex.MatchString(nino) || ukNINOInvalidPrefixes[nino[:2]] {
+		return "value must be a valid UK National Insurance number"
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *ukNINOValidator) Name() string {
+	return ukNINOTagValue
+}
@@ -0,0 +1,88 @@
+package fileprep
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNationalIDValidators(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		validator Validator
+		value     string
+		wantErr   bool
+	}{
+		{name: "ssn with dashes", validator: newUSSSNValidator(), value: "123-45-6789"},
+		{name: "ssn without dashes", validator: newUSSSNValidator(), value: "123456789"},
+		{name: "ssn with one dash", validator: newUSSSNValidator(), value: "123-456789", wantErr: true},
+		{name: "ssn area 000", validator: newUSSSNValidator(), value: "000-45-6789", wantErr: true},
+		{name: "ssn area 666", validator: newUSSSNValidator(), value: "666-45-6789", wantErr: true},
+		{name: "ssn area 9xx", validator: newUSSSNValidator(), value: "912-45-6789", wantErr: true},
+		{name: "ssn group 00", validator: newUSSSNValidator(), value: "123-00-6789", wantErr: true},
+		{name: "ssn serial 0000", validator: newUSSSNValidator(), value: "123-45-0000", wantErr: true},
+		{name: "ssn too short", validator: newUSSSNValidator(), value: "123-45-678", wantErr: true},
+		{name: "ssn empty", validator: newUSSSNValidator(), value: "", wantErr: true},
+
+		{name: "my number", validator: newJPMyNumberValidator(), value: "123456789018"},
+		{name: "my number with check digit 0", validator: newJPMyNumberValidator(), value: "111111111118"},
+		{name: "my number wrong check digit", validator: newJPMyNumberValidator(), value: "123456789012", wantErr: true},
+		{name: "my number with hyphens", validator: newJPMyNumberValidator(), value: "1234-5678-9018", wantErr: true},
+		{name: "my number too short", validator: newJPMyNumberValidator(), value: "12345678901", wantErr: true},
+		{name: "my number fullwidth digits", validator: newJPMyNumberValidator(), value: "１２３４５６７８９０１８", wantErr: true},
+
+		{name: "nino", validator: newUKNINOValidator(), value: "AB123456C"},
+		{name: "nino with spaces", validator: newUKNINOValidator(), value: "AB 12 34 56 C"},
+		{name: "nino lowercase", validator: newUKNINOValidator(), value: "ab123456c", wantErr: true},
+		{name: "nino invalid first letter", validator: newUKNINOValidator(), value: "DA123456C", wantErr: true},
+		{name: "nino invalid second letter", validator: newUKNINOValidator(), value: "AO123456C", wantErr: true},
+		{name: "nino unallocated prefix", validator: newUKNINOValidator(), value: "GB123456C", wantErr: true},
+		{name: "nino invalid suffix", validator: newUKNINOValidator(), value: "AB123456E", wantErr: true},
+		{name: "nino too few digits", validator: newUKNINOValidator(), value: "AB12345C", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := tt.validator.Validate(tt.value)
+			if (got != "") != tt.wantErr {
+				t.Errorf("%s.Validate(%q) = %q, wantErr %v", tt.validator.Name(), tt.value, got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNationalIDValidation_Processor(t *testing.T) {
+	t.Parallel()
+
+	type Employee struct {
+		SSN      string `validate:"omitempty,us_ssn"`
+		MyNumber string `prep:"keep_digits" validate:"omitempty,jp_my_number"`
+		NINO     string `prep:"uppercase" validate:"omitempty,uk_nino"`
+	}
+
+	input := "ssn,my_number,nino\n" +
+		"123-45-6789,,\n" +
+		",1234-5678-9018,ab 12 34 56 c\n" +
+		"666-45-6789,123456789012,GB123456C\n"
+	var employees []Employee
+	_, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader(input), &employees)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	var got []string
+	for _, e := range result.ValidationErrors() {
+		got = append(got, e.Tag)
+	}
+	want := []string{usSSNTagValue, jpMyNumberTagValue, ukNINOTagValue}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("error tags = %v, want %v", got, want)
+	}
+	for _, e := range result.ValidationErrors() {
+		if e.Row != 3 {
+			t.Errorf("%s error on row %d, want row 3", e.Tag, e.Row)
+		}
+	}
+}
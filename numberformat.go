@@ -0,0 +1,131 @@
+package fileprep
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// numberFormat holds the separators a locale writes numbers with.
+type numberFormat struct {
+	groups  []string // thousands separators
+	decimal string
+	indian  bool // groups of two digits above the thousands, as in 12,34,567
+}
+
+// Languages by the separators of their numbers. Languages not listed write
+// 1,234.5 as English does.
+//
+//nolint:gochecknoglobals // read-only lookup tables
+var (
+	// 1.234,5
+	dotGroupLanguages = []string{"ca", "da", "de", "el", "es", "hr", "id", "it", "nl", "pt", "ro", "sl", "sr", "tr", "vi"}
+	// 1 234,5, with a space, no-break space, or narrow no-break space
+	spaceGroupLanguages = []string{"be", "bg", "cs", "et", "fi", "fr", "hu", "kk", "lt", "lv", "nb", "nn", "no", "pl", "ru", "sk", "sv", "uk"}
+	// 12,34,567.8
+	indianLanguages = []string{"bn", "gu", "hi", "kn", "ml", "mr", "pa", "ta", "te"}
+)
+
+// spaceGroups are the spaces used as thousands separators.
+var spaceGroups = []string{" ", "\u00a0", "\u202f"} //nolint:gochecknoglobals // read-only lookup table
+
+// numberFormatFor returns the number format of a locale.
+func numberFormatFor(tag language.Tag) numberFormat {
+	base, _ := tag.Base()
+	region, _ := tag.Region()
+	lang, reg := base.String(), region.String()
+	switch {
+	case (lang == "de" || lang == "it") && (reg == "CH" || reg == "LI"):
+		return numberFormat{groups: []string{"'", "\u2019"}, decimal: "."}
+	case lang == "pt" && reg == "PT":
+		return numberFormat{groups: spaceGroups, decimal: ","}
+	case slices.Contains(dotGroupLanguages, lang):
+		return numberFormat{groups: []string{"."}, decimal: ","}
+	case slices.Contains(spaceGroupLanguages, lang):
+		return numberFormat{groups: spaceGroups, decimal: ","}
+	case slices.Contains(indianLanguages, lang) || lang == "en" && reg == "IN":
+		return numberFormat{groups: []string{","}, decimal: ".", indian: true}
+	default:
+		return numberFormat{groups: []string{","}, decimal: "."}
+	}
+}
+
+// regexp returns a pattern matching numbers in the format, with or without
+// thousands separators.
+func (f numberFormat) regexp() *regexp.Regexp {
+	quoted := make([]string, len(f.groups))
+	for i, g := range f.groups {
+		quoted[i] = regexp.QuoteMeta(g)
+	}
+	group := "(?:" + strings.Join(quoted, "|") + ")"
+	whole := `\d+|\d{1,3}(?:` + group + `\d{3})+`
+	if f.indian {
+		whole += `|\d{1,2}(?:` + group + `\d{2})*` + group + `\d{3}`
+	}
+	return regexp.MustCompile(`^[-+]?(?:` + whole + `)(?:` + regexp.QuoteMeta(f.decimal) + `\d+)?$`)
+}
+
+// parse returns a number in the format as a plain decimal number, such as
+// "1.234,5" in German as "1234.5", and false if it is not in the format.
+func (f numberFormat) parse(number string) (string, bool) {
+	if !f.regexp().MatchString(number) {
+		return "", false
+	}
+	for _, g := range f.groups {
+		number = strings.ReplaceAll(number, g, "")
+	}
+	return strings.Replace(number, f.decimal, ".", 1), true
+}
+
+// formattedNumberValidator validates numbers written with the thousands
+// separator and decimal mark of a locale, such as 1,234.5 in English or
+// 1.234,5 in German, so the original text can be validated before it is
+// normalized.
+type formattedNumberValidator struct {
+	pattern  *regexp.Regexp
+	explicit bool // the locale was given in the tag, not by WithLocale
+	errMsg   string
+}
+
+// newFormattedNumberValidator creates a new formatted number validator for
+// the locale tag. explicit reports whether the tag named the locale.
+func newFormattedNumberValidator(tag language.Tag, explicit bool) *formattedNumberValidator {
+	return &formattedNumberValidator{
+		pattern:  numberFormatFor(tag).regexp(),
+		explicit: explicit,
+		errMsg:   "value must be a number formatted for " + tag.String(),
+	}
+}
+
+// Validate checks if the value is a number in the locale's format
+func (v *formattedNumberValidator) Validate(value string) string {
+	if !v.pattern.MatchString(value) {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *formattedNumberValidator) Name() string {
+	return formattedNumberTagValue
+}
+
+// buildFormattedNumberValidator builds a formatted_number validator. The
+// parameter is a BCP 47 locale such as "de"; without one the column's
+// WithLocale locale applies, or English.
+func buildFormattedNumberValidator(value string, strict bool) (Validator, error) {
+	if value == "" {
+		return newFormattedNumberValidator(language.English, false), nil
+	}
+	tag, err := language.Parse(value)
+	if err != nil {
+		if strict {
+			return nil, fmt.Errorf("%w: formatted_number requires a locale such as en or de, got %q", ErrInvalidTagFormat, value)
+		}
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+	return newFormattedNumberValidator(tag, true), nil
+}
@@ -0,0 +1,77 @@
+package fileprep
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestFormattedNumberValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		locale  string
+		value   string
+		wantErr bool
+	}{
+		{name: "en grouped", locale: "en", value: "1,234,567.89"},
+		{name: "en ungrouped", locale: "en", value: "-1234.5"},
+		{name: "en small", locale: "en", value: "12"},
+		{name: "en misplaced group", locale: "en", value: "12,34.5", wantErr: true},
+		{name: "en German format", locale: "en", value: "1.234,5", wantErr: true},
+		{name: "de grouped", locale: "de", value: "1.234.567,89"},
+		{name: "de decimal", locale: "de", value: "0,5"},
+		{name: "de English decimal", locale: "de", value: "1.5", wantErr: true},
+		{name: "fr space", locale: "fr", value: "1 234,5"},
+		{name: "fr narrow no-break space", locale: "fr", value: "1\u202f234,5"},
+		{name: "de-CH apostrophe", locale: "de-CH", value: "1'234.50"},
+		{name: "pt-BR dot", locale: "pt-BR", value: "1.234,50"},
+		{name: "pt-PT space", locale: "pt-PT", value: "1 234,50"},
+		{name: "fr no-break space", locale: "fr", value: "1\u00a0234,5"},
+		{name: "hi lakh", locale: "hi", value: "12,34,567.8"},
+		{name: "en-IN thousands", locale: "en-IN", value: "1,234"},
+		{name: "ja like en", locale: "ja", value: "1,234"},
+		{name: "text", locale: "en", value: "abc", wantErr: true},
+		{name: "empty", locale: "en", value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			v := newFormattedNumberValidator(language.MustParse(tt.locale), true)
+			if got := v.Validate(tt.value); (got != "") != tt.wantErr {
+				t.Errorf("formatted_number=%s Validate(%q) = %q, wantErr %v", tt.locale, tt.value, got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormattedNumber_Processor(t *testing.T) {
+	t.Parallel()
+
+	type Sale struct {
+		Amount  string `validate:"formatted_number"`
+		Revenue string `validate:"formatted_number=en"`
+	}
+
+	input := "amount,revenue\n\"1.234,5\",\"1,234.5\"\n\"1,234.5\",\"1.234,5\"\n"
+	var sales []Sale
+	_, result, err := NewProcessor(FileTypeCSV, WithLocale("de")).Process(strings.NewReader(input), &sales)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	var got []string
+	for _, e := range result.ValidationErrors() {
+		if e.Row != 2 {
+			t.Errorf("%s error on row %d, want row 2", e.Column, e.Row)
+		}
+		got = append(got, e.Column+": "+e.Message())
+	}
+	want := "amount: value must be a number formatted for de,revenue: value must be a number formatted for en"
+	if strings.Join(got, ",") != want {
+		t.Errorf("errors = %v, want %s", got, want)
+	}
+}
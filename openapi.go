@@ -0,0 +1,213 @@
+package fileprep
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nao1215/fileparser"
+)
+
+// openAPIMaxRefDepth bounds $ref chains, so a reference cycle is an error
+// rather than endless recursion.
+const openAPIMaxRefDepth = 32
+
+// openAPIAnnotations are OpenAPI schema keywords without a JSON Schema
+// counterpart that do not constrain a cell value. nullable adds nothing,
+// since an empty cell is a missing value anyway.
+//
+//nolint:gochecknoglobals // keyword table
+var openAPIAnnotations = []string{"nullable", "example", "externalDocs", "xml", "discriminator"}
+
+// NewProcessorFromOpenAPI returns a processor that checks columns against a
+// schema of an OpenAPI 3 document, so CSV uploads can be validated with the
+// schema that defines the API payloads. spec is the document in JSON; convert
+// YAML documents first. ref is a JSON pointer into spec in $ref form and
+// selects either:
+//   - an object schema, such as "#/components/schemas/User", whose
+//     properties are compiled as by NewProcessorFromJSONSchema, or
+//   - a parameter list, such as "#/paths/~1users/get/parameters", where each
+//     parameter is a column named after the parameter, checked against its
+//     schema, and required when the parameter is
+//
+// Local $ref references, such as properties defined in components, are
+// resolved. OpenAPI annotations such as example and nullable are ignored,
+// as are formats like int64 and password that do not constrain a cell.
+// Anything else NewProcessorFromJSONSchema cannot compile returns an error
+// wrapping ErrInvalidSchema. Schemas written in CUE can be exported to
+// OpenAPI with "cue export --out openapi" first.
+//
+// Example:
+//
+//	spec, err := os.ReadFile("openapi.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	processor, err := fileprep.NewProcessorFromOpenAPI(spec, "#/components/schemas/User", fileparser.CSV)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	var rows []struct{}
+//	_, result, err := processor.Process(upload, &rows)
+func NewProcessorFromOpenAPI(spec []byte, ref string, fileType fileparser.FileType, opts ...Option) (*Processor, error) {
+	fields, err := compileOpenAPI(spec, ref)
+	if err != nil {
+		return nil, err
+	}
+	p := NewProcessor(fileType, opts...)
+	p.schemaFields = fields
+	return p, nil
+}
+
+// openAPIDocument resolves references in an OpenAPI document.
+type openAPIDocument struct {
+	root json.RawMessage
+}
+
+// compileOpenAPI compiles the schema or parameter list ref selects in spec.
+func compileOpenAPI(spec []byte, ref string) ([]fieldInfo, error) {
+	if !json.Valid(spec) {
+		return nil, fmt.Errorf("%w: the OpenAPI document is not valid JSON", ErrInvalidSchema)
+	}
+	doc := &openAPIDocument{root: spec}
+	node, err := doc.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	if node, err = doc.follow(node, 0); err != nil {
+		return nil, err
+	}
+
+	var parameters []json.RawMessage
+	if json.Unmarshal(node, &parameters) == nil {
+		return doc.compileParameters(parameters)
+	}
+	node, err = doc.schema(node)
+	if err != nil {
+		return nil, err
+	}
+	return compileSchemaObject(node, doc.schema)
+}
+
+// compileParameters compiles a parameter list, one column per parameter.
+func (d *openAPIDocument) compileParameters(parameters []json.RawMessage) ([]fieldInfo, error) {
+	var (
+		names      []string
+		properties = make(map[string]json.RawMessage, len(parameters))
+		required   []string
+	)
+	for i, raw := range parameters {
+		raw, err := d.follow(raw, 0)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %d: %w", i+1, err)
+		}
+		var param struct {
+			Name     string          `json:"name"`
+			Required bool            `json:"required"`
+			Schema   json.RawMessage `json:"schema"`
+			Content  json.RawMessage `json:"content"`
+		}
+		if err := json.Unmarshal(raw, &param); err != nil || param.Name == "" {
+			return nil, fmt.Errorf("%w: parameter %d is not a parameter object", ErrInvalidSchema, i+1)
+		}
+		if param.Content != nil {
+			return nil, fmt.Errorf("%w: parameter %q uses content instead of schema", ErrInvalidSchema, param.Name)
+		}
+		if _, seen := properties[param.Name]; seen {
+			return nil, fmt.Errorf("%w: parameter %q is listed twice", ErrInvalidSchema, param.Name)
+		}
+
+		schema := json.RawMessage(`{}`)
+		if param.Schema != nil {
+			if schema, err = d.schema(param.Schema); err != nil {
+				return nil, fmt.Errorf("parameter %q: %w", param.Name, err)
+			}
+		}
+		names = append(names, param.Name)
+		properties[param.Name] = schema
+		if param.Required {
+			required = append(required, param.Name)
+		}
+	}
+	return compileSchemaColumns(names, properties, required)
+}
+
+// schema returns the schema raw refers to, without OpenAPI annotations.
+func (d *openAPIDocument) schema(raw json.RawMessage) (json.RawMessage, error) {
+	raw, err := d.follow(raw, 0)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]json.RawMessage
+	if json.Unmarshal(raw, &object) != nil {
+		return raw, nil // the schema compiler reports it
+	}
+
+	changed := false
+	for _, keyword := range openAPIAnnotations {
+		if _, ok := object[keyword]; ok {
+			delete(object, keyword)
+			changed = true
+		}
+	}
+	if !changed {
+		return raw, nil
+	}
+	return json.Marshal(object)
+}
+
+// follow returns the value a {"$ref": ...} object points to, following
+// chains of references, or raw itself when it is not a reference.
+func (d *openAPIDocument) follow(raw json.RawMessage, depth int) (json.RawMessage, error) {
+	var object struct {
+		Ref *string `json:"$ref"`
+	}
+	if json.Unmarshal(raw, &object) != nil || object.Ref == nil {
+		return raw, nil
+	}
+	if depth >= openAPIMaxRefDepth {
+		return nil, fmt.Errorf("%w: $ref %q is part of a reference cycle", ErrInvalidSchema, *object.Ref)
+	}
+	target, err := d.resolve(*object.Ref)
+	if err != nil {
+		return nil, err
+	}
+	return d.follow(target, depth+1)
+}
+
+// resolve returns the value at a local JSON pointer such as
+// "#/components/schemas/User".
+func (d *openAPIDocument) resolve(ref string) (json.RawMessage, error) {
+	pointer, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("%w: only local references starting with # are supported, got %q", ErrInvalidSchema, ref)
+	}
+
+	node := d.root
+	if pointer == "" {
+		return node, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: invalid reference %q", ErrInvalidSchema, ref)
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+
+		var object map[string]json.RawMessage
+		var array []json.RawMessage
+		var found bool
+		switch {
+		case json.Unmarshal(node, &object) == nil:
+			node, found = object[token]
+		case json.Unmarshal(node, &array) == nil:
+			if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(array) {
+				node, found = array[i], true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: reference %q not found", ErrInvalidSchema, ref)
+		}
+	}
+	return node, nil
+}
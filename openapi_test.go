@@ -0,0 +1,137 @@
+package fileprep
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileparser"
+)
+
+const testOpenAPISpec = `{
+  "openapi": "3.0.3",
+  "paths": {
+    "/users": {
+      "get": {
+        "parameters": [
+          {"$ref": "#/components/parameters/Limit"},
+          {"name": "status", "in": "query", "schema": {"type": "string", "enum": ["active", "closed"]}},
+          {"name": "X-Tenant", "in": "header", "required": true, "schema": {"type": "string", "format": "uuid"}}
+        ]
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "Limit": {"name": "limit", "in": "query", "required": true, "schema": {"type": "integer", "minimum": 1, "maximum": 100}}
+    },
+    "schemas": {
+      "Email": {"type": "string", "format": "email", "example": "kai@example.com"},
+      "User": {
+        "type": "object",
+        "required": ["id", "email"],
+        "example": {"id": 1},
+        "properties": {
+          "id": {"type": "integer", "format": "int64", "minimum": 0, "exclusiveMinimum": true},
+          "email": {"$ref": "#/components/schemas/Email"},
+          "nickname": {"type": "string", "nullable": true, "maxLength": 8}
+        }
+      },
+      "Alias": {"$ref": "#/components/schemas/User"},
+      "Loop": {"$ref": "#/components/schemas/Loop"},
+      "Nested": {"type": "object", "properties": {"tags": {"type": "array"}}}
+    }
+  }
+}`
+
+func TestNewProcessorFromOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		ref   string
+		input string
+		want  []schemaIssue
+	}{
+		{
+			name:  "component schema",
+			ref:   "#/components/schemas/User",
+			input: "id,email,nickname\n1,kai@example.com,\n0,kai,kaikaikaikai\n",
+			want: []schemaIssue{
+				{Row: 2, Column: "id", Tag: "gt"},
+				{Row: 2, Column: "email", Tag: "email"},
+				{Row: 2, Column: "nickname", Tag: "maxLength"},
+			},
+		},
+		{
+			name:  "schema reference",
+			ref:   "#/components/schemas/Alias",
+			input: "id,email\n,\n",
+			want: []schemaIssue{
+				{Row: 1, Column: "id", Tag: "required"},
+				{Row: 1, Column: "email", Tag: "required"},
+			},
+		},
+		{
+			name:  "parameter list",
+			ref:   "#/paths/~1users/get/parameters",
+			input: "limit,status,X-Tenant\n10,active,0b4f1c3a-6a8e-4f5e-9a1b-2c3d4e5f6a7b\n500,pending,\n",
+			want: []schemaIssue{
+				{Row: 2, Column: "limit", Tag: "max"},
+				{Row: 2, Column: "status", Tag: "oneof"},
+				{Row: 2, Column: "X-Tenant", Tag: "required"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			processor, err := NewProcessorFromOpenAPI([]byte(testOpenAPISpec), tt.ref, fileparser.CSV)
+			if err != nil {
+				t.Fatalf("NewProcessorFromOpenAPI() error = %v", err)
+			}
+			var rows []struct{}
+			_, result, err := processor.Process(strings.NewReader(tt.input), &rows)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			var got []schemaIssue
+			for _, ve := range result.ValidationErrors() {
+				got = append(got, schemaIssue{Row: ve.Row, Column: ve.Column, Tag: ve.Tag})
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("errors mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewProcessorFromOpenAPI_InvalidSchema(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		spec string
+		ref  string
+	}{
+		{name: "YAML document", spec: "openapi: 3.0.3\n", ref: "#/components/schemas/User"},
+		{name: "missing reference", spec: testOpenAPISpec, ref: "#/components/schemas/Order"},
+		{name: "remote reference", spec: testOpenAPISpec, ref: "other.json#/components/schemas/User"},
+		{name: "reference cycle", spec: testOpenAPISpec, ref: "#/components/schemas/Loop"},
+		{name: "nested property", spec: testOpenAPISpec, ref: "#/components/schemas/Nested"},
+		{name: "not an object schema", spec: testOpenAPISpec, ref: "#/components/schemas/Email"},
+		{name: "content parameter", spec: `{"parameters": [{"name": "q", "in": "query", "content": {}}]}`, ref: "#/parameters"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewProcessorFromOpenAPI([]byte(tt.spec), tt.ref, fileparser.CSV)
+			if !errors.Is(err, ErrInvalidSchema) {
+				t.Errorf("NewProcessorFromOpenAPI() error = %v, want ErrInvalidSchema", err)
+			}
+		})
+	}
+}
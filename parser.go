@@ -2,7 +2,9 @@ package fileprep
 
 import (
 	"fmt"
+	"math"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -16,6 +18,8 @@ type fieldInfo struct {
 	Preprocessors        preprocessors        // Preprocessing rules
 	Validators           validators           // Validation rules
 	CrossFieldValidators crossFieldValidators // Cross-field validation rules
+	WarnValidators       validators           // Warning-level validation rules
+	WarnCrossValidators  crossFieldValidators // Warning-level cross-field validation rules
 }
 
 // structInfo contains parsed information about a struct type
@@ -72,12 +76,339 @@ func parseStructType(structType reflect.Type, strict bool) (*structInfo, error)
 			info.CrossFieldValidators = crossVals
 		}
 
+		// Parse warn tag (same syntax as validate, but failures are non-fatal)
+		if warnTag := field.Tag.Get(warnTagName); warnTag != "" {
+			vals, crossVals, err := parseValidateTag(warnTag, strict)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			info.WarnValidators = vals
+			info.WarnCrossValidators = crossVals
+		}
+
 		fields = append(fields, info)
 	}
 
 	return &structInfo{Fields: fields}, nil
 }
 
+// withValidatorOverrides returns a copy of the struct info in which
+// validators named in disabled are removed from every field, and validators
+// named in warn are moved from the validate rules to the warning rules.
+// The original is left untouched.
+func (si *structInfo) withValidatorOverrides(disabled, warn map[string]bool) *structInfo {
+	fields := make([]fieldInfo, len(si.Fields))
+	for i, fi := range si.Fields {
+		fi.Validators, _ = partitionValidators(fi.Validators, disabled)
+		fi.CrossFieldValidators, _ = partitionCrossFieldValidators(fi.CrossFieldValidators, disabled)
+		fi.WarnValidators, _ = partitionValidators(fi.WarnValidators, disabled)
+		fi.WarnCrossValidators, _ = partitionCrossFieldValidators(fi.WarnCrossValidators, disabled)
+
+		if len(warn) > 0 {
+			var demoted validators
+			var demotedCross crossFieldValidators
+			fi.Validators, demoted = partitionValidators(fi.Validators, warn)
+			fi.CrossFieldValidators, demotedCross = partitionCrossFieldValidators(fi.CrossFieldValidators, warn)
+			fi.WarnValidators = append(demoted, fi.WarnValidators...)
+			fi.WarnCrossValidators = append(demotedCross, fi.WarnCrossValidators...)
+		}
+		fields[i] = fi
+	}
+	return &structInfo{Fields: fields}
+}
+
+// withOmitEmpty returns a copy of the struct info in which every field's
+// validate and warn rules behave as if they started with omitempty, while
+// required still rejects empty values.
+func (si *structInfo) withOmitEmpty() *structInfo {
+	fields := make([]fieldInfo, len(si.Fields))
+	for i, fi := range si.Fields {
+		fi.Validators = omitEmptyValidators(fi.Validators)
+		fi.WarnValidators = omitEmptyValidators(fi.WarnValidators)
+		fields[i] = fi
+	}
+	return &structInfo{Fields: fields}
+}
+
+// withTemplateHeaders returns a copy of the struct info in which template
+// preprocessors read the columns named by headers. It returns si itself
+// when no field uses template.
+func (si *structInfo) withTemplateHeaders(headers []string) *structInfo {
+	var bound *structInfo
+	for i, fi := range si.Fields {
+		if !hasTemplate(fi.Preprocessors) {
+			continue
+		}
+		if bound == nil {
+			bound = &structInfo{Fields: slices.Clone(si.Fields)}
+		}
+		preps := slices.Clone(fi.Preprocessors)
+		for j, p := range preps {
+			if tp, ok := p.(*templatePreprocessor); ok {
+				preps[j] = tp.withHeaders(headers)
+			}
+		}
+		bound.Fields[i].Preprocessors = preps
+	}
+	if bound == nil {
+		return si
+	}
+	return bound
+}
+
+// withSchemaFields returns a copy of the struct info with fields appended
+// that check columns without binding them to a struct field, such as the
+// column rules of NewProcessorFromJSONSchema.
+func (si *structInfo) withSchemaFields(fields []fieldInfo) *structInfo {
+	return &structInfo{Fields: append(slices.Clone(si.Fields), fields...)}
+}
+
+// omitEmptyValidators reorders vs so that required validators run first,
+// followed by a single omitempty sentinel and the remaining validators.
+func omitEmptyValidators(vs validators) validators {
+	if len(vs) == 0 {
+		return vs
+	}
+	out := make(validators, 0, len(vs)+1)
+	for _, v := range vs {
+		if v.Name() == requiredTagValue {
+			out = append(out, v)
+		}
+	}
+	out = append(out, &omitemptyValidator{})
+	for _, v := range vs {
+		if name := v.Name(); name != requiredTagValue && name != omitemptyTagValue {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// withColumnStats returns a copy of the struct info in which column
+// statistics preprocessors (winsorize) and validators (outlier, percentile,
+// increasing, nondecreasing) are bound to their column in records. Values
+// are preprocessed with the field's prep rules first, those before a
+// preprocessor for preprocessors, so the statistics describe the values
+// that are processed and validated. It returns si itself when no field uses
+// such a rule.
+func (si *structInfo) withColumnStats(records [][]string) *structInfo {
+	var bound *structInfo
+	for i, fi := range si.Fields {
+		prepStats := hasColumnStatsPreprocessor(fi.Preprocessors)
+		validatorStats := hasColumnStats(fi.Validators) || hasColumnStats(fi.WarnValidators)
+		if !prepStats && !validatorStats {
+			continue
+		}
+		if bound == nil {
+			bound = &structInfo{Fields: slices.Clone(si.Fields)}
+		}
+		if prepStats {
+			fi.Preprocessors = bindColumnStatsPreprocessors(fi, records)
+			bound.Fields[i].Preprocessors = fi.Preprocessors
+		}
+		if !validatorStats {
+			continue
+		}
+
+		values := preparedColumn(fi, records)
+		bound.Fields[i].Validators = bindColumnStats(fi.Validators, values)
+		bound.Fields[i].WarnValidators = bindColumnStats(fi.WarnValidators, values)
+	}
+	if bound == nil {
+		return si
+	}
+	return bound
+}
+
+// hasColumnStatsPreprocessor reports whether ps contains a column
+// statistics preprocessor.
+func hasColumnStatsPreprocessor(ps preprocessors) bool {
+	return slices.ContainsFunc(ps, func(p Preprocessor) bool {
+		_, ok := p.(columnStatsPreprocessor)
+		return ok
+	})
+}
+
+// bindColumnStatsPreprocessors returns a copy of the field's preprocessors
+// with every column statistics preprocessor replaced by one bound to the
+// column's values after the preprocessors before it.
+func bindColumnStatsPreprocessors(fi fieldInfo, records [][]string) preprocessors {
+	ps := slices.Clone(fi.Preprocessors)
+	for k, p := range ps {
+		statsPreprocessor, ok := p.(columnStatsPreprocessor)
+		if !ok {
+			continue
+		}
+		preceding := fieldInfo{ColumnIndex: fi.ColumnIndex, Preprocessors: ps[:k]}
+		ps[k] = statsPreprocessor.withColumnValues(preparedColumn(preceding, records))
+	}
+	return ps
+}
+
+// hasColumnStats reports whether vs contains a column statistics validator.
+func hasColumnStats(vs validators) bool {
+	for _, v := range vs {
+		if pv, ok := v.(*paramValidator); ok {
+			v = pv.Validator
+		}
+		if _, ok := v.(columnStatsValidator); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// bindColumnStats returns a copy of vs with every column statistics
+// validator replaced by one bound to values.
+func bindColumnStats(vs validators, values []string) validators {
+	if !hasColumnStats(vs) {
+		return vs
+	}
+	bound := make(validators, len(vs))
+	for i, v := range vs {
+		bound[i] = v
+		pv, wrapped := v.(*paramValidator)
+		if wrapped {
+			v = pv.Validator
+		}
+		statsValidator, ok := v.(columnStatsValidator)
+		if !ok {
+			continue
+		}
+		prepared := statsValidator.withColumnValues(values)
+		if wrapped {
+			prepared = &paramValidator{Validator: prepared, param: pv.param}
+		}
+		bound[i] = prepared
+	}
+	return bound
+}
+
+// withUniqueKeys returns a copy of the struct info in which unique
+// validators know which rows share each key. firstRow is the row number of
+// records[0]. Key values are preprocessed with each field's prep rules, so
+// keys are compared as they are validated. It returns si itself when no
+// field uses unique.
+func (si *structInfo) withUniqueKeys(records [][]string, firstRow int) *structInfo {
+	var bound *structInfo
+	for i, fi := range si.Fields {
+		if !hasUnique(fi.CrossFieldValidators) && !hasUnique(fi.WarnCrossValidators) {
+			continue
+		}
+		if bound == nil {
+			bound = &structInfo{Fields: slices.Clone(si.Fields)}
+		}
+		bound.Fields[i].CrossFieldValidators = si.bindUnique(fi, fi.CrossFieldValidators, records, firstRow)
+		bound.Fields[i].WarnCrossValidators = si.bindUnique(fi, fi.WarnCrossValidators, records, firstRow)
+	}
+	if bound == nil {
+		return si
+	}
+	return bound
+}
+
+// hasUnique reports whether vs contains a unique validator.
+func hasUnique(vs crossFieldValidators) bool {
+	for _, v := range vs {
+		if _, ok := v.(*uniqueValidator); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// bindUnique returns a copy of vs with every unique validator of field fi
+// replaced by one bound to the keys in records. A validator whose target
+// field does not exist is left unbound; validation reports the missing field.
+func (si *structInfo) bindUnique(fi fieldInfo, vs crossFieldValidators, records [][]string, firstRow int) crossFieldValidators {
+	if !hasUnique(vs) {
+		return vs
+	}
+	bound := slices.Clone(vs)
+	for i, v := range vs {
+		uv, ok := v.(*uniqueValidator)
+		if !ok {
+			continue
+		}
+		keyFields := []fieldInfo{fi}
+		for _, name := range uv.TargetFields() {
+			idx := slices.IndexFunc(si.Fields, func(f fieldInfo) bool { return f.Name == name })
+			if idx < 0 {
+				keyFields = nil
+				break
+			}
+			keyFields = append(keyFields, si.Fields[idx])
+		}
+		if keyFields == nil {
+			continue
+		}
+
+		columns := make([][]string, len(keyFields))
+		for k, kf := range keyFields {
+			columns[k] = preparedColumn(kf, records)
+		}
+		keys := make([][]string, len(records))
+		for rowIdx := range records {
+			key := make([]string, len(columns))
+			for k, column := range columns {
+				key[k] = column[rowIdx]
+			}
+			keys[rowIdx] = key
+		}
+		bound[i] = uv.withKeys(keys, firstRow)
+	}
+	return bound
+}
+
+// preparedColumn returns the field's column values in records after the
+// field's prep rules.
+func preparedColumn(fi fieldInfo, records [][]string) []string {
+	values := make([]string, len(records))
+	for rowIdx, record := range records {
+		value := ""
+		if fi.ColumnIndex >= 0 && fi.ColumnIndex < len(record) {
+			value = record[fi.ColumnIndex]
+		}
+		values[rowIdx] = fi.Preprocessors.processRecord(value, record)
+	}
+	return values
+}
+
+// partitionValidators splits vs into the validators whose name is not in
+// names and those whose name is. If omitempty precedes a matched validator,
+// the matched list starts with omitempty too, so the moved rules still skip
+// empty values.
+func partitionValidators(vs validators, names map[string]bool) (kept, matched validators) {
+	omitempty := false
+	for _, v := range vs {
+		if v.Name() == omitemptyTagValue {
+			omitempty = true
+		}
+		if !names[v.Name()] {
+			kept = append(kept, v)
+			continue
+		}
+		if omitempty && len(matched) == 0 && v.Name() != omitemptyTagValue {
+			matched = append(matched, &omitemptyValidator{})
+		}
+		matched = append(matched, v)
+	}
+	return kept, matched
+}
+
+// partitionCrossFieldValidators splits vs into the validators whose name is
+// not in names and those whose name is.
+func partitionCrossFieldValidators(vs crossFieldValidators, names map[string]bool) (kept, matched crossFieldValidators) {
+	for _, v := range vs {
+		if names[v.Name()] {
+			matched = append(matched, v)
+		} else {
+			kept = append(kept, v)
+		}
+	}
+	return kept, matched
+}
+
 // parsePrepTag parses the prep tag string and returns preprocessors
 func parsePrepTag(tag string, strict bool) (preprocessors, error) {
 	if tag == "" {
@@ -142,6 +473,22 @@ func parsePrepTag(tag string, strict bool) (preprocessors, error) {
 			preps = append(preps, newStripHTMLPreprocessor())
 		case stripNewlineTagValue:
 			preps = append(preps, newStripNewlinePreprocessor())
+		case stripEmojiTagValue:
+			preps = append(preps, newStripEmojiPreprocessor())
+		case keepBMPTagValue:
+			preps = append(preps, newKeepBMPPreprocessor())
+		case stripControlTagValue:
+			// strip_control=keep_whitespace keeps tabs and line breaks
+			switch value {
+			case "":
+				preps = append(preps, newStripControlPreprocessor(false))
+			case "keep_whitespace":
+				preps = append(preps, newStripControlPreprocessor(true))
+			default:
+				if strict {
+					return nil, fmt.Errorf("%w: strip_control takes no value or keep_whitespace, got %q", ErrInvalidTagFormat, value)
+				}
+			}
 		case collapseSpaceTagValue:
 			preps = append(preps, newCollapseSpacePreprocessor())
 
@@ -181,7 +528,24 @@ func parsePrepTag(tag string, strict bool) (preprocessors, error) {
 
 		// Advanced preprocessors
 		case normalizeUnicodeTagValue:
-			preps = append(preps, newNormalizeUnicodePreprocessor())
+			// normalize_unicode uses NFC, normalize_unicode=NFKC picks the form
+			if form, ok := normalizationForms[strings.ToUpper(value)]; ok {
+				preps = append(preps, newNormalizeUnicodePreprocessor(form))
+			} else if strict {
+				return nil, fmt.Errorf("%w: normalize_unicode takes no value or NFC, NFD, NFKC, or NFKD, got %q", ErrInvalidTagFormat, value)
+			}
+		case sanitizeUTF8TagValue:
+			// sanitize_utf8 replaces invalid sequences, sanitize_utf8=remove drops them
+			switch value {
+			case "":
+				preps = append(preps, newSanitizeUTF8Preprocessor("\uFFFD"))
+			case "remove":
+				preps = append(preps, newSanitizeUTF8Preprocessor(""))
+			default:
+				if strict {
+					return nil, fmt.Errorf("%w: sanitize_utf8 takes no value or remove, got %q", ErrInvalidTagFormat, value)
+				}
+			}
 		case nullifyTagValue:
 			if value != "" {
 				preps = append(preps, newNullifyPreprocessor(value))
@@ -214,8 +578,90 @@ func parsePrepTag(tag string, strict bool) (preprocessors, error) {
 				return nil, fmt.Errorf("%w: regex_replace requires pattern:replacement format, got %q", ErrInvalidTagFormat, value)
 			}
 
+		case decimalTagValue:
+			// decimal strips trailing zeros, decimal=N pads the fraction to N digits
+			scale := -1
+			if value != "" {
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 0 {
+					if strict {
+						return nil, fmt.Errorf("%w: decimal takes no value or a non-negative scale, got %q", ErrInvalidTagFormat, value)
+					}
+					continue
+				}
+				scale = n
+			}
+			preps = append(preps, newDecimalPreprocessor(scale))
+		case roundTagValue:
+			// round=N rounds half up, round=N:half_even rounds ties to even
+			placesText, mode, _ := strings.Cut(value, ":")
+			places, err := strconv.Atoi(placesText)
+			if err != nil || places < 0 || mode != "" && mode != roundHalfUp && mode != roundHalfEven {
+				if strict {
+					return nil, fmt.Errorf("%w: round requires a non-negative number of places, optionally followed by :half_up or :half_even, got %q", ErrInvalidTagFormat, value)
+				}
+				continue
+			}
+			preps = append(preps, newRoundPreprocessor(places, mode == roundHalfEven))
+		case clampTagValue:
+			// clamp=min:max, with either bound omitted for an open range
+			minText, maxText, found := strings.Cut(value, ":")
+			prep, err := newClampPreprocessor(minText, maxText)
+			if !found || minText == "" && maxText == "" || err != nil {
+				if strict {
+					return nil, fmt.Errorf("%w: clamp requires min:max with min <= max, min:, or :max, got %q", ErrInvalidTagFormat, value)
+				}
+				continue
+			}
+			preps = append(preps, prep)
+		case winsorizeTagValue:
+			// winsorize=low:high percentiles, bound to the column before processing
+			lowStr, highStr, found := parseColonSeparatedValue(value)
+			low, lowErr := strconv.ParseFloat(lowStr, 64)
+			high, highErr := strconv.ParseFloat(highStr, 64)
+			if !found || lowErr != nil || highErr != nil || low < 0 || high > 100 || low >= high {
+				if strict {
+					return nil, fmt.Errorf("%w: winsorize requires low:high with 0 <= low < high <= 100, got %q", ErrInvalidTagFormat, value)
+				}
+				continue
+			}
+			preps = append(preps, newWinsorizePreprocessor(low, high))
+		case unitTagValue:
+			prep, ok := newUnitPreprocessor(value)
+			if !ok {
+				if strict {
+					return nil, fmt.Errorf("%w: unit requires a known unit such as kg or m, got %q", ErrInvalidTagFormat, value)
+				}
+				continue
+			}
+			preps = append(preps, prep)
+		case percentToDecimalTagValue:
+			preps = append(preps, newPercentToDecimalPreprocessor())
+		case bpToDecimalTagValue:
+			preps = append(preps, newBPToDecimalPreprocessor())
+		case templateTagValue:
+			// template={{.year}}-{{.month}}-01 format
+			tp, err := newTemplatePreprocessor(value)
+			switch {
+			case value != "" && err == nil:
+				preps = append(preps, tp)
+			case strict && value == "":
+				return nil, fmt.Errorf("%w: template requires a template", ErrInvalidTagFormat)
+			case strict:
+				return nil, fmt.Errorf("%w: template is not a valid text/template: %w", ErrInvalidTagFormat, err)
+			}
+		case mapTagValue:
+			// map=from:to|from:to|default:value format
+			mp, err := parseValueMap(value, strict)
+			if err != nil {
+				return nil, err
+			}
+			if mp != nil {
+				preps = append(preps, mp)
+			}
+
 		default:
-			return nil, fmt.Errorf("%w: unknown prep tag %q", ErrInvalidTagFormat, part)
+			return nil, fmt.Errorf("%w: %w %q", ErrInvalidTagFormat, ErrUnknownPrepTag, part)
 		}
 	}
 
@@ -232,6 +678,34 @@ func parseColonSeparatedValue(value string) (string, string, bool) {
 	return value[:idx], value[idx+1:], true
 }
 
+// parseValueMap parses the map tag value "from:to|from:to|default:value".
+// Entries without a colon are an error in strict mode and skipped otherwise.
+// It returns nil when no entry is usable.
+func parseValueMap(value string, strict bool) (*valueMapPreprocessor, error) {
+	mapping := make(map[string]string)
+	defaultValue, hasDefault := "", false
+	for _, entry := range strings.Split(value, "|") {
+		from, to, found := parseColonSeparatedValue(entry)
+		switch {
+		case !found:
+			if strict {
+				return nil, fmt.Errorf("%w: map requires from:to entries, got %q", ErrInvalidTagFormat, entry)
+			}
+		case from == mapDefaultKey:
+			defaultValue, hasDefault = to, true
+		default:
+			mapping[from] = to
+		}
+	}
+	if len(mapping) == 0 && !hasDefault {
+		if strict {
+			return nil, fmt.Errorf("%w: map requires at least one from:to entry", ErrInvalidTagFormat)
+		}
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+	return newValueMapPreprocessor(mapping, defaultValue, hasDefault), nil
+}
+
 // parsePadParams parses "N:char" format for padding preprocessors
 // Returns length and pad character (defaults to space if not specified)
 func parsePadParams(value string) (int, rune) {
@@ -269,6 +743,71 @@ func parseRequiredIfParams(value string) (string, string) {
 	return field, expectedVal
 }
 
+// buildWithinValidator builds a within validator from a "Field:tolerance"
+// parameter. The tolerance is an absolute difference such as "0.05", or a
+// fraction of the target value such as "5%".
+func buildWithinValidator(value string, strict bool) (CrossFieldValidator, error) {
+	field, toleranceText, _ := strings.Cut(value, ":")
+	number, relative := strings.CutSuffix(toleranceText, "%")
+	tolerance, err := strconv.ParseFloat(number, 64)
+	if field == "" || err != nil || tolerance < 0 || math.IsInf(tolerance, 0) || math.IsNaN(tolerance) {
+		if strict {
+			return nil, fmt.Errorf("%w: within requires Field:tolerance such as Total:0.05 or Total:5%%, got %q", ErrInvalidTagFormat, value)
+		}
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+	if relative {
+		tolerance /= 100
+	}
+	return newWithinValidator(field, tolerance, relative, toleranceText), nil
+}
+
+// buildSumOfValidator builds a sumof validator from a space-separated
+// field list, optionally followed by ":epsilon" to allow the sum to differ
+// from the value by up to epsilon, as in "Food Rent Other:0.01".
+func buildSumOfValidator(value string, strict bool) (CrossFieldValidator, error) {
+	fieldList, epsilonText, hasEpsilon := strings.Cut(value, ":")
+	epsilon := 0.0
+	var err error
+	if hasEpsilon {
+		epsilon, err = strconv.ParseFloat(epsilonText, 64)
+	}
+	if len(strings.Fields(fieldList)) == 0 || err != nil || epsilon < 0 || math.IsInf(epsilon, 0) || math.IsNaN(epsilon) {
+		if strict {
+			return nil, fmt.Errorf("%w: sumof requires a field list and an optional :epsilon such as \"A B:0.01\", got %q", ErrInvalidTagFormat, value)
+		}
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+	return newSumOfValidator(fieldList, epsilon, epsilonText), nil
+}
+
+// buildExprValidator builds an expr validator from an expression such as
+// "value > other('MinPrice') * 0.9". The expression is compiled here, when
+// the tag is parsed. A malformed expression is an error in both modes, as
+// for WithFilter, since dropping it would silently skip the rule.
+func buildExprValidator(value string, _ bool) (CrossFieldValidator, error) {
+	v, err := newExprValidator(value)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// buildChecksumValidator builds a checksum validator from an
+// "algorithm:Field" parameter such as "sha256:Payload". The algorithm is
+// lowercase, as HashAlgorithm.String returns it.
+func buildChecksumValidator(value string, strict bool) (CrossFieldValidator, error) {
+	name, field, ok := strings.Cut(value, ":")
+	algorithm, known := hashAlgorithmByName(name)
+	if !ok || !known || field == "" {
+		if strict {
+			return nil, fmt.Errorf("%w: checksum requires algorithm:Field with sha256, sha1, md5, or fnv64a, got %q", ErrInvalidTagFormat, value)
+		}
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+	return newChecksumValidator(algorithm, field), nil
+}
+
 // validatorBuilder creates a Validator from a tag value parameter.
 // Returns the validator (nil if parameter is invalid in non-strict mode) and an error in strict mode.
 type validatorBuilder func(value string, strict bool) (Validator, error)
@@ -288,6 +827,46 @@ func buildFloatValidator(tagName string, value string, strict bool, factory func
 	return factory(threshold), nil
 }
 
+// buildOutlierValidator parses "3sigma" (or "3") into an outlier validator.
+func buildOutlierValidator(value string, strict bool) (Validator, error) {
+	k, err := strconv.ParseFloat(strings.TrimSuffix(value, "sigma"), 64)
+	if err != nil || k <= 0 {
+		if strict {
+			return nil, fmt.Errorf("%w: outlier requires a positive sigma multiplier such as 3sigma, got %q", ErrInvalidTagFormat, value)
+		}
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+	return newOutlierValidator(k), nil
+}
+
+// buildPercentileValidator parses "low:high" percentiles (0-100) into a percentile validator.
+func buildPercentileValidator(value string, strict bool) (Validator, error) {
+	lowStr, highStr, found := parseColonSeparatedValue(value)
+	low, lowErr := strconv.ParseFloat(lowStr, 64)
+	high, highErr := strconv.ParseFloat(highStr, 64)
+	if !found || lowErr != nil || highErr != nil || low < 0 || high > 100 || low >= high {
+		if strict {
+			return nil, fmt.Errorf("%w: percentile requires low:high with 0 <= low < high <= 100, got %q", ErrInvalidTagFormat, value)
+		}
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+	return newPercentileValidator(low, high), nil
+}
+
+// buildCharsetValidator parses the space-separated Unicode scripts and
+// categories of a charset or excludescharset tag. Unknown names are an
+// error in strict mode and drop the validator otherwise.
+func buildCharsetValidator(tagName, value string, strict bool, exclude bool) (Validator, error) {
+	tables, err := parseCharsets(value)
+	if err != nil {
+		if strict {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidTagFormat, tagName, err)
+		}
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+	return newCharsetValidator(tables, value, exclude), nil
+}
+
 // validatorRegistry maps tag names to their builder functions.
 // Builders that ignore the value parameter use _ to indicate it's unused.
 //
@@ -295,6 +874,7 @@ func buildFloatValidator(tagName string, value string, strict bool, factory func
 var validatorRegistry = map[string]validatorBuilder{
 	// Sentinel
 	omitemptyTagValue: func(_ string, _ bool) (Validator, error) { return &omitemptyValidator{}, nil },
+	orclearTagValue:   func(_ string, _ bool) (Validator, error) { return &orclearValidator{}, nil },
 
 	// Basic validators
 	requiredTagValue:            func(_ string, _ bool) (Validator, error) { return newRequiredValidator(), nil },
@@ -342,6 +922,9 @@ var validatorRegistry = map[string]validatorBuilder{
 		}
 		return newLengthValidator(length), nil
 	},
+	decimalTagValue:         buildDecimalValidator,
+	formattedNumberTagValue: buildFormattedNumberValidator,
+	unitTagValue:            buildUnitValidator,
 
 	// String validators
 	oneOfTagValue: func(value string, _ bool) (Validator, error) {
@@ -350,6 +933,15 @@ var validatorRegistry = map[string]validatorBuilder{
 		}
 		return nil, nil //nolint:nilnil // empty value produces no validator
 	},
+	oneOfFileTagValue: func(v string, s bool) (Validator, error) {
+		if v == "" {
+			if s {
+				return nil, fmt.Errorf("%w: oneof_file requires the name of a list loaded with WithValueList", ErrInvalidTagFormat)
+			}
+			return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+		}
+		return newOneOfFileValidator(v), nil
+	},
 	lowercaseValidatorTagValue: func(_ string, _ bool) (Validator, error) { return newLowercaseValidator(), nil },
 	uppercaseValidatorTagValue: func(_ string, _ bool) (Validator, error) { return newUppercaseValidator(), nil },
 	asciiTagValue:              func(_ string, _ bool) (Validator, error) { return newASCIIValidator(), nil },
@@ -452,6 +1044,33 @@ var validatorRegistry = map[string]validatorBuilder{
 
 	// Misc validators
 	multibyteTagValue: func(_ string, _ bool) (Validator, error) { return newMultibyteValidator(), nil },
+	noEmojiTagValue:   func(_ string, _ bool) (Validator, error) { return newNoEmojiValidator(), nil },
+	charsetTagValue: func(v string, s bool) (Validator, error) {
+		return buildCharsetValidator(charsetTagValue, v, s, false)
+	},
+	excludesCharsetTagValue: func(v string, s bool) (Validator, error) {
+		return buildCharsetValidator(excludesCharsetTagValue, v, s, true)
+	},
+	langTagValue: buildLanguageValidator,
+	notInListTagValue: func(v string, s bool) (Validator, error) {
+		if v == "" {
+			if s {
+				return nil, fmt.Errorf("%w: not_in_list requires the name of a list loaded with WithValueList", ErrInvalidTagFormat)
+			}
+			return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+		}
+		return newNotInListValidator(v), nil
+	},
+	passwordTagValue: func(v string, s bool) (Validator, error) {
+		policy, err := parsePasswordPolicy(v)
+		if err != nil {
+			if s {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidTagFormat, err)
+			}
+			return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+		}
+		return newPasswordValidator(policy), nil
+	},
 	equalIgnoreCaseTagValue: func(v string, _ bool) (Validator, error) {
 		if v != "" {
 			return newEqualIgnoreCaseValidator(v), nil
@@ -486,6 +1105,18 @@ var validatorRegistry = map[string]validatorBuilder{
 	uuid5TagValue: func(_ string, _ bool) (Validator, error) { return newUUID5Validator(), nil },
 	ulidTagValue:  func(_ string, _ bool) (Validator, error) { return newULIDValidator(), nil },
 
+	// Check digit validators
+	luhnTagValue: buildLuhnValidator,
+
+	// National identifier validators
+	usSSNTagValue:      func(_ string, _ bool) (Validator, error) { return newUSSSNValidator(), nil },
+	jpMyNumberTagValue: func(_ string, _ bool) (Validator, error) { return newJPMyNumberValidator(), nil },
+	ukNINOTagValue:     func(_ string, _ bool) (Validator, error) { return newUKNINOValidator(), nil },
+
+	// Tax identifier validators
+	euVATTagValue:             func(_ string, _ bool) (Validator, error) { return newEUVATValidator(), nil },
+	jpCorporateNumberTagValue: func(_ string, _ bool) (Validator, error) { return newJPCorporateNumberValidator(), nil },
+
 	// Hexadecimal and color validators
 	hexadecimalTagValue: func(_ string, _ bool) (Validator, error) { return newHexadecimalValidator(), nil },
 	hexColorTagValue:    func(_ string, _ bool) (Validator, error) { return newHexColorValidator(), nil },
@@ -493,6 +1124,12 @@ var validatorRegistry = map[string]validatorBuilder{
 	rgbaTagValue:        func(_ string, _ bool) (Validator, error) { return newRGBAValidator(), nil },
 	hslTagValue:         func(_ string, _ bool) (Validator, error) { return newHSLValidator(), nil },
 	hslaTagValue:        func(_ string, _ bool) (Validator, error) { return newHSLAValidator(), nil },
+
+	// Column statistics validators
+	outlierTagValue:       buildOutlierValidator,
+	percentileTagValue:    buildPercentileValidator,
+	increasingTagValue:    func(_ string, _ bool) (Validator, error) { return newMonotonicValidator(true), nil },
+	nondecreasingTagValue: func(_ string, _ bool) (Validator, error) { return newMonotonicValidator(false), nil },
 }
 
 // crossFieldValidatorRegistry maps tag names to their builder functions.
@@ -509,6 +1146,12 @@ var crossFieldValidatorRegistry = map[string]crossFieldValidatorBuilder{
 	fieldExcludesTagValue:   func(v string) CrossFieldValidator { return newFieldExcludesValidator(v) },
 	requiredWithTagValue:    func(v string) CrossFieldValidator { return newRequiredWithValidator(v) },
 	requiredWithoutTagValue: func(v string) CrossFieldValidator { return newRequiredWithoutValidator(v) },
+
+	// Multi-field conditional validators take a space-separated field list
+	requiredWithAllTagValue:    func(v string) CrossFieldValidator { return newRequiredWithAllValidator(v) },
+	requiredWithoutAllTagValue: func(v string) CrossFieldValidator { return newRequiredWithoutAllValidator(v) },
+	excludedWithTagValue:       func(v string) CrossFieldValidator { return newExcludedWithValidator(v) },
+	excludedWithoutTagValue:    func(v string) CrossFieldValidator { return newExcludedWithoutValidator(v) },
 }
 
 // parseValidateTag parses the validate tag string and returns validators and cross-field validators.
@@ -519,7 +1162,7 @@ func parseValidateTag(tag string, strict bool) (validators, crossFieldValidators
 		return nil, nil, nil
 	}
 
-	parts := strings.Split(tag, ",")
+	parts := splitTagParts(tag)
 	vals := make(validators, 0, len(parts))
 	crossVals := make(crossFieldValidators, 0)
 
@@ -529,15 +1172,30 @@ func parseValidateTag(tag string, strict bool) (validators, crossFieldValidators
 			continue
 		}
 
+		// Validator groups: or(a|b), and(a|b), not(a)
+		if name, inner, ok := splitGroup(part); ok {
+			v, err := buildGroupValidator(name, inner, strict)
+			if err != nil {
+				return nil, nil, err
+			}
+			if v != nil {
+				vals = append(vals, &paramValidator{Validator: v, param: inner})
+			}
+			continue
+		}
+
 		key, value := splitTagKeyValue(part)
 
-		// Check single-field validator registry
-		if builder, ok := validatorRegistry[key]; ok {
+		// Check single-field validator registry, then validators added with RegisterValidator
+		if builder, ok := lookupValidatorBuilder(key); ok {
 			v, err := builder(value, strict)
 			if err != nil {
 				return nil, nil, err
 			}
 			if v != nil {
+				if value != "" {
+					v = &paramValidator{Validator: v, param: value}
+				}
 				vals = append(vals, v)
 			}
 			continue
@@ -567,14 +1225,176 @@ func parseValidateTag(tag string, strict bool) (validators, crossFieldValidators
 					crossVals = append(crossVals, newRequiredUnlessValidator(field, exceptVal))
 				}
 			}
+		case sumOfTagValue:
+			v, err := buildSumOfValidator(value, strict)
+			if err != nil {
+				return nil, nil, err
+			}
+			if v != nil {
+				crossVals = append(crossVals, v)
+			}
+		case exprTagValue:
+			v, err := buildExprValidator(value, strict)
+			if err != nil {
+				return nil, nil, err
+			}
+			if v != nil {
+				crossVals = append(crossVals, v)
+			}
+		case withinTagValue:
+			v, err := buildWithinValidator(value, strict)
+			if err != nil {
+				return nil, nil, err
+			}
+			if v != nil {
+				crossVals = append(crossVals, v)
+			}
+		case checksumTagValue:
+			v, err := buildChecksumValidator(value, strict)
+			if err != nil {
+				return nil, nil, err
+			}
+			if v != nil {
+				crossVals = append(crossVals, v)
+			}
+		case uniqueTagValue:
+			// The field list is optional: plain unique checks the field alone
+			crossVals = append(crossVals, newUniqueValidator(value))
 		default:
-			return nil, nil, fmt.Errorf("%w: unknown validate tag %q", ErrInvalidTagFormat, part)
+			return nil, nil, fmt.Errorf("%w: %w %q", ErrInvalidTagFormat, ErrUnknownValidateTag, part)
 		}
 	}
 
 	return vals, crossVals, nil
 }
 
+// splitGroup reports whether part is a validator group such as
+// "or(email|e164)" and returns the group name and the text inside the
+// parentheses.
+func splitGroup(part string) (name, inner string, ok bool) {
+	open := strings.IndexByte(part, '(')
+	if open <= 0 || !strings.HasSuffix(part, ")") {
+		return "", "", false
+	}
+	name = part[:open]
+	switch name {
+	case orTagValue, andTagValue, notTagValue:
+		return name, part[open+1 : len(part)-1], true
+	default:
+		return "", "", false
+	}
+}
+
+// splitTagParts splits a validate tag at commas that are not inside
+// parentheses or quotes, so that expressions such as
+// "expr=value in ('a', 'b')" keep their commas. A quote without a closing
+// quote later in the tag, such as an apostrophe, is an ordinary character.
+func splitTagParts(tag string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(tag); i++ {
+		switch ch := tag[i]; ch {
+		case '\'', '"':
+			if end := strings.IndexByte(tag[i+1:], ch); end >= 0 {
+				i += end + 1
+			}
+		case '(':
+			depth++
+		case ')':
+			depth = max(depth-1, 0)
+		case ',':
+			if depth == 0 {
+				parts = append(parts, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, tag[start:])
+}
+
+// splitGroupMembers splits the inside of a group at | separators that are
+// not nested in another group.
+func splitGroupMembers(inner string) []string {
+	var members []string
+	depth, start := 0, 0
+	for i := 0; i < len(inner); i++ {
+		switch inner[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '|':
+			if depth == 0 {
+				members = append(members, strings.TrimSpace(inner[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(members, strings.TrimSpace(inner[start:]))
+}
+
+// buildGroupValidator builds an or, and, or not validator from the members
+// inside its parentheses. Members can be any single-field validator with its
+// parameter, or another group. As for top-level validators, members with
+// invalid arguments are dropped in non-strict mode; a group left without
+// members is dropped too.
+func buildGroupValidator(name, inner string, strict bool) (Validator, error) {
+	parts := splitGroupMembers(inner)
+	if name == notTagValue && len(parts) != 1 {
+		return nil, fmt.Errorf("%w: not() takes exactly one validator, got %q", ErrInvalidTagFormat, inner)
+	}
+
+	var members validators
+	var labels []string
+	for _, member := range parts {
+		if member == "" {
+			return nil, fmt.Errorf("%w: empty member in %s(%s)", ErrInvalidTagFormat, name, inner)
+		}
+		v, err := buildGroupMember(member, strict)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			members = append(members, v)
+			labels = append(labels, member)
+		}
+	}
+
+	if len(members) == 0 {
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+
+	switch name {
+	case orTagValue:
+		return newOrValidator(members, labels), nil
+	case andTagValue:
+		return newAndValidator(members), nil
+	default:
+		return newNotValidator(members[0], labels[0]), nil
+	}
+}
+
+// buildGroupMember builds one validator inside a group.
+func buildGroupMember(member string, strict bool) (Validator, error) {
+	if name, inner, ok := splitGroup(member); ok {
+		return buildGroupValidator(name, inner, strict)
+	}
+
+	key, value := splitTagKeyValue(member)
+	builder, ok := lookupValidatorBuilder(key)
+	if !ok || key == omitemptyTagValue || key == orclearTagValue {
+		return nil, fmt.Errorf("%w: %q cannot be used in a validator group", ErrInvalidTagFormat, member)
+	}
+	v, err := builder(value, strict)
+	if err != nil {
+		return nil, err
+	}
+	if _, isColumnStats := v.(columnStatsValidator); isColumnStats {
+		return nil, fmt.Errorf("%w: %q cannot be used in a validator group", ErrInvalidTagFormat, member)
+	}
+	return v, nil
+}
+
 // splitTagKeyValue splits a tag part into key and value
 // For "key=value" returns ("key", "value")
 // For "key" returns ("key", "")
@@ -52,6 +52,16 @@ func TestParseValidateTag(t *testing.T) {
 		{"required", "required", 1, false},
 		{"unknown tag returns error", "unknown", 0, true},
 		{"spaces in tag", " required ", 1, false},
+		{"or group", "or(email|e164)", 1, false},
+		{"group with other validators", "required,or(email|e164)", 2, false},
+		{"nested groups", "or(and(numeric|len=5)|not(alpha))", 1, false},
+		{"group with unknown member", "or(email|unknown)", 0, true},
+		{"group with cross-field member", "or(email|eqfield=Other)", 0, true},
+		{"group with empty member", "or(email|)", 0, true},
+		{"not with two members", "not(email|e164)", 0, true},
+		{"orclear", "email,orclear", 2, false},
+		{"group with orclear member", "or(email|orclear)", 0, true},
+		{"invalid member arg dropped in non-strict mode", "or(min=abc)", 0, false},
 	}
 
 	for _, tt := range tests {
@@ -279,6 +289,9 @@ func TestParsePrepTag_AllPreprocessorTypes(t *testing.T) {
 		{"fix_scheme=https", "fix_scheme=https", 1, false},
 		{"fix_scheme= (empty)", "fix_scheme=", 0, false},
 		{"regex_replace=\\d+:X", "regex_replace=\\d+:X", 1, false},
+		{"map=active:1|inactive:0", "map=active:1|inactive:0", 1, false},
+		{"map=default:0", "map=default:0", 1, false},
+		{"map= (empty)", "map=", 0, false},
 
 		// Multiple combined preprocessors
 		{"trim,lowercase,default=N/A", "trim,lowercase,default=N/A", 3, false},
@@ -361,6 +374,41 @@ func TestSplitTagKeyValue(t *testing.T) {
 	}
 }
 
+func TestSplitTagParts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"plain validators", "required,min=1", []string{"required", "min=1"}},
+		{"in list", "required,expr=value in ('a', 'b'),max=5", []string{"required", "expr=value in ('a', 'b')", "max=5"}},
+		{"quoted comma", "expr=value != 'a,b',required", []string{"expr=value != 'a,b'", "required"}},
+		{"unclosed quote is literal", "contains=O'Brien,required", []string{"contains=O'Brien", "required"}},
+		{"unbalanced close paren", "contains=),required", []string{"contains=)", "required"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := splitTagParts(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitTagParts(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildExprValidator_MalformedInBothModes(t *testing.T) {
+	t.Parallel()
+
+	for _, strict := range []bool{true, false} {
+		if _, _, err := parseValidateTag("expr=value >", strict); !errors.Is(err, ErrInvalidTagFormat) {
+			t.Errorf("parseValidateTag(strict=%v) error = %v, want ErrInvalidTagFormat", strict, err)
+		}
+	}
+}
+
 func TestParseColonSeparatedValue(t *testing.T) {
 	t.Parallel()
 
@@ -486,6 +534,10 @@ func TestParsePrepTagInvalidFormats(t *testing.T) {
 		{"coerce valid int", "coerce=int", 1, false, ""},
 		{"coerce valid float", "coerce=float", 1, false, ""},
 		{"coerce valid bool", "coerce=bool", 1, false, ""},
+
+		// Invalid map entries are skipped
+		{"map entry without colon", "map=active|inactive:0", 1, false, ""},
+		{"map without entries", "map=active", 0, false, ""},
 	}
 
 	for _, tt := range tests {
@@ -795,6 +847,56 @@ func TestStrictTagParsing_ValidateTag(t *testing.T) {
 		{"len with valid value", "len=5", false},
 		{"required needs no value", "required", false},
 		{"email needs no value", "email", false},
+		{"outlier with sigma suffix", "outlier=3sigma", false},
+		{"outlier with plain number", "outlier=2.5", false},
+		{"outlier with invalid value", "outlier=abc", true},
+		{"outlier with zero", "outlier=0sigma", true},
+		{"percentile with valid range", "percentile=1:99", false},
+		{"percentile without colon", "percentile=5", true},
+		{"percentile with inverted range", "percentile=99:1", true},
+		{"percentile above 100", "percentile=1:101", true},
+		{"charset with scripts", "charset=han kana", false},
+		{"charset with unknown script", "charset=klingon", true},
+		{"excludescharset without value", "excludescharset", true},
+		{"lang with codes", "lang=en fr", false},
+		{"lang with unknown code", "lang=en xx", true},
+		{"lang without codes", "lang", true},
+		{"not_in_list with list name", "not_in_list=badwords.txt", false},
+		{"not_in_list without list name", "not_in_list", true},
+		{"oneof_file with list name", "oneof_file=prefectures.txt", false},
+		{"oneof_file without list name", "oneof_file", true},
+		{"password with default policy", "password", false},
+		{"password with policy", "password=min12 upper digit", false},
+		{"password with unknown rule", "password=min8 emoji", true},
+		{"checksum with algorithm and field", "checksum=sha256:Payload", false},
+		{"checksum with unknown algorithm", "checksum=crc32:Payload", true},
+		{"checksum without field", "checksum=sha256", true},
+		{"luhn needs no value", "luhn", false},
+		{"luhn with modulus", "luhn=36", false},
+		{"luhn with modulus out of range", "luhn=37", true},
+		{"within with absolute tolerance", "within=Total:0.05", false},
+		{"within with relative tolerance", "within=Total:5%", false},
+		{"within without tolerance", "within=Total", true},
+		{"within with negative tolerance", "within=Total:-1", true},
+		{"sumof with fields", "sumof=A B C", false},
+		{"sumof with epsilon", "sumof=A B:0.01", false},
+		{"sumof without fields", "sumof=:0.01", true},
+		{"sumof with invalid epsilon", "sumof=A B:abc", true},
+		{"expr", "expr=value > other('MinPrice') * 0.9", false},
+		{"expr with unknown name", "expr=price > 1", true},
+		{"expr with unknown function", "expr=len('x') > 1", true},
+		{"expr that is not a condition", "expr=value + 1", true},
+		{"expr with syntax error", "expr=value >", true},
+		{"decimal with precision and scale", "decimal=10:2", false},
+		{"decimal with precision", "decimal=10", false},
+		{"decimal with scale above precision", "decimal=2:3", true},
+		{"decimal without precision", "decimal=", true},
+		{"formatted_number", "formatted_number", false},
+		{"formatted_number with locale", "formatted_number=de-CH", false},
+		{"formatted_number with invalid locale", "formatted_number=not a locale", true},
+		{"unit", "unit=kg g t", false},
+		{"unit with unknown unit", "unit=kg stone", true},
+		{"unit without units", "unit=", true},
 	}
 
 	for _, tt := range tests {
@@ -829,6 +931,33 @@ func TestStrictTagParsing_PrepTag(t *testing.T) {
 		{"trim needs no value", "trim", false},
 		{"pad_left with valid format", "pad_left=5:0", false},
 		{"pad_left with invalid length", "pad_left=abc:0", true},
+		{"map with valid entries", "map=active:1|default:", false},
+		{"map entry without colon", "map=active:1|inactive", true},
+		{"map without entries", "map=", true},
+		{"template with columns", "template={{.year}}-{{.month}}-01", false},
+		{"template without a template", "template=", true},
+		{"template with unclosed action", "template={{.year", true},
+		{"decimal needs no value", "decimal", false},
+		{"decimal with scale", "decimal=2", false},
+		{"decimal with negative scale", "decimal=-1", true},
+		{"unit", "unit=kg", false},
+		{"unit with unknown unit", "unit=stone", true},
+		{"round", "round=2", false},
+		{"round half_even", "round=2:half_even", false},
+		{"round with unknown mode", "round=2:banker", true},
+		{"round with negative places", "round=-1", true},
+		{"clamp", "clamp=0:100", false},
+		{"clamp minimum only", "clamp=0:", false},
+		{"clamp maximum only", "clamp=:100", false},
+		{"clamp without bounds", "clamp=:", true},
+		{"clamp without colon", "clamp=100", true},
+		{"clamp with minimum above maximum", "clamp=100:0", true},
+		{"clamp with text bound", "clamp=low:high", true},
+		{"winsorize", "winsorize=1:99", false},
+		{"winsorize with low above high", "winsorize=99:1", true},
+		{"winsorize above 100", "winsorize=1:101", true},
+		{"normalize_unicode with form", "normalize_unicode=nfkc", false},
+		{"normalize_unicode with unknown form", "normalize_unicode=nfx", true},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,71 @@
+package fileprep
+
+import (
+	"io"
+	"time"
+)
+
+// PerfStats reports the input size, buffer size, and time of one Process
+// call, for capacity planning of ingestion services. Durations are wall
+// clock time. For ProcessChunks, BytesRead, DecompressedBytes, Read, and
+// Parse describe the whole input, and the other fields the chunk.
+//
+// Example:
+//
+//	_, result, _ := processor.Process(input, &records)
+//	perf := result.Perf
+//	log.Printf("%d bytes (%d decompressed), peak buffers %d bytes, %.0f rows/s, parse %v, process %v",
+//	    perf.BytesRead, perf.DecompressedBytes, perf.PeakBufferBytes, perf.RowsPerSecond, perf.Parse, perf.Process)
+type PerfStats struct {
+	// BytesRead is the number of bytes read from the input, compressed for
+	// compressed input
+	BytesRead int64
+	// DecompressedBytes is the size of the input after decompression
+	DecompressedBytes int64
+	// PeakBufferBytes is the size of the largest buffers held at once: the
+	// decompressed input and the output stream, unless the output reuses
+	// the input. Parsed rows and structs are not counted.
+	PeakBufferBytes int64
+	// RowsPerSecond is RowCount divided by Total
+	RowsPerSecond float64
+
+	// Read is the time spent reading and decompressing the input
+	Read time.Duration
+	// Parse is the time spent parsing the input into rows, checking the
+	// header, and binding struct fields and column statistics to it
+	Parse time.Duration
+	// Process is the time spent preprocessing and validating the rows
+	Process time.Duration
+	// Output is the time spent building the output stream
+	Output time.Duration
+	// Total is the sum of the phase durations
+	Total time.Duration
+}
+
+// finish sets the output phase of perf: the time spent building output,
+// the size of output unless reused shares the input buffer, and the totals
+// for rows processed rows.
+func (perf *PerfStats) finish(output *stream, reused bool, elapsed time.Duration, rows int) {
+	perf.Output = elapsed
+	perf.Total = perf.Read + perf.Parse + perf.Process + perf.Output
+	perf.PeakBufferBytes = perf.DecompressedBytes
+	if !reused {
+		perf.PeakBufferBytes += output.Size()
+	}
+	if perf.Total > 0 {
+		perf.RowsPerSecond = float64(rows) / perf.Total.Seconds()
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read reads from the underlying reader and counts the bytes
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,97 @@
+package fileprep
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nao1215/fileparser"
+)
+
+func TestProcessResult_Perf(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Name string `prep:"trim"`
+	}
+
+	t.Run("compressed input with new output", func(t *testing.T) {
+		t.Parallel()
+
+		input := "name\n alice \nbob\n"
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write([]byte(input)); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		compressedSize := int64(buf.Len())
+
+		var items []item
+		output, result, err := NewProcessor(fileparser.CSVGZ).Process(&buf, &items)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		data, err := io.ReadAll(output)
+		if err != nil {
+			t.Fatalf("io.ReadAll() error = %v", err)
+		}
+
+		perf := result.Perf
+		if perf.BytesRead != compressedSize {
+			t.Errorf("BytesRead = %d, want %d", perf.BytesRead, compressedSize)
+		}
+		if perf.DecompressedBytes != int64(len(input)) {
+			t.Errorf("DecompressedBytes = %d, want %d", perf.DecompressedBytes, len(input))
+		}
+		if want := int64(len(input) + len(data)); perf.PeakBufferBytes != want {
+			t.Errorf("PeakBufferBytes = %d, want %d", perf.PeakBufferBytes, want)
+		}
+		if perf.Total != perf.Read+perf.Parse+perf.Process+perf.Output {
+			t.Errorf("Total = %v, want the sum of the phases %+v", perf.Total, perf)
+		}
+		if perf.Total <= 0 || perf.RowsPerSecond <= 0 {
+			t.Errorf("Total = %v, RowsPerSecond = %v, want positive values", perf.Total, perf.RowsPerSecond)
+		}
+	})
+
+	t.Run("reused input", func(t *testing.T) {
+		t.Parallel()
+
+		input := "name\nalice\nbob\n"
+		var items []item
+		_, result, err := NewProcessor(fileparser.CSV).Process(strings.NewReader(input), &items)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		perf := result.Perf
+		if perf.BytesRead != int64(len(input)) || perf.PeakBufferBytes != int64(len(input)) {
+			t.Errorf("BytesRead = %d, PeakBufferBytes = %d, want %d for both", perf.BytesRead, perf.PeakBufferBytes, len(input))
+		}
+	})
+
+	t.Run("chunks share the read and parse phases", func(t *testing.T) {
+		t.Parallel()
+
+		input := "name\na\nb\nc\n"
+		var items []item
+		var perfs []PerfStats
+		err := NewProcessor(fileparser.CSV).ProcessChunks(strings.NewReader(input), &items, 2, func(_ Stream, result *ProcessResult) error {
+			perfs = append(perfs, result.Perf)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("ProcessChunks() error = %v", err)
+		}
+		if len(perfs) != 2 {
+			t.Fatalf("got %d chunks, want 2", len(perfs))
+		}
+		if perfs[0].BytesRead != int64(len(input)) || perfs[0].Read != perfs[1].Read || perfs[0].Parse != perfs[1].Parse {
+			t.Errorf("chunk perf = %+v, %+v, want the same input size, Read, and Parse", perfs[0], perfs[1])
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package fileprep
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBufferSize is the largest buffer capacity returned to bufferPool.
+// Larger buffers are dropped so that a single huge cell or line does not pin
+// memory for the lifetime of the process.
+const maxPooledBufferSize = 64 * 1024
+
+// bufferPool recycles scratch buffers used while preprocessing cells and
+// encoding output lines. Callers must not retain the buffer or its bytes
+// after returning it with putBuffer.
+//
+//nolint:gochecknoglobals // sync.Pool must be shared to be effective
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty scratch buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	buf, ok := bufferPool.Get().(*bytes.Buffer)
+	if !ok {
+		return new(bytes.Buffer)
+	}
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns a scratch buffer to the pool.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	bufferPool.Put(buf)
+}
+
+// maxPooledRowLen is the largest row capacity returned to rowPool, so a
+// single very wide row does not pin memory for the lifetime of the process.
+const maxPooledRowLen = 1024
+
+// rowPool recycles per-row scratch slices, such as the copy of a row as read
+// that template prep rules read. Callers must not retain the slice after
+// returning it with putRow.
+//
+//nolint:gochecknoglobals // sync.Pool must be shared to be effective
+var rowPool = sync.Pool{
+	New: func() any { return new([]string) },
+}
+
+// getRow returns a scratch row of length n from the pool.
+func getRow(n int) *[]string {
+	row, ok := rowPool.Get().(*[]string)
+	if !ok {
+		row = new([]string)
+	}
+	if cap(*row) < n {
+		*row = make([]string, n)
+	}
+	*row = (*row)[:n]
+	return row
+}
+
+// putRow returns a scratch row to the pool. Its cells are cleared so the
+// pool does not keep cell values alive.
+func putRow(row *[]string) {
+	if cap(*row) > maxPooledRowLen {
+		return
+	}
+	clear(*row)
+	rowPool.Put(row)
+}
@@ -0,0 +1,100 @@
+package fileprep
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGetBuffer_ReturnsEmptyBuffer(t *testing.T) {
+	t.Parallel()
+
+	buf := getBuffer()
+	buf.WriteString("leftover")
+	putBuffer(buf)
+
+	// A recycled buffer must never expose data from a previous user
+	for range 10 {
+		got := getBuffer()
+		if got.Len() != 0 {
+			t.Fatalf("getBuffer() returned buffer with %d bytes, want 0", got.Len())
+		}
+		putBuffer(got)
+	}
+}
+
+func TestPutBuffer_DropsOversizedBuffer(t *testing.T) {
+	t.Parallel()
+
+	// Must not panic and must not keep huge buffers around
+	big := bytes.NewBuffer(make([]byte, 0, maxPooledBufferSize+1))
+	putBuffer(big)
+}
+
+func TestGetRow_ReturnsClearedRow(t *testing.T) {
+	t.Parallel()
+
+	row := getRow(3)
+	copy(*row, []string{"a", "b", "c"})
+	putRow(row)
+
+	// A recycled row must have the requested length and no cells of a previous user
+	for _, n := range []int{2, 3, 5} {
+		got := getRow(n)
+		if len(*got) != n {
+			t.Fatalf("getRow(%d) returned %d cells", n, len(*got))
+		}
+		for i, cell := range *got {
+			if cell != "" {
+				t.Fatalf("getRow(%d)[%d] = %q, want empty", n, i, cell)
+			}
+		}
+		putRow(got)
+	}
+}
+
+func TestPutRow_DropsOversizedRow(t *testing.T) {
+	t.Parallel()
+
+	// Must not panic and must not keep huge rows around
+	big := make([]string, maxPooledRowLen+1)
+	putRow(&big)
+}
+
+func TestFilterRunes(t *testing.T) {
+	t.Parallel()
+
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"remove non-digits", "a1b2c3", "123"},
+		{"all kept", "12345", "12345"},
+		{"none kept", "abc", ""},
+		{"empty", "", ""},
+		{"multibyte removed", "１2あ3", "23"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := filterRunes(tt.input, isDigit); got != tt.want {
+				t.Errorf("filterRunes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterRunes_NoAllocWhenUnchanged(t *testing.T) {
+	input := "0123456789"
+	isDigit := func(r rune) bool { return r >= '0' && r <= '9' }
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = filterRunes(input, isDigit)
+	})
+	if allocs != 0 {
+		t.Errorf("filterRunes() allocated %.0f times for unchanged input, want 0", allocs)
+	}
+}
@@ -2,9 +2,12 @@ package fileprep
 
 import (
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"text/template"
 	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/text/unicode/norm"
 )
@@ -34,9 +37,19 @@ func newTrimPreprocessor() *trimPreprocessor {
 
 // Process removes leading and trailing whitespace
 func (p *trimPreprocessor) Process(value string) string {
+	// Fast path: most cells have no surrounding whitespace at all
+	if value == "" || (!isASCIISpaceOrMultiByte(value[0]) && !isASCIISpaceOrMultiByte(value[len(value)-1])) {
+		return value
+	}
 	return strings.TrimSpace(value)
 }
 
+// isASCIISpaceOrMultiByte reports whether b may start or end a whitespace sequence.
+// Non-ASCII bytes are treated conservatively because they may belong to a Unicode space.
+func isASCIISpaceOrMultiByte(b byte) bool {
+	return b >= utf8.RuneSelf || b == ' ' || (b >= '\t' && b <= '\r')
+}
+
 // Name returns the preprocessor name
 func (p *trimPreprocessor) Name() string {
 	return trimTagValue
@@ -88,6 +101,9 @@ func newLowercasePreprocessor() *lowercasePreprocessor {
 
 // Process converts value to lowercase
 func (p *lowercasePreprocessor) Process(value string) string {
+	if result, ok := convertASCIICase(value, 'A', 'Z'); ok {
+		return result
+	}
 	return strings.ToLower(value)
 }
 
@@ -106,9 +122,43 @@ func newUppercasePreprocessor() *uppercasePreprocessor {
 
 // Process converts value to uppercase
 func (p *uppercasePreprocessor) Process(value string) string {
+	if result, ok := convertASCIICase(value, 'a', 'z'); ok {
+		return result
+	}
 	return strings.ToUpper(value)
 }
 
+// convertASCIICase flips the case of bytes in [from, to] when value is pure ASCII.
+// It returns value itself without allocating when nothing needs converting, and
+// reports false for non-ASCII input so the caller can fall back to the Unicode path.
+func convertASCIICase(value string, from, to byte) (string, bool) {
+	first := -1
+	for i := range len(value) {
+		c := value[i]
+		if c >= utf8.RuneSelf {
+			return "", false
+		}
+		if first < 0 && c >= from && c <= to {
+			first = i
+		}
+	}
+	if first < 0 {
+		return value, true
+	}
+
+	var b strings.Builder
+	b.Grow(len(value))
+	b.WriteString(value[:first])
+	for i := first; i < len(value); i++ {
+		c := value[i]
+		if c >= from && c <= to {
+			c ^= 'a' - 'A'
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), true
+}
+
 // Name returns the preprocessor name
 func (p *uppercasePreprocessor) Name() string {
 	return uppercaseTagValue
@@ -142,13 +192,67 @@ type preprocessors []Preprocessor
 
 // Process applies all preprocessors in order
 func (ps preprocessors) Process(value string) string {
+	return ps.processRecord(value, nil)
+}
+
+// processRecord applies all preprocessors in order. record is the row as
+// read, for preprocessors that read other columns, or nil.
+func (ps preprocessors) processRecord(value string, record []string) string {
 	result := value
 	for _, p := range ps {
-		result = p.Process(result)
+		result = applyPreprocessor(p, result, record)
 	}
 	return result
 }
 
+// changedBy applies the preprocessors to value in order and returns the
+// names of those that changed it.
+func (ps preprocessors) changedBy(value string, record []string) []string {
+	var names []string
+	for _, p := range ps {
+		next := applyPreprocessor(p, value, record)
+		if next != value {
+			names = append(names, p.Name())
+		}
+		value = next
+	}
+	return names
+}
+
+// changedByTag reports whether a preprocessor with the tag name in the
+// chain changed value, such as truncate shortening it. Chains without one
+// are not run again.
+func (ps preprocessors) changedByTag(tag, value string, record []string) bool {
+	if !slices.ContainsFunc(ps, func(p Preprocessor) bool { return p.Name() == tag }) {
+		return false
+	}
+	return slices.Contains(ps.changedBy(value, record), tag)
+}
+
+// recordPreprocessor is a preprocessor that reads other columns of the row.
+type recordPreprocessor interface {
+	Preprocessor
+	// processRecord processes value with record, the row as read, or nil
+	processRecord(value string, record []string) string
+}
+
+// applyPreprocessor applies p to value, passing record to preprocessors
+// that read other columns.
+func applyPreprocessor(p Preprocessor, value string, record []string) string {
+	if rp, ok := p.(recordPreprocessor); ok {
+		return rp.processRecord(value, record)
+	}
+	return p.Process(value)
+}
+
+// hasTemplate reports whether ps contains a template preprocessor.
+func hasTemplate(ps preprocessors) bool {
+	return slices.ContainsFunc(ps, func(p Preprocessor) bool {
+		_, ok := p.(*templatePreprocessor)
+		return ok
+	})
+}
+
 // =============================================================================
 // String Transformation Preprocessors
 // =============================================================================
@@ -275,15 +379,7 @@ func (p *stripNewlinePreprocessor) Process(value string) string {
 	if !strings.ContainsAny(value, "\r\n") {
 		return value
 	}
-
-	var result strings.Builder
-	result.Grow(len(value))
-	for _, r := range value {
-		if r != '\r' && r != '\n' {
-			result.WriteRune(r)
-		}
-	}
-	return result.String()
+	return filterRunes(value, func(r rune) bool { return r != '\r' && r != '\n' })
 }
 
 // Name returns the preprocessor name
@@ -291,6 +387,88 @@ func (p *stripNewlinePreprocessor) Name() string {
 	return stripNewlineTagValue
 }
 
+// stripControlPreprocessor removes control characters (Unicode category Cc),
+// such as NUL, ESC, and DEL, which legacy systems leave in exported data
+type stripControlPreprocessor struct {
+	keepWhitespace bool // keep tab, line feed, and carriage return
+}
+
+// newStripControlPreprocessor creates a new strip control preprocessor
+func newStripControlPreprocessor(keepWhitespace bool) *stripControlPreprocessor {
+	return &stripControlPreprocessor{keepWhitespace: keepWhitespace}
+}
+
+// Process removes control characters from the value
+func (p *stripControlPreprocessor) Process(value string) string {
+	if strings.IndexFunc(value, p.isStripped) < 0 {
+		return value
+	}
+	return filterRunes(value, func(r rune) bool { return !p.isStripped(r) })
+}
+
+// isStripped reports whether r is removed
+func (p *stripControlPreprocessor) isStripped(r rune) bool {
+	if p.keepWhitespace && (r == '\t' || r == '\n' || r == '\r') {
+		return false
+	}
+	return unicode.IsControl(r)
+}
+
+// Name returns the preprocessor name
+func (p *stripControlPreprocessor) Name() string {
+	return stripControlTagValue
+}
+
+// stripEmojiPreprocessor removes emoji, including skin tone modifiers, flags,
+// and the joiners of emoji sequences
+type stripEmojiPreprocessor struct{}
+
+// newStripEmojiPreprocessor creates a new strip emoji preprocessor
+func newStripEmojiPreprocessor() *stripEmojiPreprocessor {
+	return &stripEmojiPreprocessor{}
+}
+
+// Process removes emoji from the value
+func (p *stripEmojiPreprocessor) Process(value string) string {
+	if !containsEmoji(value) && !strings.ContainsRune(value, variationSelector) {
+		return value
+	}
+	return stripEmoji(value)
+}
+
+// Name returns the preprocessor name
+func (p *stripEmojiPreprocessor) Name() string {
+	return stripEmojiTagValue
+}
+
+// keepBMPPreprocessor removes characters outside the Basic Multilingual
+// Plane, which take four bytes in UTF-8 and cannot be stored in columns
+// such as MySQL utf8 (utf8mb3)
+type keepBMPPreprocessor struct{}
+
+// newKeepBMPPreprocessor creates a new keep BMP preprocessor
+func newKeepBMPPreprocessor() *keepBMPPreprocessor {
+	return &keepBMPPreprocessor{}
+}
+
+// Process removes characters above U+FFFF from the value
+func (p *keepBMPPreprocessor) Process(value string) string {
+	if strings.IndexFunc(value, isAstral) < 0 {
+		return value
+	}
+	return filterRunes(value, func(r rune) bool { return !isAstral(r) })
+}
+
+// Name returns the preprocessor name
+func (p *keepBMPPreprocessor) Name() string {
+	return keepBMPTagValue
+}
+
+// isAstral reports whether r is outside the Basic Multilingual Plane
+func isAstral(r rune) bool {
+	return r > 0xffff
+}
+
 // collapseSpacePreprocessor collapses multiple spaces into one
 type collapseSpacePreprocessor struct{}
 
@@ -306,24 +484,27 @@ func (p *collapseSpacePreprocessor) Process(value string) string {
 		return value
 	}
 
-	var result strings.Builder
-	result.Grow(len(value))
+	buf := getBuffer()
+	defer putBuffer(buf)
 
 	inSpace := false
 	for _, r := range value {
 		isWhitespace := r == ' ' || r == '\t' || r == '\n' || r == '\r'
 		if isWhitespace {
 			if !inSpace {
-				result.WriteByte(' ')
+				buf.WriteByte(' ')
 				inSpace = true
 			}
 		} else {
-			result.WriteRune(r)
+			buf.WriteRune(r)
 			inSpace = false
 		}
 	}
 
-	return result.String()
+	if string(buf.Bytes()) == value {
+		return value
+	}
+	return buf.String()
 }
 
 // Name returns the preprocessor name
@@ -335,6 +516,25 @@ func (p *collapseSpacePreprocessor) Name() string {
 // Character Filtering Preprocessors
 // =============================================================================
 
+// filterRunes returns value with only the runes for which keep returns true.
+// The result is built in a pooled scratch buffer, and the input string is
+// returned as-is (without allocating) when every rune is kept.
+func filterRunes(value string, keep func(rune) bool) string {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	for _, r := range value {
+		if keep(r) {
+			buf.WriteRune(r)
+		}
+	}
+
+	if string(buf.Bytes()) == value {
+		return value
+	}
+	return buf.String()
+}
+
 // removeDigitsPreprocessor removes all digits from the value
 type removeDigitsPreprocessor struct{}
 
@@ -345,14 +545,7 @@ func newRemoveDigitsPreprocessor() *removeDigitsPreprocessor {
 
 // Process removes all digits from the value
 func (p *removeDigitsPreprocessor) Process(value string) string {
-	var result strings.Builder
-	result.Grow(len(value))
-	for _, r := range value {
-		if !unicode.IsDigit(r) {
-			result.WriteRune(r)
-		}
-	}
-	return result.String()
+	return filterRunes(value, func(r rune) bool { return !unicode.IsDigit(r) })
 }
 
 // Name returns the preprocessor name
@@ -370,14 +563,7 @@ func newRemoveAlphaPreprocessor() *removeAlphaPreprocessor {
 
 // Process removes all alphabetic characters from the value
 func (p *removeAlphaPreprocessor) Process(value string) string {
-	var result strings.Builder
-	result.Grow(len(value))
-	for _, r := range value {
-		if !unicode.IsLetter(r) {
-			result.WriteRune(r)
-		}
-	}
-	return result.String()
+	return filterRunes(value, func(r rune) bool { return !unicode.IsLetter(r) })
 }
 
 // Name returns the preprocessor name
@@ -395,14 +581,7 @@ func newKeepDigitsPreprocessor() *keepDigitsPreprocessor {
 
 // Process keeps only digits in the value
 func (p *keepDigitsPreprocessor) Process(value string) string {
-	var result strings.Builder
-	result.Grow(len(value))
-	for _, r := range value {
-		if unicode.IsDigit(r) {
-			result.WriteRune(r)
-		}
-	}
-	return result.String()
+	return filterRunes(value, func(r rune) bool { return unicode.IsDigit(r) })
 }
 
 // Name returns the preprocessor name
@@ -420,14 +599,7 @@ func newKeepAlphaPreprocessor() *keepAlphaPreprocessor {
 
 // Process keeps only alphabetic characters in the value
 func (p *keepAlphaPreprocessor) Process(value string) string {
-	var result strings.Builder
-	result.Grow(len(value))
-	for _, r := range value {
-		if unicode.IsLetter(r) {
-			result.WriteRune(r)
-		}
-	}
-	return result.String()
+	return filterRunes(value, func(r rune) bool { return unicode.IsLetter(r) })
 }
 
 // Name returns the preprocessor name
@@ -527,17 +699,31 @@ func (p *padRightPreprocessor) Name() string {
 // Advanced Preprocessors
 // =============================================================================
 
-// normalizeUnicodePreprocessor normalizes Unicode to NFC form
-type normalizeUnicodePreprocessor struct{}
+// normalizationForms maps the values of the normalize_unicode tag to their
+// forms. No value means NFC.
+//
+//nolint:gochecknoglobals // tag value table
+var normalizationForms = map[string]norm.Form{
+	"":     norm.NFC,
+	"NFC":  norm.NFC,
+	"NFD":  norm.NFD,
+	"NFKC": norm.NFKC,
+	"NFKD": norm.NFKD,
+}
+
+// normalizeUnicodePreprocessor normalizes Unicode to a normalization form
+type normalizeUnicodePreprocessor struct {
+	form norm.Form
+}
 
 // newNormalizeUnicodePreprocessor creates a new Unicode normalization preprocessor
-func newNormalizeUnicodePreprocessor() *normalizeUnicodePreprocessor {
-	return &normalizeUnicodePreprocessor{}
+func newNormalizeUnicodePreprocessor(form norm.Form) *normalizeUnicodePreprocessor {
+	return &normalizeUnicodePreprocessor{form: form}
 }
 
-// Process normalizes the value to NFC form
+// Process normalizes the value to the normalization form
 func (p *normalizeUnicodePreprocessor) Process(value string) string {
-	return norm.NFC.String(value)
+	return p.form.String(value)
 }
 
 // Name returns the preprocessor name
@@ -545,6 +731,31 @@ func (p *normalizeUnicodePreprocessor) Name() string {
 	return normalizeUnicodeTagValue
 }
 
+// sanitizeUTF8Preprocessor replaces invalid UTF-8 byte sequences, which
+// break SQLite text functions once loaded
+type sanitizeUTF8Preprocessor struct {
+	replacement string
+}
+
+// newSanitizeUTF8Preprocessor creates a preprocessor that replaces each run
+// of invalid bytes with replacement; an empty replacement removes them
+func newSanitizeUTF8Preprocessor(replacement string) *sanitizeUTF8Preprocessor {
+	return &sanitizeUTF8Preprocessor{replacement: replacement}
+}
+
+// Process replaces invalid UTF-8 sequences in the value
+func (p *sanitizeUTF8Preprocessor) Process(value string) string {
+	if utf8.ValidString(value) {
+		return value
+	}
+	return strings.ToValidUTF8(value, p.replacement)
+}
+
+// Name returns the preprocessor name
+func (p *sanitizeUTF8Preprocessor) Name() string {
+	return sanitizeUTF8TagValue
+}
+
 // nullifyPreprocessor treats a specific string as empty
 type nullifyPreprocessor struct {
 	nullValue string
@@ -677,3 +888,75 @@ func (p *regexReplacePreprocessor) Process(value string) string {
 func (p *regexReplacePreprocessor) Name() string {
 	return regexReplaceTagValue
 }
+
+// valueMapPreprocessor recodes values with a lookup table
+type valueMapPreprocessor struct {
+	mapping      map[string]string
+	defaultValue string
+	hasDefault   bool
+}
+
+// newValueMapPreprocessor creates a new value map preprocessor.
+// When hasDefault is false, unmapped values are left unchanged.
+func newValueMapPreprocessor(mapping map[string]string, defaultValue string, hasDefault bool) *valueMapPreprocessor {
+	return &valueMapPreprocessor{mapping: mapping, defaultValue: defaultValue, hasDefault: hasDefault}
+}
+
+// Process returns the mapped value, or the default for unmapped non-empty values
+func (p *valueMapPreprocessor) Process(value string) string {
+	if mapped, ok := p.mapping[value]; ok {
+		return mapped
+	}
+	if p.hasDefault && value != "" {
+		return p.defaultValue
+	}
+	return value
+}
+
+// Name returns the preprocessor name
+func (p *valueMapPreprocessor) Name() string {
+	return mapTagValue
+}
+
+// templatePreprocessor replaces the value with a text/template rendered
+// with the columns of the row, such as {{.year}}-{{.month}}-01
+type templatePreprocessor struct {
+	tmpl    *template.Template
+	headers []string // column names of the row, set by withHeaders
+}
+
+// newTemplatePreprocessor parses text as a text/template. Columns missing
+// from the row render as empty strings.
+func newTemplatePreprocessor(text string) (*templatePreprocessor, error) {
+	tmpl, err := template.New(templateTagValue).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &templatePreprocessor{tmpl: tmpl}, nil
+}
+
+// withHeaders returns a copy of the preprocessor that reads the columns
+// named by headers.
+func (p *templatePreprocessor) withHeaders(headers []string) *templatePreprocessor {
+	return &templatePreprocessor{tmpl: p.tmpl, headers: headers}
+}
+
+// Process renders the template without a row, so every column is empty
+func (p *templatePreprocessor) Process(value string) string {
+	return p.processRecord(value, nil)
+}
+
+// processRecord renders the template with the columns of record. The value
+// is left unchanged if the template fails to execute.
+func (p *templatePreprocessor) processRecord(value string, record []string) string {
+	var b strings.Builder
+	if err := p.tmpl.Execute(&b, rowMap(p.headers, record)); err != nil {
+		return value
+	}
+	return b.String()
+}
+
+// Name returns the preprocessor name
+func (p *templatePreprocessor) Name() string {
+	return templateTagValue
+}
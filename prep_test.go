@@ -16,6 +16,10 @@ func TestTrimPreprocessor(t *testing.T) {
 		{"no trim needed", "hello", "hello"},
 		{"empty string", "", ""},
 		{"only whitespace", "   ", ""},
+		{"inner whitespace kept", "hello world", "hello world"},
+		{"trailing newline", "hello\r\n", "hello"},
+		{"unicode space", "\u3000hello\u00a0", "hello"},
+		{"non-ASCII without spaces", "東京", "東京"},
 	}
 
 	prep := newTrimPreprocessor()
@@ -104,6 +108,8 @@ func TestLowercasePreprocessor(t *testing.T) {
 		{"mixed case", "HeLLo WoRLd", "hello world"},
 		{"already lowercase", "hello", "hello"},
 		{"empty string", "", ""},
+		{"digits and symbols", "ABC-123_@Z", "abc-123_@z"},
+		{"non-ASCII", "ÄPFEL École", "äpfel école"},
 	}
 
 	prep := newLowercasePreprocessor()
@@ -134,6 +140,8 @@ func TestUppercasePreprocessor(t *testing.T) {
 		{"mixed case", "HeLLo WoRLd", "HELLO WORLD"},
 		{"already uppercase", "HELLO", "HELLO"},
 		{"empty string", "", ""},
+		{"digits and symbols", "abc-123_@z", "ABC-123_@Z"},
+		{"non-ASCII", "äpfel école", "ÄPFEL ÉCOLE"},
 	}
 
 	prep := newUppercasePreprocessor()
@@ -152,6 +160,30 @@ func TestUppercasePreprocessor(t *testing.T) {
 	}
 }
 
+func TestCaseAndTrim_NoAllocWhenUnchanged(t *testing.T) {
+	// Not parallel: AllocsPerRun is unreliable with concurrent tests.
+	tests := []struct {
+		name  string
+		prep  Preprocessor
+		input string
+	}{
+		{"trim", newTrimPreprocessor(), "hello world"},
+		{"lowercase", newLowercasePreprocessor(), "hello world 123"},
+		{"uppercase", newUppercasePreprocessor(), "HELLO WORLD 123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, func() {
+				_ = tt.prep.Process(tt.input)
+			})
+			if allocs != 0 {
+				t.Errorf("Process() allocated %v times, want 0", allocs)
+			}
+		})
+	}
+}
+
 func TestDefaultPreprocessor(t *testing.T) {
 	t.Parallel()
 
@@ -395,6 +427,105 @@ func TestStripNewlinePreprocessor(t *testing.T) {
 	}
 }
 
+func TestStripControlPreprocessor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		keepWhitespace bool
+		input          string
+		want           string
+	}{
+		{"no control characters", false, "hello world", "hello world"},
+		{"NUL and ESC removed", false, "a\x00b\x1bc", "abc"},
+		{"DEL and C1 removed", false, "a\x7fb\u0085c", "abc"},
+		{"tab and newlines removed", false, "a\tb\r\nc", "abc"},
+		{"tab and newlines kept", true, "a\tb\r\nc\x00", "a\tb\r\nc"},
+		{"format characters kept", false, "a\u200bb", "a\u200bb"},
+		{"empty input", false, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			prep := newStripControlPreprocessor(tt.keepWhitespace)
+			if got := prep.Process(tt.input); got != tt.want {
+				t.Errorf("Process() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := newStripControlPreprocessor(false).Name(); got != "strip_control" {
+		t.Errorf("Name() = %q, want %q", got, "strip_control")
+	}
+}
+
+func TestStripEmojiPreprocessor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"no emoji", "hello", "hello"},
+		{"emoticon", "great job 😀", "great job "},
+		{"skin tone modifier", "👍🏽ok", "ok"},
+		{"ZWJ family sequence", "a👨\u200d👩\u200d👧b", "ab"},
+		{"flag", "🇯🇵 Tokyo", " Tokyo"},
+		{"BMP emoji with VS16", "☀\ufe0f sunny", " sunny"},
+		{"keycap keeps the digit", "1\ufe0f\u20e3", "1"},
+		{"ZWJ in Devanagari kept", "क्\u200dष", "क्\u200dष"},
+		{"CJK kept", "日本語", "日本語"},
+		{"empty input", "", ""},
+	}
+
+	prep := newStripEmojiPreprocessor()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := prep.Process(tt.input); got != tt.want {
+				t.Errorf("Process() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if prep.Name() != "strip_emoji" {
+		t.Errorf("Name() = %q, want %q", prep.Name(), "strip_emoji")
+	}
+}
+
+func TestKeepBMPPreprocessor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"BMP only", "héllo 日本語 ☀", "héllo 日本語 ☀"},
+		{"emoji removed", "ok 😀!", "ok !"},
+		{"rare CJK ideograph removed", "𠮷野家", "野家"},
+		{"empty input", "", ""},
+	}
+
+	prep := newKeepBMPPreprocessor()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := prep.Process(tt.input); got != tt.want {
+				t.Errorf("Process() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if prep.Name() != "keep_bmp" {
+		t.Errorf("Name() = %q, want %q", prep.Name(), "keep_bmp")
+	}
+}
+
 func TestCollapseSpacePreprocessor(t *testing.T) {
 	t.Parallel()
 
@@ -667,31 +798,67 @@ func TestNormalizeUnicodePreprocessor(t *testing.T) {
 
 	tests := []struct {
 		name  string
+		form  string
 		input string
 		want  string
 	}{
-		{"already NFC", "hello", "hello"},
-		{"decomposed e-acute", "e\u0301", "é"},
-		{"japanese dakuten", "か\u3099", "が"},
-		{"empty input", "", ""},
+		{"already NFC", "", "hello", "hello"},
+		{"decomposed e-acute", "", "e\u0301", "é"},
+		{"japanese dakuten", "", "か\u3099", "が"},
+		{"empty input", "", "", ""},
+		{"NFC keeps compatibility characters", "NFC", "ｶ①ﬁ", "ｶ①ﬁ"},
+		{"NFD decomposes", "NFD", "é", "e\u0301"},
+		{"NFKC folds compatibility characters", "NFKC", "ｶ①ﬁ", "カ1fi"},
+		{"NFKD folds and decomposes", "NFKD", "ｶﾞé", "カ\u3099e\u0301"},
 	}
 
-	prep := newNormalizeUnicodePreprocessor()
-
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
+			prep := newNormalizeUnicodePreprocessor(normalizationForms[tt.form])
 			if got := prep.Process(tt.input); got != tt.want {
 				t.Errorf("Process() = %q, want %q", got, tt.want)
 			}
 		})
 	}
 
-	if prep.Name() != "normalize_unicode" {
+	if prep := newNormalizeUnicodePreprocessor(normalizationForms[""]); prep.Name() != "normalize_unicode" {
 		t.Errorf("Name() = %q, want %q", prep.Name(), "normalize_unicode")
 	}
 }
 
+func TestSanitizeUTF8Preprocessor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		replacement string
+		input       string
+		want        string
+	}{
+		{"valid input unchanged", "\uFFFD", "héllo", "héllo"},
+		{"invalid byte replaced", "\uFFFD", "caf\xe9", "caf\uFFFD"},
+		{"run of invalid bytes replaced once", "\uFFFD", "a\xff\xfeb", "a\uFFFDb"},
+		{"truncated sequence replaced", "\uFFFD", "\xe3\x81", "\uFFFD"},
+		{"invalid bytes removed", "", "caf\xe9 au lait", "caf au lait"},
+		{"empty input", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			prep := newSanitizeUTF8Preprocessor(tt.replacement)
+			if got := prep.Process(tt.input); got != tt.want {
+				t.Errorf("Process() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := newSanitizeUTF8Preprocessor("").Name(); got != "sanitize_utf8" {
+		t.Errorf("Name() = %q, want %q", got, "sanitize_utf8")
+	}
+}
+
 func TestNullifyPreprocessor(t *testing.T) {
 	t.Parallel()
 
@@ -873,6 +1040,10 @@ func TestParsePrepTag_NewPreprocessors(t *testing.T) {
 		{"truncate", "truncate=10", 1, false},
 		{"strip_html", "strip_html", 1, false},
 		{"strip_newline", "strip_newline", 1, false},
+		{"strip_control", "strip_control", 1, false},
+		{"strip_control keep_whitespace", "strip_control=keep_whitespace", 1, false},
+		{"strip_emoji", "strip_emoji", 1, false},
+		{"keep_bmp", "keep_bmp", 1, false},
 		{"collapse_space", "collapse_space", 1, false},
 
 		// Character filtering preprocessors
@@ -889,12 +1060,21 @@ func TestParsePrepTag_NewPreprocessors(t *testing.T) {
 
 		// Advanced preprocessors
 		{"normalize_unicode", "normalize_unicode", 1, false},
+		{"sanitize_utf8", "sanitize_utf8", 1, false},
+		{"sanitize_utf8 remove", "sanitize_utf8=remove", 1, false},
 		{"nullify", "nullify=NA", 1, false},
 		{"coerce int", "coerce=int", 1, false},
 		{"coerce float", "coerce=float", 1, false},
 		{"coerce bool", "coerce=bool", 1, false},
 		{"fix_scheme", "fix_scheme=https", 1, false},
 		{"regex_replace", "regex_replace=\\d+:X", 1, false},
+		{"percent_to_decimal", "percent_to_decimal", 1, false},
+		{"bp_to_decimal", "bp_to_decimal", 1, false},
+		{"unit", "unit=kg", 1, false},
+		{"round", "round=2", 1, false},
+		{"round half_even", "round=0:half_even", 1, false},
+		{"clamp", "clamp=0:100", 1, false},
+		{"winsorize", "winsorize=5:95", 1, false},
 
 		// Combinations
 		{"multiple", "trim,lowercase,prefix=pre_", 3, false},
@@ -902,6 +1082,10 @@ func TestParsePrepTag_NewPreprocessors(t *testing.T) {
 		// Invalid cases
 		{"invalid truncate", "truncate=abc", 0, false},
 		{"invalid coerce", "coerce=invalid", 0, false},
+		{"invalid sanitize_utf8", "sanitize_utf8=drop", 0, false},
+		{"invalid unit", "unit=stone", 0, false},
+		{"invalid round", "round=two", 0, false},
+		{"invalid clamp", "clamp=100:0", 0, false},
 		{"unknown tag", "unknown_tag", 0, true},
 	}
 
@@ -919,3 +1103,115 @@ func TestParsePrepTag_NewPreprocessors(t *testing.T) {
 		})
 	}
 }
+
+func TestValueMapPreprocessor(t *testing.T) {
+	t.Parallel()
+
+	mapping := map[string]string{"active": "1", "inactive": "0"}
+
+	tests := []struct {
+		name       string
+		hasDefault bool
+		input      string
+		want       string
+	}{
+		{"mapped value", false, "active", "1"},
+		{"unmapped value is kept", false, "pending", "pending"},
+		{"unmapped value gets default", true, "pending", "NULL"},
+		{"empty value is kept", true, "", ""},
+		{"case sensitive", false, "Active", "Active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			prep := newValueMapPreprocessor(mapping, "NULL", tt.hasDefault)
+			if got := prep.Process(tt.input); got != tt.want {
+				t.Errorf("Process() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	prep := newValueMapPreprocessor(mapping, "", false)
+	if prep.Name() != "map" {
+		t.Errorf("Name() = %q, want %q", prep.Name(), "map")
+	}
+}
+
+func TestTemplatePreprocessor(t *testing.T) {
+	t.Parallel()
+
+	headers := []string{"year", "month", "unit price", "date"}
+	tests := []struct {
+		name   string
+		text   string
+		record []string
+		want   string
+	}{
+		{"columns of the row", "{{.year}}-{{.month}}-01", []string{"2024", "03", "", ""}, "2024-03-01"},
+		{"column name with a space", `{{index . "unit price"}} JPY`, []string{"", "", "100", ""}, "100 JPY"},
+		{"missing column is empty", "{{.year}}/{{.day}}", []string{"2024", "", "", ""}, "2024/"},
+		{"short record", "{{.year}}-{{.month}}", []string{"2024"}, "2024-"},
+		{"template functions", `{{printf "%s-%02s" .year .month}}`, []string{"2024", "3", "", ""}, "2024-03"},
+		{"execution error keeps the value", "{{.year.x}}", []string{"2024", "", "", "old"}, "old"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tp, err := newTemplatePreprocessor(tt.text)
+			if err != nil {
+				t.Fatalf("newTemplatePreprocessor(%q) error = %v", tt.text, err)
+			}
+			if got := tp.withHeaders(headers).processRecord("old", tt.record); got != tt.want {
+				t.Errorf("processRecord() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	tp, err := newTemplatePreprocessor("{{.year}}-01")
+	if err != nil {
+		t.Fatalf("newTemplatePreprocessor() error = %v", err)
+	}
+	if got := tp.Process("2024"); got != "-01" {
+		t.Errorf("Process() without a row = %q, want %q", got, "-01")
+	}
+	if tp.Name() != "template" {
+		t.Errorf("Name() = %q, want %q", tp.Name(), "template")
+	}
+}
+
+func TestScaledUnitPreprocessor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		prep  Preprocessor
+		input string
+		want  string
+	}{
+		{"percent", newPercentToDecimalPreprocessor(), "12.5%", "0.125"},
+		{"percent with space", newPercentToDecimalPreprocessor(), " 12.5 % ", "0.125"},
+		{"whole percent", newPercentToDecimalPreprocessor(), "100%", "1"},
+		{"small percent", newPercentToDecimalPreprocessor(), "0.5%", "0.005"},
+		{"negative percent", newPercentToDecimalPreprocessor(), "-3%", "-0.03"},
+		{"zero percent", newPercentToDecimalPreprocessor(), "0%", "0"},
+		{"percent without rounding", newPercentToDecimalPreprocessor(), "33.333333333333333333%", "0.33333333333333333333"},
+		{"number without suffix is kept", newPercentToDecimalPreprocessor(), "12.5", "12.5"},
+		{"text is kept", newPercentToDecimalPreprocessor(), "n/a%", "n/a%"},
+		{"empty is kept", newPercentToDecimalPreprocessor(), "", ""},
+		{"basis points", newBPToDecimalPreprocessor(), "125bp", "0.0125"},
+		{"basis points plural", newBPToDecimalPreprocessor(), "25 BPS", "0.0025"},
+		{"basis point sign", newBPToDecimalPreprocessor(), "10000‱", "1"},
+		{"percent is not basis points", newBPToDecimalPreprocessor(), "12%", "12%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.prep.Process(tt.input); got != tt.want {
+				t.Errorf("%s.Process(%q) = %q, want %q", tt.prep.Name(), tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,20 +4,109 @@ import (
 	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nao1215/fileparser"
 )
 
-// Processor handles preprocessing and validation of file data
+// Processor handles preprocessing and validation of file data.
+// A Processor is configured once by NewProcessor and is not modified by
+// Process or the other methods, so one Processor can be shared by many
+// goroutines processing different inputs at the same time.
 type Processor struct {
 	fileType         fileparser.FileType
 	strictTagParsing bool
 	validRowsOnly    bool
+	startRow         int
+	expectedColumns  []string
+
+	// head, tail, and everyNth reduce the rows of the output stream; 0 keeps all rows
+	head     int
+	tail     int
+	everyNth int
+
+	// anonymize rewrites columns of the output stream with fake data
+	anonymize     []anonymizedColumn
+	anonymizeSeed string
+
+	// outputColumns and structColumnOrder select the output column order.
+	// When both are unset, columns keep their order from the file.
+	outputColumns     []string
+	structColumnOrder bool
+	ltsvKeyOrder      LTSVKeyOrder
+
+	// outputType replaces the default output format when outputTypeSet is true
+	outputType     fileparser.FileType
+	outputTypeSet  bool
+	xlsxErrorSheet bool
+
+	csvOpts  csvParseOptions
+	xlsxOpts xlsxParseOptions
+
+	disabledValidators map[string]bool
+	warningValidators  map[string]bool
+	strictValidation   bool
+
+	duplicateKeys   []string
+	groupValidators []groupValidator
+	filters         []string
+	columnTypes     map[string]ColumnType
+	tableName       string
+
+	// valueLists are the lists loaded with WithValueList, by name;
+	// valueListErr is the first error reading one
+	valueLists   map[string][]string
+	valueListErr error
+
+	sqlHeaderCheck      bool
+	headerRules         []string
+	sanitizeHeaderNames bool
+	dedupHeaderNames    bool
+
+	// transforms add or rewrite columns before struct fields are bound
+	transforms []columnTransform
+
+	// schemaFields are column rules compiled by NewProcessorFromJSONSchema
+	schemaFields []fieldInfo
+
+	rowNumberColumn string
+	sourceColumn    string
+	ltsvExpandDots  bool
+	jsonRecordPath  string
+
+	// maxBytes and maxRows guard against oversized input; 0 means no limit
+	maxBytes int64
+	maxRows  int
+
+	// workers is the number of goroutines that run the first pass over rows
+	workers int
+
+	rejectHeaderOnly bool
+	profile          Profile
+
+	rawCellHook func(row, col int, value string) string
+
+	changeTracking      bool
+	keepRows            bool // keep the processed rows for ProcessResult.Rows
+	keepSource          bool // keep the input for ProcessResult.WriteAnnotated
+	fixSuggestions      bool
+	redactAll           bool
+	redactColumns       []string
+	checkIdempotentPrep bool
+	omitEmpty           bool
+	locales             []columnLocale
+	conversionAsInvalid bool
+	onConversionError   ConversionFallback
+
+	fetch fetchConfig
 }
 
 // Option configures a Processor.
@@ -53,9 +142,1256 @@ func WithValidRowsOnly() Option {
 	}
 }
 
+// WithHead limits the output io.Reader to the first n rows, for previews
+// and test fixtures cut from production files. All rows are still
+// preprocessed and validated, and the struct slice and ProcessResult cover
+// all of them. With WithValidRowsOnly, the first n valid rows are kept.
+// An n below 1 keeps all rows. WithEveryNth is applied before WithHead and
+// WithTail. ProcessChunks returns an error wrapping ErrInvalidOption if the
+// option is set.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithHead(100))
+//	reader, _, err := processor.Process(input, &records)
+//	// reader holds the header and the first 100 cleaned rows
+func WithHead(n int) Option {
+	return func(p *Processor) {
+		p.head = max(n, 0)
+	}
+}
+
+// WithTail limits the output io.Reader to the last n rows. It is applied
+// after WithHead, and like WithHead it only affects the output stream.
+// An n below 1 keeps all rows.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithTail(10))
+func WithTail(n int) Option {
+	return func(p *Processor) {
+		p.tail = max(n, 0)
+	}
+}
+
+// WithEveryNth keeps every k-th row of the output io.Reader, starting with
+// the first, for a systematic sample of a large file. It is applied before
+// WithHead and WithTail, so WithEveryNth(10) with WithHead(100) keeps 100
+// rows spread over the first 1000. Like WithHead it only affects the output
+// stream. A k below 2 keeps all rows.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithEveryNth(100))
+func WithEveryNth(k int) Option {
+	return func(p *Processor) {
+		p.everyNth = max(k, 0)
+	}
+}
+
+// WithFilter keeps only the rows for which expr is true, so unwanted rows
+// never reach the output instead of being deleted after loading. Rows that
+// are dropped are not validated, not added to the struct slice, and not
+// counted in RowCount; ProcessResult.FilteredRowCount counts them. expr is
+// evaluated on the values as read, before prep tags, and can use:
+//   - column names, bare like age or in brackets like [unit price]
+//   - 'single' or "double" quoted strings and numbers
+//   - comparisons ==, !=, <, <=, >, >=, which compare numbers when both
+//     sides are numbers and strings otherwise
+//   - =~ and !~ with a quoted regular expression
+//   - in, as in country in ('JP', 'KR')
+//   - &&, ||, !, and parentheses
+//
+// The option can be given more than once to keep rows matching every
+// expression. Process returns an error wrapping ErrInvalidOption for a
+// malformed expression, one wrapping ErrColumnNotFound for a column that is
+// not in the header, and one wrapping ErrUnsupportedFileType for JSON input.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithFilter("age >= 18 && country == 'JP'"))
+func WithFilter(expr string) Option {
+	return func(p *Processor) {
+		p.filters = append(slices.Clip(p.filters), expr)
+	}
+}
+
+// WithAnonymizedColumn replaces the values of column in the output
+// io.Reader with realistic fake data of the given kind, so files cleaned by
+// the prep and validate tags can be shared as test fixtures without
+// personal data. Fake values keep the format of the originals and are
+// deterministic: the same value always gets the same fake value, so the
+// output is reproducible and keys still join across files. Empty values
+// stay empty. The struct slice and ProcessResult keep the real values.
+// Process returns an error if column is not in the header, or if the input
+// is JSON or JSONL.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithAnonymizedColumn("name", fileprep.AnonymizeName),
+//	    fileprep.WithAnonymizedColumn("email", fileprep.AnonymizeEmail),
+//	    fileprep.WithAnonymizedColumn("phone", fileprep.AnonymizePhone))
+func WithAnonymizedColumn(column string, kind AnonymizeKind) Option {
+	return func(p *Processor) {
+		p.anonymize = append(slices.Clip(p.anonymize), anonymizedColumn{column: column, kind: kind})
+	}
+}
+
+// WithAnonymizeSeed sets a secret mixed into the fake values of
+// WithAnonymizedColumn. Without it, anyone holding a list of candidate
+// values can recompute their fake values and match them; with it, fake
+// values only repeat across runs that use the same seed.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithAnonymizedColumn("email", fileprep.AnonymizeEmail),
+//	    fileprep.WithAnonymizeSeed(os.Getenv("FIXTURE_SEED")))
+func WithAnonymizeSeed(seed string) Option {
+	return func(p *Processor) {
+		p.anonymizeSeed = seed
+	}
+}
+
+// WithStartRow configures the Processor to skip the first n data rows
+// (the header is not counted). Skipped rows are neither preprocessed nor
+// validated, and are omitted from the output io.Reader, the struct slice,
+// and ProcessResult.RowCount. Row numbers in errors and in
+// Stream.RowOffsets still refer to the full input, so a failed load can be
+// resumed by passing the Row of the last committed row. A negative n is
+// treated as 0.
+//
+// Example:
+//
+//	// Rows 1-500 were committed before the previous run failed
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithStartRow(500))
+//	reader, result, err := processor.Process(input, &records)
+//	// records[0] holds row 501
+func WithStartRow(n int) Option {
+	return func(p *Processor) {
+		p.startRow = max(n, 0)
+	}
+}
+
+// WithExpectedColumns configures the Processor to compare the file's header
+// with the given column names before any row is processed. If columns are
+// missing, unexpected, or in a different order, Process returns a
+// *SchemaError (matching ErrSchemaMismatch) so that pipelines can fail fast
+// on upstream format changes.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithExpectedColumns("id", "name", "email"))
+//	_, _, err := processor.Process(input, &records)
+//	if errors.Is(err, fileprep.ErrSchemaMismatch) {
+//	    // vendor changed the file layout
+//	}
+func WithExpectedColumns(columns ...string) Option {
+	return func(p *Processor) {
+		p.expectedColumns = slices.Clone(columns)
+	}
+}
+
+// WithOutputColumnOrder configures the order of columns in the output stream.
+// The listed columns are written first, in the given order, followed by any
+// remaining columns in file order. Process returns an error if a listed
+// column is not in the header. The struct slice and ProcessResult.Columns
+// are not affected. JSON/JSONL output has a single column and ignores this option.
+//
+// Example:
+//
+//	// Match the column order of an existing CREATE TABLE statement
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithOutputColumnOrder([]string{"id", "email", "name"}))
+func WithOutputColumnOrder(columns []string) Option {
+	return func(p *Processor) {
+		p.outputColumns = slices.Clone(columns)
+		p.structColumnOrder = false
+	}
+}
+
+// LTSVKeyOrder selects the order of keys in each line of LTSV output.
+// The zero value is LTSVFileOrder.
+type LTSVKeyOrder struct {
+	sorted bool
+	keys   []string
+}
+
+// LTSVFileOrder writes keys in the order they are first seen in the input.
+// This is the default.
+func LTSVFileOrder() LTSVKeyOrder {
+	return LTSVKeyOrder{}
+}
+
+// LTSVSortedOrder writes keys in lexical order, so the output is stable
+// however the input lines order their keys.
+func LTSVSortedOrder() LTSVKeyOrder {
+	return LTSVKeyOrder{sorted: true}
+}
+
+// LTSVCustomOrder writes the given keys first, in the given order, followed
+// by the remaining keys in file order. Keys that do not occur in the input
+// are skipped, since LTSV lines may omit keys.
+func LTSVCustomOrder(keys ...string) LTSVKeyOrder {
+	return LTSVKeyOrder{keys: slices.Clone(keys)}
+}
+
+// WithLTSVKeyOrder configures the key order of LTSV output, for stable
+// output in diff-based tests and for consumers that expect a fixed order.
+// It only applies to LTSV input. WithOutputColumnOrder and
+// WithStructColumnOrder take precedence over it.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.LTSV,
+//	    fileprep.WithLTSVKeyOrder(fileprep.LTSVSortedOrder()))
+func WithLTSVKeyOrder(order LTSVKeyOrder) Option {
+	return func(p *Processor) {
+		p.ltsvKeyOrder = order
+	}
+}
+
+// WithLTSVExpandDots expands LTSV values that are themselves lists of
+// key:value items into one column per key, named label.key. For example,
+// request:method:GET,path:/ becomes the columns request.method and
+// request.path, in place of request. A column is expanded only when all of
+// its non-empty values are such lists; item keys start with a letter or
+// underscore, and values starting with // (URLs) are never expanded. Lines
+// without a key get an empty value.
+//
+// Expansion runs before the other column transforms and struct binding, so
+// fields bind to the dotted names with a name tag. The option only applies
+// to LTSV input.
+//
+// Example:
+//
+//	type Access struct {
+//	    Method string `name:"request.method"`
+//	    Path   string `name:"request.path"`
+//	}
+//	processor := fileprep.NewProcessor(fileparser.LTSV, fileprep.WithLTSVExpandDots())
+func WithLTSVExpandDots() Option {
+	return func(p *Processor) {
+		p.ltsvExpandDots = true
+	}
+}
+
+// WithJSONRecordPath locates the array of records inside a JSON document
+// whose top-level value is not the array itself, such as an API response
+// {"meta":{...},"items":[...]}. The path is either a JSONPath expression
+// rooted at $ using member and index steps ($.items[*], $.data['records'],
+// $.pages[0].items) or a JSON Pointer (/data/items). An invalid path, or one
+// that does not lead to an array, makes Process return an error. The option
+// only applies to JSON input.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.JSON, fileprep.WithJSONRecordPath("$.items[*]"))
+func WithJSONRecordPath(path string) Option {
+	return func(p *Processor) {
+		p.jsonRecordPath = path
+	}
+}
+
+// WithStructColumnOrder configures the output stream to list the columns
+// bound to struct fields first, in struct field order, followed by any
+// remaining columns in file order.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithStructColumnOrder())
+func WithStructColumnOrder() Option {
+	return func(p *Processor) {
+		p.structColumnOrder = true
+		p.outputColumns = nil
+	}
+}
+
+// WithOutputFormat writes the output stream of Process and ProcessChunks
+// as FileTypeCSV, FileTypeTSV, FileTypeLTSV, or FileTypeXLSX, in place of
+// the format chosen from the input type. XLSX output is a workbook with a
+// bold header row and the data on one sheet, for returning cleaned files to
+// spreadsheet users; see WithXLSXErrorSheet. Process returns an error
+// wrapping ErrInvalidOption for other types, and one wrapping
+// ErrUnsupportedFileType for JSON and JSONL input, whose output stays JSONL.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithOutputFormat(fileprep.FileTypeXLSX), fileprep.WithXLSXErrorSheet())
+func WithOutputFormat(ft FileType) Option {
+	return func(p *Processor) {
+		p.outputType = ft
+		p.outputTypeSet = true
+	}
+}
+
+// WithXLSXErrorSheet highlights the cells of XLSX output that have errors
+// and adds an "Errors" sheet listing the row, column, tag, value, and
+// message of each error, so the recipient of a returned file can see what
+// to fix. Warnings are not listed. It has no effect on other output formats.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithOutputFormat(fileprep.FileTypeXLSX), fileprep.WithXLSXErrorSheet())
+func WithXLSXErrorSheet() Option {
+	return func(p *Processor) {
+		p.xlsxErrorSheet = true
+	}
+}
+
+// WithLazyQuotes relaxes CSV/TSV quote handling: a quote may appear in an
+// unquoted field, and a non-doubled quote may appear in a quoted field.
+// This mirrors csv.Reader.LazyQuotes and helps with hand-written files.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithLazyQuotes())
+func WithLazyQuotes() Option {
+	return func(p *Processor) {
+		p.csvOpts.lazyQuotes = true
+	}
+}
+
+// WithBackslashEscapes configures CSV/TSV parsing to accept backslash-escaped
+// quotes (\") and backslashes (\\) inside quoted fields, as produced by
+// some database exports, in addition to RFC 4180 doubled quotes.
+// The output stream always uses RFC 4180 quoting.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithBackslashEscapes())
+func WithBackslashEscapes() Option {
+	return func(p *Processor) {
+		p.csvOpts.backslashEscapes = true
+	}
+}
+
+// WithDisallowQuotedNewlines makes Process reject CSV/TSV input whose quoted
+// fields contain line breaks. The returned error wraps ErrQuotedNewline and
+// names the row and column, which helps locate unbalanced quotes that would
+// otherwise silently merge several lines into one field.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithDisallowQuotedNewlines())
+//	_, _, err := processor.Process(input, &records)
+//	if errors.Is(err, fileprep.ErrQuotedNewline) {
+//	    // err reads e.g. `row 12, column "note": quoted field contains a line break`
+//	}
+func WithDisallowQuotedNewlines() Option {
+	return func(p *Processor) {
+		p.csvOpts.disallowQuotedNewlines = true
+	}
+}
+
+// WithDelimiter sets the field delimiter of CSV input, for files that use a
+// semicolon or a pipe instead of a comma. The output stream is written with
+// commas, so it can be loaded by consumers that expect standard CSV.
+// TSV input always uses tabs.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithDelimiter(';'))
+func WithDelimiter(delimiter rune) Option {
+	return func(p *Processor) {
+		p.csvOpts.delimiter = delimiter
+	}
+}
+
+// WithExcelFormulasAsEmpty reads XLSX formula cells as empty strings
+// instead of the value cached by the spreadsheet application, for workbooks
+// whose cached values may be stale or missing. It takes precedence over
+// WithExcelErrorPolicy for formulas that evaluate to an error.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.XLSX, fileprep.WithExcelFormulasAsEmpty())
+func WithExcelFormulasAsEmpty() Option {
+	return func(p *Processor) {
+		p.xlsxOpts.formulasAsEmpty = true
+	}
+}
+
+// WithExcelErrorPolicy configures how XLSX error cells such as #N/A and
+// #DIV/0! are read: kept as their error text (the default), replaced with an
+// empty string or a sentinel value, or reported as validation errors.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.XLSX,
+//	    fileprep.WithExcelErrorPolicy(fileprep.ExcelErrorInvalid()))
+func WithExcelErrorPolicy(policy ExcelErrorPolicy) Option {
+	return func(p *Processor) {
+		p.xlsxOpts.errorPolicy = policy
+	}
+}
+
+// WithFillMergedCells copies the value of every merged cell range in an
+// XLSX sheet into all the cells it covers. Spreadsheets store the value of a
+// merged range only in its top-left cell, so without this option the other
+// rows of a vertically merged group read as empty. Merged header cells
+// repeat their name, so combine the option with WithDedupHeaders when
+// header cells are merged across columns.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.XLSX, fileprep.WithFillMergedCells())
+func WithFillMergedCells() Option {
+	return func(p *Processor) {
+		p.xlsxOpts.fillMergedCells = true
+	}
+}
+
+// WithExcelDateLayout formats XLSX cells that have a date or time number
+// format with the given Go time layout, such as "2006-01-02", instead of the
+// cell's own display format, which depends on the workbook's locale
+// settings. Serial numbers are read in the workbook's date system, so
+// workbooks using the 1904 system (common on older Mac versions of Excel)
+// give the same dates as the 1900 system. Other cells are unaffected.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.XLSX, fileprep.WithExcelDateLayout("2006-01-02"))
+func WithExcelDateLayout(layout string) Option {
+	return func(p *Processor) {
+		p.xlsxOpts.dateLayout = layout
+	}
+}
+
+// WithoutValidators disables the named validators (e.g. "email", "url",
+// "eqfield") for every field, without editing struct tags. This is useful
+// for one-off runs such as a legacy backfill with known-bad values.
+// Names that do not match any validator are ignored. Calling the option
+// more than once disables the union of the given names.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithoutValidators("email", "url"))
+func WithoutValidators(names ...string) Option {
+	return func(p *Processor) {
+		if p.disabledValidators == nil {
+			p.disabledValidators = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			p.disabledValidators[name] = true
+		}
+	}
+}
+
+// WithWarningValidators demotes the named validators from validate tags to
+// warning level for every field. Their failures are reported in
+// ProcessResult.Warnings instead of ProcessResult.Errors and do not reduce
+// ValidRowCount. Use the warn struct tag to declare warning-level rules
+// directly. Calling the option more than once demotes the union of the
+// given names.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithWarningValidators("max"))
+//	_, result, err := processor.Process(input, &records)
+//	for _, w := range result.Warnings {
+//	    log.Printf("warning: %v", w)
+//	}
+func WithWarningValidators(names ...string) Option {
+	return func(p *Processor) {
+		if p.warningValidators == nil {
+			p.warningValidators = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			p.warningValidators[name] = true
+		}
+	}
+}
+
+// WithStrictValidation makes Process fail when any row has validation or
+// preprocessing errors. In that case Process returns a nil io.Reader, the
+// ProcessResult, and a *MultiError holding every error from
+// ProcessResult.Errors, so standard errors.Is/errors.As handling works.
+// Warnings never cause a failure.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithStrictValidation())
+//	reader, result, err := processor.Process(input, &records)
+//	if errors.Is(err, fileprep.ErrValidation) {
+//	    // at least one row failed validation; result holds the details
+//	}
+func WithStrictValidation() Option {
+	return func(p *Processor) {
+		p.strictValidation = true
+	}
+}
+
+// WithDuplicateReport reports rows that share the same values in the given
+// key columns. Each group of duplicates is recorded in
+// ProcessResult.Duplicates with its key, the first row, and the rows that
+// repeat it. Keys are compared after preprocessing. Duplicates are not
+// errors: the output stream, the struct slice, and ValidRowCount are not
+// changed. Process returns an error if a key column is not in the header.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithDuplicateReport("customer_id", "order_date"))
+//	_, result, err := processor.Process(input, &orders)
+//	for _, d := range result.Duplicates {
+//	    fmt.Printf("key %v first seen at row %d, repeated at rows %v\n", d.Key, d.FirstRow, d.DuplicateRows)
+//	}
+func WithDuplicateReport(keyColumns ...string) Option {
+	return func(p *Processor) {
+		p.duplicateKeys = slices.Clone(keyColumns)
+	}
+}
+
+// WithGroupValidator groups rows by the value of keyColumn and calls
+// validate once per group with its rows, as maps from column name to
+// preprocessed value, so rules spanning rows can be checked: exactly one
+// primary contact per company, or line numbers without gaps per order.
+// Every error validate returns is reported on each row of the group as a
+// ValidationError with tag "group", the key column, and a message listing
+// the rows, and those rows count as invalid. Rows with an empty key are not
+// grouped. The option can be given more than once.
+//
+// Group checks run after every row was validated, so their errors follow
+// the per-row errors in ProcessResult.Errors. ProcessChunks checks the rows
+// of each chunk separately. Process returns an error if keyColumn is not in
+// the header.
+//
+// Example:
+//
+//	onePrimary := func(rows []map[string]string) []error {
+//	    primaries := 0
+//	    for _, row := range rows {
+//	        if row["role"] == "primary" {
+//	            primaries++
+//	        }
+//	    }
+//	    if primaries != 1 {
+//	        return []error{fmt.Errorf("company needs exactly one primary contact, has %d", primaries)}
+//	    }
+//	    return nil
+//	}
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithGroupValidator("company_id", onePrimary))
+func WithGroupValidator(keyColumn string, validate func(rows []map[string]string) []error) Option {
+	return func(p *Processor) {
+		p.groupValidators = append(slices.Clip(p.groupValidators), groupValidator{keyColumn: keyColumn, validate: validate})
+	}
+}
+
+// WithColumnTypes sets the type of columns in one place instead of coerce
+// and number tags scattered across a struct. Each value is trimmed and
+// rewritten in the canonical form of its type, such as "1.0" as "1" for
+// ColumnInt or "1.5" as "1.50" for ColumnDecimal(2), before the prep tags
+// of the field bound to the column run, and values that are not of the type
+// are reported as ValidationErrors tagged with the type name. Empty values
+// are accepted; add required to the field to reject them. Columns no field
+// is bound to are checked too. The option can be given more than once; a
+// later type for a column replaces an earlier one.
+//
+// Process returns an error wrapping ErrColumnNotFound if a column is not in
+// the header, and one wrapping ErrInvalidOption for the zero ColumnType or
+// a negative decimal scale.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithColumnTypes(map[string]fileprep.ColumnType{
+//	        "age":    fileprep.ColumnInt(),
+//	        "price":  fileprep.ColumnDecimal(2),
+//	        "active": fileprep.ColumnBool(),
+//	    }))
+func WithColumnTypes(types map[string]ColumnType) Option {
+	return func(p *Processor) {
+		columnTypes := maps.Clone(p.columnTypes)
+		if columnTypes == nil {
+			columnTypes = make(map[string]ColumnType, len(types))
+		}
+		maps.Copy(columnTypes, types)
+		p.columnTypes = columnTypes
+	}
+}
+
+// WithValueList loads a named list of values for validators that refer to
+// it by name: not_in_list=badwords.txt and oneof_file=prefectures.txt. Word
+// lists and large enumerations can then be kept in files and updated
+// without changing struct tags or recompiling. The list is read from r when
+// the option is applied: one value per line, with surrounding whitespace
+// trimmed, skipping blank lines and lines starting with #. The name is only
+// a key and need not be a file name. A later list of the same name replaces
+// an earlier one.
+//
+// Process returns an error wrapping ErrInvalidOption if r could not be read
+// or if a tag refers to a list that was not loaded.
+//
+// Example:
+//
+//	f, _ := os.Open("badwords.txt")
+//	defer f.Close()
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithValueList("badwords.txt", f))
+func WithValueList(name string, r io.Reader) Option {
+	return func(p *Processor) {
+		values, err := readValueList(r)
+		if err != nil {
+			if p.valueListErr == nil {
+				p.valueListErr = fmt.Errorf("%w: value list %q: %w", ErrInvalidOption, name, err)
+			}
+			return
+		}
+		valueLists := maps.Clone(p.valueLists)
+		if valueLists == nil {
+			valueLists = make(map[string][]string)
+		}
+		valueLists[name] = values
+		p.valueLists = valueLists
+	}
+}
+
+// WithSQLHeaderCheck reports column names that cannot be used as SQLite
+// column names without quoting: SQLite keywords, names with characters
+// other than letters, digits, and underscores (or starting with a digit),
+// and names equal to an earlier one when case is ignored. Issues are listed
+// in ProcessResult.HeaderIssues with the name WithSanitizeHeaders would use.
+// They are not errors and do not affect processing.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithSQLHeaderCheck())
+//	_, result, err := processor.Process(input, &records)
+//	for _, issue := range result.HeaderIssues {
+//	    fmt.Printf("column %q: %s (suggest %q)\n", issue.Column, issue.Reason, issue.Suggestion)
+//	}
+func WithSQLHeaderCheck() Option {
+	return func(p *Processor) {
+		p.sqlHeaderCheck = true
+	}
+}
+
+// WithHeaderRules checks the column names of the header against naming
+// rules and reports every violation in ProcessResult.HeaderIssues, for
+// enforcing data contracts such as "all columns are snake_case". The rules
+// are lowercase, uppercase, snakecase, kebabcase, camelcase, ascii (no
+// characters outside ASCII), and maxlen=N (at most N characters). The names
+// are checked as they appear in the file, before WithDedupHeaders and
+// WithSanitizeHeaders. An unknown rule makes Process return an error.
+// Calling the option more than once checks the union of the rules.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithHeaderRules("snakecase", "maxlen=30"))
+//	_, result, err := processor.Process(input, &records)
+//	for _, issue := range result.HeaderIssues {
+//	    fmt.Printf("column %q: %s (suggest %q)\n", issue.Column, issue.Reason, issue.Suggestion)
+//	}
+func WithHeaderRules(rules ...string) Option {
+	return func(p *Processor) {
+		p.headerRules = append(slices.Clip(p.headerRules), rules...)
+	}
+}
+
+// WithSanitizeHeaders rewrites column names into plain SQLite identifiers
+// before anything else uses the header: characters other than letters,
+// digits, and underscores become underscores, keywords get an underscore
+// suffix (order becomes order_), and names that collide when case is
+// ignored get a numeric suffix (id, id_2). Struct fields, WithExpectedColumns,
+// and the other column options refer to the rewritten names, which are also
+// used in the output stream and ProcessResult.Columns.
+//
+// Example:
+//
+//	// Header "Order ID,order,Unit Price" becomes "Order_ID,order_,Unit_Price"
+//	type Line struct {
+//	    OrderID   string `name:"Order_ID"`
+//	    Order     string `name:"order_"`
+//	    UnitPrice string `name:"Unit_Price"`
+//	}
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithSanitizeHeaders())
+func WithSanitizeHeaders() Option {
+	return func(p *Processor) {
+		p.sanitizeHeaderNames = true
+		p.csvOpts.allowDuplicateHeaders = true
+	}
+}
+
+// WithDedupHeaders renames repeated column names instead of rejecting the
+// file: every occurrence after the first gets a numeric suffix, so a header
+// id,id,name becomes id,id_2,name. Fields bind to the renamed columns (use
+// a name tag such as `name:"id_2"` for the second id), and the renamed
+// header is used in the output stream and ProcessResult.Columns.
+//
+// Without this option, CSV and TSV files with repeated column names fail to
+// parse. Formats whose parser accepts them bind the first occurrence.
+//
+// Example:
+//
+//	type Row struct {
+//	    ID      string `name:"id"`
+//	    OtherID string `name:"id_2"`
+//	    Name    string
+//	}
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithDedupHeaders())
+func WithDedupHeaders() Option {
+	return func(p *Processor) {
+		p.dedupHeaderNames = true
+		p.csvOpts.allowDuplicateHeaders = true
+	}
+}
+
+// WithSplitColumn splits the values of column source at sep into new
+// columns named targets, appended to the end of the header. A value is split
+// at most len(targets)-1 times, so the last target column keeps the rest;
+// targets without a part are empty. The source column is kept.
+//
+// The split runs before struct fields are bound, so fields can bind to the
+// new columns and their prep and validate tags apply to the split values.
+// Process returns an error if source is not in the header, if a target
+// column already exists, or if the input is JSON or JSONL.
+//
+// Example:
+//
+//	type Person struct {
+//	    FirstName string `prep:"trim" validate:"required"`
+//	    LastName  string `prep:"trim"`
+//	}
+//	// "full_name\nJohn Smith\n" is output as "full_name,first_name,last_name\nJohn Smith,John,Smith\n"
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithSplitColumn("full_name", " ", "first_name", "last_name"))
+func WithSplitColumn(source, sep string, targets ...string) Option {
+	return func(p *Processor) {
+		p.transforms = append(slices.Clip(p.transforms), &splitColumn{source: source, sep: sep, targets: slices.Clone(targets)})
+	}
+}
+
+// WithMergeColumns joins the values of the source columns with sep into a
+// new column named target, appended to the end of the header. Empty values
+// are skipped, so no separator is doubled. The source columns are kept;
+// add WithDropColumns after this option to remove them.
+//
+// Like WithSplitColumn, the merge runs before struct fields are bound.
+// Process returns an error if a source column is not in the header, if
+// target already exists, or if the input is JSON or JSONL.
+//
+// Example:
+//
+//	// "street,city,zip\n1 Main St,Springfield,12345\n" gets an address column
+//	// "1 Main St, Springfield, 12345"
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithMergeColumns("address", ", ", "street", "city", "zip"),
+//	    fileprep.WithDropColumns("street", "city", "zip"))
+func WithMergeColumns(target, sep string, sources ...string) Option {
+	return func(p *Processor) {
+		p.transforms = append(slices.Clip(p.transforms), &mergeColumns{target: target, sep: sep, sources: slices.Clone(sources)})
+	}
+}
+
+// WithDropColumns removes columns from the header and rows before struct
+// fields are bound, for example the sources of WithMergeColumns. It runs in
+// order with the other column transforms. Process returns an error if a
+// column is not in the header at that point, or if the input is JSON or
+// JSONL.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithDropColumns("internal_note"))
+func WithDropColumns(columns ...string) Option {
+	return func(p *Processor) {
+		p.transforms = append(slices.Clip(p.transforms), &dropColumns{columns: slices.Clone(columns)})
+	}
+}
+
+// WithValueMap recodes the values of column using mapping before struct
+// fields are bound; values not in mapping are left unchanged. It is the
+// option form of the map prep tag, for columns without a struct field or
+// for tables loaded at run time. It runs in order with the other column
+// transforms. Process returns an error if column is not in the header, or
+// if the input is JSON or JSONL.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithValueMap("status", map[string]string{"active": "1", "inactive": "0"}))
+func WithValueMap(column string, mapping map[string]string) Option {
+	return func(p *Processor) {
+		p.transforms = append(slices.Clip(p.transforms), &valueMap{column: column, mapping: maps.Clone(mapping)})
+	}
+}
+
+// WithRowHashColumn appends a column with a hex-encoded hash of the given
+// columns of every row, or of all columns when none are given. Comparing
+// the hashes of two versions of a file finds changed rows without comparing
+// every column. Values are hashed as read, before prep tags, and the hash
+// depends only on the values and their order, so it is stable across runs.
+// It runs in order with the other column transforms, so the row number and
+// source columns are never hashed. Process returns an error if a column is
+// not in the header, if the hash column already exists, or if the input is
+// JSON or JSONL.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithRowHashColumn("_hash", fileprep.SHA256, "email", "name"))
+func WithRowHashColumn(column string, algorithm HashAlgorithm, columns ...string) Option {
+	return func(p *Processor) {
+		p.transforms = append(slices.Clip(p.transforms), &rowHashColumn{name: column, algorithm: algorithm, columns: slices.Clone(columns)})
+	}
+}
+
+// WithLanguageColumn appends a column with the detected language of the
+// source column of every row, as an ISO 639-1 code such as "en" or "ja",
+// so mixed-language exports can be found or split without rejecting rows.
+// Detection works like the lang validator, on values as read: the cell is
+// empty when the language cannot be told, as for short Latin-script values,
+// or is ambiguous, as for text written only in Han characters. Process
+// returns an error if the source column is not in the header, if the
+// language column already exists, or if the input is JSON or JSONL.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithLanguageColumn("comment_lang", "comment"))
+func WithLanguageColumn(column, source string) Option {
+	return func(p *Processor) {
+		p.transforms = append(slices.Clip(p.transforms), &languageColumn{name: column, source: source})
+	}
+}
+
+// WithRowNumberColumn appends a column with the input row number of every
+// row: 1-based and excluding the header, like ValidationError.Row. Rows
+// skipped with WithStartRow still count. It makes rows traceable to the
+// source file after loading. The column is added after the other column
+// transforms and before struct fields are bound, so a field can bind to it.
+// Process returns an error if the column already exists, or if the input is
+// JSON or JSONL.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithRowNumberColumn("_row"), fileprep.WithSourceColumn("_file"))
+//	f, _ := os.Open("users.csv")
+//	reader, _, err := processor.Process(f, &users)
+//	// id,name,_row,_file
+//	// 1,Alice,1,users.csv
+func WithRowNumberColumn(column string) Option {
+	return func(p *Processor) {
+		p.rowNumberColumn = column
+	}
+}
+
+// WithSourceColumn appends a column with the name of the input in every row:
+// the file name for inputs with a Name method such as *os.File, or the URL
+// given to ProcessURL without credentials and query. The value is empty for
+// other readers. The column is added after WithRowNumberColumn. Process
+// returns an error if the column already exists, or if the input is JSON or
+// JSONL.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithSourceColumn("_file"))
+func WithSourceColumn(column string) Option {
+	return func(p *Processor) {
+		p.sourceColumn = column
+	}
+}
+
+// WithTableName sets the table name hint reported by Stream.TableName.
+// Without it, the hint is derived from the input file name when the input
+// has a Name method, as *os.File does.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithTableName("users"))
+//	reader, _, err := processor.Process(input, &users)
+//	stream := reader.(fileprep.Stream)
+//	fmt.Println(stream.TableName(), stream.Name()) // users users.csv
+func WithTableName(name string) Option {
+	return func(p *Processor) {
+		p.tableName = name
+	}
+}
+
+// WithMaxBytes limits the size of the input after decompression. Process
+// stops reading and returns ErrInputTooLarge once the input exceeds n bytes,
+// so a huge upload or a compression bomb cannot exhaust memory.
+// A value of 0 (the default) means no limit.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSVGZ, fileprep.WithMaxBytes(50<<20))
+//	_, _, err := processor.Process(upload, &records)
+//	if errors.Is(err, fileprep.ErrInputTooLarge) {
+//	    http.Error(w, "file too large", http.StatusRequestEntityTooLarge)
+//	}
+func WithMaxBytes(n int64) Option {
+	return func(p *Processor) {
+		p.maxBytes = max(n, 0)
+	}
+}
+
+// WithMaxRows limits the number of data rows (excluding the header) in the
+// input. Process returns ErrTooManyRows before processing any row when the
+// input has more than n rows. A value of 0 (the default) means no limit.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithMaxRows(100000))
+func WithMaxRows(n int) Option {
+	return func(p *Processor) {
+		p.maxRows = max(n, 0)
+	}
+}
+
+// WithWorkers configures the Processor to preprocess and validate the fields
+// of rows with n goroutines. Cross-field, duplicate, and group checks still
+// run one row at a time, and rows reach the struct slice, the output, and
+// ProcessResult in input order, so the result is the same as with one
+// worker. Values below 2 process rows one at a time, as by default.
+//
+// Validators added with RegisterValidator must be safe for concurrent use.
+// The increasing and nondecreasing validators compare each row with the one
+// before it, so a run that uses them processes rows one at a time.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithWorkers(runtime.GOMAXPROCS(0)))
+func WithWorkers(n int) Option {
+	return func(p *Processor) {
+		p.workers = n
+	}
+}
+
+// Profile is a preset of structural checks selected with WithProfile.
+type Profile int
+
+const (
+	// ProfileLenient accepts structural problems and repairs what it can:
+	// missing columns read as empty strings, short rows are padded, extra
+	// cells are ignored, and formats whose parser accepts repeated column
+	// names bind the first one. This is the default.
+	ProfileLenient Profile = iota
+	// ProfileStrict turns structural problems into errors: a struct field
+	// whose column is missing from the header fails Process with a
+	// *SchemaError, a repeated column name fails it with ErrDuplicateColumn
+	// for every format, and a row whose cell count differs from the header
+	// gets a ValidationError with the tag "column_count".
+	ProfileStrict
+)
+
+// columnCountTagName is the validation tag reported for ragged rows under
+// ProfileStrict.
+const columnCountTagName = "column_count"
+
+// WithProfile selects a preset of structural checks, so a team can choose
+// its posture with one option. ProfileLenient, the default, mirrors the
+// permissive behavior of earlier versions; ProfileStrict rejects missing
+// columns and repeated column names and reports ragged rows as invalid.
+// Options that rename columns, such as WithDedupHeaders, still apply
+// before the checks. Selecting either profile also lets CSV and TSV input
+// contain rows whose cell count differs from the header; without
+// WithProfile such rows are still a parse error.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithProfile(fileprep.ProfileStrict))
+func WithProfile(profile Profile) Option {
+	return func(p *Processor) {
+		p.profile = profile
+		p.csvOpts.raggedRows = true
+	}
+}
+
+// WithRejectHeaderOnly makes Process return ErrHeaderOnly when the input has
+// a header but no data rows, instead of an empty result. This is useful when
+// an upload without data is a user mistake, such as an exported template.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithRejectHeaderOnly())
+//	_, _, err := processor.Process(upload, &records)
+//	if errors.Is(err, fileprep.ErrHeaderOnly) {
+//	    http.Error(w, "the file has no data rows", http.StatusUnprocessableEntity)
+//	}
+func WithRejectHeaderOnly() Option {
+	return func(p *Processor) {
+		p.rejectHeaderOnly = true
+	}
+}
+
+// WithConversionErrorsAsValidation reports values that cannot be converted
+// to the struct field type, such as "abc" for an int field, as a
+// ValidationError instead of a PrepError with the tag "type_conversion".
+// The error has the tag "type", the target type name as Param (e.g. "int",
+// "float64", "bool"), and the code "TYPE_INVALID", so type mismatches can be
+// handled together with other validation failures. The other fields of the
+// row are still bound and validated.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithConversionErrorsAsValidation())
+//	_, result, _ := processor.Process(input, &records)
+//	for _, ve := range result.ValidationErrors() {
+//	    if ve.Tag == "type" {
+//	        fmt.Printf("row %d: %s is not a valid %s\n", ve.Row, ve.Value, ve.Param)
+//	    }
+//	}
+func WithConversionErrorsAsValidation() Option {
+	return func(p *Processor) {
+		p.conversionAsInvalid = true
+	}
+}
+
+// conversionMode selects what ConversionFallback does with a field.
+type conversionMode int
+
+const (
+	conversionZero conversionMode = iota
+	conversionSkipRow
+	conversionDefault
+)
+
+// ConversionFallback selects what happens to a struct field whose value
+// cannot be converted to the field type. The conversion error is reported
+// and the row is invalid in every case. The zero value is ConversionZero.
+type ConversionFallback struct {
+	mode  conversionMode
+	value string
+}
+
+// ConversionZero leaves the field at its zero value while the other fields
+// of the row are bound. This is the default.
+func ConversionZero() ConversionFallback {
+	return ConversionFallback{}
+}
+
+// ConversionSkipRow leaves the whole row out of the struct slice, so no
+// partially bound struct is returned. The output stream is not affected;
+// use WithValidRowsOnly to drop invalid rows there as well.
+func ConversionSkipRow() ConversionFallback {
+	return ConversionFallback{mode: conversionSkipRow}
+}
+
+// ConversionDefault binds the field as if the cell held value. Process
+// returns an error if value cannot be converted to the field type either.
+func ConversionDefault(value string) ConversionFallback {
+	return ConversionFallback{mode: conversionDefault, value: value}
+}
+
+// WithOnConversionError sets what happens to a struct field whose value
+// cannot be converted to the field type, such as "abc" for an int field.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithOnConversionError(fileprep.ConversionDefault("-1")))
+func WithOnConversionError(fallback ConversionFallback) Option {
+	return func(p *Processor) {
+		p.onConversionError = fallback
+	}
+}
+
+// WithRawCellHook sets a function that rewrites every cell of the input
+// before any prep tag runs, for cleanups that apply to the whole file, such
+// as stripping control characters, without adding tags to every field.
+// It is called with the 1-based data row number, the 0-based index of the
+// column in the file's header, and the value as read; the returned value
+// replaces it. Columns without a struct field are rewritten too, and column
+// transforms, unique, and column statistics validators see the rewritten
+// values. For JSON and JSONL the hook receives each record's raw JSON and
+// must keep it valid. A Processor shared by goroutines calls the hook
+// concurrently.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV,
+//	    fileprep.WithRawCellHook(func(_, _ int, value string) string {
+//	        return strings.ReplaceAll(value, "\u00a0", " ")
+//	    }))
+func WithRawCellHook(hook func(row, col int, value string) string) Option {
+	return func(p *Processor) {
+		p.rawCellHook = hook
+	}
+}
+
+// WithChangeTracking records an audit trail of preprocessing. Every value
+// changed by a prep tag is reported in ProcessResult.Changes with its row,
+// column, the value before and after, and the preprocessors that changed it.
+// Tracking costs an extra pass over the prep chain for each changed value.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithChangeTracking())
+//	_, result, err := processor.Process(input, &records)
+//	for _, c := range result.Changes() {
+//	    fmt.Printf("row %d %s: %q -> %q (%v)\n", c.Row, c.Column, c.Before, c.After, c.Applied)
+//	}
+func WithChangeTracking() Option {
+	return func(p *Processor) {
+		p.changeTracking = true
+	}
+}
+
+// WithResultRows keeps the processed rows in the result, so
+// ProcessResult.Rows and ProcessResult.RowMaps can return them. The rows
+// stay in memory as long as the result does, so use it when the cleaned
+// data is worked with in memory rather than read from the output stream.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithResultRows())
+//	_, result, err := processor.Process(input, &records)
+//	for _, row := range result.Rows() {
+//	    fmt.Println(strings.Join(row, "|"))
+//	}
+func WithResultRows() Option {
+	return func(p *Processor) {
+		p.keepRows = true
+	}
+}
+
+// WithAnnotations keeps a reference to the decompressed input in the
+// result, so ProcessResult.WriteAnnotated can write it back with an errors
+// column. The input stays in memory as long as the result does.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithAnnotations())
+//	_, result, err := processor.Process(upload, &orders)
+//	if err != nil {
+//	    return err
+//	}
+//	return result.WriteAnnotated(w)
+func WithAnnotations() Option {
+	return func(p *Processor) {
+		p.keepSource = true
+	}
+}
+
+// WithFixSuggestions proposes corrected values for common validation
+// failures in ProcessResult.Suggestions: email addresses with typos or
+// without the top-level domain of a well-known provider, dates in another
+// common layout, numbers with thousands separators or a decimal comma, and
+// misspelled oneof values. A value is only suggested when it passes the field's whole validate tag,
+// so a UI can offer it as a one-click correction.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithFixSuggestions())
+//	_, result, err := processor.Process(input, &records)
+//	for _, s := range result.Suggestions {
+//	    fmt.Printf("row %d %s: %q -> %q (%s)\n", s.Row, s.Column, s.Value, s.Suggested, s.Rule)
+//	}
+func WithFixSuggestions() Option {
+	return func(p *Processor) {
+		p.fixSuggestions = true
+	}
+}
+
+// WithPrepIdempotencyCheck applies each field's prep chain a second time to
+// its own output and reports a warning when the value changes again.
+// A well-formed chain is idempotent; one that is not, such as prefix=ID-
+// (which turns "ID-5" into "ID-ID-5") or replace rules that feed each
+// other, usually means the rules are misconfigured or the input was already
+// cleaned. Warnings have the tag "prep" and the code CodeNonIdempotentPrep,
+// and do not affect ValidRowCount. The output keeps the first-pass value.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithPrepIdempotencyCheck())
+//	_, result, err := processor.Process(input, &records)
+//	for _, w := range result.Warnings {
+//	    if w.Code() == fileprep.CodeNonIdempotentPrep {
+//	        log.Printf("field %s: %s", w.Field, w.Message())
+//	    }
+//	}
+func WithPrepIdempotencyCheck() Option {
+	return func(p *Processor) {
+		p.checkIdempotentPrep = true
+	}
+}
+
+// WithOmitEmpty makes every validator except required accept empty values,
+// as if each validate and warn tag started with omitempty.
+//
+// By default validators differ on empty values: format validators such as
+// email, uuid, and url reject them, while character class validators such
+// as alpha and numeric accept them (see the README for the full list).
+// This option normalizes that behavior so that only required decides whether
+// a value may be empty. Cross-field validators are not affected.
+//
+// Example:
+//
+//	type Contact struct {
+//	    Name  string `validate:"required"`
+//	    Email string `validate:"email"` // empty emails are accepted
+//	}
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithOmitEmpty())
+func WithOmitEmpty() Option {
+	return func(p *Processor) {
+		p.omitEmpty = true
+	}
+}
+
+// WithLocale applies the case rules of a language to the lowercase and
+// uppercase prep tags and the eq_ignore_case and ne_ignore_case validators
+// of columns, or of every column when none are given. Case mapping differs
+// by language: in Turkish and Azerbaijani, "I" lowercases to the dotless
+// "ı" and "i" uppercases to "İ", which the default rules get wrong. locale
+// is a BCP 47 tag such as "tr" or "lt". Later calls override earlier ones
+// for the same column. Process returns an error wrapping ErrInvalidOption
+// for a malformed locale, and one wrapping ErrColumnNotFound for a column
+// that is not in the header.
+//
+// Unicode normalization is chosen per column with the normalize_unicode tag,
+// for example normalize_unicode=NFKC.
+//
+// Example:
+//
+//	type Customer struct {
+//	    City string `prep:"trim,uppercase"` // "izmir" becomes "İZMİR"
+//	}
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithLocale("tr", "city"))
+func WithLocale(locale string, columns ...string) Option {
+	return func(p *Processor) {
+		p.locales = append(slices.Clip(p.locales), columnLocale{locale: locale, columns: slices.Clone(columns)})
+	}
+}
+
+// WithRedactValues masks the values of columns, or of every column when
+// none are given, as "[redacted]" in ProcessResult errors, warnings,
+// changes recorded with WithChangeTracking, fix suggestions, and duplicate
+// groups, and so in the HTML and JUnit reports built from them. Row and column references are
+// kept, as are empty values, so failures can still be located without
+// exposing sensitive data in logs. The output stream and the structs are
+// not changed; use WithAnonymizedColumn for those. Messages returned by
+// WithGroupValidator functions are not rewritten. Process returns an error
+// wrapping ErrColumnNotFound for a column that is not in the header.
+//
+// Example:
+//
+//	processor := fileprep.NewProcessor(fileparser.CSV, fileprep.WithRedactValues("email", "ssn"))
+//	_, result, _ := processor.Process(input, &records)
+//	for _, ve := range result.ValidationErrors() {
+//	    log.Printf("row %d %s: %s (value=%s)", ve.Row, ve.Column, ve.Message(), ve.Value) // value=[redacted]
+//	}
+func WithRedactValues(columns ...string) Option {
+	return func(p *Processor) {
+		if len(columns) == 0 {
+			p.redactAll = true
+			return
+		}
+		p.redactColumns = append(slices.Clip(p.redactColumns), columns...)
+	}
+}
+
 // NewProcessor creates a new Processor for the specified file type.
 // Options can be provided to configure behavior such as strict tag parsing
-// and output filtering.
+// and output filtering. Options copy the slices and maps they are given, so
+// changing them afterwards does not affect the Processor.
 //
 // Example:
 //
@@ -90,6 +1426,8 @@ func NewProcessor(fileType fileparser.FileType, opts ...Option) *Processor {
 //   - XLSX input → CSV output (tabular data)
 //   - Parquet input → CSV output (tabular data)
 //
+// WithOutputFormat selects CSV, TSV, LTSV, or XLSX output for tabular input.
+//
 // The returned io.Reader can be passed directly to filesql.AddReader:
 //
 //	reader, result, err := processor.Process(input, &records)
@@ -100,6 +1438,10 @@ func NewProcessor(fileType fileparser.FileType, opts ...Option) *Processor {
 //	stream := reader.(fileprep.Stream)
 //	fmt.Println(stream.Format()) // CSV, TSV, etc.
 //
+// Process may be called from several goroutines at once. Each call keeps
+// its own state, including unique and column statistics validators and
+// duplicate tracking, so calls on different inputs do not affect each other.
+//
 // Example:
 //
 //	type User struct {
@@ -117,139 +1459,909 @@ func NewProcessor(fileType fileparser.FileType, opts ...Option) *Processor {
 //	}
 //	fmt.Printf("Processed %d rows, %d valid\n", result.RowCount, result.ValidRowCount)
 func (p *Processor) Process(input io.Reader, structSlicePointer any) (io.Reader, *ProcessResult, error) {
+	run, err := p.prepareRun(input, structSlicePointer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := run.newResult()
+	reader, err := p.process(run, reflect.ValueOf(structSlicePointer).Elem(), result)
+	var multiErr *MultiError
+	if errors.As(err, &multiErr) {
+		return nil, result, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return reader, result, nil
+}
+
+// ProcessInto processes the input like Process but fills the given result,
+// which is Reset first, and replaces the contents of the struct slice
+// instead of appending to it. Reusing one result and one slice across calls
+// keeps their backing arrays, which reduces allocations in services that
+// validate many inputs. Values from the previous call, including the
+// structs, are overwritten, so copy anything that must outlive it.
+//
+// Each goroutine needs its own result and slice; a pool works well. Reset
+// the result before returning it, so the pool does not keep the last
+// input's errors alive:
+//
+//	var results = sync.Pool{New: func() any { return new(fileprep.ProcessResult) }}
+//
+//	result := results.Get().(*fileprep.ProcessResult)
+//	defer func() {
+//	    result.Reset()
+//	    results.Put(result)
+//	}()
+//	users = users[:0]
+//	reader, err := processor.ProcessInto(upload, &users, result)
+func (p *Processor) ProcessInto(input io.Reader, structSlicePointer any, result *ProcessResult) (io.Reader, error) {
+	if result == nil {
+		return nil, fmt.Errorf("%w: result must not be nil", ErrInvalidOption)
+	}
+	result.Reset()
+
+	run, err := p.prepareRun(input, structSlicePointer)
+	if err != nil {
+		return nil, err
+	}
+
+	run.resetResult(result)
+	structSliceValue := reflect.ValueOf(structSlicePointer).Elem()
+	structSliceValue.SetLen(0)
+	return p.process(run, structSliceValue, result)
+}
+
+// process runs the prepared input through preprocessing and validation,
+// appending structs to structSliceValue and filling result. With
+// WithStrictValidation it returns a *MultiError if any row failed.
+func (p *Processor) process(run *processRun, structSliceValue reflect.Value, result *ProcessResult) (io.Reader, error) {
+	records := run.records
+
+	// Pre-allocate the struct slice to avoid repeated growth
+	if structSliceValue.Cap() < len(records) {
+		newSlice := reflect.MakeSlice(structSliceValue.Type(), 0, len(records))
+		structSliceValue.Set(newSlice)
+	}
+
+	processStart := time.Now()
+	out, err := p.processRecords(run, records, run.startRow, structSliceValue, result)
+	if err != nil {
+		return nil, err
+	}
+	if run.dupes != nil {
+		result.Duplicates = run.dupes.groups()
+	}
+	if run.redacted != nil {
+		result.redactValues(run.redacted, p.duplicateKeys)
+	}
+	result.Perf.Process = time.Since(processStart)
+
+	if p.strictValidation && result.HasErrors() {
+		return nil, &MultiError{Errors: result.Errors}
+	}
+
+	// Reuse the decompressed input when the output would be an identical re-encoding
+	outputStart := time.Now()
+	if run.columnOrder == nil && !run.headerChanged && p.canReuseInput(out.modified || run.cellsRewritten, result) {
+		s := newStream(run.rawData, p.outputFormat(), p.fileType).withTableName(run.tableName)
+		result.Perf.finish(s, true, time.Since(outputStart), result.RowCount)
+		return s, nil
+	}
+
+	s, err := p.buildRunOutput(run, records, out, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Perf.finish(s, false, time.Since(outputStart), result.RowCount)
+	return s, nil
+}
+
+// ProcessChunks processes the input like Process but hands the output to fn
+// in chunks of at most chunkRows data rows, in input order. For each chunk,
+// structSlicePointer is reset to hold only that chunk's structs (reusing the
+// same backing array, so copy values that must outlive fn), and fn
+// receives the chunk's output stream (with its own header) and a
+// ProcessResult covering only the chunk's rows. Row numbers stay relative to
+// the whole input.
+//
+// This lets callers load each chunk in its own transaction and resume with
+// WithStartRow after a failure. The input is still parsed in one pass, but
+// output streams and struct values are only held for one chunk at a time.
+// Column statistics validators see the whole column. With
+// WithDuplicateReport, a key repeated across chunks is reported in the chunk
+// holding the repeat, and FirstRow may point to an earlier chunk. Like
+// Process, ProcessChunks may run concurrently with other calls.
+//
+// Processing stops at the first error returned by fn, which ProcessChunks
+// returns unchanged. With WithStrictValidation, a chunk with errors is not
+// passed to fn and its *MultiError is returned instead.
+//
+// Example:
+//
+//	var orders []Order
+//	err := processor.ProcessChunks(input, &orders, 10000, func(chunk fileprep.Stream, res *fileprep.ProcessResult) error {
+//	    tx, err := db.Begin()
+//	    if err != nil {
+//	        return err
+//	    }
+//	    if err := loadChunk(tx, chunk); err != nil {
+//	        tx.Rollback()
+//	        return err
+//	    }
+//	    return tx.Commit()
+//	})
+func (p *Processor) ProcessChunks(
+	input io.Reader,
+	structSlicePointer any,
+	chunkRows int,
+	fn func(chunk Stream, res *ProcessResult) error,
+) error {
+	if chunkRows <= 0 {
+		return fmt.Errorf("%w: chunk size must be positive, got %d", ErrInvalidOption, chunkRows)
+	}
+	if p.samplesOutput() {
+		return fmt.Errorf("%w: WithHead, WithTail, and WithEveryNth cannot be used with ProcessChunks", ErrInvalidOption)
+	}
+
+	run, err := p.prepareRun(input, structSlicePointer)
+	if err != nil {
+		return err
+	}
+
+	structSliceValue := reflect.ValueOf(structSlicePointer).Elem()
+	structSliceValue.Set(reflect.MakeSlice(structSliceValue.Type(), 0, min(chunkRows, len(run.records))))
+
+	for chunkStart := 0; chunkStart < len(run.records); chunkStart += chunkRows {
+		records := run.records[chunkStart:min(chunkStart+chunkRows, len(run.records))]
+		firstRowIdx := run.startRow + chunkStart
+
+		structSliceValue.SetLen(0)
+		result := run.newResult()
+		if run.dupes != nil {
+			run.dupes.startChunk()
+		}
+
+		processStart := time.Now()
+		out, err := p.processRecords(run, records, firstRowIdx, structSliceValue, result)
+		if err != nil {
+			return err
+		}
+		if run.dupes != nil {
+			result.Duplicates = run.dupes.groups()
+		}
+		if run.redacted != nil {
+			result.redactValues(run.redacted, p.duplicateKeys)
+		}
+		result.Perf.Process = time.Since(processStart)
+
+		if p.strictValidation && result.HasErrors() {
+			return &MultiError{Errors: result.Errors}
+		}
+
+		outputStart := time.Now()
+		chunk, err := p.buildRunOutput(run, records, out, result)
+		if err != nil {
+			return err
+		}
+		result.Perf.finish(chunk, false, time.Since(outputStart), result.RowCount)
+		if err := fn(chunk, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processRun holds the per-call state shared by Process and ProcessChunks:
+// the parsed input and the struct info bound to its header. Everything a call
+// resolves or accumulates lives here or in its ProcessResult, never in the
+// Processor, so concurrent calls do not see each other's state.
+type processRun struct {
+	fileType          fileparser.FileType
+	structType        reflect.Type
+	structInfo        *structInfo
+	rawData           []byte
+	headers           []string
+	records           [][]string // data rows after WithStartRow
+	startRow          int        // number of skipped data rows
+	columnOrder       []int
+	dupes             *duplicateTracker
+	groupChecks       []groupCheck
+	filter            rowFilter
+	redacted          func(column string) bool // nil unless WithRedactValues is used
+	readsRecord       bool                     // some prep rule reads other columns of the row
+	fieldNameToColIdx map[string]int
+	isJSONFormat      bool
+	tableName         string
+	headerIssues      []HeaderIssue
+	headerChanged     bool                     // the output header differs from the file's
+	cellsRewritten    bool                     // WithRawCellHook changed a cell
+	excelErrors       map[int][]excelErrorCell // XLSX error cells by row, only with ExcelErrorInvalid
+	raggedRows        map[int]int              // cell count of rows that differ from the header, only with ProfileStrict
+	parsedColumns     int                      // column count of the parsed header, before column transforms
+	perf              PerfStats                // input size and read and parse durations
+}
+
+// newResult returns an empty ProcessResult for the run.
+func (r *processRun) newResult() *ProcessResult {
+	result := &ProcessResult{}
+	r.resetResult(result)
+	return result
+}
+
+// resetResult sets the run's columns, format, and header issues on a result
+// that was just Reset, keeping the capacity of its Errors slice.
+func (r *processRun) resetResult(result *ProcessResult) {
+	result.Columns = r.headers
+	result.OriginalFormat = r.fileType
+	result.HeaderIssues = r.headerIssues
+	result.Perf = r.perf
+	// Pre-allocate errors slice with estimated capacity (assume ~10% error rate)
+	if estimatedErrors := max(len(r.records)/10, 16); cap(result.Errors) < estimatedErrors {
+		result.Errors = make([]error, 0, estimatedErrors)
+	}
+}
+
+// runOutput is what processRecords collects for building the output stream.
+type runOutput struct {
+	// selectedRecords holds the rows for the output and selectedRowNums
+	// their row numbers, only when selectsRows is true
+	selectedRecords [][]string
+	selectedRowNums []int
+	firstRow        int  // input row number of the first processed row
+	modified        bool // true if any cell differs from the parsed input
+}
+
+// prepareRun parses the struct tags and the input, checks the header, and
+// resolves everything that depends on the whole file before rows are processed.
+func (p *Processor) prepareRun(input io.Reader, structSlicePointer any) (*processRun, error) {
+	start := time.Now()
+
 	// Get struct type and parse tags
 	structType, err := getStructType(structSlicePointer)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	structInfo, err := parseStructType(structType, p.strictTagParsing)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	if len(p.schemaFields) > 0 {
+		structInfo = structInfo.withSchemaFields(p.schemaFields)
+	}
+	if len(p.columnTypes) > 0 {
+		if structInfo, err = structInfo.withColumnTypes(p.columnTypes); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.disabledValidators) > 0 || len(p.warningValidators) > 0 {
+		structInfo = structInfo.withValidatorOverrides(p.disabledValidators, p.warningValidators)
+	}
+	if p.omitEmpty {
+		structInfo = structInfo.withOmitEmpty()
+	}
+	if p.valueListErr != nil {
+		return nil, p.valueListErr
+	}
+	if structInfo, err = structInfo.withValueLists(p.valueLists); err != nil {
+		return nil, err
+	}
+
+	// Decompress the whole input up front. The decompressed buffer is kept so it
+	// can be returned as-is when preprocessing does not change any value. The
+	// bytes read are counted for PerfStats; a nil input stays nil for ErrNilReader.
+	counter := &countingReader{r: input}
+	var reader io.Reader
+	if input != nil {
+		reader = counter
+	}
+	readStart := time.Now()
+	rawData, err := readDecompressed(reader, p.fileType, p.maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	readTime := time.Since(readStart)
+	if err := checkEmptyInput(rawData, p.fileType); err != nil {
+		return nil, err
+	}
+
+	tableData, excelErrors, err := p.parse(rawData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrParse, err)
+	}
+
+	headers := tableData.Headers
+	records := tableData.Records
+
+	var headerIssues []HeaderIssue
+	if p.sqlHeaderCheck {
+		headerIssues = checkSQLHeaders(headers)
+	}
+	if len(p.headerRules) > 0 {
+		rules, err := parseHeaderRules(p.headerRules)
+		if err != nil {
+			return nil, err
+		}
+		headerIssues = append(headerIssues, checkHeaderRules(headers, rules)...)
+	}
+	if p.dedupHeaderNames {
+		headers = dedupHeaders(headers)
+	}
+	if p.sanitizeHeaderNames {
+		headers = sanitizeHeaders(headers)
+	}
+	headersRenamed := !slices.Equal(headers, tableData.Headers)
+	if p.profile == ProfileStrict {
+		if err := validateHeaderNames(headers); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.rejectHeaderOnly && len(records) == 0 {
+		return nil, ErrHeaderOnly
+	}
+	if p.maxRows > 0 && len(records) > p.maxRows {
+		return nil, fmt.Errorf("%w: %d data rows, limit is %d", ErrTooManyRows, len(records), p.maxRows)
+	}
+
+	if p.expectedColumns != nil {
+		if schemaErr := diffColumns(headers, p.expectedColumns); schemaErr != nil {
+			return nil, schemaErr
+		}
+	}
+
+	// Skip rows that were already handled by a previous run. This happens
+	// before anything reads the records, so column statistics, unique keys,
+	// and transforms only see the rows that are processed.
+	startRow := min(p.startRow, len(records))
+	records = records[startRow:]
+	cellsRewritten := p.rewriteRawCells(records, startRow+1)
+	var raggedRows map[int]int
+	if p.profile == ProfileStrict {
+		raggedRows = findRaggedRows(records, len(headers), startRow+1)
+	}
+
+	baseType := fileparser.BaseFileType(p.fileType)
+	isJSONFormat := baseType == fileparser.JSON || baseType == fileparser.JSONL
+	if p.outputTypeSet {
+		if err := p.checkOutputFormat(isJSONFormat); err != nil {
+			return nil, err
+		}
+	}
+	transforms := p.runTransforms(startRow+1, input)
+	if len(transforms) > 0 {
+		if isJSONFormat {
+			return nil, fmt.Errorf("%w: column transforms need tabular input", ErrUnsupportedFileType)
+		}
+		headers, records, err = applyTransforms(transforms, headers, records)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Build header name to column index map (first occurrence wins for duplicates)
+	headerToColIdx := make(map[string]int, len(headers))
+	for i, h := range headers {
+		if _, exists := headerToColIdx[h]; !exists {
+			headerToColIdx[h] = i
+		}
+	}
+
+	for column := range p.columnTypes {
+		if _, ok := headerToColIdx[column]; !ok {
+			return nil, fmt.Errorf("column type for %q: %w", column, ErrColumnNotFound)
+		}
+	}
+
+	// Resolve column indices for each field based on column name
+	for i := range structInfo.Fields {
+		fi := &structInfo.Fields[i]
+		if colIdx, ok := headerToColIdx[fi.ColumnName]; ok {
+			fi.ColumnIndex = colIdx
+		}
+		// If not found, ColumnIndex remains -1
+	}
+	if len(p.locales) > 0 {
+		localeOf, err := p.columnLocales(headers)
+		if err != nil {
+			return nil, err
+		}
+		structInfo = structInfo.withLocales(localeOf)
+	}
+	structInfo = structInfo.withTemplateHeaders(headers)
+	readsRecord := slices.ContainsFunc(structInfo.Fields, func(fi fieldInfo) bool { return hasTemplate(fi.Preprocessors) })
+	if p.profile == ProfileStrict {
+		if schemaErr := missingFieldColumns(structInfo); schemaErr != nil {
+			return nil, schemaErr
+		}
+	}
+
+	// Bind winsorize preprocessors and outlier/percentile validators to this
+	// file's column distributions
+	structInfo = structInfo.withColumnStats(records)
+
+	// Group rows by their unique keys before any row is validated, so every
+	// row of a duplicate group is reported
+	structInfo = structInfo.withUniqueKeys(records, startRow+1)
+
+	columnOrder, err := p.outputColumnOrder(headers, headerToColIdx, structInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	var dupes *duplicateTracker
+	if len(p.duplicateKeys) > 0 {
+		dupes, err = newDuplicateTracker(p.duplicateKeys, headerToColIdx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	groupChecks, err := p.groupChecks(headerToColIdx, structInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted, err := p.redactedColumns(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter rowFilter
+	if len(p.filters) > 0 {
+		if isJSONFormat {
+			return nil, fmt.Errorf("%w: filters need tabular input", ErrUnsupportedFileType)
+		}
+		if filter, err = compileFilters(p.filters, headerToColIdx); err != nil {
+			return nil, err
+		}
+	}
+
+	// Build field name to column index map for cross-field validation
+	fieldNameToColIdx := make(map[string]int)
+	for _, fi := range structInfo.Fields {
+		fieldNameToColIdx[fi.Name] = fi.ColumnIndex
+	}
+
+	return &processRun{
+		fileType:          p.fileType,
+		structType:        structType,
+		structInfo:        structInfo,
+		rawData:           rawData,
+		headers:           headers,
+		records:           records,
+		startRow:          startRow,
+		columnOrder:       columnOrder,
+		dupes:             dupes,
+		groupChecks:       groupChecks,
+		filter:            filter,
+		redacted:          redacted,
+		readsRecord:       readsRecord,
+		fieldNameToColIdx: fieldNameToColIdx,
+		isJSONFormat:      isJSONFormat,
+		tableName:         p.tableNameFor(input),
+		headerIssues:      headerIssues,
+		headerChanged:     headersRenamed || len(transforms) > 0,
+		cellsRewritten:    cellsRewritten,
+		excelErrors:       excelErrorsByRow(excelErrors),
+		raggedRows:        raggedRows,
+		parsedColumns:     len(tableData.Headers),
+		perf: PerfStats{
+			BytesRead:         counter.n,
+			DecompressedBytes: int64(len(rawData)),
+			Read:              readTime,
+			Parse:             time.Since(start) - readTime,
+		},
+	}, nil
+}
+
+// processRecords applies preprocessing and validation to records, whose first
+// row is data row firstRowIdx+1 of the input, and appends the resulting
+// structs to structSliceValue.
+func (p *Processor) processRecords(
+	run *processRun,
+	records [][]string,
+	firstRowIdx int,
+	structSliceValue reflect.Value,
+	result *ProcessResult,
+) (*runOutput, error) {
+	out := &runOutput{firstRow: firstRowIdx + 1}
+
+	// When rows are left out of the output, collect the ones that remain
+	if p.selectsRows() {
+		out.selectedRecords = make([][]string, 0, len(records))
+		out.selectedRowNums = make([]int, 0, len(records))
+	}
+
+	// structValue is reused for every row: reflect.Append copies it into the
+	// destination slice, so a single scratch value avoids a per-row allocation.
+	structValue := reflect.New(run.structType).Elem()
+
+	// With WithWorkers, filtering, padding, and the first pass run for all
+	// rows up front, and their results are merged below in input order
+	var concurrent []concurrentRow
+	if p.workers > 1 && !run.structInfo.validatesInOrder() {
+		concurrent = p.firstPassConcurrently(run, records, firstRowIdx, result, out)
 	}
 
-	// Parse the file using fileparser
-	tableData, err := fileparser.Parse(input, p.fileType)
-	if err != nil {
-		return nil, nil, err
-	}
+	var pending []pendingRow
+
+	// Process records in-place to avoid unnecessary allocations
+	for rowIdx := range records {
+		rowNum := firstRowIdx + rowIdx + 1 // 1-based row number in the input (excluding header)
+
+		// First pass: preprocessing and single-field validation
+		var pass rowPass
+		rowValue := structValue
+		if concurrent != nil {
+			row := &concurrent[rowIdx]
+			if row.filtered {
+				continue
+			}
+			result.appendRow(row.batch, row.from, row.to)
+			pass, rowValue = row.rowPass, row.value
+		} else {
+			if !p.prepareRow(run, records, rowIdx, result, out) {
+				continue
+			}
+			pass = p.firstPass(run, records[rowIdx], rowNum, structValue, result)
+		}
+		if pass.err != nil {
+			return nil, pass.err
+		}
+		record := records[rowIdx]
+		rowHasError := pass.hasError
+		if pass.modified {
+			out.modified = true
+		}
+
+		if p.reportExcelErrors(run, rowNum, result) {
+			rowHasError = true
+		}
+		if reportRaggedRow(run, rowNum, result) {
+			rowHasError = true
+		}
+
+		// Second pass: cross-field validation
+		if p.applyCrossFieldValidation(record, rowNum, run.structInfo, run.fieldNameToColIdx, result) {
+			rowHasError = true
+		}
 
-	headers := tableData.Headers
-	records := tableData.Records
+		if run.dupes != nil {
+			run.dupes.add(record, rowNum)
+		}
 
-	// Build header name to column index map (first occurrence wins for duplicates)
-	headerToColIdx := make(map[string]int, len(headers))
-	for i, h := range headers {
-		if _, exists := headerToColIdx[h]; !exists {
-			headerToColIdx[h] = i
+		skipStruct := pass.convFailed && p.onConversionError.mode == conversionSkipRow
+		if len(run.groupChecks) > 0 {
+			// Group checks need every row, so the row is kept until they ran
+			held := reflect.New(run.structType).Elem()
+			held.Set(rowValue)
+			pending = append(pending, pendingRow{
+				record: record, rowNum: rowNum, hasError: rowHasError, skipStruct: skipStruct, value: held,
+			})
+			continue
 		}
+		p.commitRow(out, structSliceValue, result, record, rowNum, rowHasError, skipStruct, rowValue)
 	}
 
-	// Resolve column indices for each field based on column name
-	for i := range structInfo.Fields {
-		fi := &structInfo.Fields[i]
-		if colIdx, ok := headerToColIdx[fi.ColumnName]; ok {
-			fi.ColumnIndex = colIdx
+	if len(run.groupChecks) > 0 {
+		invalid := runGroupChecks(run.groupChecks, run.headers, pending, result)
+		for _, row := range pending {
+			p.commitRow(out, structSliceValue, result, row.record, row.rowNum, row.hasError || invalid[row.rowNum], row.skipStruct, row.value)
 		}
-		// If not found, ColumnIndex remains -1
 	}
 
-	// Process records: apply preprocessing and validation
-	// Pre-allocate errors slice with estimated capacity (assume ~10% error rate)
-	estimatedErrors := max(len(records)/10, 16)
-	result := &ProcessResult{
-		Columns:        headers,
-		OriginalFormat: p.fileType,
-		Errors:         make([]error, 0, estimatedErrors),
+	rows := records
+	if p.selectsRows() {
+		rows = out.selectedRecords
 	}
-	structSliceValue := reflect.ValueOf(structSlicePointer).Elem()
+	if p.keepRows {
+		result.rows = rows
+	}
+	if p.keepSource {
+		result.source = &annotationSource{processor: p, data: run.rawData, firstRow: firstRowIdx + 1, rowCount: len(records)}
+	}
+	if run.isJSONFormat {
+		result.JSONTypes = jsonRecordTypes(rows, 0)
+	}
+	return out, nil
+}
 
-	// Pre-allocate the struct slice to avoid repeated growth
-	if structSliceValue.Cap() < len(records) {
-		newSlice := reflect.MakeSlice(structSliceValue.Type(), 0, len(records))
-		structSliceValue.Set(newSlice)
+// jsonDataColumn is the column name used by fileparser for JSON/JSONL data.
+// Each JSON element is stored as a raw JSON string in this single column.
+const jsonDataColumn = "data"
+
+// rowPass is the outcome of the first pass over a row: preprocessing and
+// single-field validation.
+type rowPass struct {
+	hasError   bool
+	modified   bool
+	convFailed bool
+	err        error
+}
+
+// prepareRow applies WithFilter to the row at rowIdx and pads it to the
+// header length if it is short. It returns false for a row the filter drops.
+func (p *Processor) prepareRow(run *processRun, records [][]string, rowIdx int, result *ProcessResult, out *runOutput) bool {
+	record := records[rowIdx]
+	if run.filter != nil && !run.filter(record) {
+		result.FilteredRowCount++
+		return false
 	}
+	result.RowCount++
 
-	// Build field name to column index map for cross-field validation
-	fieldNameToColIdx := make(map[string]int)
-	for _, fi := range structInfo.Fields {
-		fieldNameToColIdx[fi.Name] = fi.ColumnIndex
+	// Pad short rows with empty strings only if needed
+	if len(record) < len(run.headers) {
+		for _, column := range run.headers[len(record):] {
+			result.repair(column, func(c *ColumnRepairs) { c.Padded++ })
+		}
+		padded := make([]string, len(run.headers))
+		copy(padded, record)
+		records[rowIdx] = padded
+		out.modified = true
 	}
+	return true
+}
 
-	headerLen := len(headers)
-	baseType := fileparser.BaseFileType(p.fileType)
-	isJSONFormat := baseType == fileparser.JSON || baseType == fileparser.JSONL
+// firstPass zeroes structValue and runs processRow over record.
+func (p *Processor) firstPass(run *processRun, record []string, rowNum int, structValue reflect.Value, result *ProcessResult) rowPass {
+	structValue.SetZero()
+
+	// Template prep rules read the row as read, not as other fields rewrite
+	// it. The copy is only read during processRow, so it comes from rowPool.
+	var rawRow *[]string
+	var rawRecord []string
+	if run.readsRecord {
+		rawRow = getRow(len(record))
+		rawRecord = *rawRow
+		copy(rawRecord, record)
+	}
+
+	var pass rowPass
+	pass.hasError, pass.modified, pass.convFailed, pass.err = p.processRow(
+		record, rawRecord, rowNum, run.structInfo, structValue, result, run.isJSONFormat, jsonDataColumn)
+	if rawRow != nil {
+		putRow(rawRow)
+	}
+	return pass
+}
 
-	// jsonDataColumn is the column name used by fileparser for JSON/JSONL data.
-	// Each JSON element is stored as a raw JSON string in this single column.
-	const jsonDataColumn = "data"
+// pendingRow is a processed row waiting for group checks.
+type pendingRow struct {
+	record     []string
+	rowNum     int
+	hasError   bool
+	skipStruct bool
+	value      reflect.Value
+}
 
-	// When validRowsOnly is enabled, collect only valid records for output
-	var validRecords [][]string
-	if p.validRowsOnly {
-		validRecords = make([][]string, 0, len(records))
+// commitRow counts a processed row and adds it to the struct slice and, when
+// rows are selected for the output, to the selected records.
+func (p *Processor) commitRow(
+	out *runOutput,
+	structSliceValue reflect.Value,
+	result *ProcessResult,
+	record []string,
+	rowNum int,
+	hasError, skipStruct bool,
+	structValue reflect.Value,
+) {
+	if !hasError {
+		result.ValidRowCount++
+	}
+	if hasError && p.validRowsOnly {
+		return
+	}
+	if p.selectsRows() {
+		out.selectedRecords = append(out.selectedRecords, record)
+		out.selectedRowNums = append(out.selectedRowNums, rowNum)
 	}
+	if !hasError || !skipStruct {
+		structSliceValue.Set(reflect.Append(structSliceValue, structValue))
+	}
+}
 
-	// Process records in-place to avoid unnecessary allocations
-	for rowIdx := range records {
-		record := records[rowIdx]
-		rowNum := rowIdx + 1 // 1-based row number (excluding header)
-		result.RowCount++
+// selectsRows reports whether the output holds only some of the processed
+// rows, as with WithValidRowsOnly and WithFilter.
+func (p *Processor) selectsRows() bool {
+	return p.validRowsOnly || len(p.filters) > 0
+}
 
-		// Pad short rows with empty strings only if needed
-		if len(record) < headerLen {
-			padded := make([]string, headerLen)
-			copy(padded, record)
-			records[rowIdx] = padded
-			record = padded
+// buildRunOutput builds the output stream for processed records, applying
+// the configured column order. The errors of result are marked in XLSX
+// output with WithXLSXErrorSheet.
+func (p *Processor) buildRunOutput(run *processRun, records [][]string, out *runOutput, result *ProcessResult) (*stream, error) {
+	headers := run.headers
+	selectedRecords := out.selectedRecords
+	if run.columnOrder != nil && !run.isJSONFormat {
+		headers = reorderRow(headers, run.columnOrder)
+		if p.selectsRows() {
+			selectedRecords = reorderRecords(selectedRecords, run.columnOrder)
+		} else {
+			records = reorderRecords(records, run.columnOrder)
 		}
+	}
+	var errs []error
+	if p.xlsxErrorSheet {
+		errs = result.Errors
+	}
+	s, err := p.buildOutput(headers, records, selectedRecords, out.selectedRowNums, out.firstRow, run.isJSONFormat, errs)
+	if err != nil {
+		return nil, err
+	}
+	return s.withTableName(run.tableName), nil
+}
 
-		structValue := reflect.New(structType).Elem()
+// tableNameFor returns the table name hint for input: the WithTableName
+// value, or the input's file name without directory and extensions.
+func (p *Processor) tableNameFor(input io.Reader) string {
+	if p.tableName != "" {
+		return p.tableName
+	}
+	named, ok := input.(interface{ Name() string })
+	if !ok {
+		return ""
+	}
+	return tableNameFromPath(named.Name())
+}
 
-		// First pass: preprocessing and single-field validation
-		rowHasError, err := p.processRow(record, rowNum, structInfo, structValue, result, isJSONFormat, jsonDataColumn)
+// parse parses the decompressed input. CSV, TSV, and XLSX use fileprep's own
+// readers when parse options are set; everything else goes through
+// fileparser. JSON input is first narrowed to the WithJSONRecordPath array.
+// The returned error cells are only set for XLSX with ExcelErrorInvalid.
+func (p *Processor) parse(data []byte) (*fileparser.TableData, []excelErrorCell, error) {
+	baseType := fileparser.BaseFileType(p.fileType)
+	if baseType == fileparser.JSON && p.jsonRecordPath != "" {
+		records, err := extractJSONRecords(data, p.jsonRecordPath)
 		if err != nil {
 			return nil, nil, err
 		}
+		data = records
+	}
 
-		// Second pass: cross-field validation
-		if p.applyCrossFieldValidation(record, rowNum, structInfo, fieldNameToColIdx, result) {
-			rowHasError = true
+	var tableData *fileparser.TableData
+	var err error
+	switch {
+	case baseType == fileparser.CSV && !p.csvOpts.isDefault():
+		tableData, err = parseDelimited(data, p.csvOpts.comma(), "CSV", p.csvOpts)
+	case baseType == fileparser.TSV && !p.csvOpts.isDefault():
+		tableData, err = parseDelimited(data, '\t', "TSV", p.csvOpts)
+	case baseType == fileparser.XLSX && !p.xlsxOpts.isDefault():
+		return parseXLSX(data, p.xlsxOpts, p.csvOpts.allowDuplicateHeaders)
+	default:
+		tableData, err = fileparser.Parse(bytes.NewReader(data), baseType)
+		// fileparser reports repeated header names without a sentinel.
+		if err != nil {
+			if name, ok := strings.CutPrefix(err.Error(), "duplicate column name: "); ok {
+				err = fmt.Errorf("%w: %s", ErrDuplicateColumn, name)
+			}
 		}
+	}
+	return tableData, nil, err
+}
 
-		if !rowHasError {
-			result.ValidRowCount++
-			if p.validRowsOnly {
-				validRecords = append(validRecords, record)
+// rewriteRawCells applies the WithRawCellHook function to every cell of
+// records, whose first row has the data row number firstRow. It reports
+// whether any cell changed.
+func (p *Processor) rewriteRawCells(records [][]string, firstRow int) bool {
+	if p.rawCellHook == nil {
+		return false
+	}
+	changed := false
+	for i, record := range records {
+		for col, value := range record {
+			if rewritten := p.rawCellHook(firstRow+i, col, value); rewritten != value {
+				record[col] = rewritten
+				changed = true
 			}
-			structSliceValue.Set(reflect.Append(structSliceValue, structValue))
-		} else if !p.validRowsOnly {
-			structSliceValue.Set(reflect.Append(structSliceValue, structValue))
 		}
 	}
+	return changed
+}
 
-	// Build output from the processed records
-	reader, err := p.buildOutput(headers, records, validRecords, isJSONFormat)
-	if err != nil {
-		return nil, nil, err
+// checkEmptyInput returns ErrNoHeader for input that is empty or only a
+// UTF-8 byte order mark, and ErrWhitespaceOnly for text input that holds
+// only whitespace. XLSX and Parquet are binary and are left to their parsers.
+func checkEmptyInput(data []byte, fileType fileparser.FileType) error {
+	switch fileparser.BaseFileType(fileType) {
+	case fileparser.XLSX, fileparser.Parquet:
+		if len(data) == 0 {
+			return ErrNoHeader
+		}
+		return nil
+	}
+	data = bytes.TrimPrefix(data, []byte("\uFEFF"))
+	if len(data) == 0 {
+		return ErrNoHeader
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return ErrWhitespaceOnly
 	}
+	return nil
+}
 
-	return reader, result, nil
+// excelErrorsByRow groups XLSX error cells by row number.
+func excelErrorsByRow(cells []excelErrorCell) map[int][]excelErrorCell {
+	if len(cells) == 0 {
+		return nil
+	}
+	byRow := make(map[int][]excelErrorCell)
+	for _, c := range cells {
+		byRow[c.row] = append(byRow[c.row], c)
+	}
+	return byRow
+}
+
+// reportExcelErrors records a ValidationError for each XLSX error cell of the
+// row and reports whether there was any.
+func (p *Processor) reportExcelErrors(run *processRun, rowNum int, result *ProcessResult) bool {
+	cells := run.excelErrors[rowNum]
+	for _, c := range cells {
+		field := ""
+		for _, fi := range run.structInfo.Fields {
+			if fi.ColumnName == c.column {
+				field = fi.Name
+				break
+			}
+		}
+		result.Errors = append(result.Errors, newValidationError(
+			rowNum, c.column, field, c.value, excelErrorTagName, "", "cell holds the Excel error "+c.value,
+		))
+	}
+	return len(cells) > 0
+}
+
+// findRaggedRows returns the cell count of every row whose count differs from
+// the header, by row number. records[0] is row firstRow.
+func findRaggedRows(records [][]string, headerLen, firstRow int) map[int]int {
+	var ragged map[int]int
+	for i, record := range records {
+		if len(record) == headerLen {
+			continue
+		}
+		if ragged == nil {
+			ragged = make(map[int]int)
+		}
+		ragged[firstRow+i] = len(record)
+	}
+	return ragged
+}
+
+// reportRaggedRow records a ValidationError if the row's cell count differs
+// from the header under ProfileStrict, and reports whether it did.
+func reportRaggedRow(run *processRun, rowNum int, result *ProcessResult) bool {
+	count, ok := run.raggedRows[rowNum]
+	if !ok {
+		return false
+	}
+	result.Errors = append(result.Errors, newValidationError(
+		rowNum, "", "", strconv.Itoa(count), columnCountTagName, "",
+		fmt.Sprintf("row has %d cells, header has %d columns", count, run.parsedColumns),
+	))
+	return true
 }
 
 // processRow applies preprocessing and single-field validation to one row.
-// It returns true if the row has any errors, and a non-nil error for fatal
-// conditions (e.g., JSON corruption after preprocessing).
+// It returns whether the row has any errors, whether preprocessing changed
+// any cell of the record, whether a value could not be converted to its
+// field type, and a non-nil error for fatal conditions (e.g., JSON
+// corruption after preprocessing). rawRecord is a copy of record as read,
+// for prep rules that read other columns, or nil.
 func (p *Processor) processRow(
 	record []string,
+	rawRecord []string,
 	rowNum int,
 	structInfo *structInfo,
 	structValue reflect.Value,
 	result *ProcessResult,
 	isJSONFormat bool,
 	jsonDataColumn string,
-) (bool, error) {
+) (bool, bool, bool, error) {
 	rowHasError := false
+	rowModified := false
+	convFailed := false
 
 	for _, fieldInfo := range structInfo.Fields {
 		colIdx := fieldInfo.ColumnIndex
@@ -262,57 +2374,159 @@ func (p *Processor) processRow(
 
 		colName := fieldInfo.ColumnName
 
-		// Apply preprocessing and update record in-place
-		processedValue := fieldInfo.Preprocessors.Process(value)
-		if colIdx >= 0 && colIdx < len(record) {
-			record[colIdx] = processedValue
+		// Apply preprocessing and update record in-place. Fields without prep
+		// tags skip the chain entirely since the value cannot change.
+		processedValue := value
+		hasPrep := len(fieldInfo.Preprocessors) > 0
+		if hasPrep {
+			processedValue = fieldInfo.Preprocessors.processRecord(value, rawRecord)
+			if colIdx >= 0 && colIdx < len(record) && processedValue != value {
+				rowModified = true
+				record[colIdx] = processedValue
+			}
+			if processedValue != value && fieldInfo.Preprocessors.changedByTag(truncateTagValue, value, rawRecord) {
+				result.repair(colName, func(c *ColumnRepairs) { c.Truncated++ })
+			}
+			if processedValue != value && (fieldInfo.Preprocessors.changedByTag(clampTagValue, value, rawRecord) ||
+				fieldInfo.Preprocessors.changedByTag(winsorizeTagValue, value, rawRecord)) {
+				result.repair(colName, func(c *ColumnRepairs) { c.Clamped++ })
+			}
+			if p.changeTracking && processedValue != value {
+				result.changes = append(result.changes, CellChange{
+					Row:     rowNum,
+					Column:  colName,
+					Field:   fieldInfo.Name,
+					Before:  value,
+					After:   processedValue,
+					Applied: fieldInfo.Preprocessors.changedBy(value, rawRecord),
+				})
+			}
+			if p.checkIdempotentPrep {
+				if again := fieldInfo.Preprocessors.processRecord(processedValue, rawRecord); again != processedValue {
+					ve := newValidationError(rowNum, colName, fieldInfo.Name, processedValue, prepTagName, "",
+						"prep chain is not idempotent: a second pass changes "+strconv.Quote(processedValue)+
+							" to "+strconv.Quote(again))
+					ve.code = CodeNonIdempotentPrep
+					result.Warnings = append(result.Warnings, ve.asWarning())
+				}
+			}
 		}
 
 		// For JSON/JSONL formats, verify the "data" column integrity after preprocessing.
 		// Only the "data" column contains JSON values; other struct fields may map to
 		// non-existent columns and receive default/preprocessed non-JSON values, so
-		// checking all fields would cause false positives.
-		if isJSONFormat && colName == jsonDataColumn {
+		// checking all fields would cause false positives. Untouched values
+		// are left as parsed, so only fields with prep tags need the check.
+		if hasPrep && isJSONFormat && colName == jsonDataColumn {
 			if processedValue != "" && !json.Valid([]byte(processedValue)) {
 				// Prep tags (e.g. truncate, replace) destroyed the JSON structure.
 				// This is a hard error: invalid JSON lines in JSONL output cause
 				// downstream parsers to fail entirely.
-				return false, fmt.Errorf("row %d, column %q: %w: %s",
+				return false, false, false, fmt.Errorf("row %d, column %q: %w: %s",
 					rowNum, colName, ErrInvalidJSONAfterPrep, truncateForError(processedValue, 100))
 			} else if value != "" && processedValue == "" {
 				// Preprocessing emptied the JSON data (e.g. nullify).
 				// The row will be skipped in JSONL output, so record a PrepError
 				// to keep ValidRowCount consistent with actual output line count.
 				result.Errors = append(result.Errors, newPrepError(
-					rowNum, colName, fieldInfo.Name, "empty_json_data",
+					rowNum, colName, fieldInfo.Name, emptyJSONDataTag,
 					"JSON data is empty after preprocessing (original: "+truncateForError(value, 100)+")",
 				))
 				rowHasError = true
 			}
 		}
 
-		// Apply validation
-		if tag, msg := fieldInfo.Validators.Validate(processedValue); msg != "" {
+		// Apply validation. With orclear, an invalid value is cleared instead
+		// of reported, and only required can still reject the field.
+		v, msg := fieldInfo.Validators.Validate(processedValue)
+		if msg != "" && v.Name() != requiredTagValue && fieldInfo.Validators.clearsInvalid() {
+			if processedValue != "" {
+				if p.changeTracking {
+					result.changes = appendClearedChange(result.changes, rowNum, colName, fieldInfo.Name, value, processedValue)
+				}
+				processedValue = ""
+				if colIdx >= 0 && colIdx < len(record) {
+					rowModified = true
+					record[colIdx] = processedValue
+				}
+				result.repair(colName, func(c *ColumnRepairs) { c.Cleared++ })
+			}
+			v, msg = fieldInfo.Validators.validateCleared()
+		}
+		if msg != "" {
 			result.Errors = append(result.Errors, newValidationError(
-				rowNum, colName, fieldInfo.Name, processedValue, tag, msg,
+				rowNum, colName, fieldInfo.Name, reportedValue(v, processedValue), v.Name(), validatorParam(v), msg,
 			))
 			rowHasError = true
+			if p.fixSuggestions {
+				if suggested, rule, ok := suggestFix(v, processedValue, fieldInfo.Validators); ok {
+					result.Suggestions = append(result.Suggestions, FixSuggestion{
+						Row: rowNum, Column: colName, Field: fieldInfo.Name, Value: processedValue, Suggested: suggested, Rule: rule,
+					})
+				}
+			}
+		}
+
+		// Warning-level rules are reported but do not invalidate the row
+		if v, msg := fieldInfo.WarnValidators.Validate(processedValue); msg != "" {
+			result.Warnings = append(result.Warnings, newValidationError(
+				rowNum, colName, fieldInfo.Name, reportedValue(v, processedValue), v.Name(), validatorParam(v), msg,
+			).asWarning())
+		}
+
+		// Schema rules check a column that has no struct field
+		if fieldInfo.Index < 0 {
+			continue
 		}
 
 		// Set struct field value (use field index, not column index)
-		if err := setFieldValue(structValue.Field(fieldInfo.Index), processedValue); err != nil {
-			result.Errors = append(result.Errors, newPrepError(
-				rowNum, colName, fieldInfo.Name, "type_conversion",
-				fmt.Sprintf("failed to convert value %q: %v", processedValue, err),
-			))
+		field := structValue.Field(fieldInfo.Index)
+		if err := setFieldValue(field, processedValue); err != nil {
+			if p.conversionAsInvalid {
+				result.Errors = append(result.Errors, newTypeValidationError(
+					rowNum, colName, fieldInfo.Name, processedValue, field.Type(),
+				))
+			} else {
+				result.Errors = append(result.Errors, newConversionError(
+					rowNum, colName, fieldInfo.Name, processedValue, err,
+				))
+			}
 			rowHasError = true
+			convFailed = true
+			result.repair(colName, func(c *ColumnRepairs) { c.ConversionFallbacks++ })
+			if p.onConversionError.mode == conversionDefault {
+				if err := setFieldValue(field, p.onConversionError.value); err != nil {
+					return false, false, false, fmt.Errorf("row %d, column %q: conversion fallback %q for field %s: %w",
+						rowNum, colName, p.onConversionError.value, fieldInfo.Name, err)
+				}
+			}
 		}
 	}
 
-	return rowHasError, nil
+	return rowHasError, rowModified, convFailed, nil
+}
+
+// appendClearedChange records a value cleared by orclear. If preprocessing
+// changed the value first, its change, the last one recorded, is extended
+// instead, so each cell has one change.
+func appendClearedChange(changes []CellChange, rowNum int, colName, fieldName, value, processedValue string) []CellChange {
+	if processedValue != value {
+		last := &changes[len(changes)-1]
+		last.After = ""
+		last.Applied = append(last.Applied, orclearTagValue)
+		return changes
+	}
+	return append(changes, CellChange{
+		Row:     rowNum,
+		Column:  colName,
+		Field:   fieldName,
+		Before:  value,
+		Applied: []string{orclearTagValue},
+	})
 }
 
 // applyCrossFieldValidation runs cross-field validators for one row.
+// Failures of warn-tag rules are recorded in result.Warnings.
 // It returns true if any cross-field validation error was found.
 func (p *Processor) applyCrossFieldValidation(
 	record []string,
@@ -323,69 +2537,150 @@ func (p *Processor) applyCrossFieldValidation(
 ) bool {
 	hasError := false
 
-	for _, fieldInfo := range structInfo.Fields {
-		if len(fieldInfo.CrossFieldValidators) == 0 {
+	for i := range structInfo.Fields {
+		fieldInfo := &structInfo.Fields[i]
+		if len(fieldInfo.CrossFieldValidators) == 0 && len(fieldInfo.WarnCrossValidators) == 0 {
 			continue
 		}
 
-		colIdx := fieldInfo.ColumnIndex
-		srcValue := ""
-		if colIdx >= 0 && colIdx < len(record) {
-			srcValue = record[colIdx]
+		for _, ve := range crossFieldFailures(record, rowNum, fieldInfo, fieldInfo.CrossFieldValidators, fieldNameToColIdx) {
+			result.Errors = append(result.Errors, ve)
+			hasError = true
 		}
-		colName := fieldInfo.ColumnName
+		for _, ve := range crossFieldFailures(record, rowNum, fieldInfo, fieldInfo.WarnCrossValidators, fieldNameToColIdx) {
+			result.Warnings = append(result.Warnings, ve.asWarning())
+		}
+	}
 
-		for _, crossValidator := range fieldInfo.CrossFieldValidators {
-			targetFieldName := crossValidator.TargetField()
-			targetColIdx, ok := fieldNameToColIdx[targetFieldName]
-			if !ok || targetColIdx < 0 {
-				result.Errors = append(result.Errors, newValidationError(
-					rowNum, colName, fieldInfo.Name, srcValue,
-					crossValidator.Name(),
-					fmt.Sprintf("target field %s not found", targetFieldName),
-				))
-				hasError = true
-				continue
-			}
+	return hasError
+}
 
-			if targetColIdx >= len(record) {
-				result.Errors = append(result.Errors, newValidationError(
-					rowNum, colName, fieldInfo.Name, srcValue,
-					crossValidator.Name(),
-					fmt.Sprintf("target field %s index out of range", targetFieldName),
-				))
-				hasError = true
-				continue
-			}
+// crossFieldFailures runs the given cross-field validators for one field
+// and returns a ValidationError for each failure.
+func crossFieldFailures(
+	record []string,
+	rowNum int,
+	fieldInfo *fieldInfo,
+	crossValidators crossFieldValidators,
+	fieldNameToColIdx map[string]int,
+) []*ValidationError {
+	if len(crossValidators) == 0 {
+		return nil
+	}
 
-			targetValue := record[targetColIdx]
-			if msg := crossValidator.Validate(srcValue, targetValue); msg != "" {
-				result.Errors = append(result.Errors, newValidationError(
+	colIdx := fieldInfo.ColumnIndex
+	srcValue := ""
+	if colIdx >= 0 && colIdx < len(record) {
+		srcValue = record[colIdx]
+	}
+	colName := fieldInfo.ColumnName
+
+	var failures []*ValidationError
+	for _, crossValidator := range crossValidators {
+		multi, isMulti := crossValidator.(multiFieldValidator)
+		targetFieldNames := []string{crossValidator.TargetField()}
+		if isMulti {
+			targetFieldNames = multi.TargetFields()
+		}
+
+		targetValues := make([]string, 0, len(targetFieldNames))
+		var lookupErr *ValidationError
+		for _, targetFieldName := range targetFieldNames {
+			value, problem := targetFieldValue(record, targetFieldName, fieldNameToColIdx)
+			if problem != "" {
+				lookupErr = newValidationError(
 					rowNum, colName, fieldInfo.Name, srcValue,
-					crossValidator.Name(), msg,
-				))
-				hasError = true
+					crossValidator.Name(), crossValidator.TargetField(),
+					"target field "+targetFieldName+" "+problem,
+				)
+				lookupErr.code = CodeTargetFieldNotFound
+				break
 			}
+			targetValues = append(targetValues, value)
+		}
+		if lookupErr != nil {
+			failures = append(failures, lookupErr)
+			continue
+		}
+
+		var msg string
+		if isMulti {
+			msg = multi.ValidateFields(srcValue, targetValues)
+		} else {
+			msg = crossValidator.Validate(srcValue, targetValues[0])
+		}
+		if msg != "" {
+			failures = append(failures, newValidationError(
+				rowNum, colName, fieldInfo.Name, srcValue,
+				crossValidator.Name(), crossValidator.TargetField(), msg,
+			))
 		}
 	}
+	return failures
+}
 
-	return hasError
+// targetFieldValue returns the value of a cross-field target in record.
+// problem is non-empty when the target cannot be read.
+func targetFieldValue(record []string, targetFieldName string, fieldNameToColIdx map[string]int) (value, problem string) {
+	targetColIdx, ok := fieldNameToColIdx[targetFieldName]
+	if !ok || targetColIdx < 0 {
+		return "", "not found"
+	}
+	if targetColIdx >= len(record) {
+		return "", "index out of range"
+	}
+	return record[targetColIdx], ""
 }
 
 // buildOutput generates the output io.Reader from processed records.
-// When validRowsOnly is enabled, validRecords is used instead of all records.
-func (p *Processor) buildOutput(headers []string, records [][]string, validRecords [][]string, isJSONFormat bool) (io.Reader, error) {
+// When only some rows go to the output, selectedRecords and selectedRowNums
+// are used instead of all records. firstRow is the input row number of
+// records[0].
+func (p *Processor) buildOutput(
+	headers []string,
+	records [][]string,
+	selectedRecords [][]string,
+	selectedRowNums []int,
+	firstRow int,
+	isJSONFormat bool,
+	errs []error,
+) (*stream, error) {
 	// Select which records to include in output
 	outputRecords := records
-	if p.validRowsOnly {
-		outputRecords = validRecords
+	var rowNums []int
+	if p.selectsRows() {
+		outputRecords = selectedRecords
+		rowNums = selectedRowNums
+	}
+	outputRecords, rowNums = p.sampleRows(outputRecords, rowNums, firstRow)
+	if len(p.anonymize) > 0 {
+		if isJSONFormat {
+			return nil, fmt.Errorf("%w: anonymized columns need tabular input", ErrUnsupportedFileType)
+		}
+		var err error
+		if outputRecords, err = p.anonymizeRows(headers, outputRecords); err != nil {
+			return nil, err
+		}
+	}
+	if isJSONFormat {
+		rowNums = jsonlRowNums(outputRecords, rowNums, firstRow)
 	}
 
 	// Pre-allocate buffer capacity based on estimated output size to reduce allocations
 	var outputBuf bytes.Buffer
 	estimatedSize := p.estimateOutputSize(headers, outputRecords)
 	outputBuf.Grow(estimatedSize)
-	if err := p.writeOutput(&outputBuf, headers, outputRecords); err != nil {
+	var err error
+	if p.outputFormat() == fileparser.XLSX {
+		var errSheet *xlsxErrorSheet
+		if p.xlsxErrorSheet {
+			errSheet = newXLSXErrorSheet(errs, headers, rowNums, firstRow, len(outputRecords))
+		}
+		err = writeXLSX(&outputBuf, headers, outputRecords, errSheet)
+	} else {
+		err = p.writeOutput(&outputBuf, headers, outputRecords)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to write output: %w", err)
 	}
 
@@ -395,14 +2690,110 @@ func (p *Processor) buildOutput(headers []string, records [][]string, validRecor
 		return nil, ErrEmptyJSONOutput
 	}
 
-	return newStream(outputBuf.Bytes(), p.outputFormat(), p.fileType), nil
+	return newStream(outputBuf.Bytes(), p.outputFormat(), p.fileType).withRows(firstRow, rowNums), nil
+}
+
+// samplesOutput reports whether WithHead, WithTail, or WithEveryNth is set.
+func (p *Processor) samplesOutput() bool {
+	return p.head > 0 || p.tail > 0 || p.everyNth > 1
+}
+
+// sampleRows applies WithEveryNth, WithHead, and WithTail, in that order, to
+// the output records. rowNums holds the row number of each record, or is nil
+// when the records are contiguous starting at firstRow; the returned row
+// numbers are nil only when no record was dropped.
+func (p *Processor) sampleRows(records [][]string, rowNums []int, firstRow int) ([][]string, []int) {
+	if !p.samplesOutput() {
+		return records, rowNums
+	}
+	if rowNums == nil {
+		rowNums = make([]int, len(records))
+		for i := range rowNums {
+			rowNums[i] = firstRow + i
+		}
+	}
+
+	if p.everyNth > 1 {
+		sampled := make([][]string, 0, (len(records)+p.everyNth-1)/p.everyNth)
+		sampledNums := make([]int, 0, cap(sampled))
+		for i := 0; i < len(records); i += p.everyNth {
+			sampled = append(sampled, records[i])
+			sampledNums = append(sampledNums, rowNums[i])
+		}
+		records, rowNums = sampled, sampledNums
+	}
+	if p.head > 0 && len(records) > p.head {
+		records, rowNums = records[:p.head], rowNums[:p.head]
+	}
+	if p.tail > 0 && len(records) > p.tail {
+		records, rowNums = records[len(records)-p.tail:], rowNums[len(rowNums)-p.tail:]
+	}
+	return records, rowNums
+}
+
+// jsonlRowNums returns the input row numbers of the records that writeJSONL
+// emits, since records with empty data are skipped and leave gaps.
+// rowNums holds the row number of each record, or is nil when the records
+// are contiguous starting at firstRow.
+func jsonlRowNums(records [][]string, rowNums []int, firstRow int) []int {
+	nums := make([]int, 0, len(records))
+	for i, record := range records {
+		if len(record) == 0 || record[0] == "" {
+			continue
+		}
+		if rowNums != nil {
+			nums = append(nums, rowNums[i])
+		} else {
+			nums = append(nums, firstRow+i)
+		}
+	}
+	return nums
+}
+
+// canReuseInput reports whether the decompressed input can be returned as the
+// output stream without re-encoding. This holds for CSV and TSV input when
+// no cell was changed by preprocessing and no row is dropped from the output
+// or skipped by WithStartRow or the sampling options, and no column is
+// anonymized.
+// Other formats are always re-encoded because their output differs from the
+// input (LTSV values are trimmed by the parser, JSON is compacted to JSONL,
+// XLSX and Parquet are converted to CSV).
+func (p *Processor) canReuseInput(modified bool, result *ProcessResult) bool {
+	if modified || p.startRow > 0 || p.samplesOutput() || len(p.anonymize) > 0 {
+		return false
+	}
+	// Lenient parsing accepts input that strict consumers would reject, and a
+	// custom delimiter is not understood by them, so the output must be
+	// re-encoded in RFC 4180 form
+	if p.csvOpts.lazyQuotes || p.csvOpts.backslashEscapes || p.csvOpts.delimiter != 0 {
+		return false
+	}
+	if p.validRowsOnly && result.ValidRowCount != result.RowCount {
+		return false
+	}
+	if result.FilteredRowCount > 0 {
+		return false
+	}
+	if p.outputFormat() != fileparser.BaseFileType(p.fileType) {
+		return false
+	}
+	switch fileparser.BaseFileType(p.fileType) {
+	case fileparser.CSV, fileparser.TSV:
+		return true
+	default:
+		return false
+	}
 }
 
 // outputFormat returns the actual output format for the stream.
 // CSV, TSV, and LTSV preserve their format.
 // JSON and JSONL are output as JSONL (one JSON value per line).
 // XLSX and Parquet are converted to CSV.
+// WithOutputFormat overrides the format.
 func (p *Processor) outputFormat() fileparser.FileType {
+	if p.outputTypeSet {
+		return p.outputType
+	}
 	switch fileparser.BaseFileType(p.fileType) {
 	case fileparser.CSV, fileparser.TSV, fileparser.LTSV:
 		return fileparser.BaseFileType(p.fileType)
@@ -427,6 +2818,19 @@ func (p *Processor) estimateOutputSize(headers []string, records [][]string) int
 	return headerSize + recordSize
 }
 
+// checkOutputFormat checks the WithOutputFormat type against the input.
+func (p *Processor) checkOutputFormat(isJSONFormat bool) error {
+	switch p.outputType {
+	case fileparser.CSV, fileparser.TSV, fileparser.LTSV, fileparser.XLSX:
+	default:
+		return fmt.Errorf("%w: output format must be CSV, TSV, LTSV, or XLSX, got %s", ErrInvalidOption, p.outputType)
+	}
+	if isJSONFormat {
+		return fmt.Errorf("%w: output format %s needs tabular input", ErrUnsupportedFileType, p.outputType)
+	}
+	return nil
+}
+
 // writeOutput writes the processed data back in the original format.
 //
 // Output format by input type:
@@ -437,13 +2841,16 @@ func (p *Processor) estimateOutputSize(headers []string, records [][]string) int
 //   - JSONL → JSONL (one JSON value per line)
 //   - XLSX → CSV (tabular data as comma-delimited)
 //   - Parquet → CSV (tabular data as comma-delimited)
+//
+// WithOutputFormat selects CSV, TSV, or LTSV for any tabular input; XLSX
+// output is written by writeXLSX.
 func (p *Processor) writeOutput(w io.Writer, headers []string, records [][]string) error {
-	switch fileparser.BaseFileType(p.fileType) {
+	switch p.outputFormat() {
 	case fileparser.TSV:
 		return p.writeTSV(w, headers, records)
 	case fileparser.LTSV:
 		return p.writeLTSV(w, headers, records)
-	case fileparser.JSON, fileparser.JSONL:
+	case fileparser.JSONL:
 		return p.writeJSONL(w, records)
 	default:
 		// CSV, XLSX, Parquet all output as CSV (tabular format)
@@ -490,11 +2897,9 @@ func (p *Processor) writeTSV(w io.Writer, headers []string, records [][]string)
 
 // writeLTSV writes data in LTSV format
 func (p *Processor) writeLTSV(w io.Writer, headers []string, records [][]string) error {
-	// Pre-allocate a reusable buffer for building each line
-	var lineBuf strings.Builder
-	// Estimate line size: header + ":" + avg_value_size + "\t" for each field
-	estimatedLineSize := len(headers) * 20
-	lineBuf.Grow(estimatedLineSize)
+	// Build each line in a pooled buffer that is reused across lines
+	lineBuf := getBuffer()
+	defer putBuffer(lineBuf)
 
 	for _, record := range records {
 		lineBuf.Reset()
@@ -509,7 +2914,7 @@ func (p *Processor) writeLTSV(w io.Writer, headers []string, records [][]string)
 			}
 		}
 		lineBuf.WriteByte('\n')
-		if _, err := io.WriteString(w, lineBuf.String()); err != nil {
+		if _, err := w.Write(lineBuf.Bytes()); err != nil {
 			return err
 		}
 	}
@@ -526,7 +2931,9 @@ func (p *Processor) writeLTSV(w io.Writer, headers []string, records [][]string)
 // Pretty-printed JSON from fileparser may contain newlines within a single element,
 // which would break JSONL format without compaction.
 func (p *Processor) writeJSONL(w io.Writer, records [][]string) error {
-	var compactBuf bytes.Buffer
+	compactBuf := getBuffer()
+	defer putBuffer(compactBuf)
+
 	for _, record := range records {
 		// record[0] is the "data" column: fileparser stores each JSON element
 		// as a single-column row for JSON/JSONL input.
@@ -534,7 +2941,7 @@ func (p *Processor) writeJSONL(w io.Writer, records [][]string) error {
 			continue
 		}
 		compactBuf.Reset()
-		if err := json.Compact(&compactBuf, []byte(record[0])); err != nil {
+		if err := json.Compact(compactBuf, []byte(record[0])); err != nil {
 			// Should not happen: invalid JSON is caught by ErrInvalidJSONAfterPrep
 			// before reaching writeJSONL. Return error rather than writing broken JSONL.
 			return fmt.Errorf("failed to compact JSON at output: %w", err)
@@ -549,6 +2956,149 @@ func (p *Processor) writeJSONL(w io.Writer, records [][]string) error {
 	return nil
 }
 
+// outputColumnOrder returns the source column index for each output column,
+// or nil when the output keeps the file's column order.
+func (p *Processor) outputColumnOrder(headers []string, headerToColIdx map[string]int, info *structInfo) ([]int, error) {
+	var leading []int
+	switch {
+	case p.outputColumns != nil:
+		leading = make([]int, 0, len(p.outputColumns))
+		for _, name := range p.outputColumns {
+			colIdx, ok := headerToColIdx[name]
+			if !ok {
+				return nil, fmt.Errorf("output column %q: %w", name, ErrColumnNotFound)
+			}
+			leading = append(leading, colIdx)
+		}
+	case p.structColumnOrder:
+		leading = make([]int, 0, len(info.Fields))
+		for _, fi := range info.Fields {
+			if fi.ColumnIndex >= 0 {
+				leading = append(leading, fi.ColumnIndex)
+			}
+		}
+	case fileparser.BaseFileType(p.fileType) == fileparser.LTSV && p.ltsvKeyOrder.sorted:
+		leading = make([]int, len(headers))
+		for i := range leading {
+			leading[i] = i
+		}
+		slices.SortStableFunc(leading, func(a, b int) int {
+			return strings.Compare(headers[a], headers[b])
+		})
+	case fileparser.BaseFileType(p.fileType) == fileparser.LTSV && p.ltsvKeyOrder.keys != nil:
+		leading = make([]int, 0, len(p.ltsvKeyOrder.keys))
+		for _, key := range p.ltsvKeyOrder.keys {
+			if colIdx, ok := headerToColIdx[key]; ok {
+				leading = append(leading, colIdx)
+			}
+		}
+	default:
+		return nil, nil
+	}
+
+	// Append the remaining columns in file order, skipping duplicates
+	placed := make([]bool, len(headers))
+	order := make([]int, 0, len(headers))
+	for _, colIdx := range leading {
+		if !placed[colIdx] {
+			placed[colIdx] = true
+			order = append(order, colIdx)
+		}
+	}
+	for colIdx := range headers {
+		if !placed[colIdx] {
+			order = append(order, colIdx)
+		}
+	}
+
+	for i, colIdx := range order {
+		if i != colIdx {
+			return order, nil
+		}
+	}
+	return nil, nil
+}
+
+// reorderRow returns a copy of row where column i holds row[order[i]].
+// Rows are padded to the header length during processing, so the bounds
+// check only guards against malformed input.
+func reorderRow(row []string, order []int) []string {
+	reordered := make([]string, len(order))
+	for i, colIdx := range order {
+		if colIdx < len(row) {
+			reordered[i] = row[colIdx]
+		}
+	}
+	return reordered
+}
+
+// reorderRecords applies reorderRow to every record.
+func reorderRecords(records [][]string, order []int) [][]string {
+	reordered := make([][]string, len(records))
+	for i, record := range records {
+		reordered[i] = reorderRow(record, order)
+	}
+	return reordered
+}
+
+// missingFieldColumns returns a SchemaError listing the columns of struct
+// fields that are not in the header, or nil if every field is bound.
+func missingFieldColumns(info *structInfo) *SchemaError {
+	var missing []string
+	for _, fi := range info.Fields {
+		if fi.ColumnIndex < 0 && !slices.Contains(missing, fi.ColumnName) {
+			missing = append(missing, fi.ColumnName)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return &SchemaError{Missing: missing}
+}
+
+// diffColumns compares headers with the expected columns and returns a
+// SchemaError describing the differences, or nil when they match exactly.
+// Reordered lists the common columns whose position differs once missing
+// and extra columns are ignored.
+func diffColumns(headers, expected []string) *SchemaError {
+	headerSet := make(map[string]bool, len(headers))
+	for _, h := range headers {
+		headerSet[h] = true
+	}
+	expectedSet := make(map[string]bool, len(expected))
+	for _, c := range expected {
+		expectedSet[c] = true
+	}
+
+	var schemaErr SchemaError
+	commonExpected := make([]string, 0, len(expected))
+	for _, c := range expected {
+		if headerSet[c] {
+			commonExpected = append(commonExpected, c)
+		} else {
+			schemaErr.Missing = append(schemaErr.Missing, c)
+		}
+	}
+	commonHeaders := make([]string, 0, len(headers))
+	for _, h := range headers {
+		if expectedSet[h] {
+			commonHeaders = append(commonHeaders, h)
+		} else {
+			schemaErr.Extra = append(schemaErr.Extra, h)
+		}
+	}
+	for i := range min(len(commonHeaders), len(commonExpected)) {
+		if commonHeaders[i] != commonExpected[i] {
+			schemaErr.Reordered = append(schemaErr.Reordered, commonHeaders[i])
+		}
+	}
+
+	if schemaErr.Missing == nil && schemaErr.Extra == nil && schemaErr.Reordered == nil {
+		return nil
+	}
+	return &schemaErr
+}
+
 // truncateForError truncates a string for inclusion in error messages.
 // It truncates on rune boundaries to avoid splitting multi-byte characters.
 func truncateForError(s string, maxLen int) string {
@@ -609,7 +3159,7 @@ func setFieldValue(field reflect.Value, value string) error {
 		}
 		field.SetBool(boolVal)
 	default:
-		return fmt.Errorf("unsupported field type: %s", field.Kind())
+		return fmt.Errorf("%w: %s", ErrUnsupportedFieldType, field.Kind())
 	}
 	return nil
 }
@@ -5,9 +5,13 @@ import (
 	"compress/gzip"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -972,7 +976,7 @@ func TestProcessor_JSON_PrettyPrintedGzip(t *testing.T) {
 	t.Parallel()
 
 	// Verify that compressed pretty-printed JSON also produces compact JSONL.
-	// Decompression is handled by fileparser, but the full pipeline
+	// Decompression is handled before parsing, and the full pipeline
 	// (decompress → parse → prep → compact → JSONL) should be exercised.
 	prettyJSON := `[
   {"name": "Alice", "age": 30},
@@ -1722,3 +1726,3399 @@ func TestWriteJSONL_ErrorPath(t *testing.T) {
 		}
 	})
 }
+
+func TestProcessor_ReuseInputWhenUnmodified(t *testing.T) {
+	t.Parallel()
+
+	type plainRecord struct {
+		Name string `validate:"required"`
+		Age  string
+	}
+	type trimRecord struct {
+		Name string `prep:"trim" validate:"required"`
+		Age  string
+	}
+
+	// Quoted fields and CRLF line endings are normalized by csv.Writer,
+	// so an exact byte match proves the input buffer was reused.
+	csvData := "name,age\r\n\"Alice\",30\r\n\"Bob\",25\r\n"
+
+	t.Run("validate-only run returns the original bytes", func(t *testing.T) {
+		t.Parallel()
+
+		var records []plainRecord
+		reader, _, err := NewProcessor(fileparser.CSV).Process(strings.NewReader(csvData), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		got, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if string(got) != csvData {
+			t.Errorf("output = %q, want %q", got, csvData)
+		}
+	})
+
+	t.Run("compressed input returns the decompressed bytes", func(t *testing.T) {
+		t.Parallel()
+
+		var compressed bytes.Buffer
+		gw := gzip.NewWriter(&compressed)
+		if _, err := gw.Write([]byte(csvData)); err != nil {
+			t.Fatalf("gzip write error: %v", err)
+		}
+		if err := gw.Close(); err != nil {
+			t.Fatalf("gzip close error: %v", err)
+		}
+
+		var records []plainRecord
+		reader, _, err := NewProcessor(fileparser.CSVGZ).Process(&compressed, &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		got, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if string(got) != csvData {
+			t.Errorf("output = %q, want %q", got, csvData)
+		}
+	})
+
+	t.Run("unchanged values with trim are still reused", func(t *testing.T) {
+		t.Parallel()
+
+		var records []trimRecord
+		reader, _, err := NewProcessor(fileparser.CSV).Process(strings.NewReader(csvData), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		got, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if string(got) != csvData {
+			t.Errorf("output = %q, want %q", got, csvData)
+		}
+	})
+
+	t.Run("modified values are re-encoded", func(t *testing.T) {
+		t.Parallel()
+
+		input := "name,age\r\n\"  Alice  \",30\r\n"
+		var records []trimRecord
+		reader, _, err := NewProcessor(fileparser.CSV).Process(strings.NewReader(input), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		got, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		want := "name,age\nAlice,30\n"
+		if string(got) != want {
+			t.Errorf("output = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("dropped invalid rows are re-encoded", func(t *testing.T) {
+		t.Parallel()
+
+		input := "name,age\r\n\"Alice\",30\r\n,25\r\n"
+		var records []plainRecord
+		reader, _, err := NewProcessor(fileparser.CSV, WithValidRowsOnly()).Process(strings.NewReader(input), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		got, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		want := "name,age\nAlice,30\n"
+		if string(got) != want {
+			t.Errorf("output = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestProcessor_WithStartRow(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		Name string `prep:"trim" validate:"required"`
+		Age  int
+	}
+
+	csvData := "name,age\nAlice,30\nBob,25\n,40\nDave,35\n"
+
+	tests := []struct {
+		name         string
+		startRow     int
+		wantNames    []string
+		wantRowCount int
+		wantErrRows  []int
+		wantOutput   string
+	}{
+		{
+			name:         "zero processes all rows",
+			startRow:     0,
+			wantNames:    []string{"Alice", "Bob", "", "Dave"},
+			wantRowCount: 4,
+			wantErrRows:  []int{3},
+			wantOutput:   csvData,
+		},
+		{
+			name:         "skip committed rows",
+			startRow:     2,
+			wantNames:    []string{"", "Dave"},
+			wantRowCount: 2,
+			wantErrRows:  []int{3},
+			wantOutput:   "name,age\n,40\nDave,35\n",
+		},
+		{
+			name:         "negative is treated as zero",
+			startRow:     -1,
+			wantNames:    []string{"Alice", "Bob", "", "Dave"},
+			wantRowCount: 4,
+			wantErrRows:  []int{3},
+			wantOutput:   csvData,
+		},
+		{
+			name:         "past the end leaves only the header",
+			startRow:     10,
+			wantNames:    nil,
+			wantRowCount: 0,
+			wantErrRows:  nil,
+			wantOutput:   "name,age\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var records []record
+			processor := NewProcessor(fileparser.CSV, WithStartRow(tt.startRow))
+			reader, result, err := processor.Process(strings.NewReader(csvData), &records)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if result.RowCount != tt.wantRowCount {
+				t.Errorf("RowCount = %d, want %d", result.RowCount, tt.wantRowCount)
+			}
+
+			var gotNames []string
+			for _, r := range records {
+				gotNames = append(gotNames, r.Name)
+			}
+			if diff := cmp.Diff(tt.wantNames, gotNames); diff != "" {
+				t.Errorf("names mismatch (-want +got):\n%s", diff)
+			}
+
+			var gotErrRows []int
+			for _, ve := range result.ValidationErrors() {
+				gotErrRows = append(gotErrRows, ve.Row)
+			}
+			if diff := cmp.Diff(tt.wantErrRows, gotErrRows); diff != "" {
+				t.Errorf("error rows mismatch (-want +got):\n%s", diff)
+			}
+
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if string(got) != tt.wantOutput {
+				t.Errorf("output = %q, want %q", got, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestProcessor_WithStartRow_ColumnStats(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		Score  string `validate:"outlier=2sigma"`
+		Capped string `prep:"winsorize=0:75"`
+	}
+
+	// The skipped rows would move the mean and the 75th percentile far up
+	csvData := "score,capped\n1000,1000\n1000,1000\n1000,1000\n" +
+		"1,1\n2,2\n3,3\n2,2\n1,1\n2,2\n3,3\n2,2\n50,50\n"
+	wantErrRows := []int{12}
+	wantCapped := []string{"1", "2", "3", "2", "1", "2", "3", "2", "3"}
+
+	check := func(t *testing.T, records []record, errs []*ValidationError) {
+		t.Helper()
+		var gotErrRows []int
+		for _, ve := range errs {
+			gotErrRows = append(gotErrRows, ve.Row)
+		}
+		if diff := cmp.Diff(wantErrRows, gotErrRows); diff != "" {
+			t.Errorf("outlier rows mismatch (-want +got):\n%s", diff)
+		}
+		var gotCapped []string
+		for _, r := range records {
+			gotCapped = append(gotCapped, r.Capped)
+		}
+		if diff := cmp.Diff(wantCapped, gotCapped); diff != "" {
+			t.Errorf("winsorized values mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	t.Run("Process", func(t *testing.T) {
+		t.Parallel()
+
+		var records []record
+		_, result, err := NewProcessor(fileparser.CSV, WithStartRow(3)).Process(strings.NewReader(csvData), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		check(t, records, result.ValidationErrors())
+	})
+
+	t.Run("ProcessChunks", func(t *testing.T) {
+		t.Parallel()
+
+		var chunk, records []record
+		var errs []*ValidationError
+		err := NewProcessor(fileparser.CSV, WithStartRow(3)).ProcessChunks(strings.NewReader(csvData), &chunk, 4,
+			func(_ Stream, result *ProcessResult) error {
+				records = append(records, chunk...)
+				errs = append(errs, result.ValidationErrors()...)
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("ProcessChunks() error = %v", err)
+		}
+		check(t, records, errs)
+	})
+}
+
+func TestProcessor_RowOffsets(t *testing.T) {
+	t.Parallel()
+
+	t.Run("reused CSV input", func(t *testing.T) {
+		t.Parallel()
+
+		type record struct {
+			Name string
+			Note string
+		}
+		csvData := "name,note\r\nAlice,\"line1\nline2\"\r\nBob,ok\r\n"
+
+		var records []record
+		reader, _, err := NewProcessor(fileparser.CSV).Process(strings.NewReader(csvData), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		want := []RowOffset{{Row: 1, Offset: 11}, {Row: 2, Offset: 32}}
+		if diff := cmp.Diff(want, reader.(Stream).RowOffsets()); diff != "" {
+			t.Errorf("RowOffsets() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("valid rows only after start row", func(t *testing.T) {
+		t.Parallel()
+
+		type record struct {
+			Name string `validate:"required"`
+		}
+		csvData := "name\nAlice\n\"\"\nCarol\nDave\n"
+
+		var records []record
+		processor := NewProcessor(fileparser.CSV, WithStartRow(1), WithValidRowsOnly())
+		reader, _, err := processor.Process(strings.NewReader(csvData), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		s := reader.(Stream)
+		want := []RowOffset{{Row: 3, Offset: 5}, {Row: 4, Offset: 11}}
+		if diff := cmp.Diff(want, s.RowOffsets()); diff != "" {
+			t.Errorf("RowOffsets() mismatch (-want +got):\n%s", diff)
+		}
+
+		// Each offset points at the start of the row's line
+		line := make([]byte, 4)
+		if _, err := s.ReadAt(line, want[1].Offset); err != nil {
+			t.Fatalf("ReadAt() error = %v", err)
+		}
+		if string(line) != "Dave" {
+			t.Errorf("ReadAt(offset of row 4) = %q, want %q", line, "Dave")
+		}
+	})
+
+	t.Run("JSONL skips rows emptied by preprocessing", func(t *testing.T) {
+		t.Parallel()
+
+		type record struct {
+			Data string `name:"data" prep:"nullify={\"skip\":true}"`
+		}
+		jsonlData := "{\"a\":1}\n{\"skip\":true}\n{\"b\":2}\n"
+
+		var records []record
+		reader, _, err := NewProcessor(fileparser.JSONL).Process(strings.NewReader(jsonlData), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		want := []RowOffset{{Row: 1, Offset: 0}, {Row: 3, Offset: 8}}
+		if diff := cmp.Diff(want, reader.(Stream).RowOffsets()); diff != "" {
+			t.Errorf("RowOffsets() mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestProcessor_WithExpectedColumns(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		ID   string
+		Name string
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		columns []string
+		want    *SchemaError
+	}{
+		{
+			name:    "exact match",
+			data:    "id,name\n1,Alice\n",
+			columns: []string{"id", "name"},
+			want:    nil,
+		},
+		{
+			name:    "missing column",
+			data:    "id\n1\n",
+			columns: []string{"id", "name"},
+			want:    &SchemaError{Missing: []string{"name"}},
+		},
+		{
+			name:    "extra column",
+			data:    "id,name,email\n1,Alice,a@example.com\n",
+			columns: []string{"id", "name"},
+			want:    &SchemaError{Extra: []string{"email"}},
+		},
+		{
+			name:    "reordered columns",
+			data:    "name,id\nAlice,1\n",
+			columns: []string{"id", "name"},
+			want:    &SchemaError{Reordered: []string{"name", "id"}},
+		},
+		{
+			name:    "all kinds of drift",
+			data:    "name,id,phone\nAlice,1,555\n",
+			columns: []string{"id", "name", "email"},
+			want: &SchemaError{
+				Missing:   []string{"email"},
+				Extra:     []string{"phone"},
+				Reordered: []string{"name", "id"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var records []record
+			processor := NewProcessor(fileparser.CSV, WithExpectedColumns(tt.columns...))
+			_, _, err := processor.Process(strings.NewReader(tt.data), &records)
+
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Process() error = %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrSchemaMismatch) {
+				t.Fatalf("Process() error = %v, want ErrSchemaMismatch", err)
+			}
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("Process() error = %T, want *SchemaError", err)
+			}
+			if diff := cmp.Diff(tt.want, schemaErr); diff != "" {
+				t.Errorf("SchemaError mismatch (-want +got):\n%s", diff)
+			}
+			if len(records) != 0 {
+				t.Errorf("records = %v, want none processed", records)
+			}
+		})
+	}
+}
+
+func TestProcessor_OutputColumnOrder(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		Email string `prep:"trim"`
+		ID    string
+	}
+
+	csvData := "id,name,email\n1,Alice,a@example.com\n2,Bob,b@example.com\n"
+
+	tests := []struct {
+		name     string
+		fileType fileparser.FileType
+		data     string
+		opts     []Option
+		want     string
+	}{
+		{
+			name:     "file order by default",
+			fileType: fileparser.CSV,
+			data:     csvData,
+			want:     csvData,
+		},
+		{
+			name:     "struct order",
+			fileType: fileparser.CSV,
+			data:     csvData,
+			opts:     []Option{WithStructColumnOrder()},
+			want:     "email,id,name\na@example.com,1,Alice\nb@example.com,2,Bob\n",
+		},
+		{
+			name:     "explicit list puts remaining columns last",
+			fileType: fileparser.CSV,
+			data:     csvData,
+			opts:     []Option{WithOutputColumnOrder([]string{"name", "id"})},
+			want:     "name,id,email\nAlice,1,a@example.com\nBob,2,b@example.com\n",
+		},
+		{
+			name:     "explicit list matching file order reuses input",
+			fileType: fileparser.CSV,
+			data:     "id,name,email\r\n1,Alice,a@example.com\r\n",
+			opts:     []Option{WithOutputColumnOrder([]string{"id", "name", "email"})},
+			want:     "id,name,email\r\n1,Alice,a@example.com\r\n",
+		},
+		{
+			name:     "later option wins",
+			fileType: fileparser.CSV,
+			data:     csvData,
+			opts:     []Option{WithOutputColumnOrder([]string{"name"}), WithStructColumnOrder()},
+			want:     "email,id,name\na@example.com,1,Alice\nb@example.com,2,Bob\n",
+		},
+		{
+			name:     "valid rows only",
+			fileType: fileparser.TSV,
+			data:     "id\temail\n1\ta@example.com\n\t\n",
+			opts:     []Option{WithOutputColumnOrder([]string{"email"}), WithValidRowsOnly()},
+			want:     "email\tid\na@example.com\t1\n\t\n",
+		},
+		{
+			name:     "LTSV labels follow the order",
+			fileType: fileparser.LTSV,
+			data:     "id:1\temail:a@example.com\n",
+			opts:     []Option{WithStructColumnOrder()},
+			want:     "email:a@example.com\tid:1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var records []record
+			reader, result, err := NewProcessor(tt.fileType, tt.opts...).Process(strings.NewReader(tt.data), &records)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			got, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+			if len(result.Columns) == 0 {
+				t.Error("result.Columns is empty")
+			}
+		})
+	}
+
+	t.Run("unknown column", func(t *testing.T) {
+		t.Parallel()
+
+		var records []record
+		processor := NewProcessor(fileparser.CSV, WithOutputColumnOrder([]string{"phone"}))
+		if _, _, err := processor.Process(strings.NewReader(csvData), &records); err == nil {
+			t.Error("Process() expected error for unknown column, got nil")
+		}
+	})
+}
+
+func TestProcessor_CSVParseOptions(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		Name string `prep:"trim"`
+		Note string
+	}
+
+	t.Run("lenient input is re-encoded as RFC 4180", func(t *testing.T) {
+		t.Parallel()
+
+		data := "name,note\nAlice,\"say \\\"hi\\\"\"\n"
+		var records []record
+		processor := NewProcessor(fileparser.CSV, WithBackslashEscapes())
+		reader, _, err := processor.Process(strings.NewReader(data), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if records[0].Note != `say "hi"` {
+			t.Errorf("Note = %q, want %q", records[0].Note, `say "hi"`)
+		}
+		got, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		want := "name,note\nAlice,\"say \"\"hi\"\"\"\n"
+		if string(got) != want {
+			t.Errorf("output = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("quoted newline rejected", func(t *testing.T) {
+		t.Parallel()
+
+		data := "name,note\nAlice,\"line1\nline2\"\n"
+		var records []record
+		processor := NewProcessor(fileparser.CSV, WithDisallowQuotedNewlines())
+		_, _, err := processor.Process(strings.NewReader(data), &records)
+		if !errors.Is(err, ErrQuotedNewline) {
+			t.Errorf("Process() error = %v, want ErrQuotedNewline", err)
+		}
+	})
+
+	t.Run("options do not affect other formats", func(t *testing.T) {
+		t.Parallel()
+
+		data := "name:Alice\tnote:x\"y\n"
+		var records []record
+		processor := NewProcessor(fileparser.LTSV, WithLazyQuotes(), WithDisallowQuotedNewlines())
+		if _, _, err := processor.Process(strings.NewReader(data), &records); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if records[0].Note != `x"y` {
+			t.Errorf("Note = %q, want %q", records[0].Note, `x"y`)
+		}
+	})
+}
+
+func TestProcessor_WithoutValidators(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		Email   string `validate:"required,email"`
+		Site    string `validate:"url"`
+		Confirm string `validate:"eqfield=Email"`
+	}
+
+	csvData := "email,site,confirm\nnot-an-email,not-a-url,other\n"
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		wantTags []string
+	}{
+		{
+			name:     "all validators run by default",
+			wantTags: []string{"email", "url", "eqfield"},
+		},
+		{
+			name:     "single validator disabled",
+			opts:     []Option{WithoutValidators("email")},
+			wantTags: []string{"url", "eqfield"},
+		},
+		{
+			name:     "cross-field validator disabled",
+			opts:     []Option{WithoutValidators("eqfield")},
+			wantTags: []string{"email", "url"},
+		},
+		{
+			name:     "repeated option accumulates names",
+			opts:     []Option{WithoutValidators("email"), WithoutValidators("url", "eqfield")},
+			wantTags: nil,
+		},
+		{
+			name:     "unknown name is ignored",
+			opts:     []Option{WithoutValidators("no_such_validator")},
+			wantTags: []string{"email", "url", "eqfield"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var records []record
+			_, result, err := NewProcessor(fileparser.CSV, tt.opts...).Process(strings.NewReader(csvData), &records)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			var gotTags []string
+			for _, ve := range result.ValidationErrors() {
+				gotTags = append(gotTags, ve.Tag)
+			}
+			if diff := cmp.Diff(tt.wantTags, gotTags); diff != "" {
+				t.Errorf("failed tags mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_Warnings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("warn tag does not invalidate rows", func(t *testing.T) {
+		t.Parallel()
+
+		type record struct {
+			Age     string `validate:"required" warn:"max=150"`
+			Email   string `warn:"omitempty,email"`
+			Confirm string `warn:"eqfield=Email"`
+		}
+		csvData := "age,email,confirm\n200,a@example.com,b@example.com\n30,,\n"
+
+		var records []record
+		_, result, err := NewProcessor(fileparser.CSV, WithValidRowsOnly()).Process(strings.NewReader(csvData), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		if result.ValidRowCount != 2 || len(records) != 2 {
+			t.Errorf("ValidRowCount = %d, records = %d, want 2 and 2", result.ValidRowCount, len(records))
+		}
+		if result.HasErrors() {
+			t.Errorf("Errors = %v, want none", result.Errors)
+		}
+		if !result.HasWarnings() {
+			t.Fatal("HasWarnings() = false, want true")
+		}
+
+		type warning struct {
+			Row   int
+			Field string
+			Tag   string
+		}
+		var got []warning
+		for _, w := range result.Warnings {
+			got = append(got, warning{w.Row, w.Field, w.Tag})
+			if w.Severity != SeverityWarning {
+				t.Errorf("Severity = %v, want %v", w.Severity, SeverityWarning)
+			}
+		}
+		want := []warning{{1, "Age", "max"}, {1, "Confirm", "eqfield"}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("WithWarningValidators demotes validate rules", func(t *testing.T) {
+		t.Parallel()
+
+		type record struct {
+			Age   string `validate:"required,max=150"`
+			Email string `validate:"omitempty,email"`
+		}
+		csvData := "age,email\n200,\n,not-an-email\n"
+
+		var records []record
+		processor := NewProcessor(fileparser.CSV, WithWarningValidators("max", "email"))
+		_, result, err := processor.Process(strings.NewReader(csvData), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		if result.ValidRowCount != 1 {
+			t.Errorf("ValidRowCount = %d, want 1 (only the missing age is an error)", result.ValidRowCount)
+		}
+		var gotTags []string
+		for _, w := range result.Warnings {
+			gotTags = append(gotTags, w.Tag)
+		}
+		// Demoted rules run independently of the remaining validate rules, so the
+		// empty age in row 2 fails both required (error) and max (warning).
+		// The empty email in row 1 stays skipped by the carried-over omitempty.
+		if diff := cmp.Diff([]string{"max", "max", "email"}, gotTags); diff != "" {
+			t.Errorf("warning tags mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("WithoutValidators also disables warn rules", func(t *testing.T) {
+		t.Parallel()
+
+		type record struct {
+			Age string `warn:"max=150"`
+		}
+		var records []record
+		processor := NewProcessor(fileparser.CSV, WithoutValidators("max"))
+		_, result, err := processor.Process(strings.NewReader("age\n200\n"), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if result.HasWarnings() {
+			t.Errorf("Warnings = %v, want none", result.Warnings)
+		}
+	})
+
+	t.Run("invalid warn tag", func(t *testing.T) {
+		t.Parallel()
+
+		type record struct {
+			Name string `warn:"no_such_rule"`
+		}
+		var records []record
+		_, _, err := NewProcessor(fileparser.CSV).Process(strings.NewReader("name\nx\n"), &records)
+		if !errors.Is(err, ErrInvalidTagFormat) {
+			t.Errorf("Process() error = %v, want ErrInvalidTagFormat", err)
+		}
+	})
+}
+
+func TestProcessor_ValidationErrorCodes(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		Name  string `validate:"required"`
+		Email string `validate:"email"`
+		Other string `validate:"eqfield=Missing"`
+	}
+
+	var records []record
+	_, result, err := NewProcessor(fileparser.CSV).Process(strings.NewReader("name,email,other\n,bad,x\n"), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	var gotCodes []string
+	for _, ve := range result.ValidationErrors() {
+		gotCodes = append(gotCodes, ve.Code())
+		if ve.Severity != SeverityError {
+			t.Errorf("Severity = %v, want %v", ve.Severity, SeverityError)
+		}
+	}
+	want := []string{CodeRequiredMissing, "EMAIL_INVALID", CodeTargetFieldNotFound}
+	if diff := cmp.Diff(want, gotCodes); diff != "" {
+		t.Errorf("codes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_WithStrictValidation(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		Name string `validate:"required"`
+		Age  int    `warn:"max=150"`
+	}
+
+	tests := []struct {
+		name      string
+		data      string
+		wantErr   bool
+		wantCount int
+	}{
+		{"all rows valid", "name,age\nAlice,30\n", false, 0},
+		{"warnings do not fail", "name,age\nAlice,200\n", false, 0},
+		{"validation and prep errors are combined", "name,age\n,30\nBob,abc\n", true, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var records []record
+			processor := NewProcessor(fileparser.CSV, WithStrictValidation())
+			reader, result, err := processor.Process(strings.NewReader(tt.data), &records)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Process() error = %v", err)
+				}
+				if reader == nil {
+					t.Error("reader = nil, want non-nil")
+				}
+				return
+			}
+
+			var me *MultiError
+			if !errors.As(err, &me) {
+				t.Fatalf("Process() error = %v, want *MultiError", err)
+			}
+			if len(me.Errors) != tt.wantCount {
+				t.Errorf("len(MultiError.Errors) = %d, want %d", len(me.Errors), tt.wantCount)
+			}
+			if !errors.Is(err, ErrValidation) || !errors.Is(err, ErrPrep) {
+				t.Error("errors.Is() did not match both ErrValidation and ErrPrep")
+			}
+			if reader != nil {
+				t.Error("reader != nil, want nil on strict failure")
+			}
+			if result == nil || result.RowCount != 2 {
+				t.Errorf("result = %+v, want RowCount 2", result)
+			}
+		})
+	}
+}
+
+func TestProcessor_ColumnStatsValidators(t *testing.T) {
+	t.Parallel()
+
+	type reading struct {
+		Sensor string
+		Temp   string `prep:"trim" validate:"outlier=2sigma"`
+		Humid  string `warn:"percentile=10:90"`
+	}
+
+	// Temp: nine readings near 20 and one glitch at 999
+	var b strings.Builder
+	b.WriteString("sensor,temp,humid\n")
+	for i := range 9 {
+		fmt.Fprintf(&b, "s%d, %d ,%d\n", i, 19+i%3, 40+i)
+	}
+	b.WriteString("s9, 999 ,80\n")
+
+	var records []reading
+	_, result, err := NewProcessor(fileparser.CSV).Process(strings.NewReader(b.String()), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	var errRows []int
+	for _, ve := range result.ValidationErrors() {
+		if ve.Tag != "outlier" || ve.Param != "2sigma" {
+			t.Errorf("unexpected error %v", ve)
+		}
+		errRows = append(errRows, ve.Row)
+	}
+	if diff := cmp.Diff([]int{10}, errRows); diff != "" {
+		t.Errorf("outlier rows mismatch (-want +got):\n%s", diff)
+	}
+
+	var warnRows []int
+	for _, w := range result.Warnings {
+		warnRows = append(warnRows, w.Row)
+	}
+	// 10th percentile is 40.9 and 90th is 49.3, so rows 1 (40) and 10 (80) warn
+	if diff := cmp.Diff([]int{1, 10}, warnRows); diff != "" {
+		t.Errorf("percentile warning rows mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_MonotonicValidators(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		Seq       string `validate:"increasing"`
+		Timestamp string `prep:"trim" validate:"nondecreasing"`
+	}
+
+	input := "seq,timestamp\n" +
+		"1,2024-01-01T00:00:00Z\n" +
+		"2, 2024-01-01T00:00:00Z\n" +
+		"3,2023-12-31T00:00:00Z\n" +
+		"3,2024-01-02T00:00:00Z\n" +
+		"2,2024-01-01T00:00:00Z\n"
+
+	var records []event
+	_, result, err := NewProcessor(fileparser.CSV).Process(strings.NewReader(input), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	type failure struct {
+		Row int
+		Tag string
+	}
+	var got []failure
+	for _, ve := range result.ValidationErrors() {
+		got = append(got, failure{Row: ve.Row, Tag: ve.Tag})
+	}
+	want := []failure{{Row: 3, Tag: "nondecreasing"}, {Row: 4, Tag: "increasing"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_UniqueValidator(t *testing.T) {
+	t.Parallel()
+
+	type contact struct {
+		Email     string `prep:"trim,lowercase" validate:"unique=CompanyID"`
+		CompanyID string
+		Code      string `validate:"unique"`
+	}
+
+	input := "email,company_id,code\n" +
+		"a@example.com,1,x\n" +
+		"A@EXAMPLE.COM ,1,y\n" +
+		"a@example.com,2,x\n" +
+		"a@example.com,1,z\n" +
+		",1,\n" +
+		",1,\n"
+
+	type failure struct {
+		Row     int
+		Tag     string
+		Message string
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    []failure
+	}{
+		{
+			name: "every row of a group is reported",
+			want: []failure{
+				{Row: 1, Tag: "unique", Message: "value is not unique: rows 1, 2, 4 share the same key"},
+				{Row: 1, Tag: "unique", Message: "value is not unique: rows 1, 3 share the same key"},
+				{Row: 2, Tag: "unique", Message: "value is not unique: rows 1, 2, 4 share the same key"},
+				{Row: 3, Tag: "unique", Message: "value is not unique: rows 1, 3 share the same key"},
+				{Row: 4, Tag: "unique", Message: "value is not unique: rows 1, 2, 4 share the same key"},
+			},
+		},
+		{
+			name:    "rows before the start row are not compared",
+			options: []Option{WithStartRow(1)},
+			want: []failure{
+				{Row: 2, Tag: "unique", Message: "value is not unique: rows 2, 4 share the same key"},
+				{Row: 4, Tag: "unique", Message: "value is not unique: rows 2, 4 share the same key"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var records []contact
+			_, result, err := NewProcessor(fileparser.CSV, tt.options...).Process(strings.NewReader(input), &records)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			var got []failure
+			for _, ve := range result.ValidationErrors() {
+				got = append(got, failure{Row: ve.Row, Tag: ve.Tag, Message: ve.Message()})
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_SanitizeHeaders(t *testing.T) {
+	t.Parallel()
+
+	type line struct {
+		OrderID   string `name:"Order_ID" validate:"required"`
+		Order     string `name:"order_"`
+		UnitPrice string `name:"Unit_Price"`
+	}
+
+	input := "Order ID,order,Unit Price\n1,first,9.99\n"
+
+	var lines []line
+	reader, result, err := NewProcessor(fileparser.CSV, WithSanitizeHeaders(), WithSQLHeaderCheck()).
+		Process(strings.NewReader(input), &lines)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if diff := cmp.Diff([]line{{OrderID: "1", Order: "first", UnitPrice: "9.99"}}, lines); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Order_ID", "order_", "Unit_Price"}, result.Columns); diff != "" {
+		t.Errorf("columns mismatch (-want +got):\n%s", diff)
+	}
+	// Issues describe the header as it appears in the file
+	if got := len(result.HeaderIssues); got != 3 {
+		t.Errorf("len(HeaderIssues) = %d, want 3: %v", got, result.HeaderIssues)
+	}
+
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if diff := cmp.Diff("Order_ID,order_,Unit_Price\n1,first,9.99\n", string(output)); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_DedupHeaders(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID      string `name:"id"`
+		OtherID string `name:"id_2"`
+		Name    string
+	}
+
+	t.Run("without the option the file is rejected", func(t *testing.T) {
+		t.Parallel()
+		var rows []row
+		_, _, err := NewProcessor(fileparser.CSV).Process(strings.NewReader("id,id,name\n1,2,a\n"), &rows)
+		if err == nil {
+			t.Fatal("Process() error = nil, want duplicate column error")
+		}
+	})
+
+	tests := []struct {
+		name       string
+		fileType   fileparser.FileType
+		input      string
+		wantOutput string
+	}{
+		{
+			name:       "csv",
+			fileType:   fileparser.CSV,
+			input:      "id,id,name\n1,2,a\n",
+			wantOutput: "id,id_2,name\n1,2,a\n",
+		},
+		{
+			name:       "tsv",
+			fileType:   fileparser.TSV,
+			input:      "id\tid\tname\n1\t2\ta\n",
+			wantOutput: "id\tid_2\tname\n1\t2\ta\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rows []row
+			reader, result, err := NewProcessor(tt.fileType, WithDedupHeaders()).Process(strings.NewReader(tt.input), &rows)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if diff := cmp.Diff([]row{{ID: "1", OtherID: "2", Name: "a"}}, rows); diff != "" {
+				t.Errorf("rows mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]string{"id", "id_2", "name"}, result.Columns); diff != "" {
+				t.Errorf("columns mismatch (-want +got):\n%s", diff)
+			}
+			output, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantOutput, string(output)); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_SplitColumn(t *testing.T) {
+	t.Parallel()
+
+	type person struct {
+		FirstName string `validate:"required"`
+		LastName  string `prep:"uppercase"`
+	}
+
+	t.Run("fields bind to the new columns", func(t *testing.T) {
+		t.Parallel()
+
+		var people []person
+		reader, result, err := NewProcessor(fileparser.CSV, WithSplitColumn("full_name", " ", "first_name", "last_name")).
+			Process(strings.NewReader("full_name\nJohn Smith\n"), &people)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if diff := cmp.Diff([]person{{FirstName: "John", LastName: "SMITH"}}, people); diff != "" {
+			t.Errorf("records mismatch (-want +got):\n%s", diff)
+		}
+		if result.ValidRowCount != result.RowCount {
+			t.Errorf("ValidRowCount = %d, RowCount = %d", result.ValidRowCount, result.RowCount)
+		}
+
+		output, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if diff := cmp.Diff("full_name,first_name,last_name\nJohn Smith,John,SMITH\n", string(output)); diff != "" {
+			t.Errorf("output mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("JSON input is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		var people []person
+		_, _, err := NewProcessor(fileparser.JSONL, WithSplitColumn("data", " ", "a", "b")).
+			Process(strings.NewReader("{\"a\":1}\n"), &people)
+		if !errors.Is(err, ErrUnsupportedFileType) {
+			t.Errorf("Process() error = %v, want ErrUnsupportedFileType", err)
+		}
+	})
+}
+
+func TestProcessor_MergeColumns(t *testing.T) {
+	t.Parallel()
+
+	type customer struct {
+		Name    string
+		Address string `validate:"required"`
+	}
+
+	input := "name,street,city,zip\nAlice,1 Main St,Springfield,12345\nBob,,,\n"
+
+	var customers []customer
+	reader, result, err := NewProcessor(fileparser.CSV,
+		WithMergeColumns("address", ", ", "street", "city", "zip"),
+		WithDropColumns("street", "city", "zip"),
+	).Process(strings.NewReader(input), &customers)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := []customer{{Name: "Alice", Address: "1 Main St, Springfield, 12345"}, {Name: "Bob"}}
+	if diff := cmp.Diff(want, customers); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+	if result.ValidRowCount != 1 {
+		t.Errorf("ValidRowCount = %d, want 1", result.ValidRowCount)
+	}
+
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if diff := cmp.Diff("name,address\nAlice,\"1 Main St, Springfield, 12345\"\nBob,\n", string(output)); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_ValueMap(t *testing.T) {
+	t.Parallel()
+
+	type account struct {
+		Status string `prep:"trim,lowercase,map=active:1|inactive:0|default:" validate:"omitempty,oneof=0 1"`
+		Region string
+	}
+
+	input := "status,region\n Active ,east\ninactive,west\nunknown,north\n"
+
+	var accounts []account
+	reader, result, err := NewProcessor(fileparser.CSV,
+		WithValueMap("region", map[string]string{"east": "E", "west": "W"}),
+	).Process(strings.NewReader(input), &accounts)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.HasErrors() {
+		t.Errorf("unexpected errors: %v", result.Errors)
+	}
+
+	want := []account{{Status: "1", Region: "E"}, {Status: "0", Region: "W"}, {Status: "", Region: "north"}}
+	if diff := cmp.Diff(want, accounts); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if diff := cmp.Diff("status,region\n1,E\n0,W\n,north\n", string(output)); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_RowNumberAndSourceColumns(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		Name string
+		Row  int    `name:"_row"`
+		File string `name:"_file"`
+	}
+
+	t.Run("row numbers count skipped rows", func(t *testing.T) {
+		t.Parallel()
+
+		var rows []row
+		reader, _, err := NewProcessor(fileparser.CSV, WithStartRow(1), WithRowNumberColumn("_row"), WithSourceColumn("_file")).
+			Process(strings.NewReader("name\na\nb\nc\n"), &rows)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if diff := cmp.Diff([]row{{Name: "b", Row: 2}, {Name: "c", Row: 3}}, rows); diff != "" {
+			t.Errorf("rows mismatch (-want +got):\n%s", diff)
+		}
+		output, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		if diff := cmp.Diff("name,_row,_file\nb,2,\nc,3,\n", string(output)); diff != "" {
+			t.Errorf("output mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("source is the file name", func(t *testing.T) {
+		t.Parallel()
+
+		type sample struct {
+			File string `name:"_file"`
+		}
+		var samples []sample
+		_, _, err := NewProcessor(fileparser.CSV, WithSourceColumn("_file")).
+			Process(openTestFile(t, filepath.Join("testdata", "sample.csv")), &samples)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if len(samples) == 0 || samples[0].File != filepath.Join("testdata", "sample.csv") {
+			t.Errorf("samples = %+v", samples)
+		}
+	})
+
+	t.Run("existing column is rejected", func(t *testing.T) {
+		t.Parallel()
+
+		var rows []row
+		_, _, err := NewProcessor(fileparser.CSV, WithRowNumberColumn("name")).Process(strings.NewReader("name\na\n"), &rows)
+		if err == nil {
+			t.Error("Process() error = nil, want column exists error")
+		}
+	})
+}
+
+func TestProcessResult_Rows(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Name  string `prep:"trim"`
+		Email string `prep:"lowercase" validate:"email"`
+	}
+
+	input := "name,email\n Alice ,ALICE@EXAMPLE.COM\nBob,not-an-email\n"
+
+	tests := []struct {
+		name     string
+		options  []Option
+		wantRows [][]string
+	}{
+		{
+			name:     "all processed rows",
+			wantRows: [][]string{{"Alice", "alice@example.com"}, {"Bob", "not-an-email"}},
+		},
+		{
+			name:     "valid rows only",
+			options:  []Option{WithValidRowsOnly()},
+			wantRows: [][]string{{"Alice", "alice@example.com"}},
+		},
+		{
+			name:     "header order regardless of output order",
+			options:  []Option{WithOutputColumnOrder([]string{"email", "name"})},
+			wantRows: [][]string{{"Alice", "alice@example.com"}, {"Bob", "not-an-email"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var users []user
+			_, result, err := NewProcessor(fileparser.CSV, append([]Option{WithResultRows()}, tt.options...)...).Process(strings.NewReader(input), &users)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantRows, result.Rows()); diff != "" {
+				t.Errorf("Rows() mismatch (-want +got):\n%s", diff)
+			}
+
+			var maps []map[string]string
+			for m := range result.RowMaps() {
+				maps = append(maps, m)
+			}
+			if len(maps) != len(tt.wantRows) || maps[0]["email"] != "alice@example.com" || maps[0]["name"] != "Alice" {
+				t.Errorf("RowMaps() = %v", maps)
+			}
+		})
+	}
+
+	t.Run("rows not kept without WithResultRows", func(t *testing.T) {
+		t.Parallel()
+
+		var users []user
+		_, result, err := NewProcessor(fileparser.CSV).Process(strings.NewReader(input), &users)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if rows := result.Rows(); rows != nil {
+			t.Errorf("Rows() = %v, want nil", rows)
+		}
+	})
+}
+
+func TestProcessor_LTSVKeyOrder(t *testing.T) {
+	t.Parallel()
+
+	type entry struct {
+		Host   string
+		Status string
+	}
+
+	input := "status:200\thost:a.example\ttime:1\nhost:b.example\tstatus:404\ttime:2\n"
+
+	tests := []struct {
+		name    string
+		options []Option
+		want    string
+	}{
+		{
+			name: "file order by default",
+			want: "status:200\thost:a.example\ttime:1\nstatus:404\thost:b.example\ttime:2\n",
+		},
+		{
+			name:    "sorted",
+			options: []Option{WithLTSVKeyOrder(LTSVSortedOrder())},
+			want:    "host:a.example\tstatus:200\ttime:1\nhost:b.example\tstatus:404\ttime:2\n",
+		},
+		{
+			name:    "custom skips missing keys",
+			options: []Option{WithLTSVKeyOrder(LTSVCustomOrder("time", "user", "host"))},
+			want:    "time:1\thost:a.example\tstatus:200\ntime:2\thost:b.example\tstatus:404\n",
+		},
+		{
+			name:    "output column order takes precedence",
+			options: []Option{WithLTSVKeyOrder(LTSVSortedOrder()), WithOutputColumnOrder([]string{"time"})},
+			want:    "time:1\tstatus:200\thost:a.example\ntime:2\tstatus:404\thost:b.example\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var entries []entry
+			reader, _, err := NewProcessor(fileparser.LTSV, tt.options...).Process(strings.NewReader(input), &entries)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			output, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, string(output)); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_LTSVExpandDots(t *testing.T) {
+	t.Parallel()
+
+	type access struct {
+		Host   string
+		Method string `name:"request.method" validate:"oneof=GET POST"`
+		Path   string `name:"request.path"`
+	}
+
+	input := "host:a.example\trequest:method:GET,path:/index\nhost:b.example\trequest:method:PUT,path:/upload\n"
+
+	var entries []access
+	reader, result, err := NewProcessor(fileparser.LTSV, WithLTSVExpandDots()).Process(strings.NewReader(input), &entries)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	want := []access{
+		{Host: "a.example", Method: "GET", Path: "/index"},
+		{Host: "b.example", Method: "PUT", Path: "/upload"},
+	}
+	if diff := cmp.Diff(want, entries); diff != "" {
+		t.Errorf("entries mismatch (-want +got):\n%s", diff)
+	}
+	if result.ValidRowCount != 1 {
+		t.Errorf("ValidRowCount = %d, want 1", result.ValidRowCount)
+	}
+
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	wantOutput := "host:a.example\trequest.method:GET\trequest.path:/index\nhost:b.example\trequest.method:PUT\trequest.path:/upload\n"
+	if diff := cmp.Diff(wantOutput, string(output)); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_JSONRecordPath(t *testing.T) {
+	t.Parallel()
+
+	input := `{"meta":{"total":2},"items":[{"id":1},{"id":2}]}`
+
+	var records []JSONRecord
+	reader, result, err := NewProcessor(fileparser.JSON, WithJSONRecordPath("$.items[*]")).Process(strings.NewReader(input), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.RowCount != 2 {
+		t.Errorf("RowCount = %d, want 2", result.RowCount)
+	}
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if diff := cmp.Diff("{\"id\":1}\n{\"id\":2}\n", string(output)); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+
+	_, _, err = NewProcessor(fileparser.JSON, WithJSONRecordPath("$.meta")).Process(strings.NewReader(input), &records)
+	if err == nil {
+		t.Error("Process() with a path to an object: error = nil")
+	}
+}
+
+func TestProcessResult_JSONTypes(t *testing.T) {
+	t.Parallel()
+
+	input := "{\"id\":1,\"score\":1.5,\"ok\":true}\n{\"id\":2,\"score\":3,\"ok\":false}\n"
+
+	var records []JSONRecord
+	_, result, err := NewProcessor(fileparser.JSONL).Process(strings.NewReader(input), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	want := map[string]JSONType{"id": JSONInteger, "score": JSONNumber, "ok": JSONBoolean}
+	if diff := cmp.Diff(want, result.JSONTypes); diff != "" {
+		t.Errorf("JSONTypes mismatch (-want +got):\n%s", diff)
+	}
+
+	var users []TestRecord
+	_, result, err = NewProcessor(fileparser.CSV).Process(strings.NewReader("name,email,age\nJohn,john@example.com,30\n"), &users)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.JSONTypes != nil {
+		t.Errorf("JSONTypes = %v for CSV input, want nil", result.JSONTypes)
+	}
+}
+
+func TestProcessor_ExcelErrorPolicy(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID    int
+		Total string
+		Rate  string
+	}
+
+	data := newTestXLSX(t, formulaAndErrorSheet)
+
+	var rows []row
+	_, result, err := NewProcessor(fileparser.XLSX, WithExcelErrorPolicy(ExcelErrorInvalid())).Process(bytes.NewReader(data), &rows)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.ValidRowCount != 0 {
+		t.Errorf("ValidRowCount = %d, want 0", result.ValidRowCount)
+	}
+	errs := result.ValidationErrors()
+	if len(errs) != 2 {
+		t.Fatalf("len(ValidationErrors()) = %d, want 2: %v", len(errs), errs)
+	}
+	if errs[0].Row != 1 || errs[0].Field != "Rate" || errs[0].Value != "#N/A" || errs[0].Tag != "excel_error" {
+		t.Errorf("ValidationErrors()[0] = %+v", errs[0])
+	}
+
+	rows = nil
+	_, result, err = NewProcessor(fileparser.XLSX, WithExcelFormulasAsEmpty(), WithExcelErrorPolicy(ExcelErrorEmpty())).Process(bytes.NewReader(data), &rows)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	want := []row{{ID: 1}, {ID: 2, Rate: "0.5"}}
+	if diff := cmp.Diff(want, rows); diff != "" {
+		t.Errorf("rows mismatch (-want +got):\n%s", diff)
+	}
+	if result.HasErrors() {
+		t.Errorf("HasErrors() = true: %v", result.Errors)
+	}
+}
+
+func TestProcessor_FillMergedCells(t *testing.T) {
+	t.Parallel()
+
+	type sale struct {
+		Region string `validate:"required"`
+		Amount int
+	}
+
+	data := newTestXLSX(t, `<row r="1"><c r="A1" t="inlineStr"><is><t>region</t></is></c><c r="B1" t="inlineStr"><is><t>amount</t></is></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><t>East</t></is></c><c r="B2"><v>10</v></c></row>
+<row r="3"><c r="B3"><v>20</v></c></row>`, "A2:A3")
+
+	var sales []sale
+	_, result, err := NewProcessor(fileparser.XLSX, WithFillMergedCells()).Process(bytes.NewReader(data), &sales)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.HasErrors() {
+		t.Errorf("HasErrors() = true: %v", result.Errors)
+	}
+	want := []sale{{Region: "East", Amount: 10}, {Region: "East", Amount: 20}}
+	if diff := cmp.Diff(want, sales); diff != "" {
+		t.Errorf("sales mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_ExcelDateLayout(t *testing.T) {
+	t.Parallel()
+
+	type event struct {
+		Day string `validate:"datetime=2006-01-02"`
+	}
+
+	data := testXLSX{
+		sheetData: `<row r="1"><c r="A1" t="inlineStr"><is><t>day</t></is></c></row>
+<row r="2"><c r="A2" s="1"><v>43831</v></c></row>`,
+		dateStyle: "m/d/yy",
+		date1904:  true,
+	}.build(t)
+
+	var events []event
+	_, result, err := NewProcessor(fileparser.XLSX, WithExcelDateLayout("2006-01-02")).Process(bytes.NewReader(data), &events)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.HasErrors() {
+		t.Errorf("HasErrors() = true: %v", result.Errors)
+	}
+	if diff := cmp.Diff([]event{{Day: "2024-01-02"}}, events); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_ConversionErrorsAsValidation(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Name  string `validate:"required"`
+		Qty   int
+		Price float64
+	}
+
+	input := "name,qty,price\nwidget,abc,1.5\n,2,x\n"
+
+	var items []item
+	_, result, err := NewProcessor(fileparser.CSV, WithConversionErrorsAsValidation()).Process(strings.NewReader(input), &items)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if len(result.PrepErrors()) != 0 {
+		t.Errorf("PrepErrors() = %v, want none", result.PrepErrors())
+	}
+
+	errs := result.ValidationErrors()
+	if len(errs) != 3 {
+		t.Fatalf("len(ValidationErrors()) = %d, want 3: %v", len(errs), errs)
+	}
+	ve := errs[0]
+	if ve.Row != 1 || ve.Field != "Qty" || ve.Value != "abc" || ve.Tag != "type" || ve.Param != "int" || ve.Code() != "TYPE_INVALID" {
+		t.Errorf("ValidationErrors()[0] = %+v, code %s", ve, ve.Code())
+	}
+	if errs[1].Tag != "required" || errs[2].Param != "float64" {
+		t.Errorf("ValidationErrors()[1:] = %v", errs[1:])
+	}
+
+	// Fields after the failed one are still bound
+	want := []item{{Name: "widget", Price: 1.5}, {Qty: 2}}
+	if diff := cmp.Diff(want, items); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_OnConversionError(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Name string
+		Qty  int
+	}
+
+	input := "name,qty\nwidget,abc\ngadget,3\n"
+
+	tests := []struct {
+		name     string
+		fallback ConversionFallback
+		want     []item
+		wantErr  bool
+	}{
+		{
+			name:     "zero",
+			fallback: ConversionZero(),
+			want:     []item{{Name: "widget"}, {Name: "gadget", Qty: 3}},
+		},
+		{
+			name:     "skip row",
+			fallback: ConversionSkipRow(),
+			want:     []item{{Name: "gadget", Qty: 3}},
+		},
+		{
+			name:     "default",
+			fallback: ConversionDefault("-1"),
+			want:     []item{{Name: "widget", Qty: -1}, {Name: "gadget", Qty: 3}},
+		},
+		{
+			name:     "default that does not convert",
+			fallback: ConversionDefault("none"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var items []item
+			_, result, err := NewProcessor(fileparser.CSV, WithOnConversionError(tt.fallback)).Process(strings.NewReader(input), &items)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.want, items); diff != "" {
+				t.Errorf("items mismatch (-want +got):\n%s", diff)
+			}
+			if len(result.PrepErrors()) != 1 || result.ValidRowCount != 1 {
+				t.Errorf("PrepErrors() = %v, ValidRowCount = %d, want 1 error and 1 valid row", result.PrepErrors(), result.ValidRowCount)
+			}
+		})
+	}
+}
+
+func TestProcessResult_Repairs(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Name string `prep:"truncate=3"`
+		Qty  int
+		Note string
+	}
+
+	// The spreadsheet omits the trailing empty cells of the last two rows;
+	// WithFillMergedCells selects fileprep's XLSX reader, which keeps them short
+	data := newTestXLSX(t, `<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c><c r="B1" t="inlineStr"><is><t>qty</t></is></c><c r="C1" t="inlineStr"><is><t>note</t></is></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><t>widget</t></is></c><c r="B2" t="inlineStr"><is><t>abc</t></is></c><c r="C2" t="inlineStr"><is><t>x</t></is></c></row>
+<row r="3"><c r="A3" t="inlineStr"><is><t>nut</t></is></c><c r="B3"><v>2</v></c></row>
+<row r="4"><c r="A4" t="inlineStr"><is><t>bolt</t></is></c></row>`)
+
+	var items []item
+	_, result, err := NewProcessor(fileparser.XLSX, WithFillMergedCells()).Process(bytes.NewReader(data), &items)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	want := map[string]ColumnRepairs{
+		"name": {Truncated: 2},
+		"qty":  {Padded: 1, ConversionFallbacks: 1},
+		"note": {Padded: 2},
+	}
+	if diff := cmp.Diff(want, result.Repairs); diff != "" {
+		t.Errorf("Repairs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_HeaderRules(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		UserID string `name:"UserID"`
+	}
+
+	var rows []row
+	_, result, err := NewProcessor(fileparser.CSV, WithHeaderRules("snakecase")).Process(strings.NewReader("UserID,name\n1,a\n"), &rows)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	want := []HeaderIssue{{Index: 0, Column: "UserID", Reason: HeaderNotSnakeCase, Suggestion: "user_id"}}
+	if diff := cmp.Diff(want, result.HeaderIssues); diff != "" {
+		t.Errorf("HeaderIssues mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, _, err := NewProcessor(fileparser.CSV, WithHeaderRules("pascalcase")).Process(strings.NewReader("id\n1\n"), &rows); err == nil {
+		t.Error("Process() with an unknown header rule: error = nil")
+	}
+}
+
+func TestProcessor_EmptyInputErrors(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		Name string
+	}
+
+	tests := []struct {
+		name     string
+		fileType fileparser.FileType
+		input    string
+		opts     []Option
+		wantErr  error
+	}{
+		{"no bytes", fileparser.CSV, "", nil, ErrNoHeader},
+		{"BOM only", fileparser.CSV, "\uFEFF", nil, ErrNoHeader},
+		{"whitespace only", fileparser.CSV, " \n\t\r\n", nil, ErrWhitespaceOnly},
+		{"whitespace only TSV", fileparser.TSV, "\n\n", nil, ErrWhitespaceOnly},
+		{"whitespace only JSONL", fileparser.JSONL, "  \n", nil, ErrWhitespaceOnly},
+		{"header only is allowed by default", fileparser.CSV, "name\n", nil, nil},
+		{"header only rejected", fileparser.CSV, "name\n", []Option{WithRejectHeaderOnly()}, ErrHeaderOnly},
+		{"data rows with reject option", fileparser.CSV, "name\nalice\n", []Option{WithRejectHeaderOnly()}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var records []row
+			_, _, err := NewProcessor(tt.fileType, tt.opts...).Process(strings.NewReader(tt.input), &records)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, ErrEmptyFile) {
+				t.Errorf("Process() error = %v, want it to match ErrEmptyFile", err)
+			}
+		})
+	}
+}
+
+func TestProcessor_SentinelErrors(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		Name string
+	}
+	type unknownPrep struct {
+		Name string `prep:"no_such_prep"`
+	}
+	type unknownValidate struct {
+		Name string `validate:"no_such_validator"`
+	}
+
+	tests := []struct {
+		name     string
+		process  func() error
+		wantErrs []error
+	}{
+		{
+			name: "nil reader",
+			process: func() error {
+				var records []row
+				_, _, err := NewProcessor(fileparser.CSV).Process(nil, &records)
+				return err
+			},
+			wantErrs: []error{ErrNilReader},
+		},
+		{
+			name: "unknown prep tag",
+			process: func() error {
+				var records []unknownPrep
+				_, _, err := NewProcessor(fileparser.CSV).Process(strings.NewReader("name\na\n"), &records)
+				return err
+			},
+			wantErrs: []error{ErrUnknownPrepTag, ErrInvalidTagFormat},
+		},
+		{
+			name: "unknown validate tag",
+			process: func() error {
+				var records []unknownValidate
+				_, _, err := NewProcessor(fileparser.CSV).Process(strings.NewReader("name\na\n"), &records)
+				return err
+			},
+			wantErrs: []error{ErrUnknownValidateTag, ErrInvalidTagFormat},
+		},
+		{
+			name: "corrupt gzip",
+			process: func() error {
+				var records []row
+				_, _, err := NewProcessor(fileparser.CSVGZ).Process(strings.NewReader("not gzip"), &records)
+				return err
+			},
+			wantErrs: []error{ErrDecompression},
+		},
+		{
+			name: "duplicate header",
+			process: func() error {
+				var records []row
+				_, _, err := NewProcessor(fileparser.CSV, WithLazyQuotes()).Process(strings.NewReader("name,name\na,b\n"), &records)
+				return err
+			},
+			wantErrs: []error{ErrParse, ErrDuplicateColumn},
+		},
+		{
+			name: "unknown header rule",
+			process: func() error {
+				var records []row
+				_, _, err := NewProcessor(fileparser.CSV, WithHeaderRules("no_such_rule")).Process(strings.NewReader("name\na\n"), &records)
+				return err
+			},
+			wantErrs: []error{ErrInvalidOption},
+		},
+		{
+			name: "missing transform column",
+			process: func() error {
+				var records []row
+				_, _, err := NewProcessor(fileparser.CSV, WithDropColumns("missing")).Process(strings.NewReader("name\na\n"), &records)
+				return err
+			},
+			wantErrs: []error{ErrColumnNotFound},
+		},
+		{
+			name: "non-positive chunk size",
+			process: func() error {
+				var records []row
+				return NewProcessor(fileparser.CSV).ProcessChunks(strings.NewReader("name\na\n"), &records, 0,
+					func(Stream, *ProcessResult) error { return nil })
+			},
+			wantErrs: []error{ErrInvalidOption},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.process()
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("error = %v, want it to match %v", err, want)
+				}
+			}
+		})
+	}
+
+	t.Run("unsupported field type", func(t *testing.T) {
+		t.Parallel()
+
+		type mapRow struct {
+			Name map[string]string
+		}
+		var records []mapRow
+		_, result, err := NewProcessor(fileparser.CSV).Process(strings.NewReader("name\na\n"), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if len(result.Errors) != 1 || !errors.Is(result.Errors[0], ErrUnsupportedFieldType) {
+			t.Errorf("Errors = %v, want one error matching ErrUnsupportedFieldType", result.Errors)
+		}
+	})
+}
+
+func TestProcessor_WithDelimiter(t *testing.T) {
+	t.Parallel()
+
+	type product struct {
+		Name  string `prep:"trim"`
+		Price string `validate:"number"`
+	}
+
+	var records []product
+	reader, result, err := NewProcessor(fileparser.CSV, WithDelimiter(';')).
+		Process(strings.NewReader("name;price\nwidget;1,50\ngadget;2\n"), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	want := []product{{Name: "widget", Price: "1,50"}, {Name: "gadget", Price: "2"}}
+	if diff := cmp.Diff(want, records); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+	if result.ValidRowCount != 1 {
+		t.Errorf("ValidRowCount = %d, want 1", result.ValidRowCount)
+	}
+
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(output), "name,price\nwidget,\"1,50\"\ngadget,2\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestProcessor_ConcurrentUse(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		Name  string `prep:"trim,uppercase"`
+		Score string `validate:"numeric"`
+	}
+
+	expected := []string{"name", "score"}
+	processor := NewProcessor(fileparser.CSV, WithExpectedColumns(expected...), WithValidRowsOnly())
+	expected[0] = "changed" // options keep their own copy
+
+	const goroutines = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+	for i := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			name := fmt.Sprintf("user%d", i)
+			input := fmt.Sprintf("name,score\n %s ,%d\nbad,x\n", name, i)
+			var records []row
+			reader, result, err := processor.Process(strings.NewReader(input), &records)
+			if err != nil {
+				errs <- err
+				return
+			}
+			output, err := io.ReadAll(reader)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			want := fmt.Sprintf("name,score\n%s,%d\n", strings.ToUpper(name), i)
+			if string(output) != want || result.ValidRowCount != 1 || len(records) != 1 {
+				errs <- fmt.Errorf("goroutine %d: output %q, %d valid rows, %d records", i, output, result.ValidRowCount, len(records))
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestProcessor_ConcurrentCallsAreIsolated(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID   string `validate:"unique"`
+		Name string `prep:"trim"`
+	}
+
+	processor := NewProcessor(fileparser.CSV, WithDuplicateReport("id"), WithChangeTracking())
+
+	// Every input repeats its own ID once, so a call that saw another call's
+	// rows would report extra duplicates, unique errors, or changes.
+	inputFor := func(i int) string {
+		return fmt.Sprintf("id,name\n%d, a \n%d,b\nx%d,c\n", i, i, i)
+	}
+	check := func(i int, result *ProcessResult) error {
+		wantDupes := []DuplicateGroup{{Key: []string{strconv.Itoa(i)}, FirstRow: 1, DuplicateRows: []int{2}}}
+		if diff := cmp.Diff(wantDupes, result.Duplicates); diff != "" {
+			return fmt.Errorf("input %d: Duplicates mismatch (-want +got):\n%s", i, diff)
+		}
+		if len(result.Errors) != 2 || len(result.Changes()) != 1 || result.RowCount != 3 {
+			return fmt.Errorf("input %d: %d errors, %d changes, %d rows", i, len(result.Errors), len(result.Changes()), result.RowCount)
+		}
+		return nil
+	}
+
+	const goroutines = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*goroutines)
+	for i := range goroutines {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			var records []row
+			_, result, err := processor.Process(strings.NewReader(inputFor(i)), &records)
+			if err == nil {
+				err = check(i, result)
+			}
+			if err != nil {
+				errs <- err
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			var records []row
+			err := processor.ProcessChunks(strings.NewReader(inputFor(i)), &records, 10, func(_ Stream, result *ProcessResult) error {
+				return check(i, result)
+			})
+			if err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestProcessor_WithWorkers(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID    string `validate:"required" warn:"max=900"`
+		Name  string `prep:"trim,truncate=4" validate:"alpha"`
+		Age   int
+		Email string `validate:"omitempty,email,nefield=Name"`
+	}
+
+	var b strings.Builder
+	b.WriteString("id,name,age,email\n")
+	for i := range 1000 {
+		switch i % 7 {
+		case 0:
+			fmt.Fprintf(&b, ",  name%d  ,x,bad\n", i)
+		case 3:
+			fmt.Fprintf(&b, "%d,short,,\n", i)
+		default:
+			fmt.Fprintf(&b, "%d, alice ,%d,a%d@example.com\n", i, i%90, i)
+		}
+	}
+	input := b.String()
+
+	type outcome struct {
+		Output   string
+		Records  []row
+		Errors   []string
+		Warnings []string
+		Changes  []CellChange
+		Repairs  map[string]ColumnRepairs
+		Counts   [3]int
+	}
+	run := func(opts ...Option) outcome {
+		t.Helper()
+		opts = append(opts, WithChangeTracking(), WithFilter("id != '500'"))
+		var records []row
+		reader, result, err := NewProcessor(fileparser.CSV, opts...).Process(strings.NewReader(input), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		output, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		o := outcome{
+			Output:  string(output),
+			Records: records,
+			Changes: result.Changes(),
+			Repairs: result.Repairs,
+			Counts:  [3]int{result.RowCount, result.ValidRowCount, result.FilteredRowCount},
+		}
+		for _, err := range result.Errors {
+			o.Errors = append(o.Errors, err.Error())
+		}
+		for _, w := range result.Warnings {
+			o.Warnings = append(o.Warnings, w.Error())
+		}
+		return o
+	}
+
+	want := run()
+	if len(want.Errors) == 0 || len(want.Warnings) == 0 || len(want.Changes) == 0 || len(want.Repairs) == 0 {
+		t.Fatalf("fixture does not cover errors, warnings, changes, and repairs: %+v", want.Counts)
+	}
+	for _, workers := range []int{2, 4, 16} {
+		if diff := cmp.Diff(want, run(WithWorkers(workers))); diff != "" {
+			t.Errorf("WithWorkers(%d) result differs from one worker (-want +got):\n%s", workers, diff)
+		}
+	}
+
+	t.Run("row order validators run one row at a time", func(t *testing.T) {
+		t.Parallel()
+
+		type seqRow struct {
+			Seq string `validate:"increasing"`
+		}
+		var b strings.Builder
+		b.WriteString("seq\n")
+		for i := range 600 {
+			n := i
+			if i == 400 {
+				n = 1
+			}
+			fmt.Fprintf(&b, "%d\n", n)
+		}
+		var records []seqRow
+		_, result, err := NewProcessor(fileparser.CSV, WithWorkers(4)).Process(strings.NewReader(b.String()), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		errs := result.ValidationErrors()
+		if len(errs) != 1 || errs[0].Row != 401 {
+			t.Errorf("errors = %v, want one increasing error on row 401", errs)
+		}
+	})
+}
+
+func TestProcessor_ProcessInto(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		Name string `prep:"trim"`
+		Code string `validate:"required"`
+	}
+
+	processor := NewProcessor(fileparser.CSV, WithDuplicateReport("name"))
+	result := &ProcessResult{}
+	records := make([]row, 0, 8)
+
+	inputs := []struct {
+		input      string
+		want       []row
+		wantErrors int
+		wantDupes  int
+		wantOutput string
+	}{
+		{"name,code\n a ,1\nb,\na,2\n", []row{{"a", "1"}, {"b", ""}, {"a", "2"}}, 1, 1, "name,code\na,1\nb,\na,2\n"},
+		{"name,code\nc,3\n", []row{{"c", "3"}}, 0, 0, "name,code\nc,3\n"},
+	}
+	for i, in := range inputs {
+		reader, err := processor.ProcessInto(strings.NewReader(in.input), &records, result)
+		if err != nil {
+			t.Fatalf("call %d: ProcessInto() error = %v", i, err)
+		}
+		if diff := cmp.Diff(in.want, records); diff != "" {
+			t.Errorf("call %d: records mismatch (-want +got):\n%s", i, diff)
+		}
+		if cap(records) != 8 {
+			t.Errorf("call %d: cap(records) = %d, want the backing array to be reused", i, cap(records))
+		}
+		if len(result.Errors) != in.wantErrors || len(result.Duplicates) != in.wantDupes || result.RowCount != len(in.want) {
+			t.Errorf("call %d: %d errors, %d duplicate groups, %d rows", i, len(result.Errors), len(result.Duplicates), result.RowCount)
+		}
+		output, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(output) != in.wantOutput {
+			t.Errorf("call %d: output = %q, want %q", i, output, in.wantOutput)
+		}
+	}
+
+	t.Run("nil result", func(t *testing.T) {
+		t.Parallel()
+
+		var records []row
+		if _, err := processor.ProcessInto(strings.NewReader("name,code\na,1\n"), &records, nil); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("ProcessInto() error = %v, want ErrInvalidOption", err)
+		}
+	})
+
+	t.Run("strict validation fills the result", func(t *testing.T) {
+		t.Parallel()
+
+		var records []row
+		result := &ProcessResult{}
+		_, err := NewProcessor(fileparser.CSV, WithStrictValidation()).ProcessInto(strings.NewReader("name,code\na,\n"), &records, result)
+		var multiErr *MultiError
+		if !errors.As(err, &multiErr) || len(result.Errors) != 1 {
+			t.Errorf("ProcessInto() error = %v with %d result errors, want a MultiError and 1 error", err, len(result.Errors))
+		}
+	})
+}
+
+func TestProcessor_WithRawCellHook(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		Name string `prep:"uppercase" validate:"alpha"`
+		Code string `validate:"unique"`
+	}
+
+	type call struct {
+		Row, Col int
+		Value    string
+	}
+	var calls []call
+	hook := func(row, col int, value string) string {
+		calls = append(calls, call{row, col, value})
+		return strings.ReplaceAll(value, "\x00", "")
+	}
+
+	// The hook runs before unique, so "a\x00" and "a" are duplicates
+	input := "name,code,note\nal\x00ice,a\x00,x\nbob,a,y\n"
+	var records []row
+	reader, result, err := NewProcessor(fileparser.CSV, WithRawCellHook(hook)).
+		Process(strings.NewReader(input), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	wantCalls := []call{
+		{1, 0, "al\x00ice"}, {1, 1, "a\x00"}, {1, 2, "x"},
+		{2, 0, "bob"}, {2, 1, "a"}, {2, 2, "y"},
+	}
+	if diff := cmp.Diff(wantCalls, calls); diff != "" {
+		t.Errorf("hook calls mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]row{{"ALICE", "a"}, {"BOB", "a"}}, records); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+	if result.ValidRowCount != 0 {
+		t.Errorf("ValidRowCount = %d, want 0 (unique sees the rewritten codes)", result.ValidRowCount)
+	}
+
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(output), "name,code,note\nALICE,a,x\nBOB,a,y\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	t.Run("rewritten cells without prep are written out", func(t *testing.T) {
+		t.Parallel()
+
+		type plain struct {
+			Name string
+		}
+		var records []plain
+		reader, _, err := NewProcessor(fileparser.CSV, WithRawCellHook(func(_, _ int, v string) string {
+			return strings.TrimSpace(v)
+		})).Process(strings.NewReader("name,note\n a , b \n"), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		output, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(output), "name,note\na,b\n"; got != want {
+			t.Errorf("output = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestProcessor_WithRowHashColumn(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Email string `prep:"lowercase"`
+		Name  string
+		Hash  string `name:"_hash"`
+	}
+
+	process := func(t *testing.T, input string) []user {
+		t.Helper()
+		var users []user
+		_, _, err := NewProcessor(fileparser.CSV,
+			WithRowHashColumn("_hash", SHA256, "email", "name"), WithRowNumberColumn("_row")).
+			Process(strings.NewReader(input), &users)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		return users
+	}
+
+	before := process(t, "email,name\na@example.com,Alice\nb@example.com,Bob\n")
+	after := process(t, "email,name\nb@example.com,Bob\na@example.com,Alicia\n")
+	if before[1].Hash != after[0].Hash {
+		t.Error("unchanged row has a different hash after moving")
+	}
+	if before[0].Hash == after[1].Hash {
+		t.Error("changed row has the same hash")
+	}
+
+	t.Run("values are hashed before prep", func(t *testing.T) {
+		t.Parallel()
+
+		users := process(t, "email,name\nA@EXAMPLE.COM,Alice\na@example.com,Alice\n")
+		if users[0].Hash == users[1].Hash {
+			t.Error("raw values differing only in case have the same hash")
+		}
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		t.Parallel()
+
+		var users []user
+		_, _, err := NewProcessor(fileparser.CSV, WithRowHashColumn("_hash", SHA256, "phone")).
+			Process(strings.NewReader("email,name\na@example.com,Alice\n"), &users)
+		if !errors.Is(err, ErrColumnNotFound) {
+			t.Errorf("Process() error = %v, want ErrColumnNotFound", err)
+		}
+	})
+}
+
+func TestProcessor_OutputSampling(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		ID string `validate:"numeric"`
+	}
+
+	input := "id\n1\n2\nx\n4\n5\n6\n7\n"
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		want     string
+		wantRows []int
+	}{
+		{name: "head", opts: []Option{WithHead(2)}, want: "id\n1\n2\n", wantRows: []int{1, 2}},
+		{name: "tail", opts: []Option{WithTail(2)}, want: "id\n6\n7\n", wantRows: []int{6, 7}},
+		{name: "every nth", opts: []Option{WithEveryNth(3)}, want: "id\n1\n4\n7\n", wantRows: []int{1, 4, 7}},
+		{name: "every nth then head", opts: []Option{WithEveryNth(2), WithHead(2)}, want: "id\n1\nx\n", wantRows: []int{1, 3}},
+		{name: "head then tail", opts: []Option{WithHead(5), WithTail(2)}, want: "id\n4\n5\n", wantRows: []int{4, 5}},
+		{name: "valid rows only", opts: []Option{WithValidRowsOnly(), WithHead(3)}, want: "id\n1\n2\n4\n", wantRows: []int{1, 2, 4}},
+		{name: "head larger than input", opts: []Option{WithHead(100)}, want: input, wantRows: []int{1, 2, 3, 4, 5, 6, 7}},
+		{name: "zero keeps all rows", opts: []Option{WithHead(0), WithEveryNth(1)}, want: input, wantRows: []int{1, 2, 3, 4, 5, 6, 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var rows []row
+			reader, result, err := NewProcessor(fileparser.CSV, tt.opts...).Process(strings.NewReader(input), &rows)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			output, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, string(output)); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+			var gotRows []int
+			for _, offset := range reader.(Stream).RowOffsets() {
+				gotRows = append(gotRows, offset.Row)
+			}
+			if diff := cmp.Diff(tt.wantRows, gotRows); diff != "" {
+				t.Errorf("RowOffsets() rows mismatch (-want +got):\n%s", diff)
+			}
+			if result.RowCount != 7 {
+				t.Errorf("RowCount = %d, want 7", result.RowCount)
+			}
+		})
+	}
+
+	t.Run("ProcessChunks rejects sampling", func(t *testing.T) {
+		t.Parallel()
+		var rows []row
+		err := NewProcessor(fileparser.CSV, WithHead(1)).ProcessChunks(strings.NewReader(input), &rows, 2,
+			func(Stream, *ProcessResult) error { return nil })
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("ProcessChunks() error = %v, want ErrInvalidOption", err)
+		}
+	})
+}
+
+func TestProcessor_WithAnonymizedColumn(t *testing.T) {
+	t.Parallel()
+
+	type customer struct {
+		ID    string
+		Name  string `prep:"trim"`
+		Email string `prep:"trim,lowercase" validate:"email"`
+	}
+
+	input := "id,name,email\n1, Alice Smith ,ALICE@CORP.EXAMPLE\n2,Bob Jones,bob@corp.example\n3,Alice Smith,alice@corp.example\n"
+	opts := []Option{
+		WithAnonymizedColumn("name", AnonymizeName),
+		WithAnonymizedColumn("email", AnonymizeEmail),
+		WithAnonymizeSeed("fixture"),
+		WithResultRows(),
+	}
+
+	var customers []customer
+	reader, result, err := NewProcessor(fileparser.CSV, opts...).Process(strings.NewReader(input), &customers)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.HasErrors() {
+		t.Errorf("unexpected errors: %v", result.Errors)
+	}
+	output, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 4 || lines[0] != "id,name,email" {
+		t.Fatalf("output = %q", output)
+	}
+	if strings.Contains(string(output), "Alice") || strings.Contains(string(output), "corp.example") {
+		t.Errorf("output contains real values: %q", output)
+	}
+	// Prep runs first, so the same cleaned value gets the same fake value
+	if lines[1][2:] != lines[3][2:] {
+		t.Errorf("rows 1 and 3 differ after anonymization: %q, %q", lines[1], lines[3])
+	}
+	if customers[0].Email != "alice@corp.example" || result.Rows()[0][1] != "Alice Smith" {
+		t.Errorf("struct slice or result rows were anonymized: %+v, %v", customers[0], result.Rows()[0])
+	}
+
+	// The same seed reproduces the same file
+	var again []customer
+	reader, _, err = NewProcessor(fileparser.CSV, opts...).Process(strings.NewReader(input), &again)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	output2, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if diff := cmp.Diff(string(output), string(output2)); diff != "" {
+		t.Errorf("output is not reproducible (-first +second):\n%s", diff)
+	}
+
+	t.Run("missing column", func(t *testing.T) {
+		t.Parallel()
+
+		var customers []customer
+		_, _, err := NewProcessor(fileparser.CSV, WithAnonymizedColumn("phone", AnonymizePhone)).
+			Process(strings.NewReader(input), &customers)
+		if !errors.Is(err, ErrColumnNotFound) {
+			t.Errorf("Process() error = %v, want ErrColumnNotFound", err)
+		}
+	})
+}
+
+func TestProcessor_WithProfile(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		ID    string
+		Name  string
+		Email string
+	}
+
+	tests := []struct {
+		name       string
+		fileType   fileparser.FileType
+		input      string
+		opts       []Option
+		wantErr    error
+		wantErrors []string // "row:tag" of result.Errors
+	}{
+		{
+			name:     "lenient accepts missing columns and ragged rows",
+			fileType: fileparser.CSV,
+			input:    "id,name\n1,Alice\n2\n3,Carol,extra\n",
+			opts:     []Option{WithProfile(ProfileLenient)},
+		},
+		{
+			name:     "strict rejects missing columns",
+			fileType: fileparser.CSV,
+			input:    "id,name\n1,Alice\n",
+			opts:     []Option{WithProfile(ProfileStrict)},
+			wantErr:  ErrSchemaMismatch,
+		},
+		{
+			name:       "strict reports ragged rows",
+			fileType:   fileparser.CSV,
+			input:      "id,name,email\n1,Alice,a@example.com\n2,Bob\n3,Carol,c@example.com,extra\n",
+			opts:       []Option{WithProfile(ProfileStrict)},
+			wantErrors: []string{"2:column_count", "3:column_count"},
+		},
+		{
+			name:       "strict reports ragged TSV rows",
+			fileType:   fileparser.TSV,
+			input:      "id\tname\temail\n1\tAlice\n",
+			opts:       []Option{WithProfile(ProfileStrict)},
+			wantErrors: []string{"1:column_count"},
+		},
+		{
+			name:       "ragged rows are found before column transforms",
+			fileType:   fileparser.CSV,
+			input:      "id,name,email\n1,Alice\n",
+			opts:       []Option{WithProfile(ProfileStrict), WithRowNumberColumn("_row")},
+			wantErrors: []string{"1:column_count"},
+		},
+		{
+			name:     "strict rejects repeated columns",
+			fileType: fileparser.CSV,
+			input:    "id,name,email,name\n1,Alice,a@example.com,Bob\n",
+			opts:     []Option{WithProfile(ProfileStrict)},
+			wantErr:  ErrDuplicateColumn,
+		},
+		{
+			name:     "strict rejects repeated XLSX columns",
+			fileType: fileparser.XLSX,
+			input: string(newTestXLSX(t, `<row r="1"><c r="A1" t="inlineStr"><is><t>id</t></is></c><c r="B1" t="inlineStr"><is><t>id</t></is></c></row>
+<row r="2"><c r="A2"><v>1</v></c><c r="B2"><v>2</v></c></row>`)),
+			opts:    []Option{WithProfile(ProfileStrict)},
+			wantErr: ErrDuplicateColumn,
+		},
+		{
+			name:     "renamed columns pass the strict checks",
+			fileType: fileparser.CSV,
+			input:    "id,name,email,name\n1,Alice,a@example.com,Bob\n",
+			opts:     []Option{WithProfile(ProfileStrict), WithDedupHeaders()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var users []user
+			_, result, err := NewProcessor(tt.fileType, tt.opts...).Process(strings.NewReader(tt.input), &users)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Process() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			var gotErrors []string
+			for _, ve := range result.ValidationErrors() {
+				gotErrors = append(gotErrors, fmt.Sprintf("%d:%s", ve.Row, ve.Tag))
+			}
+			if diff := cmp.Diff(tt.wantErrors, gotErrors); diff != "" {
+				t.Errorf("errors mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestProcessor_WithLocale(t *testing.T) {
+	t.Parallel()
+
+	type place struct {
+		City    string `prep:"uppercase"`
+		Country string `prep:"lowercase" validate:"eq_ignore_case=TÜRKIYE"`
+		Code    string `prep:"normalize_unicode=NFKC"`
+	}
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		want       []place
+		wantErrors int
+	}{
+		{
+			name:       "default case rules",
+			want:       []place{{City: "IZMIR", Country: "türkiye", Code: "AB12"}},
+			wantErrors: 0,
+		},
+		{
+			name:       "turkish for every column",
+			opts:       []Option{WithLocale("tr")},
+			want:       []place{{City: "İZMİR", Country: "türkiye", Code: "AB12"}},
+			wantErrors: 1, // TÜRKIYE lowercases to türkıye
+		},
+		{
+			name:       "turkish for one column",
+			opts:       []Option{WithLocale("tr", "city")},
+			want:       []place{{City: "İZMİR", Country: "türkiye", Code: "AB12"}},
+			wantErrors: 0,
+		},
+		{
+			name:       "later call overrides",
+			opts:       []Option{WithLocale("tr"), WithLocale("en", "city")},
+			want:       []place{{City: "IZMIR", Country: "türkiye", Code: "AB12"}},
+			wantErrors: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var places []place
+			_, result, err := NewProcessor(fileparser.CSV, tt.opts...).Process(
+				strings.NewReader("city,country,code\nizmir,TÜRKİYE,ＡＢ１２\n"), &places)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, places); diff != "" {
+				t.Errorf("places mismatch (-want +got):\n%s", diff)
+			}
+			if len(result.Errors) != tt.wantErrors {
+				t.Errorf("errors = %v, want %d", result.Errors, tt.wantErrors)
+			}
+		})
+	}
+
+	t.Run("invalid locale", func(t *testing.T) {
+		t.Parallel()
+
+		var places []place
+		_, _, err := NewProcessor(fileparser.CSV, WithLocale("not a locale")).Process(strings.NewReader("city\nizmir\n"), &places)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("Process() error = %v, want ErrInvalidOption", err)
+		}
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		t.Parallel()
+
+		var places []place
+		_, _, err := NewProcessor(fileparser.CSV, WithLocale("tr", "town")).Process(strings.NewReader("city\nizmir\n"), &places)
+		if !errors.Is(err, ErrColumnNotFound) {
+			t.Errorf("Process() error = %v, want ErrColumnNotFound", err)
+		}
+	})
+}
+
+func TestProcessor_PasswordValidation(t *testing.T) {
+	t.Parallel()
+
+	type account struct {
+		User     string
+		Password string `validate:"required,password=min8 upper digit"`
+	}
+
+	var accounts []account
+	_, result, err := NewProcessor(fileparser.CSV).Process(
+		strings.NewReader("user,password\nkai,Hunter2024\nsam,hunter2\n"), &accounts)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	errs := result.ValidationErrors()
+	if len(errs) != 1 {
+		t.Fatalf("ValidationErrors() = %v, want 1 error", errs)
+	}
+	want := "row 2, column \"password\" (field Password): password must have at least 8 characters, an uppercase letter " +
+		"(value=\"[redacted]\", tag=password)"
+	if got := errs[0].Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if strings.Contains(errs[0].Error(), "hunter2") || errs[0].Value != "[redacted]" {
+		t.Errorf("password leaked into the error: %+v", errs[0])
+	}
+}
+
+func TestProcessor_WithGroupValidator(t *testing.T) {
+	t.Parallel()
+
+	type Contact struct {
+		CompanyID string `prep:"trim"`
+		Name      string
+		Role      string
+	}
+	onePrimary := func(rows []map[string]string) []error {
+		primaries := 0
+		for _, row := range rows {
+			if row["role"] == "primary" {
+				primaries++
+			}
+		}
+		if primaries != 1 {
+			return []error{fmt.Errorf("company needs exactly one primary contact, has %d", primaries)}
+		}
+		return nil
+	}
+	input := "company_id,name,role\n" +
+		"c1,Kai,primary\n" +
+		"c2,Sam,billing\n" +
+		" c1 ,Ann,billing\n" +
+		"c2,Lee,billing\n" +
+		",Bob,billing\n"
+
+	t.Run("errors are reported on every row of the group", func(t *testing.T) {
+		t.Parallel()
+
+		var contacts []Contact
+		processor := NewProcessor(FileTypeCSV, WithValidRowsOnly(), WithGroupValidator("company_id", onePrimary))
+		output, result, err := processor.Process(strings.NewReader(input), &contacts)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		type issue struct {
+			Row     int
+			Column  string
+			Field   string
+			Tag     string
+			Message string
+		}
+		var got []issue
+		for _, ve := range result.ValidationErrors() {
+			got = append(got, issue{ve.Row, ve.Column, ve.Field, ve.Tag, ve.Message()})
+		}
+		msg := "company needs exactly one primary contact, has 0 (rows 2, 4)"
+		want := []issue{
+			{Row: 2, Column: "company_id", Field: "CompanyID", Tag: "group", Message: msg},
+			{Row: 4, Column: "company_id", Field: "CompanyID", Tag: "group", Message: msg},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("errors mismatch (-want +got):\n%s", diff)
+		}
+		if result.ValidRowCount != 3 {
+			t.Errorf("ValidRowCount = %d, want 3", result.ValidRowCount)
+		}
+		var names []string
+		for _, c := range contacts {
+			names = append(names, c.Name)
+		}
+		if diff := cmp.Diff([]string{"Kai", "Ann", "Bob"}, names); diff != "" {
+			t.Errorf("contacts mismatch (-want +got):\n%s", diff)
+		}
+		data, err := io.ReadAll(output)
+		if err != nil {
+			t.Fatalf("io.ReadAll() error = %v", err)
+		}
+		if string(data) != "company_id,name,role\nc1,Kai,primary\nc1,Ann,billing\n,Bob,billing\n" {
+			t.Errorf("output = %q", data)
+		}
+	})
+
+	t.Run("unknown key column", func(t *testing.T) {
+		t.Parallel()
+
+		var contacts []Contact
+		_, _, err := NewProcessor(FileTypeCSV, WithGroupValidator("company", onePrimary)).Process(strings.NewReader(input), &contacts)
+		if !errors.Is(err, ErrColumnNotFound) {
+			t.Errorf("Process() error = %v, want ErrColumnNotFound", err)
+		}
+	})
+}
+
+func TestProcessor_WithFilter(t *testing.T) {
+	t.Parallel()
+
+	type Person struct {
+		Name    string `prep:"trim" validate:"required"`
+		Age     int
+		Country string
+	}
+	input := "name,age,country\n" +
+		"Kai,30,JP\n" +
+		"Sam,17,JP\n" +
+		",40,JP\n" +
+		"Lee,50,US\n"
+
+	t.Run("rows that do not match are dropped", func(t *testing.T) {
+		t.Parallel()
+
+		var people []Person
+		processor := NewProcessor(FileTypeCSV, WithFilter("age >= 18 && country == 'JP'"))
+		output, result, err := processor.Process(strings.NewReader(input), &people)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if result.RowCount != 2 || result.ValidRowCount != 1 || result.FilteredRowCount != 2 {
+			t.Errorf("RowCount, ValidRowCount, FilteredRowCount = %d, %d, %d, want 2, 1, 2",
+				result.RowCount, result.ValidRowCount, result.FilteredRowCount)
+		}
+		if errs := result.ValidationErrors(); len(errs) != 1 || errs[0].Row != 3 {
+			t.Errorf("ValidationErrors() = %v, want one error on row 3", errs)
+		}
+		var names []string
+		for _, p := range people {
+			names = append(names, p.Name)
+		}
+		if diff := cmp.Diff([]string{"Kai", ""}, names); diff != "" {
+			t.Errorf("people mismatch (-want +got):\n%s", diff)
+		}
+		data, err := io.ReadAll(output)
+		if err != nil {
+			t.Fatalf("io.ReadAll() error = %v", err)
+		}
+		if diff := cmp.Diff("name,age,country\nKai,30,JP\n,40,JP\n", string(data)); diff != "" {
+			t.Errorf("output mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("with valid rows only", func(t *testing.T) {
+		t.Parallel()
+
+		var people []Person
+		processor := NewProcessor(FileTypeCSV, WithFilter("age >= 18"), WithFilter("country == 'JP'"), WithValidRowsOnly())
+		output, _, err := processor.Process(strings.NewReader(input), &people)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if len(people) != 1 || people[0].Name != "Kai" {
+			t.Errorf("people = %+v, want only Kai", people)
+		}
+		data, err := io.ReadAll(output)
+		if err != nil {
+			t.Fatalf("io.ReadAll() error = %v", err)
+		}
+		if diff := cmp.Diff("name,age,country\nKai,30,JP\n", string(data)); diff != "" {
+			t.Errorf("output mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name     string
+			fileType FileType
+			input    string
+			expr     string
+			wantErr  error
+		}{
+			{name: "malformed expression", fileType: FileTypeCSV, input: input, expr: "age >>= 18", wantErr: ErrInvalidOption},
+			{name: "unknown column", fileType: FileTypeCSV, input: input, expr: "city == 'Tokyo'", wantErr: ErrColumnNotFound},
+			{name: "JSON input", fileType: FileTypeJSON, input: `[{"name":"Kai"}]`, expr: "data != ''", wantErr: ErrUnsupportedFileType},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+				var people []Person
+				_, _, err := NewProcessor(tt.fileType, WithFilter(tt.expr)).Process(strings.NewReader(tt.input), &people)
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Process() error = %v, want %v", err, tt.wantErr)
+				}
+			})
+		}
+	})
+}
+
+func TestProcessor_TemplatePrep(t *testing.T) {
+	t.Parallel()
+
+	type Period struct {
+		Year  string `prep:"trim"`
+		Month string `prep:"pad_left=2:0"`
+		Start string `prep:"template={{.year}}-{{.month}}-01" validate:"datetime=2006-01-02"`
+	}
+
+	input := "year,month,start\n2024,03,\n2024,13,\n"
+	var periods []Period
+	output, result, err := NewProcessor(FileTypeCSV, WithChangeTracking()).Process(strings.NewReader(input), &periods)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	var starts []string
+	for _, p := range periods {
+		starts = append(starts, p.Start)
+	}
+	if diff := cmp.Diff([]string{"2024-03-01", "2024-13-01"}, starts); diff != "" {
+		t.Errorf("starts mismatch (-want +got):\n%s", diff)
+	}
+	if errs := result.ValidationErrors(); len(errs) != 1 || errs[0].Row != 2 || errs[0].Field != "Start" {
+		t.Errorf("ValidationErrors() = %v, want one Start error on row 2", errs)
+	}
+	var applied []string
+	for _, c := range result.Changes() {
+		if c.Field == "Start" {
+			applied = append(applied, c.Applied...)
+		}
+	}
+	if diff := cmp.Diff([]string{"template", "template"}, applied); diff != "" {
+		t.Errorf("applied mismatch (-want +got):\n%s", diff)
+	}
+	data, err := io.ReadAll(output)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+	if diff := cmp.Diff("year,month,start\n2024,03,2024-03-01\n2024,13,2024-13-01\n", string(data)); diff != "" {
+		t.Errorf("output mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestProcessor_WithColumnTypes(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Age   int
+		Price string `validate:"required"`
+	}
+	types := map[string]ColumnType{
+		"age":    ColumnInt(),
+		"price":  ColumnDecimal(2),
+		"active": ColumnBool(),
+	}
+
+	t.Run("values are coerced and validated", func(t *testing.T) {
+		t.Parallel()
+
+		input := "age,price,active\n30.0,1.5,yes\nthirty,1.005,maybe\n,,\n"
+		var items []Item
+		output, result, err := NewProcessor(FileTypeCSV, WithColumnTypes(types)).Process(strings.NewReader(input), &items)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		type issue struct {
+			Row    int
+			Column string
+			Tag    string
+			Param  string
+		}
+		var got []issue
+		for _, ve := range result.ValidationErrors() {
+			got = append(got, issue{ve.Row, ve.Column, ve.Tag, ve.Param})
+		}
+		want := []issue{
+			{Row: 2, Column: "age", Tag: "int"},
+			{Row: 2, Column: "price", Tag: "decimal", Param: "2"},
+			{Row: 2, Column: "active", Tag: "bool"},
+			{Row: 3, Column: "price", Tag: "required"},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("errors mismatch (-want +got):\n%s", diff)
+		}
+		if len(items) == 0 || items[0].Age != 30 || items[0].Price != "1.50" {
+			t.Errorf("items[0] = %+v, want {Age:30 Price:1.50}", items)
+		}
+		data, err := io.ReadAll(output)
+		if err != nil {
+			t.Fatalf("io.ReadAll() error = %v", err)
+		}
+		if diff := cmp.Diff("age,price,active\n30,1.50,true\nthirty,1.005,maybe\n,,\n", string(data)); diff != "" {
+			t.Errorf("output mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name    string
+			types   map[string]ColumnType
+			wantErr error
+		}{
+			{name: "unknown column", types: map[string]ColumnType{"weight": ColumnFloat()}, wantErr: ErrColumnNotFound},
+			{name: "zero type", types: map[string]ColumnType{"age": {}}, wantErr: ErrInvalidOption},
+			{name: "negative scale", types: map[string]ColumnType{"price": ColumnDecimal(-1)}, wantErr: ErrInvalidOption},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+				var items []Item
+				_, _, err := NewProcessor(FileTypeCSV, WithColumnTypes(tt.types)).Process(strings.NewReader("age,price\n1,2\n"), &items)
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Process() error = %v, want %v", err, tt.wantErr)
+				}
+			})
+		}
+	})
+}
+
+func TestProcessor_OrClear(t *testing.T) {
+	t.Parallel()
+
+	type contact struct {
+		Name  string `validate:"required"`
+		Email string `prep:"trim" validate:"email,orclear"`
+		Phone string `validate:"required,e164,orclear"`
+		Age   int    `validate:"omitempty,gte=0,orclear"`
+	}
+
+	input := "name,email,phone,age\n" +
+		"alice, alice@example.com ,+15551234567,30\n" +
+		"bob, not-an-email ,555-1234,-5\n" +
+		"carol,,+15557654321,\n"
+
+	var records []contact
+	output, result, err := NewProcessor(fileparser.CSV, WithChangeTracking()).Process(strings.NewReader(input), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	// The cleared phone still fails required; the email and age are dropped silently
+	errs := result.ValidationErrors()
+	if len(errs) != 1 || errs[0].Row != 2 || errs[0].Column != "phone" || errs[0].Tag != requiredTagValue {
+		t.Errorf("ValidationErrors() = %v, want one required error on row 2 phone", errs)
+	}
+	if records[1].Email != "" || records[1].Age != 0 {
+		t.Errorf("records[1] = %+v, want cleared Email and Age", records[1])
+	}
+
+	wantRepairs := map[string]ColumnRepairs{
+		"email": {Cleared: 1},
+		"phone": {Cleared: 1},
+		"age":   {Cleared: 1},
+	}
+	if diff := cmp.Diff(wantRepairs, result.Repairs); diff != "" {
+		t.Errorf("Repairs mismatch (-want +got):\n%s", diff)
+	}
+
+	wantChanges := []CellChange{
+		{Row: 1, Column: "email", Field: "Email", Before: " alice@example.com ", After: "alice@example.com", Applied: []string{"trim"}},
+		{Row: 2, Column: "email", Field: "Email", Before: " not-an-email ", After: "", Applied: []string{"trim", "orclear"}},
+		{Row: 2, Column: "phone", Field: "Phone", Before: "555-1234", Applied: []string{"orclear"}},
+		{Row: 2, Column: "age", Field: "Age", Before: "-5", Applied: []string{"orclear"}},
+	}
+	if diff := cmp.Diff(wantChanges, result.Changes()); diff != "" {
+		t.Errorf("Changes() mismatch (-want +got):\n%s", diff)
+	}
+
+	data, err := io.ReadAll(output)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+	want := "name,email,phone,age\nalice,alice@example.com,+15551234567,30\nbob,,,\ncarol,,+15557654321,\n"
+	if string(data) != want {
+		t.Errorf("output = %q, want %q", string(data), want)
+	}
+}
+
+func TestProcessor_ProcessChunks(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		ID   string `validate:"required"`
+		Name string `prep:"trim"`
+	}
+
+	input := "id,name\n1, a \n2,b\n,c\n4,d\n5,e\n"
+
+	type chunkSummary struct {
+		Output    string
+		IDs       []string
+		ErrorRows []int
+		RowCount  int
+		Valid     int
+		Offsets   []RowOffset
+	}
+
+	t.Run("yields chunks in order", func(t *testing.T) {
+		t.Parallel()
+
+		var items []item
+		var got []chunkSummary
+		err := NewProcessor(fileparser.CSV).ProcessChunks(strings.NewReader(input), &items, 2,
+			func(chunk Stream, res *ProcessResult) error {
+				data, err := io.ReadAll(chunk)
+				if err != nil {
+					return err
+				}
+				summary := chunkSummary{
+					Output:   string(data),
+					RowCount: res.RowCount,
+					Valid:    res.ValidRowCount,
+					Offsets:  chunk.RowOffsets(),
+				}
+				for _, it := range items {
+					summary.IDs = append(summary.IDs, it.ID)
+				}
+				for _, ve := range res.ValidationErrors() {
+					summary.ErrorRows = append(summary.ErrorRows, ve.Row)
+				}
+				got = append(got, summary)
+				return nil
+			})
+		if err != nil {
+			t.Fatalf("ProcessChunks() error = %v", err)
+		}
+
+		want := []chunkSummary{
+			{
+				Output: "id,name\n1,a\n2,b\n", IDs: []string{"1", "2"}, RowCount: 2, Valid: 2,
+				Offsets: []RowOffset{{Row: 1, Offset: 8}, {Row: 2, Offset: 12}},
+			},
+			{
+				Output: "id,name\n,c\n4,d\n", IDs: []string{"", "4"}, ErrorRows: []int{3}, RowCount: 2, Valid: 1,
+				Offsets: []RowOffset{{Row: 3, Offset: 8}, {Row: 4, Offset: 11}},
+			},
+			{
+				Output: "id,name\n5,e\n", IDs: []string{"5"}, RowCount: 1, Valid: 1,
+				Offsets: []RowOffset{{Row: 5, Offset: 8}},
+			},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("chunks mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("callback error stops processing", func(t *testing.T) {
+		t.Parallel()
+
+		errStop := errors.New("stop")
+		calls := 0
+		var items []item
+		err := NewProcessor(fileparser.CSV).ProcessChunks(strings.NewReader(input), &items, 2,
+			func(_ Stream, _ *ProcessResult) error {
+				calls++
+				return errStop
+			})
+		if !errors.Is(err, errStop) || calls != 1 {
+			t.Errorf("ProcessChunks() error = %v after %d calls, want errStop after 1 call", err, calls)
+		}
+	})
+
+	t.Run("strict validation fails the chunk with errors", func(t *testing.T) {
+		t.Parallel()
+
+		var firstRows []int
+		var items []item
+		err := NewProcessor(fileparser.CSV, WithStrictValidation(), WithStartRow(1)).ProcessChunks(
+			strings.NewReader(input), &items, 1,
+			func(chunk Stream, _ *ProcessResult) error {
+				firstRows = append(firstRows, chunk.RowOffsets()[0].Row)
+				return nil
+			})
+		if !errors.Is(err, ErrValidation) {
+			t.Errorf("ProcessChunks() error = %v, want ErrValidation", err)
+		}
+		if diff := cmp.Diff([]int{2}, firstRows); diff != "" {
+			t.Errorf("processed chunks mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("invalid chunk size", func(t *testing.T) {
+		t.Parallel()
+
+		var items []item
+		err := NewProcessor(fileparser.CSV).ProcessChunks(strings.NewReader(input), &items, 0,
+			func(_ Stream, _ *ProcessResult) error { return nil })
+		if err == nil {
+			t.Error("ProcessChunks() error = nil, want error for zero chunk size")
+		}
+	})
+}
+
+func TestProcessor_TableName(t *testing.T) {
+	t.Parallel()
+
+	type sample struct {
+		Name string
+	}
+
+	tests := []struct {
+		name      string
+		open      func(t *testing.T) io.Reader
+		opts      []Option
+		fileType  fileparser.FileType
+		wantTable string
+		wantName  string
+	}{
+		{
+			name:      "derived from file name",
+			open:      func(t *testing.T) io.Reader { return openTestFile(t, "testdata/sample.csv.gz") },
+			fileType:  fileparser.CSVGZ,
+			wantTable: "sample",
+			wantName:  "sample.csv",
+		},
+		{
+			name:      "option overrides file name",
+			open:      func(t *testing.T) io.Reader { return openTestFile(t, "testdata/sample.csv") },
+			opts:      []Option{WithTableName("users")},
+			fileType:  fileparser.CSV,
+			wantTable: "users",
+			wantName:  "users.csv",
+		},
+		{
+			name:      "unnamed reader",
+			open:      func(_ *testing.T) io.Reader { return strings.NewReader("name\nalice\n") },
+			fileType:  fileparser.CSV,
+			wantTable: "",
+			wantName:  "data.csv",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var records []sample
+			reader, _, err := NewProcessor(tt.fileType, tt.opts...).Process(tt.open(t), &records)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			stream := reader.(Stream)
+			if got := stream.TableName(); got != tt.wantTable {
+				t.Errorf("TableName() = %q, want %q", got, tt.wantTable)
+			}
+			if got := stream.Name(); got != tt.wantName {
+				t.Errorf("Name() = %q, want %q", got, tt.wantName)
+			}
+		})
+	}
+}
+
+func openTestFile(t *testing.T, path string) *os.File {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", path, err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestProcessor_InputLimits(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		Name string
+	}
+
+	input := "name\nalice\nbob\ncarol\n" // 21 bytes, 3 data rows
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	if _, err := zw.Write([]byte(input)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		fileType fileparser.FileType
+		data     []byte
+		opts     []Option
+		wantErr  error
+	}{
+		{"no limits", fileparser.CSV, []byte(input), nil, nil},
+		{"bytes at limit", fileparser.CSV, []byte(input), []Option{WithMaxBytes(21)}, nil},
+		{"bytes over limit", fileparser.CSV, []byte(input), []Option{WithMaxBytes(20)}, ErrInputTooLarge},
+		{"limit applies after decompression", fileparser.CSVGZ, gz.Bytes(), []Option{WithMaxBytes(20)}, ErrInputTooLarge},
+		{"rows at limit", fileparser.CSV, []byte(input), []Option{WithMaxRows(3)}, nil},
+		{"rows over limit", fileparser.CSV, []byte(input), []Option{WithMaxRows(2)}, ErrTooManyRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var records []row
+			_, _, err := NewProcessor(tt.fileType, tt.opts...).Process(bytes.NewReader(tt.data), &records)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Process() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestProcessor_WithChangeTracking(t *testing.T) {
+	t.Parallel()
+
+	type contact struct {
+		Name   string `prep:"trim,uppercase"`
+		Email  string `prep:"trim,lowercase"`
+		Status string `name:"status" prep:"default=active"`
+	}
+
+	input := "name,email,status\n  alice ,ALICE@EXAMPLE.COM,\nBOB,bob@example.com,inactive\n"
+
+	t.Run("records changed cells", func(t *testing.T) {
+		t.Parallel()
+
+		var records []contact
+		_, result, err := NewProcessor(fileparser.CSV, WithChangeTracking()).Process(strings.NewReader(input), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		want := []CellChange{
+			{Row: 1, Column: "name", Field: "Name", Before: "  alice ", After: "ALICE", Applied: []string{"trim", "uppercase"}},
+			{Row: 1, Column: "email", Field: "Email", Before: "ALICE@EXAMPLE.COM", After: "alice@example.com", Applied: []string{"lowercase"}},
+			{Row: 1, Column: "status", Field: "Status", Before: "", After: "active", Applied: []string{"default"}},
+		}
+		if diff := cmp.Diff(want, result.Changes()); diff != "" {
+			t.Errorf("Changes() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		var records []contact
+		_, result, err := NewProcessor(fileparser.CSV).Process(strings.NewReader(input), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if got := result.Changes(); got != nil {
+			t.Errorf("Changes() = %v, want nil", got)
+		}
+	})
+}
+
+func TestProcessor_WithPrepIdempotencyCheck(t *testing.T) {
+	t.Parallel()
+
+	type product struct {
+		SKU  string `prep:"prefix=ID-"`
+		Name string `prep:"trim,lowercase"`
+	}
+
+	input := "sku,name\n5, Widget \n"
+
+	var records []product
+	_, result, err := NewProcessor(fileparser.CSV, WithPrepIdempotencyCheck()).Process(strings.NewReader(input), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	if len(result.Warnings) != 1 {
+		t.Fatalf("Warnings = %v, want 1 warning", result.Warnings)
+	}
+	w := result.Warnings[0]
+	if w.Field != "SKU" || w.Row != 1 || w.Code() != CodeNonIdempotentPrep || w.Severity != SeverityWarning {
+		t.Errorf("warning = %+v (code %s), want non-idempotent SKU on row 1", w, w.Code())
+	}
+	if want := `prep chain is not idempotent: a second pass changes "ID-5" to "ID-ID-5"`; w.Message() != want {
+		t.Errorf("Message() = %q, want %q", w.Message(), want)
+	}
+	if result.ValidRowCount != 1 || records[0].SKU != "ID-5" {
+		t.Errorf("ValidRowCount = %d, SKU = %q, want 1 and first-pass value", result.ValidRowCount, records[0].SKU)
+	}
+}
+
+func TestProcessor_GroupValidators(t *testing.T) {
+	t.Parallel()
+
+	type contact struct {
+		Contact string `prep:"trim" validate:"required,or(email|e164)"`
+	}
+
+	input := "contact\nuser@example.com\n+14155552671\nnot a contact\n"
+
+	var records []contact
+	_, result, err := NewProcessor(fileparser.CSV).Process(strings.NewReader(input), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	errs := result.ValidationErrors()
+	if len(errs) != 1 {
+		t.Fatalf("ValidationErrors() = %v, want 1 error", errs)
+	}
+	if errs[0].Row != 3 || errs[0].Tag != "or" || errs[0].Param != "email|e164" || errs[0].Code() != "OR_INVALID" {
+		t.Errorf("error = %+v, want or(email|e164) failure on row 3", errs[0])
+	}
+}
+
+func TestProcessor_WithOmitEmpty(t *testing.T) {
+	t.Parallel()
+
+	type contact struct {
+		Name  string `validate:"required"`
+		Email string `validate:"email"`
+		ID    string `validate:"required,uuid"`
+	}
+
+	input := "name,email,id\nalice,,\n,bob@example.com,not-a-uuid\n"
+
+	tests := []struct {
+		name string
+		opts []Option
+		want []string // "row:field:tag"
+	}{
+		{
+			name: "default rejects empty email",
+			want: []string{"1:Email:email", "1:ID:required", "2:Name:required", "2:ID:uuid"},
+		},
+		{
+			name: "omitempty accepts empty email but keeps required",
+			opts: []Option{WithOmitEmpty()},
+			want: []string{"1:ID:required", "2:Name:required", "2:ID:uuid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var records []contact
+			_, result, err := NewProcessor(fileparser.CSV, tt.opts...).Process(strings.NewReader(input), &records)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			var got []string
+			for _, ve := range result.ValidationErrors() {
+				got = append(got, strconv.Itoa(ve.Row)+":"+ve.Field+":"+ve.Tag)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
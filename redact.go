@@ -0,0 +1,117 @@
+package fileprep
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// emptyJSONDataTag is the PrepError tag of JSON data emptied by preprocessing.
+const emptyJSONDataTag = "empty_json_data"
+
+// redactedColumns returns a function reporting whether the values of a
+// column are redacted, or nil when WithRedactValues is not used. It returns
+// an error wrapping ErrColumnNotFound for a column that is not in headers.
+func (p *Processor) redactedColumns(headers []string) (func(column string) bool, error) {
+	if !p.redactAll && len(p.redactColumns) == 0 {
+		return nil, nil //nolint:nilnil // nil means no column is redacted
+	}
+	if p.redactAll {
+		return func(string) bool { return true }, nil
+	}
+	for _, column := range p.redactColumns {
+		if !slices.Contains(headers, column) {
+			return nil, fmt.Errorf("redacted column %q: %w", column, ErrColumnNotFound)
+		}
+	}
+	return func(column string) bool { return slices.Contains(p.redactColumns, column) }, nil
+}
+
+// redactValues replaces the values of redacted columns in the errors,
+// warnings, change tracking, fix suggestions, and duplicate groups of the
+// result. Empty values are kept, since they reveal nothing and tell missing
+// values apart. Values that are not data, such as the cell count of a
+// ragged row, are kept too.
+func (r *ProcessResult) redactValues(redacted func(column string) bool, duplicateKeys []string) {
+	for _, err := range r.Errors {
+		switch e := err.(type) {
+		case *ValidationError:
+			redactValidationError(e, redacted)
+		case *PrepError:
+			if !redacted(e.Column) {
+				continue
+			}
+			if e.cause != nil {
+				e.value = redactedValue
+				e.cause = &redactedCause{err: e.cause}
+			} else if e.Tag == emptyJSONDataTag {
+				e.message = "JSON data is empty after preprocessing"
+			}
+		}
+	}
+	for _, w := range r.Warnings {
+		redactValidationError(w, redacted)
+	}
+	for i := range r.changes {
+		c := &r.changes[i]
+		if redacted(c.Column) {
+			c.Before, c.After = redact(c.Before), redact(c.After)
+		}
+	}
+	for i := range r.Suggestions {
+		s := &r.Suggestions[i]
+		if redacted(s.Column) {
+			s.Value, s.Suggested = redact(s.Value), redact(s.Suggested)
+		}
+	}
+	for i := range r.Duplicates {
+		key := slices.Clone(r.Duplicates[i].Key)
+		for k, column := range duplicateKeys {
+			if k < len(key) && redacted(column) {
+				key[k] = redact(key[k])
+			}
+		}
+		r.Duplicates[i].Key = key
+	}
+}
+
+// redactValidationError replaces the value of e if its column is redacted.
+func redactValidationError(e *ValidationError, redacted func(column string) bool) {
+	if e.Tag == columnCountTagName || e.Tag == excelErrorTagName || !redacted(e.Column) {
+		return
+	}
+	e.Value = redact(e.Value)
+	if e.code == CodeNonIdempotentPrep {
+		e.message = "prep chain is not idempotent: a second pass changes the value"
+	}
+}
+
+// redact returns redactedValue for a non-empty value.
+func redact(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// redactedCause hides the text of a conversion error, which quotes the
+// value, while keeping the error for errors.Is and errors.As.
+type redactedCause struct {
+	err error
+}
+
+// Error returns the reason of a strconv error, such as "invalid syntax",
+// or a generic reason for other errors
+func (c *redactedCause) Error() string {
+	var numErr *strconv.NumError
+	if errors.As(c.err, &numErr) {
+		return numErr.Err.Error()
+	}
+	return "invalid value"
+}
+
+// Unwrap returns the hidden error
+func (c *redactedCause) Unwrap() error {
+	return c.err
+}
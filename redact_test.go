@@ -0,0 +1,146 @@
+package fileprep
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileparser"
+)
+
+func TestProcessor_WithRedactValues(t *testing.T) {
+	t.Parallel()
+
+	type customer struct {
+		Name  string `validate:"required"`
+		Email string `prep:"trim" validate:"email"`
+		Age   int    `warn:"lte=120"`
+	}
+
+	input := "name,email,age\n" +
+		"alice, alice@example ,150\n" +
+		"bob, bob@example.com ,abc\n" +
+		",alice@example,30\n"
+
+	t.Run("listed columns", func(t *testing.T) {
+		t.Parallel()
+
+		var records []customer
+		output, result, err := NewProcessor(fileparser.CSV,
+			WithRedactValues("email", "age"),
+			WithChangeTracking(),
+			WithDuplicateReport("email"),
+		).Process(strings.NewReader(input), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+
+		var got []string
+		for _, ve := range result.ValidationErrors() {
+			got = append(got, strconv.Itoa(ve.Row)+" "+ve.Column+"="+strconv.Quote(ve.Value))
+		}
+		for _, w := range result.Warnings {
+			got = append(got, "warn "+strconv.Itoa(w.Row)+" "+w.Column+"="+strconv.Quote(w.Value))
+		}
+		want := []string{`1 email="[redacted]"`, `3 name=""`, `3 email="[redacted]"`, `warn 1 age="[redacted]"`, `warn 2 age="[redacted]"`}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("errors mismatch (-want +got):\n%s", diff)
+		}
+
+		prepErrs := result.PrepErrors()
+		if len(prepErrs) != 1 {
+			t.Fatalf("PrepErrors() = %v, want one conversion error", prepErrs)
+		}
+		if msg := prepErrs[0].Error(); strings.Contains(msg, "abc") || !strings.Contains(msg, "[redacted]") {
+			t.Errorf("PrepError = %q, want the value redacted", msg)
+		}
+		if !errors.Is(prepErrs[0], strconv.ErrSyntax) {
+			t.Errorf("PrepError should still wrap strconv.ErrSyntax")
+		}
+
+		for _, c := range result.Changes() {
+			if c.Column == "email" && (c.Before != redactedValue || c.After != redactedValue) {
+				t.Errorf("change %+v, want redacted Before and After", c)
+			}
+		}
+		if len(result.Duplicates) != 1 || result.Duplicates[0].Key[0] != redactedValue {
+			t.Errorf("Duplicates = %v, want one group with a redacted key", result.Duplicates)
+		}
+
+		// The data itself is not changed
+		data, err := io.ReadAll(output)
+		if err != nil {
+			t.Fatalf("io.ReadAll() error = %v", err)
+		}
+		if !strings.Contains(string(data), "alice@example") || records[0].Email != "alice@example" {
+			t.Errorf("output = %q, want the values unredacted", string(data))
+		}
+	})
+
+	t.Run("every column", func(t *testing.T) {
+		t.Parallel()
+
+		var records []customer
+		_, result, err := NewProcessor(fileparser.CSV, WithRedactValues()).Process(strings.NewReader(input), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		for _, e := range result.Errors {
+			if strings.Contains(e.Error(), "alice") || strings.Contains(e.Error(), "abc") {
+				t.Errorf("error %q contains a raw value", e.Error())
+			}
+		}
+		for _, w := range result.Warnings {
+			if w.Value != redactedValue {
+				t.Errorf("warning value = %q, want %q", w.Value, redactedValue)
+			}
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Parallel()
+
+		var records []customer
+		_, result, err := NewProcessor(fileparser.CSV).Process(strings.NewReader(input), &records)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if errs := result.ValidationErrors(); len(errs) == 0 || errs[0].Value != "alice@example" {
+			t.Errorf("ValidationErrors() = %v, want raw values", errs)
+		}
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		t.Parallel()
+
+		var records []customer
+		_, _, err := NewProcessor(fileparser.CSV, WithRedactValues("ssn")).Process(strings.NewReader(input), &records)
+		if !errors.Is(err, ErrColumnNotFound) {
+			t.Errorf("Process() error = %v, want ErrColumnNotFound", err)
+		}
+	})
+}
+
+func TestProcessResult_RedactValues_NonIdempotentPrep(t *testing.T) {
+	t.Parallel()
+
+	type record struct {
+		Code string `prep:"pad_left=4:0,truncate=3"`
+	}
+
+	var records []record
+	_, result, err := NewProcessor(fileparser.CSV, WithPrepIdempotencyCheck(), WithRedactValues("code")).
+		Process(strings.NewReader("code\n12345\n"), &records)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Fatalf("Warnings = %v, want one non-idempotent prep warning", result.Warnings)
+	}
+	if msg := result.Warnings[0].Error(); strings.Contains(msg, "123") {
+		t.Errorf("warning %q contains a raw value", msg)
+	}
+}
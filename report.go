@@ -0,0 +1,405 @@
+package fileprep
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// reportSampleRows is the number of invalid rows listed in a report.
+const reportSampleRows = 20
+
+// reportIssue is one error or warning of a ProcessResult, flattened for reports.
+type reportIssue struct {
+	Row     int
+	Column  string
+	Tag     string
+	Value   string
+	Message string
+}
+
+// reportColumn counts the issues of one column.
+type reportColumn struct {
+	Column string
+	Errors int
+	Tags   []reportCount // by count, then tag
+}
+
+// reportCount is a tag with the number of issues that have it.
+type reportCount struct {
+	Tag   string
+	Count int
+}
+
+// reportRow groups the issues of one invalid row.
+type reportRow struct {
+	Row    int
+	Issues []reportIssue
+}
+
+// resultReport summarizes a ProcessResult for the report writers.
+type resultReport struct {
+	Format       string
+	RowCount     int
+	ValidRows    int
+	InvalidRows  int
+	ErrorCount   int
+	WarningCount int
+	Columns      []reportColumn // columns with errors, most errors first
+	SampleRows   []reportRow    // the first invalid rows, in row order
+	MoreRows     int            // invalid rows not in SampleRows
+	Warnings     []reportIssue
+	Repairs      []reportRepair
+	HeaderIssues []HeaderIssue
+	Duplicates   int
+	Issues       []reportIssue // every error, in result order
+}
+
+// reportRepair is the repair counts of one column.
+type reportRepair struct {
+	Column string
+	ColumnRepairs
+}
+
+// newResultReport builds the report model of r.
+func newResultReport(r *ProcessResult) *resultReport {
+	rep := &resultReport{
+		Format:       r.OriginalFormat.String(),
+		RowCount:     r.RowCount,
+		ValidRows:    r.ValidRowCount,
+		InvalidRows:  r.InvalidRowCount(),
+		WarningCount: len(r.Warnings),
+		HeaderIssues: r.HeaderIssues,
+		Duplicates:   len(r.Duplicates),
+	}
+
+	for _, err := range r.Errors {
+		if issue, ok := newReportIssue(err); ok {
+			rep.Issues = append(rep.Issues, issue)
+		}
+	}
+	rep.ErrorCount = len(rep.Issues)
+	for _, w := range r.Warnings {
+		issue, _ := newReportIssue(w)
+		rep.Warnings = append(rep.Warnings, issue)
+	}
+
+	tagCounts := make(map[string]map[string]int)
+	rowIssues := make(map[int][]reportIssue)
+	for _, issue := range rep.Issues {
+		if tagCounts[issue.Column] == nil {
+			tagCounts[issue.Column] = make(map[string]int)
+		}
+		tagCounts[issue.Column][issue.Tag]++
+		rowIssues[issue.Row] = append(rowIssues[issue.Row], issue)
+	}
+	for column, tags := range tagCounts {
+		c := reportColumn{Column: column}
+		for tag, count := range tags {
+			c.Errors += count
+			c.Tags = append(c.Tags, reportCount{Tag: tag, Count: count})
+		}
+		slices.SortFunc(c.Tags, func(a, b reportCount) int {
+			return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Tag, b.Tag))
+		})
+		rep.Columns = append(rep.Columns, c)
+	}
+	slices.SortFunc(rep.Columns, func(a, b reportColumn) int {
+		return cmp.Or(cmp.Compare(b.Errors, a.Errors), cmp.Compare(a.Column, b.Column))
+	})
+
+	rows := slices.Sorted(maps.Keys(rowIssues))
+	for _, row := range rows[:min(len(rows), reportSampleRows)] {
+		rep.SampleRows = append(rep.SampleRows, reportRow{Row: row, Issues: rowIssues[row]})
+	}
+	rep.MoreRows = len(rows) - len(rep.SampleRows)
+
+	for _, column := range slices.Sorted(maps.Keys(r.Repairs)) {
+		rep.Repairs = append(rep.Repairs, reportRepair{Column: column, ColumnRepairs: r.Repairs[column]})
+	}
+	return rep
+}
+
+// newReportIssue flattens a *ValidationError or *PrepError. It reports false
+// for other errors.
+func newReportIssue(err error) (reportIssue, bool) {
+	switch e := err.(type) {
+	case *ValidationError:
+		return reportIssue{
+			Row: e.Row, Column: e.Column, Tag: e.Tag, Value: e.Value, Message: e.Message(),
+		}, true
+	case *PrepError:
+		return reportIssue{
+			Row: e.Row, Column: e.Column, Tag: e.Tag, Value: e.value, Message: e.Message(),
+		}, true
+	default:
+		return reportIssue{}, false
+	}
+}
+
+// WriteHTMLReport writes a self-contained HTML page summarizing the result:
+// row counts, errors by column and tag, the first invalid rows with their
+// errors, warnings, repairs, and header issues. The page has no external
+// resources, so it can be attached to CI artifacts or emailed to the data
+// provider. Values from the file are HTML-escaped.
+//
+// Example:
+//
+//	_, result, err := processor.Process(upload, &orders)
+//	if err != nil {
+//	    return err
+//	}
+//	f, _ := os.Create("report.html")
+//	defer f.Close()
+//	if err := result.WriteHTMLReport(f); err != nil {
+//	    return err
+//	}
+func (r *ProcessResult) WriteHTMLReport(w io.Writer) error {
+	if err := htmlReportTemplate.Execute(w, newResultReport(r)); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
+
+//nolint:gochecknoglobals // parsed once
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>fileprep report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f3f3f3; }
+.ok { color: #17672a; }
+.bad { color: #a11; }
+code { background: #f6f6f6; padding: 0 0.2em; }
+</style>
+</head>
+<body>
+<h1>fileprep report</h1>
+<h2>Summary</h2>
+<table>
+<tr><th>Format</th><td>{{.Format}}</td></tr>
+<tr><th>Rows</th><td>{{.RowCount}}</td></tr>
+<tr><th>Valid rows</th><td class="ok">{{.ValidRows}}</td></tr>
+<tr><th>Invalid rows</th><td{{if .InvalidRows}} class="bad"{{end}}>{{.InvalidRows}}</td></tr>
+<tr><th>Errors</th><td>{{.ErrorCount}}</td></tr>
+<tr><th>Warnings</th><td>{{.WarningCount}}</td></tr>
+<tr><th>Duplicate key groups</th><td>{{.Duplicates}}</td></tr>
+</table>
+{{- if .Columns}}
+<h2>Errors by column</h2>
+<table>
+<tr><th>Column</th><th>Errors</th><th>Tags</th></tr>
+{{- range .Columns}}
+<tr><td>{{.Column}}</td><td>{{.Errors}}</td><td>{{range $i, $t := .Tags}}{{if $i}}, {{end}}<code>{{$t.Tag}}</code> ({{$t.Count}}){{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .SampleRows}}
+<h2>Invalid rows</h2>
+<table>
+<tr><th>Row</th><th>Column</th><th>Tag</th><th>Value</th><th>Message</th></tr>
+{{- range .SampleRows}}{{$row := .Row}}
+{{- range .Issues}}
+<tr><td>{{$row}}</td><td>{{.Column}}</td><td><code>{{.Tag}}</code></td><td><code>{{.Value}}</code></td><td>{{.Message}}</td></tr>
+{{- end}}
+{{- end}}
+</table>
+{{- if .MoreRows}}
+<p>{{.MoreRows}} more invalid rows are not shown.</p>
+{{- end}}
+{{- end}}
+{{- if .Warnings}}
+<h2>Warnings</h2>
+<table>
+<tr><th>Row</th><th>Column</th><th>Tag</th><th>Value</th><th>Message</th></tr>
+{{- range .Warnings}}
+<tr><td>{{.Row}}</td><td>{{.Column}}</td><td><code>{{.Tag}}</code></td><td><code>{{.Value}}</code></td><td>{{.Message}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Repairs}}
+<h2>Repairs</h2>
+<table>
+<tr><th>Column</th><th>Padded</th><th>Truncated</th><th>Clamped</th><th>Cleared</th><th>Conversion fallbacks</th></tr>
+{{- range .Repairs}}
+<tr><td>{{.Column}}</td><td>{{.Padded}}</td><td>{{.Truncated}}</td><td>{{.Clamped}}</td><td>{{.Cleared}}</td><td>{{.ConversionFallbacks}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .HeaderIssues}}
+<h2>Header issues</h2>
+<table>
+<tr><th>Column</th><th>Reason</th><th>Suggestion</th></tr>
+{{- range .HeaderIssues}}
+<tr><td>{{.Column}}</td><td>{{.Reason}}</td><td>{{.Suggestion}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
+
+// junitMaxIssues is the number of errors listed in the failure text of one
+// JUnit test case.
+const junitMaxIssues = 100
+
+// JUnit XML elements written by WriteJUnit.
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+	junitTestSuite struct {
+		Name       string          `xml:"name,attr"`
+		Tests      int             `xml:"tests,attr"`
+		Failures   int             `xml:"failures,attr"`
+		Errors     int             `xml:"errors,attr"`
+		Properties []junitProperty `xml:"properties>property"`
+		Cases      []junitTestCase `xml:"testcase"`
+	}
+	junitProperty struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value,attr"`
+	}
+	junitTestCase struct {
+		ClassName string        `xml:"classname,attr"`
+		Name      string        `xml:"name,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+		SystemOut string        `xml:"system-out,omitempty"`
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// junitRowCase is the test case name for errors that belong to a whole row
+// rather than a column, such as column_count.
+const junitRowCase = "row structure"
+
+// WriteJUnit writes the result as a JUnit XML report, so data quality checks
+// show up in CI systems that render JUnit test reports, such as GitLab and
+// Jenkins. Every column of the header is a test case named "column <name>"
+// that fails when any of its values has an error; the failure lists the
+// errors, up to 100, one per line. Errors not tied to a column fail the
+// "row structure" case. Warnings are written to the system-out of their
+// column's case and do not fail it. The row counts are suite properties.
+//
+// Example:
+//
+//	_, result, err := processor.Process(upload, &orders)
+//	if err != nil {
+//	    return err
+//	}
+//	f, _ := os.Create("data-quality.xml")
+//	defer f.Close()
+//	if err := result.WriteJUnit(f); err != nil {
+//	    return err
+//	}
+func (r *ProcessResult) WriteJUnit(w io.Writer) error {
+	rep := newResultReport(r)
+
+	byColumn := make(map[string][]reportIssue)
+	for _, issue := range rep.Issues {
+		byColumn[issue.Column] = append(byColumn[issue.Column], issue)
+	}
+	warnings := make(map[string][]reportIssue)
+	for _, issue := range rep.Warnings {
+		warnings[issue.Column] = append(warnings[issue.Column], issue)
+	}
+
+	columns := slices.Clone(r.Columns)
+	for column := range byColumn {
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+
+	suite := junitTestSuite{
+		Name: "fileprep",
+		Properties: []junitProperty{
+			{Name: "format", Value: rep.Format},
+			{Name: "rows", Value: strconv.Itoa(rep.RowCount)},
+			{Name: "valid_rows", Value: strconv.Itoa(rep.ValidRows)},
+			{Name: "invalid_rows", Value: strconv.Itoa(rep.InvalidRows)},
+		},
+	}
+	className := "fileprep." + rep.Format
+	for _, column := range columns {
+		name := "column " + column
+		if column == "" {
+			name = junitRowCase
+		}
+		tc := junitTestCase{ClassName: className, Name: name, SystemOut: junitIssueLines(warnings[column])}
+		if issues := byColumn[column]; len(issues) > 0 {
+			tc.Failure = &junitFailure{
+				Message: junitFailureMessage(len(issues)),
+				Type:    junitFailureType(issues),
+				Text:    junitIssueLines(issues),
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+
+	doc := junitTestSuites{Name: "fileprep", Tests: suite.Tests, Failures: suite.Failures, Suites: []junitTestSuite{suite}}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+// junitFailureMessage returns the failure message for n errors.
+func junitFailureMessage(n int) string {
+	if n == 1 {
+		return "1 error"
+	}
+	return fmt.Sprintf("%d errors", n)
+}
+
+// junitIssueLines renders issues one per line, up to junitMaxIssues.
+func junitIssueLines(issues []reportIssue) string {
+	var b strings.Builder
+	for _, issue := range issues[:min(len(issues), junitMaxIssues)] {
+		fmt.Fprintf(&b, "row %d: %s: %s (value=%q)\n", issue.Row, issue.Tag, issue.Message, issue.Value)
+	}
+	if more := len(issues) - junitMaxIssues; more > 0 {
+		fmt.Fprintf(&b, "... %d more\n", more)
+	}
+	return b.String()
+}
+
+// junitFailureType returns the tags of issues, comma-separated in order of
+// first appearance.
+func junitFailureType(issues []reportIssue) string {
+	var tags []string
+	for _, issue := range issues {
+		if !slices.Contains(tags, issue.Tag) {
+			tags = append(tags, issue.Tag)
+		}
+	}
+	return strings.Join(tags, ",")
+}
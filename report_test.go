@@ -0,0 +1,132 @@
+package fileprep
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileparser"
+)
+
+type reportOrder struct {
+	ID    string `validate:"required,numeric"`
+	Email string `validate:"email"`
+	Note  string `prep:"truncate=3"`
+}
+
+func processReportInput(t *testing.T, input string) *ProcessResult {
+	t.Helper()
+	var orders []reportOrder
+	_, result, err := NewProcessor(fileparser.CSV).Process(strings.NewReader(input), &orders)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	return result
+}
+
+func TestNewResultReport(t *testing.T) {
+	t.Parallel()
+
+	result := processReportInput(t, "id,email,note\n1,a@example.com,ok\nx,bad,long note\n,also-bad,\n4,d@example.com,\n")
+	rep := newResultReport(result)
+
+	want := []reportColumn{
+		{Column: "email", Errors: 2, Tags: []reportCount{{Tag: "email", Count: 2}}},
+		{Column: "id", Errors: 2, Tags: []reportCount{{Tag: "numeric", Count: 1}, {Tag: "required", Count: 1}}},
+	}
+	if diff := cmp.Diff(want, rep.Columns); diff != "" {
+		t.Errorf("Columns mismatch (-want +got):\n%s", diff)
+	}
+	var sampleRows []int
+	for _, row := range rep.SampleRows {
+		sampleRows = append(sampleRows, row.Row)
+	}
+	if diff := cmp.Diff([]int{2, 3}, sampleRows); diff != "" {
+		t.Errorf("SampleRows mismatch (-want +got):\n%s", diff)
+	}
+	if rep.RowCount != 4 || rep.InvalidRows != 2 || rep.ErrorCount != 4 {
+		t.Errorf("counts = %d rows, %d invalid, %d errors", rep.RowCount, rep.InvalidRows, rep.ErrorCount)
+	}
+	wantRepairs := []reportRepair{{Column: "note", ColumnRepairs: ColumnRepairs{Truncated: 1}}}
+	if diff := cmp.Diff(wantRepairs, rep.Repairs); diff != "" {
+		t.Errorf("Repairs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewResultReport_SampleRowLimit(t *testing.T) {
+	t.Parallel()
+
+	var input strings.Builder
+	input.WriteString("id,email,note\n")
+	for range reportSampleRows + 5 {
+		input.WriteString("x,a@example.com,\n")
+	}
+	rep := newResultReport(processReportInput(t, input.String()))
+	if len(rep.SampleRows) != reportSampleRows || rep.MoreRows != 5 {
+		t.Errorf("SampleRows = %d, MoreRows = %d", len(rep.SampleRows), rep.MoreRows)
+	}
+}
+
+func TestProcessResult_WriteHTMLReport(t *testing.T) {
+	t.Parallel()
+
+	result := processReportInput(t, "id,email,note\n1,<script>alert(1)</script>,ok\n")
+	var buf bytes.Buffer
+	if err := result.WriteHTMLReport(&buf); err != nil {
+		t.Fatalf("WriteHTMLReport() error = %v", err)
+	}
+	html := buf.String()
+	for _, want := range []string{"<!DOCTYPE html>", "Errors by column", "Invalid rows", "&lt;script&gt;"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("report contains an unescaped value")
+	}
+}
+
+func TestProcessResult_WriteJUnit(t *testing.T) {
+	t.Parallel()
+
+	result := processReportInput(t, "id,email,note\n1,a@example.com,ok\nx,bad,\n,c@example.com,\n")
+	var buf bytes.Buffer
+	if err := result.WriteJUnit(&buf); err != nil {
+		t.Fatalf("WriteJUnit() error = %v", err)
+	}
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Tests != 3 || doc.Failures != 2 {
+		t.Errorf("tests = %d, failures = %d, want 3 and 2", doc.Tests, doc.Failures)
+	}
+
+	type caseResult struct {
+		Name    string
+		Type    string
+		Message string
+	}
+	var got []caseResult
+	for _, tc := range doc.Suites[0].Cases {
+		c := caseResult{Name: tc.Name}
+		if tc.Failure != nil {
+			c.Type, c.Message = tc.Failure.Type, tc.Failure.Message
+		}
+		got = append(got, c)
+	}
+	want := []caseResult{
+		{Name: "column id", Type: "numeric,required", Message: "2 errors"},
+		{Name: "column email", Type: "email", Message: "1 error"},
+		{Name: "column note"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("test cases mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(doc.Suites[0].Cases[0].Failure.Text, "row 2: numeric:") {
+		t.Errorf("failure text = %q", doc.Suites[0].Cases[0].Failure.Text)
+	}
+}
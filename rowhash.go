@@ -0,0 +1,113 @@
+package fileprep
+
+import (
+	"crypto/md5"  //nolint:gosec // MD5 is offered for compatibility with existing change-detection columns
+	"crypto/sha1" //nolint:gosec // SHA-1 is offered for compatibility with existing change-detection columns
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"slices"
+)
+
+// HashAlgorithm selects the hash function of WithRowHashColumn.
+type HashAlgorithm int
+
+const (
+	// SHA256 hashes with SHA-256 (64 hex digits). This is the default.
+	SHA256 HashAlgorithm = iota
+	// SHA1 hashes with SHA-1 (40 hex digits).
+	SHA1
+	// MD5 hashes with MD5 (32 hex digits).
+	MD5
+	// FNV64a hashes with 64-bit FNV-1a (16 hex digits). It is fast but not
+	// collision resistant, so it suits change detection within one table.
+	FNV64a
+)
+
+// String returns the algorithm name
+func (a HashAlgorithm) String() string {
+	switch a {
+	case SHA256:
+		return "sha256"
+	case SHA1:
+		return "sha1"
+	case MD5:
+		return "md5"
+	case FNV64a:
+		return "fnv64a"
+	default:
+		return "unknown"
+	}
+}
+
+// hashAlgorithmByName returns the algorithm whose String is name.
+func hashAlgorithmByName(name string) (HashAlgorithm, bool) {
+	for _, a := range []HashAlgorithm{SHA256, SHA1, MD5, FNV64a} {
+		if a.String() == name {
+			return a, true
+		}
+	}
+	return 0, false
+}
+
+// newHash returns a new hash.Hash for the algorithm.
+func (a HashAlgorithm) newHash() (hash.Hash, error) {
+	switch a {
+	case SHA256:
+		return sha256.New(), nil
+	case SHA1:
+		return sha1.New(), nil //nolint:gosec // see import
+	case MD5:
+		return md5.New(), nil //nolint:gosec // see import
+	case FNV64a:
+		return fnv.New64a(), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown hash algorithm %d", ErrInvalidOption, int(a))
+	}
+}
+
+// rowHashColumn appends a hash of selected columns of each row.
+type rowHashColumn struct {
+	name      string
+	algorithm HashAlgorithm
+	columns   []string // all columns when empty
+}
+
+// apply appends the hash column. Each value is written with its length
+// before it, so ("ab", "c") and ("a", "bc") hash differently.
+func (r *rowHashColumn) apply(headers []string, records [][]string) ([]string, [][]string, error) {
+	h, err := r.algorithm.newHash()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	colIdxs := make([]int, 0, len(headers))
+	if len(r.columns) == 0 {
+		for i := range headers {
+			colIdxs = append(colIdxs, i)
+		}
+	}
+	for _, column := range r.columns {
+		colIdx := slices.Index(headers, column)
+		if colIdx < 0 {
+			return nil, nil, fmt.Errorf("row hash column %q: %w", column, ErrColumnNotFound)
+		}
+		colIdxs = append(colIdxs, colIdx)
+	}
+
+	var lenBuf [binary.MaxVarintLen64]byte
+	var sum []byte
+	return addColumns(headers, records, []string{r.name}, func(_ int, record []string) []string {
+		h.Reset()
+		for _, colIdx := range colIdxs {
+			value := cell(record, colIdx)
+			h.Write(lenBuf[:binary.PutUvarint(lenBuf[:], uint64(len(value)))])
+			h.Write([]byte(value))
+		}
+		sum = h.Sum(sum[:0])
+		return []string{hex.EncodeToString(sum)}
+	})
+}
@@ -0,0 +1,99 @@
+package fileprep
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRowHashColumn(t *testing.T) {
+	t.Parallel()
+
+	headers := []string{"id", "email", "name"}
+
+	t.Run("hash lengths per algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			algorithm HashAlgorithm
+			wantLen   int
+		}{
+			{algorithm: SHA256, wantLen: 64},
+			{algorithm: SHA1, wantLen: 40},
+			{algorithm: MD5, wantLen: 32},
+			{algorithm: FNV64a, wantLen: 16},
+		}
+		for _, tt := range tests {
+			t.Run(tt.algorithm.String(), func(t *testing.T) {
+				t.Parallel()
+				h := &rowHashColumn{name: "_hash", algorithm: tt.algorithm}
+				_, records, err := h.apply(headers, [][]string{{"1", "a@example.com", "Alice"}})
+				if err != nil {
+					t.Fatalf("apply() error = %v", err)
+				}
+				if got := len(records[0][3]); got != tt.wantLen {
+					t.Errorf("hash length = %d, want %d", got, tt.wantLen)
+				}
+			})
+		}
+	})
+
+	t.Run("selected columns only", func(t *testing.T) {
+		t.Parallel()
+
+		h := &rowHashColumn{name: "_hash", algorithm: SHA256, columns: []string{"email", "name"}}
+		gotHeaders, records, err := h.apply(headers, [][]string{
+			{"1", "a@example.com", "Alice"},
+			{"2", "a@example.com", "Alice"},
+			{"3", "a@example.com", "Alicia"},
+		})
+		if err != nil {
+			t.Fatalf("apply() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"id", "email", "name", "_hash"}, gotHeaders); diff != "" {
+			t.Errorf("headers mismatch (-want +got):\n%s", diff)
+		}
+		if records[0][3] != records[1][3] {
+			t.Error("rows differing only in unhashed columns have different hashes")
+		}
+		if records[0][3] == records[2][3] {
+			t.Error("rows differing in a hashed column have the same hash")
+		}
+	})
+
+	t.Run("values are length prefixed", func(t *testing.T) {
+		t.Parallel()
+
+		h := &rowHashColumn{name: "_hash", algorithm: SHA256, columns: []string{"email", "name"}}
+		_, records, err := h.apply(headers, [][]string{{"1", "ab", "c"}, {"2", "a", "bc"}, {"3"}})
+		if err != nil {
+			t.Fatalf("apply() error = %v", err)
+		}
+		if records[0][3] == records[1][3] {
+			t.Error(`("ab", "c") and ("a", "bc") have the same hash`)
+		}
+		// Two empty values: two zero length prefixes.
+		if want := "96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7"; records[2][3] != want {
+			t.Errorf("hash of missing cells = %s, want %s", records[2][3], want)
+		}
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		t.Parallel()
+
+		h := &rowHashColumn{name: "_hash", algorithm: SHA256, columns: []string{"phone"}}
+		if _, _, err := h.apply(headers, nil); !errors.Is(err, ErrColumnNotFound) {
+			t.Errorf("apply() error = %v, want ErrColumnNotFound", err)
+		}
+	})
+
+	t.Run("unknown algorithm", func(t *testing.T) {
+		t.Parallel()
+
+		h := &rowHashColumn{name: "_hash", algorithm: HashAlgorithm(99)}
+		if _, _, err := h.apply(headers, nil); !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("apply() error = %v, want ErrInvalidOption", err)
+		}
+	})
+}
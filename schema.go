@@ -0,0 +1,351 @@
+package fileprep
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaColumn describes one column for schema export, in terms shared by
+// Table Schema and JSON Schema.
+type schemaColumn struct {
+	name     string
+	typ      string // string, integer, number, boolean, or datetime
+	format   string // JSON Schema format: email, uri, uuid, ipv4, ipv6, hostname
+	required bool
+	unique   bool
+
+	minimum, maximum                   *float64
+	exclusiveMinimum, exclusiveMaximum *float64
+	length                             *int
+	enum                               []string
+}
+
+// schemaColumns returns the columns of the struct type of v with the
+// constraints derived from their validate tags. Tags without a schema
+// counterpart, such as startswith or cross-field rules, are left out. For a
+// ProcessResult, it returns the processed columns as strings.
+func schemaColumns(v any) ([]schemaColumn, [][]string, error) {
+	if r, ok := v.(*ProcessResult); ok && r != nil {
+		columns := make([]schemaColumn, len(r.Columns))
+		for i, name := range r.Columns {
+			columns[i] = schemaColumn{name: name, typ: "string"}
+		}
+		return columns, nil, nil
+	}
+
+	structType, err := schemaStructType(v)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := parseStructType(structType, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columnOf := make(map[string]string, len(info.Fields))
+	for _, fi := range info.Fields {
+		columnOf[fi.Name] = fi.ColumnName
+	}
+
+	columns := make([]schemaColumn, 0, len(info.Fields))
+	var uniqueKeys [][]string
+	for _, fi := range info.Fields {
+		col := schemaColumn{name: fi.ColumnName, typ: schemaKindType(structType.Field(fi.Index).Type)}
+		for _, val := range fi.Validators {
+			col.applyValidator(val.Name(), validatorParam(val))
+		}
+		for _, cv := range fi.CrossFieldValidators {
+			u, ok := cv.(*uniqueValidator)
+			if !ok {
+				continue
+			}
+			if len(u.targetFields) == 0 {
+				col.unique = true
+				continue
+			}
+			key := []string{fi.ColumnName}
+			for _, target := range u.targetFields {
+				key = append(key, cmp.Or(columnOf[target], target))
+			}
+			uniqueKeys = append(uniqueKeys, key)
+		}
+		columns = append(columns, col)
+	}
+	return columns, uniqueKeys, nil
+}
+
+// schemaStructType returns the struct type of v: a struct, a slice or array
+// of structs, a pointer to either, or their reflect.Type.
+func schemaStructType(v any) (reflect.Type, error) {
+	if v == nil {
+		return nil, fmt.Errorf("%w: nil value provided", ErrStructSlicePointer)
+	}
+	t, ok := v.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(v)
+	}
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: expected a struct type, got %s", ErrStructSlicePointer, t.Kind())
+	}
+	return t, nil
+}
+
+// schemaKindType returns the schema type of a struct field type.
+func schemaKindType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeFor[time.Time]() {
+		return "datetime"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// applyValidator adds the constraint of one validate tag.
+func (c *schemaColumn) applyValidator(name, param string) {
+	number := func() *float64 {
+		f, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil
+		}
+		return &f
+	}
+	// Numeric comparisons turn a string column into a number column
+	numeric := func() {
+		if c.typ == "string" {
+			c.typ = "number"
+		}
+	}
+
+	switch name {
+	case requiredTagValue:
+		c.required = true
+	case numericTagValue:
+		if c.typ == "string" || c.typ == "number" {
+			c.typ = "integer"
+		}
+	case numberTagValue:
+		numeric()
+	case booleanTagValue:
+		if c.typ == "string" {
+			c.typ = "boolean"
+		}
+	case minTagValue, greaterThanEqualTagValue:
+		numeric()
+		c.minimum = number()
+	case maxTagValue, lessThanEqualTagValue:
+		numeric()
+		c.maximum = number()
+	case greaterThanTagValue:
+		numeric()
+		c.exclusiveMinimum = number()
+	case lessThanTagValue:
+		numeric()
+		c.exclusiveMaximum = number()
+	case lengthTagValue:
+		if n, err := strconv.Atoi(param); err == nil {
+			c.length = &n
+		}
+	case oneOfTagValue:
+		c.enum = strings.Fields(param)
+	case emailTagValue:
+		c.format = "email"
+	case uriTagValue, urlTagValue, httpURLTagValue, httpsURLTagValue:
+		c.format = "uri"
+	case uuidTagValue, uuid3TagValue, uuid4TagValue, uuid5TagValue:
+		c.format = "uuid"
+	case ip4AddrTagValue:
+		c.format = "ipv4"
+	case ip6AddrTagValue:
+		c.format = "ipv6"
+	case hostnameTagValue, hostnameRFC1123TagValue:
+		c.format = "hostname"
+	}
+}
+
+// enumValues returns the enum values typed for the column: numbers for
+// numeric columns when they parse, strings otherwise.
+func (c *schemaColumn) enumValues() []any {
+	values := make([]any, len(c.enum))
+	for i, v := range c.enum {
+		values[i] = v
+		if c.typ == "integer" || c.typ == "number" {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				values[i] = f
+			}
+		}
+	}
+	return values
+}
+
+// Frictionless Table Schema documents written by ExportTableSchema.
+type (
+	tableSchema struct {
+		Fields        []tableSchemaField `json:"fields"`
+		MissingValues []string           `json:"missingValues"`
+		UniqueKeys    [][]string         `json:"uniqueKeys,omitempty"`
+	}
+	tableSchemaField struct {
+		Name        string                 `json:"name"`
+		Type        string                 `json:"type"`
+		Format      string                 `json:"format,omitempty"`
+		Constraints *tableSchemaConstraint `json:"constraints,omitempty"`
+	}
+	tableSchemaConstraint struct {
+		Required  bool     `json:"required,omitempty"`
+		Unique    bool     `json:"unique,omitempty"`
+		Minimum   *float64 `json:"minimum,omitempty"`
+		Maximum   *float64 `json:"maximum,omitempty"`
+		MinLength *int     `json:"minLength,omitempty"`
+		MaxLength *int     `json:"maxLength,omitempty"`
+		Enum      []any    `json:"enum,omitempty"`
+	}
+)
+
+// ExportTableSchema returns a Frictionless Data Table Schema
+// (https://specs.frictionlessdata.io/table-schema/) describing the columns
+// of a struct: their names, types, and the constraints of their validate
+// tags, for use with other validation tooling. v is a struct, a slice of
+// structs, a pointer to either, or a reflect.Type. v may also be the
+// *ProcessResult of a run, which describes its columns as strings without
+// constraints, e.g. for a file processed with an empty struct.
+//
+// Types come from the field type, or from the numeric, number, and boolean
+// validators for string fields. required, unique, min, max, gte, lte, len,
+// and oneof become constraints, and email, uri, and uuid formats. Table
+// Schema has no exclusive bounds, so gt and lt are left out; rules without
+// a counterpart, such as startswith and cross-field rules, are left out too.
+//
+// Example:
+//
+//	type User struct {
+//	    ID    int    `validate:"required,min=1"`
+//	    Email string `validate:"required,email"`
+//	}
+//	schema, err := fileprep.ExportTableSchema([]User{})
+//	// {"fields":[{"name":"id","type":"integer","constraints":{"required":true,"minimum":1}}, ...
+func ExportTableSchema(v any) ([]byte, error) {
+	columns, uniqueKeys, err := schemaColumns(v)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := tableSchema{MissingValues: []string{""}, UniqueKeys: uniqueKeys}
+	for _, c := range columns {
+		field := tableSchemaField{Name: c.name, Type: c.typ}
+		if c.format == "email" || c.format == "uri" || c.format == "uuid" {
+			field.Format = c.format
+		}
+		constraints := tableSchemaConstraint{
+			Required:  c.required,
+			Unique:    c.unique,
+			Minimum:   c.minimum,
+			Maximum:   c.maximum,
+			MinLength: c.length,
+			MaxLength: c.length,
+		}
+		if len(c.enum) > 0 {
+			constraints.Enum = c.enumValues()
+		}
+		if !reflect.ValueOf(constraints).IsZero() {
+			field.Constraints = &constraints
+		}
+		schema.Fields = append(schema.Fields, field)
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// JSON Schema documents written by ExportJSONSchema.
+type (
+	jsonSchema struct {
+		Schema     string                        `json:"$schema"`
+		Title      string                        `json:"title,omitempty"`
+		Type       string                        `json:"type"`
+		Properties map[string]jsonSchemaProperty `json:"properties"`
+		Required   []string                      `json:"required,omitempty"`
+	}
+	jsonSchemaProperty struct {
+		Type             string   `json:"type"`
+		Format           string   `json:"format,omitempty"`
+		Minimum          *float64 `json:"minimum,omitempty"`
+		Maximum          *float64 `json:"maximum,omitempty"`
+		ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum *float64 `json:"exclusiveMaximum,omitempty"`
+		MinLength        *int     `json:"minLength,omitempty"`
+		MaxLength        *int     `json:"maxLength,omitempty"`
+		Enum             []any    `json:"enum,omitempty"`
+	}
+)
+
+// jsonSchemaDraft is the JSON Schema dialect written by ExportJSONSchema.
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// ExportJSONSchema returns a JSON Schema (draft 2020-12) describing one row
+// of a struct as an object keyed by column name, with the same types and
+// constraints as ExportTableSchema. gt and lt become exclusiveMinimum and
+// exclusiveMaximum, datetime columns are strings with the date-time format,
+// and required string columns get a minLength of 1, since fileprep treats
+// an empty value as missing. v is the same as for ExportTableSchema.
+//
+// Example:
+//
+//	schema, err := fileprep.ExportJSONSchema([]User{})
+func ExportJSONSchema(v any) ([]byte, error) {
+	columns, _, err := schemaColumns(v)
+	if err != nil {
+		return nil, err
+	}
+	schema := jsonSchema{
+		Schema:     jsonSchemaDraft,
+		Type:       "object",
+		Properties: make(map[string]jsonSchemaProperty, len(columns)),
+	}
+	if structType, err := schemaStructType(v); err == nil {
+		schema.Title = structType.Name()
+	}
+	for _, c := range columns {
+		prop := jsonSchemaProperty{
+			Type:             c.typ,
+			Format:           c.format,
+			Minimum:          c.minimum,
+			Maximum:          c.maximum,
+			ExclusiveMinimum: c.exclusiveMinimum,
+			ExclusiveMaximum: c.exclusiveMaximum,
+			MinLength:        c.length,
+			MaxLength:        c.length,
+		}
+		if c.typ == "datetime" {
+			prop.Type, prop.Format = "string", "date-time"
+		}
+		if len(c.enum) > 0 {
+			prop.Enum = c.enumValues()
+		}
+		if c.required {
+			schema.Required = append(schema.Required, c.name)
+			if prop.Type == "string" && prop.MinLength == nil {
+				one := 1
+				prop.MinLength = &one
+			}
+		}
+		schema.Properties[c.name] = prop
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
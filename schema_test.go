@@ -0,0 +1,145 @@
+package fileprep
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type schemaCustomer struct {
+	ID        int       `validate:"required,unique,min=1"`
+	Email     string    `validate:"required,email"`
+	CompanyID string    `name:"company" validate:"unique=Email"`
+	Score     string    `validate:"gt=0,lte=100"`
+	Plan      string    `validate:"oneof=free pro"`
+	Level     string    `validate:"numeric,oneof=1 2 3"`
+	Code      string    `validate:"len=4,startswith=C"`
+	Website   string    `validate:"omitempty,https_url"`
+	Host      string    `validate:"hostname"`
+	Active    bool      `validate:"boolean"`
+	JoinedAt  time.Time `validate:"required"`
+	Note      string
+}
+
+func decodeSchema(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v\n%s", err, data)
+	}
+	return doc
+}
+
+func TestExportTableSchema(t *testing.T) {
+	t.Parallel()
+
+	data, err := ExportTableSchema([]schemaCustomer{})
+	if err != nil {
+		t.Fatalf("ExportTableSchema() error = %v", err)
+	}
+
+	want := map[string]any{
+		"fields": []any{
+			map[string]any{"name": "id", "type": "integer", "constraints": map[string]any{"required": true, "unique": true, "minimum": 1.0}},
+			map[string]any{"name": "email", "type": "string", "format": "email", "constraints": map[string]any{"required": true}},
+			map[string]any{"name": "company", "type": "string"},
+			map[string]any{"name": "score", "type": "number", "constraints": map[string]any{"maximum": 100.0}},
+			map[string]any{"name": "plan", "type": "string", "constraints": map[string]any{"enum": []any{"free", "pro"}}},
+			map[string]any{"name": "level", "type": "integer", "constraints": map[string]any{"enum": []any{1.0, 2.0, 3.0}}},
+			map[string]any{"name": "code", "type": "string", "constraints": map[string]any{"minLength": 4.0, "maxLength": 4.0}},
+			map[string]any{"name": "website", "type": "string", "format": "uri"},
+			map[string]any{"name": "host", "type": "string"},
+			map[string]any{"name": "active", "type": "boolean"},
+			map[string]any{"name": "joined_at", "type": "datetime", "constraints": map[string]any{"required": true}},
+			map[string]any{"name": "note", "type": "string"},
+		},
+		"missingValues": []any{""},
+		"uniqueKeys":    []any{[]any{"company", "email"}},
+	}
+	if diff := cmp.Diff(want, decodeSchema(t, data)); diff != "" {
+		t.Errorf("ExportTableSchema() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExportJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	data, err := ExportJSONSchema(&[]schemaCustomer{})
+	if err != nil {
+		t.Fatalf("ExportJSONSchema() error = %v", err)
+	}
+
+	want := map[string]any{
+		"$schema": jsonSchemaDraft,
+		"title":   "schemaCustomer",
+		"type":    "object",
+		"properties": map[string]any{
+			"id":        map[string]any{"type": "integer", "minimum": 1.0},
+			"email":     map[string]any{"type": "string", "format": "email", "minLength": 1.0},
+			"company":   map[string]any{"type": "string"},
+			"score":     map[string]any{"type": "number", "exclusiveMinimum": 0.0, "maximum": 100.0},
+			"plan":      map[string]any{"type": "string", "enum": []any{"free", "pro"}},
+			"level":     map[string]any{"type": "integer", "enum": []any{1.0, 2.0, 3.0}},
+			"code":      map[string]any{"type": "string", "minLength": 4.0, "maxLength": 4.0},
+			"website":   map[string]any{"type": "string", "format": "uri"},
+			"host":      map[string]any{"type": "string", "format": "hostname"},
+			"active":    map[string]any{"type": "boolean"},
+			"joined_at": map[string]any{"type": "string", "format": "date-time", "minLength": 1.0},
+			"note":      map[string]any{"type": "string"},
+		},
+		"required": []any{"id", "email", "joined_at"},
+	}
+	if diff := cmp.Diff(want, decodeSchema(t, data)); diff != "" {
+		t.Errorf("ExportJSONSchema() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestExportTableSchema_Input(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		Name string `validate:"required"`
+	}
+
+	tests := []struct {
+		name    string
+		input   any
+		wantErr bool
+	}{
+		{name: "struct", input: row{}},
+		{name: "struct pointer", input: &row{}},
+		{name: "slice pointer", input: &[]row{}},
+		{name: "reflect type", input: reflect.TypeFor[row]()},
+		{name: "process result", input: &ProcessResult{Columns: []string{"name"}}},
+		{name: "nil", input: nil, wantErr: true},
+		{name: "not a struct", input: []string{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := ExportTableSchema(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrStructSlicePointer) {
+					t.Errorf("ExportTableSchema() error = %v, want ErrStructSlicePointer", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExportTableSchema() error = %v", err)
+			}
+			want := map[string]any{"name": "name", "type": "string", "constraints": map[string]any{"required": true}}
+			if _, ok := tt.input.(*ProcessResult); ok {
+				want = map[string]any{"name": "name", "type": "string"}
+			}
+			fields := decodeSchema(t, data)["fields"].([]any)
+			if diff := cmp.Diff([]any{want}, fields); diff != "" {
+				t.Errorf("fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -2,47 +2,128 @@ package fileprep
 
 import (
 	"bytes"
+	"encoding/csv"
 	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/nao1215/fileparser"
 )
 
 // Stream represents a preprocessed data stream with format information.
 // It implements io.Reader and provides metadata about the file format.
+//
+// Stream also implements io.WriterTo for efficient copies into files or
+// sockets, and io.ReaderAt so that consumers can issue concurrent range
+// reads without buffering the data again. ReadAt does not move the read
+// position used by Read and WriteTo.
+//
+// Stream is also an fs.File with a synthetic file name whose extension
+// matches Format(), for loaders such as filesql that derive the table name
+// and format from a file name. No file is created on disk.
 type Stream interface {
-	io.Reader
+	fs.File
+	io.WriterTo
+	io.ReaderAt
+	// Name returns the synthetic file name of the stream: TableName (or
+	// "data" when it is empty) plus an extension matching Format, e.g. "users.csv"
+	Name() string
+	// TableName returns a table name hint for downstream loaders, set with
+	// WithTableName or derived from the input file name. It is empty when
+	// neither is available.
+	TableName() string
+	// Len returns the number of bytes of the unread portion of the stream
+	Len() int
+	// Size returns the total number of bytes in the stream
+	Size() int64
 	// Format returns the actual output format of the stream data.
 	// For CSV/TSV/LTSV input, this matches the input format.
 	// For JSON/JSONL input, this returns JSONL since the output is JSONL-formatted.
 	// For XLSX/Parquet input, this returns CSV since the output is CSV-formatted.
+	// WithOutputFormat overrides it.
 	Format() fileparser.FileType
 	// OriginalFormat returns the original input file type including compression
 	OriginalFormat() fileparser.FileType
+	// RowOffsets returns the location of every data row in the stream, in
+	// stream order. Offsets are computed on the first call. XLSX streams
+	// have no row offsets.
+	RowOffsets() []RowOffset
+}
+
+// RowOffset locates one data row within a Stream.
+// A failed load can be resumed by passing the Row of the last committed row
+// to WithStartRow, or by seeking to the Offset of the next row.
+type RowOffset struct {
+	// Row is the 1-based data row number in the input (same as ValidationError.Row)
+	Row int
+	// Offset is the byte offset in the stream where the row's encoded line begins
+	Offset int64
 }
 
 // stream implements the Stream interface
 type stream struct {
 	reader         *bytes.Reader
+	data           []byte
 	format         fileparser.FileType
 	originalFormat fileparser.FileType
+
+	// firstRow is the input row number of the first data row in the stream.
+	// rowNums overrides it when the rows in the stream are not contiguous
+	// (e.g. WithValidRowsOnly dropped rows, or empty JSON values were skipped).
+	firstRow int
+	rowNums  []int
+
+	rowOffsetsOnce sync.Once
+	rowOffsets     []RowOffset
+
+	modTime   time.Time // creation time, reported by Stat
+	tableName string
 }
 
+// streamBaseName is the file name of a Stream without its extension when
+// there is no table name hint.
+const streamBaseName = "data"
+
 // newStream creates a new Stream from data and format information.
 // outputFormat is the actual format of the data in the stream.
 // originalFormat is the format of the input file.
 func newStream(data []byte, outputFormat fileparser.FileType, originalFormat fileparser.FileType) *stream {
 	return &stream{
 		reader:         bytes.NewReader(data),
+		data:           data,
 		format:         outputFormat,
 		originalFormat: originalFormat,
+		firstRow:       1,
+		modTime:        time.Now(),
 	}
 }
 
+// withRows records which input rows the stream contains.
+// firstRow is used when rowNums is nil.
+func (s *stream) withRows(firstRow int, rowNums []int) *stream {
+	s.firstRow = firstRow
+	s.rowNums = rowNums
+	return s
+}
+
 // Read implements io.Reader
 func (s *stream) Read(p []byte) (n int, err error) {
 	return s.reader.Read(p)
 }
 
+// WriteTo implements io.WriterTo
+func (s *stream) WriteTo(w io.Writer) (n int64, err error) {
+	return s.reader.WriteTo(w)
+}
+
+// ReadAt implements io.ReaderAt
+func (s *stream) ReadAt(p []byte, off int64) (n int, err error) {
+	return s.reader.ReadAt(p, off)
+}
+
 // Format returns the actual output format of the stream data.
 // For CSV/TSV/LTSV input, this matches the input format.
 // For JSON/JSONL input, this returns JSONL since the output is JSONL-formatted.
@@ -56,6 +137,38 @@ func (s *stream) OriginalFormat() fileparser.FileType {
 	return s.originalFormat
 }
 
+// withTableName sets the table name hint.
+func (s *stream) withTableName(name string) *stream {
+	s.tableName = name
+	return s
+}
+
+// TableName returns the table name hint for downstream loaders
+func (s *stream) TableName() string {
+	return s.tableName
+}
+
+// Name returns the synthetic file name of the stream
+func (s *stream) Name() string {
+	base := s.tableName
+	if base == "" {
+		base = streamBaseName
+	}
+	return base + formatExtension(s.format)
+}
+
+// Stat implements fs.File. The returned info describes a read-only regular
+// file named Name() holding the whole stream.
+func (s *stream) Stat() (fs.FileInfo, error) {
+	return &streamFileInfo{name: s.Name(), size: s.Size(), modTime: s.modTime}, nil
+}
+
+// Close implements fs.File. It is a no-op because the data is held in
+// memory; the stream can still be read after Close.
+func (s *stream) Close() error {
+	return nil
+}
+
 // Seek implements io.Seeker for rewinding the stream
 func (s *stream) Seek(offset int64, whence int) (int64, error) {
 	return s.reader.Seek(offset, whence)
@@ -65,3 +178,125 @@ func (s *stream) Seek(offset int64, whence int) (int64, error) {
 func (s *stream) Len() int {
 	return s.reader.Len()
 }
+
+// Size returns the total number of bytes in the stream
+func (s *stream) Size() int64 {
+	return s.reader.Size()
+}
+
+// RowOffsets returns the location of every data row in the stream
+func (s *stream) RowOffsets() []RowOffset {
+	s.rowOffsetsOnce.Do(func() {
+		offsets := scanRecordOffsets(s.data, s.format)
+		s.rowOffsets = make([]RowOffset, len(offsets))
+		for i, off := range offsets {
+			row := s.firstRow + i
+			if s.rowNums != nil && i < len(s.rowNums) {
+				row = s.rowNums[i]
+			}
+			s.rowOffsets[i] = RowOffset{Row: row, Offset: off}
+		}
+	})
+	return s.rowOffsets
+}
+
+// scanRecordOffsets returns the byte offset of each data record in data.
+// CSV and TSV are scanned with a csv.Reader so quoted newlines are honored,
+// and the header record is skipped. LTSV and JSONL have one record per
+// non-blank line and no header. XLSX is a zip archive without row offsets.
+func scanRecordOffsets(data []byte, format fileparser.FileType) []int64 {
+	switch format {
+	case fileparser.XLSX:
+		return nil
+	case fileparser.CSV, fileparser.TSV:
+		r := csv.NewReader(bytes.NewReader(data))
+		if format == fileparser.TSV {
+			r.Comma = '\t'
+		}
+		r.FieldsPerRecord = -1
+		r.ReuseRecord = true
+
+		var offsets []int64
+		header := true
+		for {
+			off := r.InputOffset()
+			if _, err := r.Read(); err != nil {
+				// io.EOF ends the scan; a malformed tail cannot be located either
+				break
+			}
+			if header {
+				header = false
+				continue
+			}
+			offsets = append(offsets, off)
+		}
+		return offsets
+	default:
+		var offsets []int64
+		for off := 0; off < len(data); {
+			end := bytes.IndexByte(data[off:], '\n')
+			if end < 0 {
+				end = len(data) - off
+			}
+			if len(bytes.TrimSpace(data[off:off+end])) > 0 {
+				offsets = append(offsets, int64(off))
+			}
+			off += end + 1
+		}
+		return offsets
+	}
+}
+
+// formatExtension returns the file extension for an output format.
+func formatExtension(format fileparser.FileType) string {
+	switch format {
+	case fileparser.TSV:
+		return ".tsv"
+	case fileparser.LTSV:
+		return ".ltsv"
+	case fileparser.JSONL:
+		return ".jsonl"
+	case fileparser.XLSX:
+		return ".xlsx"
+	default:
+		return ".csv"
+	}
+}
+
+// streamFileInfo implements fs.FileInfo for a Stream.
+type streamFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (fi *streamFileInfo) Name() string       { return fi.name }
+func (fi *streamFileInfo) Size() int64        { return fi.size }
+func (fi *streamFileInfo) Mode() fs.FileMode  { return 0o444 }
+func (fi *streamFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *streamFileInfo) IsDir() bool        { return false }
+func (fi *streamFileInfo) Sys() any           { return nil }
+
+// compressionExtensions are file extensions of the compression formats that
+// fileprep reads.
+//
+//nolint:gochecknoglobals // lookup table
+var compressionExtensions = map[string]bool{
+	".gz": true, ".bz2": true, ".xz": true, ".zst": true, ".z": true,
+	".snappy": true, ".s2": true, ".lz4": true,
+}
+
+// tableNameFromPath derives a table name from a file path by removing the
+// directory, a compression extension, and the format extension:
+// "/data/users.csv.gz" becomes "users".
+func tableNameFromPath(path string) string {
+	name := filepath.Base(path)
+	if ext := filepath.Ext(name); compressionExtensions[strings.ToLower(ext)] {
+		name = strings.TrimSuffix(name, ext)
+	}
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if name == "." || name == string(filepath.Separator) {
+		return ""
+	}
+	return name
+}
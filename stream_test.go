@@ -1,7 +1,11 @@
 package fileprep
 
 import (
+	"bytes"
+	"errors"
 	"io"
+	"io/fs"
+	"sync"
 	"testing"
 
 	"github.com/nao1215/fileparser"
@@ -110,3 +114,238 @@ func TestStream_Len(t *testing.T) {
 		t.Errorf("After read, Len() = %d, want %d", got, len(data)-2)
 	}
 }
+
+func TestStream_WriteTo(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("id,name\n1,john\n")
+	s := newStream(data, fileparser.CSV, fileparser.CSV)
+
+	// Consume a prefix first; WriteTo copies only the unread portion.
+	prefix := make([]byte, 3)
+	if _, err := s.Read(prefix); err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := s.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if n != int64(len(data)-3) {
+		t.Errorf("WriteTo() n = %d, want %d", n, len(data)-3)
+	}
+	if got := buf.String(); got != string(data[3:]) {
+		t.Errorf("WriteTo() wrote %q, want %q", got, data[3:])
+	}
+	if got := s.Len(); got != 0 {
+		t.Errorf("After WriteTo, Len() = %d, want 0", got)
+	}
+}
+
+func TestStream_ReadAt(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("0123456789")
+	s := newStream(data, fileparser.CSV, fileparser.CSV)
+
+	if got := s.Size(); got != int64(len(data)) {
+		t.Errorf("Size() = %d, want %d", got, len(data))
+	}
+
+	t.Run("range read does not move read position", func(t *testing.T) {
+		t.Parallel()
+		s := newStream(data, fileparser.CSV, fileparser.CSV)
+
+		buf := make([]byte, 4)
+		n, err := s.ReadAt(buf, 3)
+		if err != nil {
+			t.Fatalf("ReadAt() error = %v", err)
+		}
+		if got := string(buf[:n]); got != "3456" {
+			t.Errorf("ReadAt() = %q, want %q", got, "3456")
+		}
+		if got := s.Len(); got != len(data) {
+			t.Errorf("After ReadAt, Len() = %d, want %d", got, len(data))
+		}
+	})
+
+	t.Run("read past end returns EOF", func(t *testing.T) {
+		t.Parallel()
+		s := newStream(data, fileparser.CSV, fileparser.CSV)
+
+		buf := make([]byte, 4)
+		n, err := s.ReadAt(buf, 8)
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("ReadAt() error = %v, want io.EOF", err)
+		}
+		if got := string(buf[:n]); got != "89" {
+			t.Errorf("ReadAt() = %q, want %q", got, "89")
+		}
+	})
+
+	t.Run("concurrent section readers", func(t *testing.T) {
+		t.Parallel()
+		s := newStream(data, fileparser.CSV, fileparser.CSV)
+
+		var wg sync.WaitGroup
+		results := make([]string, 2)
+		for i := range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				got, err := io.ReadAll(io.NewSectionReader(s, int64(i*5), 5))
+				if err != nil {
+					t.Errorf("ReadAll() error = %v", err)
+					return
+				}
+				results[i] = string(got)
+			}()
+		}
+		wg.Wait()
+
+		if results[0] != "01234" || results[1] != "56789" {
+			t.Errorf("section reads = %q, want [\"01234\" \"56789\"]", results)
+		}
+	})
+}
+
+func TestStream_RowOffsets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     string
+		format   fileparser.FileType
+		firstRow int
+		rowNums  []int
+		want     []RowOffset
+	}{
+		{
+			name:     "CSV skips header",
+			data:     "a,b\n1,2\n3,4\n",
+			format:   fileparser.CSV,
+			firstRow: 1,
+			want:     []RowOffset{{Row: 1, Offset: 4}, {Row: 2, Offset: 8}},
+		},
+		{
+			name:     "CSV quoted newline stays in one row",
+			data:     "a\n\"x\ny\"\nz\n",
+			format:   fileparser.CSV,
+			firstRow: 1,
+			want:     []RowOffset{{Row: 1, Offset: 2}, {Row: 2, Offset: 8}},
+		},
+		{
+			name:     "TSV with start row",
+			data:     "a\tb\n1\t2\n",
+			format:   fileparser.TSV,
+			firstRow: 6,
+			want:     []RowOffset{{Row: 6, Offset: 4}},
+		},
+		{
+			name:     "LTSV has no header",
+			data:     "a:1\tb:2\na:3\tb:4\n",
+			format:   fileparser.LTSV,
+			firstRow: 1,
+			want:     []RowOffset{{Row: 1, Offset: 0}, {Row: 2, Offset: 8}},
+		},
+		{
+			name:     "JSONL with explicit row numbers",
+			data:     "{\"a\":1}\n{\"a\":3}",
+			format:   fileparser.JSONL,
+			firstRow: 1,
+			rowNums:  []int{1, 3},
+			want:     []RowOffset{{Row: 1, Offset: 0}, {Row: 3, Offset: 8}},
+		},
+		{
+			name:     "header only",
+			data:     "a,b\n",
+			format:   fileparser.CSV,
+			firstRow: 1,
+			want:     []RowOffset{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := newStream([]byte(tt.data), tt.format, tt.format).withRows(tt.firstRow, tt.rowNums)
+
+			got := s.RowOffsets()
+			if len(got) != len(tt.want) {
+				t.Fatalf("RowOffsets() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("RowOffsets()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStream_FSFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format   fileparser.FileType
+		wantName string
+	}{
+		{fileparser.CSV, "data.csv"},
+		{fileparser.TSV, "data.tsv"},
+		{fileparser.LTSV, "data.ltsv"},
+		{fileparser.JSONL, "data.jsonl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantName, func(t *testing.T) {
+			t.Parallel()
+
+			var f fs.File = newStream([]byte("a,b\n1,2\n"), tt.format, tt.format)
+			info, err := f.Stat()
+			if err != nil {
+				t.Fatalf("Stat() error = %v", err)
+			}
+			if info.Name() != tt.wantName || info.Size() != 8 || info.IsDir() || !info.Mode().IsRegular() {
+				t.Errorf("Stat() = {Name: %q, Size: %d, Mode: %v}, want regular file %q of 8 bytes",
+					info.Name(), info.Size(), info.Mode(), tt.wantName)
+			}
+			if got := f.(Stream).Name(); got != tt.wantName {
+				t.Errorf("Name() = %q, want %q", got, tt.wantName)
+			}
+
+			if err := f.Close(); err != nil {
+				t.Errorf("Close() error = %v", err)
+			}
+			data, err := io.ReadAll(f)
+			if err != nil || string(data) != "a,b\n1,2\n" {
+				t.Errorf("ReadAll() after Close = %q, %v", data, err)
+			}
+		})
+	}
+}
+
+func TestTableNameFromPath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"users.csv", "users"},
+		{"/data/exports/users.csv.gz", "users"},
+		{"sales.2024.tsv", "sales.2024"},
+		{"events.JSONL.ZST", "events"},
+		{"noext", "noext"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+			if got := tableNameFromPath(tt.path); got != tt.want {
+				t.Errorf("tableNameFromPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package fileprep
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FixSuggestion is a corrected value proposed for a value that failed
+// validation, so that a UI can offer a one-click correction. The suggested
+// value passes every rule of the field's validate tag.
+type FixSuggestion struct {
+	Row       int    // 1-based row number, as in ValidationError.Row
+	Column    string // Column name
+	Field     string // Struct field name
+	Value     string // The invalid value, as validated (after preprocessing)
+	Suggested string // The corrected value
+	Rule      string // The fix that produced Suggested, one of the Fix constants
+}
+
+// Fix rules of FixSuggestion.Rule.
+const (
+	// FixEmailTypo removes spaces from an email address and replaces commas
+	// and doubled dots in its domain with a single dot
+	FixEmailTypo = "email_typo"
+	// FixEmailMissingTLD adds the top-level domain of a well-known mail
+	// provider, as in "alice@gmail" to "alice@gmail.com"
+	FixEmailMissingTLD = "email_missing_tld"
+	// FixDateLayout rewrites a date that was written in another common
+	// layout, as in "31.01.2024" to "2024-01-31" for datetime=2006-01-02.
+	// Dates that read differently in several layouts, such as 01/02/2024,
+	// get no suggestion.
+	FixDateLayout = "date_layout"
+	// FixThousandsSeparator removes comma thousands separators, as in
+	// "1,234,567" to "1234567"
+	FixThousandsSeparator = "thousands_separator"
+	// FixDecimalComma replaces a decimal comma with a point and drops point
+	// thousands separators, as in "1.234,5" to "1234.5"
+	FixDecimalComma = "decimal_comma"
+	// FixClosestMatch replaces a value that fails oneof or oneof_file with
+	// the allowed value closest to it in edit distance, ignoring case, as in
+	// "actve" to "active"
+	FixClosestMatch = "closest_match"
+)
+
+// emailProviderDomains maps the name of well-known mail providers to their
+// domain, for FixEmailMissingTLD.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var emailProviderDomains = map[string]string{
+	"gmail":      "gmail.com",
+	"googlemail": "googlemail.com",
+	"yahoo":      "yahoo.com",
+	"hotmail":    "hotmail.com",
+	"outlook":    "outlook.com",
+	"live":       "live.com",
+	"msn":        "msn.com",
+	"icloud":     "icloud.com",
+	"me":         "me.com",
+	"aol":        "aol.com",
+	"protonmail": "protonmail.com",
+	"proton":     "proton.me",
+	"gmx":        "gmx.com",
+}
+
+// suggestionDateLayouts are the layouts FixDateLayout reads dates in.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var suggestionDateLayouts = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"2006.01.02",
+	"20060102",
+	"02.01.2006",
+	"02-01-2006",
+	"01/02/2006",
+	"02/01/2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+}
+
+var (
+	thousandsCommaRegex = regexp.MustCompile(`^[+-]?\d{1,3}(,\d{3})+(\.\d+)?$`)
+	decimalCommaRegex   = regexp.MustCompile(`^[+-]?(\d+|\d{1,3}(\.\d{3})+),\d+$`)
+)
+
+// suggestFix returns a corrected value for value, which failed the rule v of
+// vs, and the Fix rule that produced it. It reports false when no fix
+// applies or the corrected value still fails vs.
+func suggestFix(v Validator, value string, vs validators) (string, string, bool) {
+	if pv, ok := v.(*paramValidator); ok {
+		v = pv.Validator
+	}
+
+	var suggested, rule string
+	switch v := v.(type) {
+	case *emailValidator:
+		suggested, rule = suggestEmail(value)
+	case *datetimeValidator:
+		suggested, rule = suggestDate(value, v.layout)
+	case *numericValidator, *numberValidator, *decimalValidator:
+		suggested, rule = suggestNumber(value)
+	case *oneOfValidator:
+		if closest, ok := v.closest(value); ok {
+			suggested, rule = closest, FixClosestMatch
+		}
+	case *oneOfFileValidator:
+		if v.oneOf != nil {
+			if closest, ok := v.oneOf.closest(value); ok {
+				suggested, rule = closest, FixClosestMatch
+			}
+		}
+	}
+	if rule == "" || suggested == value {
+		return "", "", false
+	}
+	if _, msg := vs.Validate(suggested); msg != "" {
+		return "", "", false
+	}
+	return suggested, rule, true
+}
+
+// suggestEmail fixes typos in an email address and adds the top-level domain
+// of a well-known provider.
+func suggestEmail(value string) (string, string) {
+	local, domain, ok := strings.Cut(strings.Join(strings.Fields(value), ""), "@")
+	if !ok || local == "" || domain == "" {
+		return "", ""
+	}
+	domain = strings.ReplaceAll(domain, ",", ".")
+	for strings.Contains(domain, "..") {
+		domain = strings.ReplaceAll(domain, "..", ".")
+	}
+	domain = strings.Trim(domain, ".")
+
+	if full, known := emailProviderDomains[strings.ToLower(domain)]; known {
+		return local + "@" + full, FixEmailMissingTLD
+	}
+	return local + "@" + domain, FixEmailTypo
+}
+
+// suggestDate rewrites a date in another common layout to layout. Dates
+// without a time of day are not rewritten to a layout with one, since the
+// time would be made up.
+func suggestDate(value, layout string) (string, string) {
+	var found time.Time
+	var matched bool
+	for _, from := range suggestionDateLayouts {
+		if hasClock(from) != hasClock(layout) {
+			continue
+		}
+		t, err := time.Parse(from, value)
+		if err != nil {
+			continue
+		}
+		if matched && !t.Equal(found) {
+			return "", "" // ambiguous, such as 01/02/2006 and 02/01/2006
+		}
+		found, matched = t, true
+	}
+	if !matched {
+		return "", ""
+	}
+	return found.Format(layout), FixDateLayout
+}
+
+// hasClock reports whether a time layout has a time of day.
+func hasClock(layout string) bool {
+	return strings.Contains(layout, "15") || strings.Contains(layout, "03") || strings.Contains(layout, "04")
+}
+
+// suggestNumber removes thousands separators and replaces a decimal comma.
+func suggestNumber(value string) (string, string) {
+	switch {
+	case thousandsCommaRegex.MatchString(value):
+		return strings.ReplaceAll(value, ",", ""), FixThousandsSeparator
+	case decimalCommaRegex.MatchString(value):
+		return strings.Replace(strings.ReplaceAll(value, ".", ""), ",", ".", 1), FixDecimalComma
+	default:
+		return "", ""
+	}
+}
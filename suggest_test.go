@@ -0,0 +1,116 @@
+package fileprep
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileparser"
+)
+
+func TestProcessor_FixSuggestions(t *testing.T) {
+	t.Parallel()
+
+	type contact struct {
+		Email  string `validate:"email"`
+		Joined string `validate:"datetime=2006-01-02"`
+		Amount string `validate:"number"`
+		Count  string `validate:"numeric,max=5000"`
+	}
+
+	tests := []struct {
+		name string
+		row  string
+		want []FixSuggestion
+	}{
+		{
+			name: "email missing TLD",
+			row:  "alice@gmail,2024-01-31,1,1",
+			want: []FixSuggestion{{Row: 1, Column: "email", Field: "Email", Value: "alice@gmail", Suggested: "alice@gmail.com", Rule: FixEmailMissingTLD}},
+		},
+		{
+			name: "email typo",
+			row:  "\"bob @example,com\",2024-01-31,1,1",
+			want: []FixSuggestion{{Row: 1, Column: "email", Field: "Email", Value: "bob @example,com", Suggested: "bob@example.com", Rule: FixEmailTypo}},
+		},
+		{
+			name: "date in another layout",
+			row:  "a@example.com,31.01.2024,1,1",
+			want: []FixSuggestion{{Row: 1, Column: "joined", Field: "Joined", Value: "31.01.2024", Suggested: "2024-01-31", Rule: FixDateLayout}},
+		},
+		{
+			name: "US-only date",
+			row:  "a@example.com,01/31/2024,1,1",
+			want: []FixSuggestion{{Row: 1, Column: "joined", Field: "Joined", Value: "01/31/2024", Suggested: "2024-01-31", Rule: FixDateLayout}},
+		},
+		{
+			name: "ambiguous date",
+			row:  "a@example.com,01/02/2024,1,1",
+		},
+		{
+			name: "decimal comma",
+			row:  "a@example.com,2024-01-31,\"1.234,5\",1",
+			want: []FixSuggestion{{Row: 1, Column: "amount", Field: "Amount", Value: "1.234,5", Suggested: "1234.5", Rule: FixDecimalComma}},
+		},
+		{
+			name: "thousands separator",
+			row:  "a@example.com,2024-01-31,1,\"1,234\"",
+			want: []FixSuggestion{{Row: 1, Column: "count", Field: "Count", Value: "1,234", Suggested: "1234", Rule: FixThousandsSeparator}},
+		},
+		{
+			name: "fix failing a later rule",
+			row:  "a@example.com,2024-01-31,1,\"12,345\"",
+		},
+		{
+			name: "no fix applies",
+			row:  "not an email,2024-01-31,1,1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			input := "email,joined,amount,count\n" + tt.row + "\n"
+
+			var contacts []contact
+			_, result, err := NewProcessor(fileparser.CSV, WithFixSuggestions()).Process(strings.NewReader(input), &contacts)
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, result.Suggestions); diff != "" {
+				t.Errorf("Suggestions mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("closest oneof value", func(t *testing.T) {
+		t.Parallel()
+
+		type order struct {
+			Status string `validate:"oneof=active inactive"`
+		}
+		var orders []order
+		_, result, err := NewProcessor(fileparser.CSV, WithFixSuggestions()).Process(strings.NewReader("status\nActve\nclosed\n"), &orders)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		want := []FixSuggestion{{Row: 1, Column: "status", Field: "Status", Value: "Actve", Suggested: "active", Rule: FixClosestMatch}}
+		if diff := cmp.Diff(want, result.Suggestions); diff != "" {
+			t.Errorf("Suggestions mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("not set without the option", func(t *testing.T) {
+		t.Parallel()
+
+		var contacts []contact
+		_, result, err := NewProcessor(fileparser.CSV).Process(strings.NewReader("email,joined,amount,count\nalice@gmail,2024-01-31,1,1\n"), &contacts)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		if result.Suggestions != nil {
+			t.Errorf("Suggestions = %v, want nil", result.Suggestions)
+		}
+	})
+}
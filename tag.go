@@ -8,6 +8,8 @@ const (
 	prepTagName = "prep"
 	// nameTagName is the struct tag name for column name mapping
 	nameTagName = "name"
+	// warnTagName is the struct tag name for warning-level validation rules
+	warnTagName = "warn"
 )
 
 // Validation tag values
@@ -15,6 +17,14 @@ const (
 	// omitemptyTagValue is the tag value for skipping validation on empty values.
 	// When present, subsequent validators are skipped if the value is empty.
 	omitemptyTagValue = "omitempty"
+	// orclearTagValue is the tag value for clearing invalid values instead of reporting them.
+	// When present, a value failing the field's other validators is replaced with an empty string.
+	orclearTagValue = "orclear"
+	// orTagValue, andTagValue, and notTagValue group validators, e.g. or(email|e164).
+	// Members are separated by | and groups may be nested.
+	orTagValue  = "or"
+	andTagValue = "and"
+	notTagValue = "not"
 	// requiredTagValue is the tag value for required validation
 	requiredTagValue = "required"
 	// booleanTagValue is the tag value for boolean validation
@@ -53,6 +63,8 @@ const (
 	lengthTagValue = "len"
 	// oneOfTagValue is the tag value for one of validation
 	oneOfTagValue = "oneof"
+	// oneOfFileTagValue is the tag value for one of validation against a list loaded with WithValueList (oneof_file=prefectures.txt)
+	oneOfFileTagValue = "oneof_file"
 	// lowercaseValidatorTagValue is the tag value for lowercase validation
 	lowercaseValidatorTagValue = "lowercase"
 	// uppercaseValidatorTagValue is the tag value for uppercase validation
@@ -119,6 +131,18 @@ const (
 	excludesRuneTagValue = "excludesrune"
 	// multibyteTagValue is the tag value for multibyte validation
 	multibyteTagValue = "multibyte"
+	// noEmojiTagValue is the tag value for rejecting values that contain emoji
+	noEmojiTagValue = "no_emoji"
+	// charsetTagValue is the tag value for allowing only some Unicode scripts or categories
+	charsetTagValue = "charset"
+	// excludesCharsetTagValue is the tag value for rejecting some Unicode scripts or categories
+	excludesCharsetTagValue = "excludescharset"
+	// langTagValue is the tag value for free-text language validation (lang=ja or lang=en fr)
+	langTagValue = "lang"
+	// notInListTagValue is the tag value for rejecting words of a list loaded with WithValueList (not_in_list=badwords.txt)
+	notInListTagValue = "not_in_list"
+	// passwordTagValue is the tag value for password policy validation
+	passwordTagValue = "password"
 	// equalIgnoreCaseTagValue is the tag value for case-insensitive equal validation
 	equalIgnoreCaseTagValue = "eq_ignore_case"
 	// notEqualIgnoreCaseTagValue is the tag value for case-insensitive not equal validation
@@ -133,6 +157,18 @@ const (
 	requiredWithTagValue = "required_with"
 	// requiredWithoutTagValue is the tag value for required if another field is not present
 	requiredWithoutTagValue = "required_without"
+	// requiredWithAllTagValue is the tag value for required if all other fields are present
+	requiredWithAllTagValue = "required_with_all"
+	// requiredWithoutAllTagValue is the tag value for required if all other fields are not present
+	requiredWithoutAllTagValue = "required_without_all"
+	// excludedWithTagValue is the tag value for must be empty if any other field is present
+	excludedWithTagValue = "excluded_with"
+	// excludedWithoutTagValue is the tag value for must be empty if any other field is not present
+	excludedWithoutTagValue = "excluded_without"
+	// exprTagValue is the tag value for expression validation (expr=value > other('MinPrice') * 0.9)
+	exprTagValue = "expr"
+	// sumOfTagValue is the tag value for equals the sum of other fields validation (sumof=F1 F2 or sumof=F1 F2:0.01)
+	sumOfTagValue = "sumof"
 
 	// Date/time validator
 	// datetimeTagValue is the tag value for datetime format validation
@@ -158,6 +194,32 @@ const (
 	// ulidTagValue is the tag value for ULID validation
 	ulidTagValue = "ulid"
 
+	// Check digit validators
+	// luhnTagValue is the tag value for Luhn check digit validation (luhn or luhn=N for mod N)
+	luhnTagValue = "luhn"
+	// formattedNumberTagValue is the tag value for locale-formatted number validation (formatted_number or formatted_number=de)
+	formattedNumberTagValue = "formatted_number"
+	// decimalTagValue is the tag value for decimal precision and scale validation (decimal=10:2),
+	// and for decimal normalization in prep tags (decimal or decimal=2)
+	decimalTagValue = "decimal"
+	// unitTagValue is the tag value for allowed units of quantities (unit=kg g t),
+	// and for converting quantities to a unit in prep tags (unit=kg)
+	unitTagValue = "unit"
+
+	// National identifier validators
+	// usSSNTagValue is the tag value for US Social Security Number validation
+	usSSNTagValue = "us_ssn"
+	// jpMyNumberTagValue is the tag value for Japanese My Number validation, check digit included
+	jpMyNumberTagValue = "jp_my_number"
+	// ukNINOTagValue is the tag value for UK National Insurance number validation
+	ukNINOTagValue = "uk_nino"
+
+	// Tax identifier validators
+	// euVATTagValue is the tag value for EU VAT number validation, check digits included where defined
+	euVATTagValue = "eu_vat"
+	// jpCorporateNumberTagValue is the tag value for Japanese corporate number validation
+	jpCorporateNumberTagValue = "jp_corporate_number"
+
 	// Hexadecimal and color validators
 	// hexadecimalTagValue is the tag value for hexadecimal validation
 	hexadecimalTagValue = "hexadecimal"
@@ -176,6 +238,18 @@ const (
 	// macTagValue is the tag value for MAC address validation
 	macTagValue = "mac"
 
+	// Column statistics validators
+	// outlierTagValue is the tag value for standard-deviation outlier detection (outlier=3sigma)
+	outlierTagValue = "outlier"
+	// percentileTagValue is the tag value for percentile range validation (percentile=1:99)
+	percentileTagValue = "percentile"
+	// increasingTagValue is the tag value for strictly increasing column validation
+	increasingTagValue = "increasing"
+	// nondecreasingTagValue is the tag value for non-decreasing column validation
+	nondecreasingTagValue = "nondecreasing"
+	// uniqueTagValue is the tag value for file-wide uniqueness of one or more fields
+	uniqueTagValue = "unique"
+
 	// Cross-field validation tag values
 	// eqFieldTagValue is the tag value for equal to another field validation
 	eqFieldTagValue = "eqfield"
@@ -193,6 +267,10 @@ const (
 	fieldContainsTagValue = "fieldcontains"
 	// fieldExcludesTagValue is the tag value for field excludes another field's value validation
 	fieldExcludesTagValue = "fieldexcludes"
+	// checksumTagValue is the tag value for hash digest of another field validation (checksum=sha256:Field)
+	checksumTagValue = "checksum"
+	// withinTagValue is the tag value for within a tolerance of another field validation (within=Field:0.05 or within=Field:5%)
+	withinTagValue = "within"
 )
 
 // Preprocessing tag values
@@ -224,6 +302,12 @@ const (
 	stripHTMLTagValue = "strip_html"
 	// stripNewlineTagValue is the tag value for newline removal preprocessing
 	stripNewlineTagValue = "strip_newline"
+	// stripControlTagValue is the tag value for control character removal (strip_control[=keep_whitespace])
+	stripControlTagValue = "strip_control"
+	// stripEmojiTagValue is the tag value for emoji removal
+	stripEmojiTagValue = "strip_emoji"
+	// keepBMPTagValue is the tag value for removing characters outside the Basic Multilingual Plane
+	keepBMPTagValue = "keep_bmp"
 	// collapseSpaceTagValue is the tag value for collapsing multiple spaces into one
 	collapseSpaceTagValue = "collapse_space"
 
@@ -246,7 +330,7 @@ const (
 	padRightTagValue = "pad_right"
 
 	// Advanced preprocessors
-	// normalizeUnicodeTagValue is the tag value for Unicode normalization (NFC form)
+	// normalizeUnicodeTagValue is the tag value for Unicode normalization (NFC unless a form is given)
 	normalizeUnicodeTagValue = "normalize_unicode"
 	// nullifyTagValue is the tag value for treating specific string as empty (nullify=value)
 	nullifyTagValue = "nullify"
@@ -256,4 +340,29 @@ const (
 	fixSchemeTagValue = "fix_scheme"
 	// regexReplaceTagValue is the tag value for regex-based replacement (regex_replace=pattern:replacement)
 	regexReplaceTagValue = "regex_replace"
+	// mapTagValue is the tag value for recoding values with a lookup table (map=a:1|b:2|default:0)
+	mapTagValue = "map"
+	// percentToDecimalTagValue is the tag value for converting percentages to decimals (12.5% -> 0.125)
+	percentToDecimalTagValue = "percent_to_decimal"
+	// bpToDecimalTagValue is the tag value for converting basis points to decimals (125bp -> 0.0125)
+	bpToDecimalTagValue = "bp_to_decimal"
+	// roundTagValue is the tag value for rounding decimal numbers (round=2, round=2:half_even)
+	roundTagValue = "round"
+	// clampTagValue is the tag value for clipping numbers into a range (clamp=0:100, clamp=0:, clamp=:100)
+	clampTagValue = "clamp"
+	// winsorizeTagValue is the tag value for clipping numbers to percentiles of the column (winsorize=1:99)
+	winsorizeTagValue = "winsorize"
+	// templateTagValue is the tag value for rendering a template with the row's columns (template={{.year}}-{{.month}}-01)
+	templateTagValue = "template"
+	// sanitizeUTF8TagValue is the tag value for replacing or removing invalid UTF-8 (sanitize_utf8[=remove])
+	sanitizeUTF8TagValue = "sanitize_utf8"
+)
+
+// mapDefaultKey is the map tag key whose value replaces unmapped values
+const mapDefaultKey = "default"
+
+// Rounding modes of the round tag
+const (
+	roundHalfUp   = "half_up"
+	roundHalfEven = "half_even"
 )
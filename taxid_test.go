@@ -0,0 +1,111 @@
+package fileprep
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEUVATValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   string
+		wantMsg string
+	}{
+		{name: "AT", value: "ATU13585627"},
+		{name: "BE", value: "BE0776091951"},
+		{name: "DE", value: "DE136695976"},
+		{name: "DE with spaces", value: "DE 136 695 976"},
+		{name: "DK", value: "DK13585628"},
+		{name: "FI", value: "FI20774740"},
+		{name: "FR numeric key", value: "FR40303265045"},
+		{name: "FR letter key", value: "FRK7399859412"},
+		{name: "IT", value: "IT00743110157"},
+		{name: "LU", value: "LU15027442"},
+		{name: "NL company", value: "NL004495445B01"},
+		{name: "NL sole proprietor", value: "NL001000000B24"},
+		{name: "PL", value: "PL8567346215"},
+		{name: "PT", value: "PT501964843"},
+		{name: "SE", value: "SE556188840401"},
+		{name: "EL format only", value: "EL094259216"},
+		{name: "XI government department", value: "XIGD001"},
+
+		{name: "DE wrong check digit", value: "DE136695977", wantMsg: "value must have a valid DE VAT check digit"},
+		{name: "AT wrong check digit", value: "ATU13585626", wantMsg: "value must have a valid AT VAT check digit"},
+		{name: "FR wrong key", value: "FR41303265045", wantMsg: "value must have a valid FR VAT check digit"},
+		{name: "IT wrong check digit", value: "IT00743110158", wantMsg: "value must have a valid IT VAT check digit"},
+		{name: "NL wrong check digit", value: "NL004495446B01", wantMsg: "value must have a valid NL VAT check digit"},
+		{name: "SE without 01 suffix", value: "SE556188840402", wantMsg: "value must be a valid SE VAT number"},
+		{name: "DE too short", value: "DE13669597", wantMsg: "value must be a valid DE VAT number"},
+		{name: "lowercase prefix", value: "de136695976", wantMsg: "value must start with an EU VAT country prefix"},
+		{name: "GR is not the VAT prefix of Greece", value: "GR094259216", wantMsg: "value must start with an EU VAT country prefix"},
+		{name: "GB left the EU", value: "GB123456789", wantMsg: "value must start with an EU VAT country prefix"},
+		{name: "empty", value: "", wantMsg: "value must be a valid EU VAT number"},
+	}
+
+	v := newEUVATValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := v.Validate(tt.value); got != tt.wantMsg {
+				t.Errorf("euVATValidator.Validate(%q) = %q, want %q", tt.value, got, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestJPCorporateNumberValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "valid", value: "7000012050002"},
+		{name: "wrong check digit", value: "8000012050002", wantErr: true},
+		{name: "12 digits", value: "000012050002", wantErr: true},
+		{name: "with hyphens", value: "7-0000-1205-0002", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+
+	v := newJPCorporateNumberValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := v.Validate(tt.value)
+			if (got != "") != tt.wantErr {
+				t.Errorf("jpCorporateNumberValidator.Validate(%q) = %q, wantErr %v", tt.value, got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTaxIDValidation_Processor(t *testing.T) {
+	t.Parallel()
+
+	type Vendor struct {
+		VAT             string `prep:"uppercase" validate:"omitempty,eu_vat"`
+		CorporateNumber string `validate:"omitempty,jp_corporate_number"`
+	}
+
+	input := "vat,corporate_number\nde136695976,\n,7000012050002\nDE136695977,7000012050003\n"
+	var vendors []Vendor
+	_, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader(input), &vendors)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	var got []string
+	for _, e := range result.ValidationErrors() {
+		got = append(got, e.Tag)
+		if e.Row != 3 {
+			t.Errorf("%s error on row %d, want row 3", e.Tag, e.Row)
+		}
+	}
+	want := []string{euVATTagValue, jpCorporateNumberTagValue}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("error tags = %v, want %v", got, want)
+	}
+}
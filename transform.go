@@ -0,0 +1,373 @@
+package fileprep
+
+import (
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/nao1215/fileparser"
+)
+
+// columnTransform rewrites the header and the data rows before struct
+// fields are bound, so fields can bind to the columns it produces.
+// Transforms run in the order their options were given.
+type columnTransform interface {
+	// apply returns the new header and rows. Rows may be shorter than the
+	// header; missing cells are empty.
+	apply(headers []string, records [][]string) ([]string, [][]string, error)
+}
+
+// runTransforms returns the column transforms of one run: LTSV expansion,
+// the configured transforms, and the row number and source columns, which
+// depend on the run's first row and input.
+func (p *Processor) runTransforms(firstRow int, input io.Reader) []columnTransform {
+	transforms := p.transforms
+	if p.ltsvExpandDots && fileparser.BaseFileType(p.fileType) == fileparser.LTSV {
+		transforms = append([]columnTransform{ltsvExpandDots{}}, transforms...)
+	}
+	if p.rowNumberColumn != "" {
+		transforms = append(slices.Clip(transforms), &rowNumberColumn{name: p.rowNumberColumn, firstRow: firstRow})
+	}
+	if p.sourceColumn != "" {
+		transforms = append(slices.Clip(transforms), &constantColumn{name: p.sourceColumn, value: sourceNameFor(input)})
+	}
+	return transforms
+}
+
+// applyTransforms runs column transforms in order.
+func applyTransforms(transforms []columnTransform, headers []string, records [][]string) ([]string, [][]string, error) {
+	for _, t := range transforms {
+		var err error
+		headers, records, err = t.apply(headers, records)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return headers, records, nil
+}
+
+// addColumns returns headers and records with the named columns appended.
+// value returns the new cells of one row. It fails if a new column already
+// exists.
+func addColumns(
+	headers []string,
+	records [][]string,
+	names []string,
+	value func(rowIdx int, record []string) []string,
+) ([]string, [][]string, error) {
+	for _, name := range names {
+		if slices.Contains(headers, name) {
+			return nil, nil, fmt.Errorf("%w: %q already exists in header", ErrDuplicateColumn, name)
+		}
+	}
+
+	newHeaders := append(slices.Clip(headers), names...)
+	for i, record := range records {
+		row := make([]string, len(newHeaders))
+		copy(row, record)
+		copy(row[len(headers):], value(i, record))
+		records[i] = row
+	}
+	return newHeaders, records, nil
+}
+
+// cell returns the value at colIdx, or "" when the row is too short.
+func cell(record []string, colIdx int) string {
+	if colIdx < len(record) {
+		return record[colIdx]
+	}
+	return ""
+}
+
+// splitColumn splits one column into several new columns.
+type splitColumn struct {
+	source  string
+	sep     string
+	targets []string
+}
+
+// apply appends the target columns. A value is split at most
+// len(targets)-1 times, so the last target keeps the remainder.
+func (s *splitColumn) apply(headers []string, records [][]string) ([]string, [][]string, error) {
+	if s.sep == "" {
+		return nil, nil, fmt.Errorf("%w: split column separator must not be empty", ErrInvalidOption)
+	}
+	if len(s.targets) == 0 {
+		return nil, nil, fmt.Errorf("%w: split column %q has no target columns", ErrInvalidOption, s.source)
+	}
+	srcIdx := slices.Index(headers, s.source)
+	if srcIdx < 0 {
+		return nil, nil, fmt.Errorf("split column %q: %w", s.source, ErrColumnNotFound)
+	}
+	return addColumns(headers, records, s.targets, func(_ int, record []string) []string {
+		value := cell(record, srcIdx)
+		if value == "" {
+			return nil
+		}
+		return strings.SplitN(value, s.sep, len(s.targets))
+	})
+}
+
+// mergeColumns joins several columns into a new column.
+type mergeColumns struct {
+	target  string
+	sep     string
+	sources []string
+}
+
+// apply appends the target column. Empty source values are skipped, so no
+// separator is doubled.
+func (m *mergeColumns) apply(headers []string, records [][]string) ([]string, [][]string, error) {
+	if len(m.sources) == 0 {
+		return nil, nil, fmt.Errorf("%w: merge column %q has no source columns", ErrInvalidOption, m.target)
+	}
+	srcIdxs := make([]int, len(m.sources))
+	for i, source := range m.sources {
+		srcIdxs[i] = slices.Index(headers, source)
+		if srcIdxs[i] < 0 {
+			return nil, nil, fmt.Errorf("merge source column %q: %w", source, ErrColumnNotFound)
+		}
+	}
+	parts := make([]string, 0, len(srcIdxs))
+	return addColumns(headers, records, []string{m.target}, func(_ int, record []string) []string {
+		parts = parts[:0]
+		for _, srcIdx := range srcIdxs {
+			if value := cell(record, srcIdx); value != "" {
+				parts = append(parts, value)
+			}
+		}
+		return []string{strings.Join(parts, m.sep)}
+	})
+}
+
+// dropColumns removes columns from the header and rows.
+type dropColumns struct {
+	columns []string
+}
+
+// apply removes the columns; it fails if one is not in the header.
+func (d *dropColumns) apply(headers []string, records [][]string) ([]string, [][]string, error) {
+	drop := make([]bool, len(headers))
+	for _, column := range d.columns {
+		colIdx := slices.Index(headers, column)
+		if colIdx < 0 {
+			return nil, nil, fmt.Errorf("drop column %q: %w", column, ErrColumnNotFound)
+		}
+		drop[colIdx] = true
+	}
+
+	keep := func(row []string) []string {
+		kept := make([]string, 0, len(headers))
+		for i := range headers {
+			if !drop[i] {
+				kept = append(kept, cell(row, i))
+			}
+		}
+		return kept
+	}
+	for i, record := range records {
+		records[i] = keep(record)
+	}
+	return keep(headers), records, nil
+}
+
+// valueMap recodes the values of one column.
+type valueMap struct {
+	column  string
+	mapping map[string]string
+}
+
+// apply replaces mapped values in place; other values are left unchanged.
+func (v *valueMap) apply(headers []string, records [][]string) ([]string, [][]string, error) {
+	colIdx := slices.Index(headers, v.column)
+	if colIdx < 0 {
+		return nil, nil, fmt.Errorf("value map column %q: %w", v.column, ErrColumnNotFound)
+	}
+	for _, record := range records {
+		if colIdx >= len(record) {
+			continue
+		}
+		if mapped, ok := v.mapping[record[colIdx]]; ok {
+			record[colIdx] = mapped
+		}
+	}
+	return headers, records, nil
+}
+
+// rowNumberColumn appends the input row number of each row.
+type rowNumberColumn struct {
+	name     string
+	firstRow int // row number of records[0]
+}
+
+// apply appends the row numbers, 1-based and excluding the header like
+// ValidationError.Row.
+func (r *rowNumberColumn) apply(headers []string, records [][]string) ([]string, [][]string, error) {
+	return addColumns(headers, records, []string{r.name}, func(rowIdx int, _ []string) []string {
+		return []string{strconv.Itoa(r.firstRow + rowIdx)}
+	})
+}
+
+// constantColumn appends a column with the same value in every row.
+type constantColumn struct {
+	name  string
+	value string
+}
+
+// apply appends the column.
+func (c *constantColumn) apply(headers []string, records [][]string) ([]string, [][]string, error) {
+	cells := []string{c.value}
+	return addColumns(headers, records, []string{c.name}, func(_ int, _ []string) []string {
+		return cells
+	})
+}
+
+// sourceNameFor returns the source name of input for WithSourceColumn: the
+// URL given to ProcessURL without credentials and query, or the name of an
+// input with a Name method such as *os.File.
+func sourceNameFor(input io.Reader) string {
+	if r, ok := input.(*namedReader); ok && r.source != "" {
+		return r.source
+	}
+	if named, ok := input.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return ""
+}
+
+// ltsvExpandDots expands LTSV columns whose values are lists of key:value
+// items, such as request:method:GET,path:/, into one column per key named
+// label.key (request.method, request.path).
+type ltsvExpandDots struct{}
+
+// apply replaces every expandable column with its dotted columns, in the
+// order the keys are first seen. A column is expandable when all of its
+// non-empty values are key:value lists. Rows without a key get an empty
+// cell.
+func (ltsvExpandDots) apply(headers []string, records [][]string) ([]string, [][]string, error) {
+	var newHeaders []string
+	type source struct {
+		colIdx int
+		keys   map[string]int // key -> index into newHeaders
+	}
+	sources := make([]source, 0, len(headers))
+	taken := make(map[string]bool, len(headers))
+	for _, h := range headers {
+		taken[h] = true
+	}
+
+	for colIdx, h := range headers {
+		keys, ok := nestedKeys(records, colIdx)
+		if !ok {
+			sources = append(sources, source{colIdx: colIdx})
+			newHeaders = append(newHeaders, h)
+			continue
+		}
+		src := source{colIdx: colIdx, keys: make(map[string]int, len(keys))}
+		for _, key := range keys {
+			name := h + "." + key
+			if taken[name] {
+				return nil, nil, fmt.Errorf("%w: expanded LTSV column %q already exists in header", ErrDuplicateColumn, name)
+			}
+			taken[name] = true
+			src.keys[key] = len(newHeaders)
+			newHeaders = append(newHeaders, name)
+		}
+		sources = append(sources, src)
+	}
+	if slices.Equal(newHeaders, headers) {
+		return headers, records, nil
+	}
+
+	for i, record := range records {
+		row := make([]string, len(newHeaders))
+		pos := 0
+		for _, src := range sources {
+			value := cell(record, src.colIdx)
+			if src.keys == nil {
+				row[pos] = value
+				pos++
+				continue
+			}
+			for key, val := range nestedItems(value) {
+				row[src.keys[key]] = val
+			}
+			pos += len(src.keys)
+		}
+		records[i] = row
+	}
+	return newHeaders, records, nil
+}
+
+// nestedKeys returns the keys of column colIdx in first-seen order, and
+// whether every non-empty value of the column is a key:value list.
+func nestedKeys(records [][]string, colIdx int) ([]string, bool) {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, record := range records {
+		value := cell(record, colIdx)
+		if value == "" {
+			continue
+		}
+		if !isNestedValue(value) {
+			return nil, false
+		}
+		for key := range nestedItems(value) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, len(keys) > 0
+}
+
+// isNestedValue reports whether value is a comma-separated list of
+// key:value items. Keys start with a letter or underscore and contain
+// letters, digits, underscores, hyphens, and dots. Items whose value starts
+// with // are rejected so URLs are not mistaken for lists.
+func isNestedValue(value string) bool {
+	for _, item := range strings.Split(value, ",") {
+		key, val, found := strings.Cut(item, ":")
+		if !found || !isNestedKey(key) || strings.HasPrefix(val, "//") {
+			return false
+		}
+	}
+	return true
+}
+
+// isNestedKey reports whether key is a valid key in a nested LTSV value.
+func isNestedKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// nestedItems iterates over the key:value items of a nested value. When a
+// key repeats, the last value wins.
+func nestedItems(value string) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		if value == "" {
+			return
+		}
+		for _, item := range strings.Split(value, ",") {
+			key, val, _ := strings.Cut(item, ":")
+			if !yield(key, val) {
+				return
+			}
+		}
+	}
+}
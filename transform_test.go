@@ -0,0 +1,193 @@
+package fileprep
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitColumn(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		split       *splitColumn
+		records     [][]string
+		wantHeaders []string
+		wantRecords [][]string
+		wantErr     bool
+	}{
+		{
+			name:        "last target keeps the remainder",
+			split:       &splitColumn{source: "full_name", sep: " ", targets: []string{"first", "last"}},
+			records:     [][]string{{"1", "John Ronald Tolkien"}, {"2", "Plato"}, {"3", ""}, {"4"}},
+			wantHeaders: []string{"id", "full_name", "first", "last"},
+			wantRecords: [][]string{
+				{"1", "John Ronald Tolkien", "John", "Ronald Tolkien"},
+				{"2", "Plato", "Plato", ""},
+				{"3", "", "", ""},
+				{"4", "", "", ""},
+			},
+		},
+		{
+			name:    "missing source column",
+			split:   &splitColumn{source: "name", sep: " ", targets: []string{"first"}},
+			wantErr: true,
+		},
+		{
+			name:    "existing target column",
+			split:   &splitColumn{source: "full_name", sep: " ", targets: []string{"id"}},
+			wantErr: true,
+		},
+		{
+			name:    "empty separator",
+			split:   &splitColumn{source: "full_name", targets: []string{"first"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			headers, records, err := tt.split.apply([]string{"id", "full_name"}, tt.records)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.wantHeaders, headers); diff != "" {
+				t.Errorf("headers mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantRecords, records); diff != "" {
+				t.Errorf("records mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMergeColumns(t *testing.T) {
+	t.Parallel()
+
+	headers, records, err := (&mergeColumns{target: "address", sep: ", ", sources: []string{"street", "city", "zip"}}).apply(
+		[]string{"street", "city", "zip"},
+		[][]string{{"1 Main St", "Springfield", "12345"}, {"", "Shelbyville", ""}, {"2 Elm St"}},
+	)
+	if err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"street", "city", "zip", "address"}, headers); diff != "" {
+		t.Errorf("headers mismatch (-want +got):\n%s", diff)
+	}
+	want := [][]string{
+		{"1 Main St", "Springfield", "12345", "1 Main St, Springfield, 12345"},
+		{"", "Shelbyville", "", "Shelbyville"},
+		{"2 Elm St", "", "", "2 Elm St"},
+	}
+	if diff := cmp.Diff(want, records); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, _, err := (&mergeColumns{target: "address", sep: " ", sources: []string{"country"}}).apply([]string{"street"}, nil); err == nil {
+		t.Error("apply() with a missing source column: error = nil")
+	}
+}
+
+func TestDropColumns(t *testing.T) {
+	t.Parallel()
+
+	headers, records, err := (&dropColumns{columns: []string{"b", "d"}}).apply(
+		[]string{"a", "b", "c", "d"},
+		[][]string{{"1", "2", "3", "4"}, {"5", "6"}},
+	)
+	if err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"a", "c"}, headers); diff != "" {
+		t.Errorf("headers mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([][]string{{"1", "3"}, {"5", ""}}, records); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, _, err := (&dropColumns{columns: []string{"x"}}).apply([]string{"a"}, nil); err == nil {
+		t.Error("apply() with a missing column: error = nil")
+	}
+}
+
+func TestValueMap(t *testing.T) {
+	t.Parallel()
+
+	vm := &valueMap{column: "status", mapping: map[string]string{"active": "1", "inactive": "0"}}
+	headers, records, err := vm.apply([]string{"id", "status"}, [][]string{{"1", "active"}, {"2", "pending"}, {"3"}})
+	if err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"id", "status"}, headers); diff != "" {
+		t.Errorf("headers mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([][]string{{"1", "1"}, {"2", "pending"}, {"3"}}, records); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, _, err := vm.apply([]string{"id"}, nil); err == nil {
+		t.Error("apply() with a missing column: error = nil")
+	}
+}
+
+func TestLTSVExpandDots(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		headers     []string
+		records     [][]string
+		wantHeaders []string
+		wantRecords [][]string
+		wantErr     bool
+	}{
+		{
+			name:        "nested values become dotted columns",
+			headers:     []string{"host", "request", "status"},
+			records:     [][]string{{"a", "method:GET,path:/", "200"}, {"b", "method:POST,size:10", "201"}, {"c", "", "500"}},
+			wantHeaders: []string{"host", "request.method", "request.path", "request.size", "status"},
+			wantRecords: [][]string{
+				{"a", "GET", "/", "", "200"},
+				{"b", "POST", "", "10", "201"},
+				{"c", "", "", "", "500"},
+			},
+		},
+		{
+			name:        "URLs and plain values are kept",
+			headers:     []string{"referer", "time"},
+			records:     [][]string{{"http://example.com", "12:30"}, {"-", "13:00"}},
+			wantHeaders: []string{"referer", "time"},
+			wantRecords: [][]string{{"http://example.com", "12:30"}, {"-", "13:00"}},
+		},
+		{
+			name:    "expanded name already exists",
+			headers: []string{"req", "req.method"},
+			records: [][]string{{"method:GET", "GET"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			headers, records, err := ltsvExpandDots{}.apply(tt.headers, tt.records)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if diff := cmp.Diff(tt.wantHeaders, headers); diff != "" {
+				t.Errorf("headers mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantRecords, records); diff != "" {
+				t.Errorf("records mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
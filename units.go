@@ -0,0 +1,254 @@
+package fileprep
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// unitDef is a unit of measurement. A quantity in the unit is converted to
+// the base unit of its dimension as quantity*factor + offset.
+type unitDef struct {
+	dimension string
+	factor    float64
+	offset    float64 // non-zero only for temperatures
+}
+
+// units are the units the unit tags know, by symbol. Each dimension has one
+// unit with factor 1 and no offset, its base unit.
+//
+//nolint:gochecknoglobals // read-only lookup table
+var units = map[string]unitDef{
+	// mass, in kilograms
+	"mg": {dimension: "mass", factor: 1e-6},
+	"g":  {dimension: "mass", factor: 1e-3},
+	"kg": {dimension: "mass", factor: 1},
+	"t":  {dimension: "mass", factor: 1e3},
+	"oz": {dimension: "mass", factor: 0.028349523125},
+	"lb": {dimension: "mass", factor: 0.45359237},
+	// length, in meters
+	"mm": {dimension: "length", factor: 1e-3},
+	"cm": {dimension: "length", factor: 1e-2},
+	"m":  {dimension: "length", factor: 1},
+	"km": {dimension: "length", factor: 1e3},
+	"in": {dimension: "length", factor: 0.0254},
+	"ft": {dimension: "length", factor: 0.3048},
+	"yd": {dimension: "length", factor: 0.9144},
+	"mi": {dimension: "length", factor: 1609.344},
+	// volume, in liters
+	"ml": {dimension: "volume", factor: 1e-3},
+	"cl": {dimension: "volume", factor: 1e-2},
+	"l":  {dimension: "volume", factor: 1},
+	"m3": {dimension: "volume", factor: 1e3},
+	"m³": {dimension: "volume", factor: 1e3},
+	// time, in seconds
+	"ms":  {dimension: "time", factor: 1e-3},
+	"s":   {dimension: "time", factor: 1},
+	"min": {dimension: "time", factor: 60},
+	"h":   {dimension: "time", factor: 3600},
+	// temperature, in kelvins
+	"K":  {dimension: "temperature", factor: 1},
+	"°C": {dimension: "temperature", factor: 1, offset: 273.15},
+	"°F": {dimension: "temperature", factor: 5.0 / 9, offset: 459.67 * 5 / 9},
+	// power, in watts
+	"W":  {dimension: "power", factor: 1},
+	"kW": {dimension: "power", factor: 1e3},
+	"MW": {dimension: "power", factor: 1e6},
+	// energy, in watt-hours
+	"Wh":  {dimension: "energy", factor: 1},
+	"kWh": {dimension: "energy", factor: 1e3},
+	"MWh": {dimension: "energy", factor: 1e6},
+}
+
+// lookupUnit returns the unit with the symbol. Symbols are matched exactly
+// first, then ignoring case if that matches only one unit, so "KG" is kg.
+func lookupUnit(symbol string) (unitDef, bool) {
+	if u, ok := units[symbol]; ok {
+		return u, true
+	}
+	var found []unitDef
+	for s, u := range units {
+		if strings.EqualFold(s, symbol) {
+			found = append(found, u)
+		}
+	}
+	if len(found) != 1 {
+		return unitDef{}, false
+	}
+	return found[0], true
+}
+
+// splitQuantity splits a quantity such as "1,2 t" into its number and its
+// unit symbol. The unit is empty for a bare number.
+func splitQuantity(value string) (number, symbol string) {
+	value = strings.TrimSpace(value)
+	i := len(value)
+	for i > 0 {
+		r, size := utf8.DecodeLastRuneInString(value[:i])
+		if !unicode.IsLetter(r) && r != '°' && r != '²' && r != '³' && r != '3' {
+			break
+		}
+		i -= size
+	}
+	// "3" belongs to the unit only in m3
+	for i < len(value) && value[i] == '3' {
+		i++
+	}
+	return strings.TrimSpace(value[:i]), value[i:]
+}
+
+// parseQuantityNumber parses the number of a quantity. With a number
+// format, the number is read with its thousands separators and decimal
+// mark; without one, "." or a single "," is the decimal mark.
+func parseQuantityNumber(number string, format *numberFormat) (float64, bool) {
+	if format != nil {
+		plain, ok := format.parse(number)
+		if !ok {
+			return 0, false
+		}
+		number = plain
+	} else if !strings.Contains(number, ".") && strings.Count(number, ",") == 1 {
+		number = strings.Replace(number, ",", ".", 1)
+	}
+	if _, _, _, ok := parseDecimal(number); !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(number, 64)
+	return f, err == nil
+}
+
+// formatQuantity formats a converted quantity, rounded to 12 significant
+// digits of magnitude, the largest term of the conversion in the target
+// unit, so that conversions such as 1200 g to 1.2 kg or 32 °F to 0 °C do
+// not show float error.
+func formatQuantity(f, magnitude float64) string {
+	places := 12
+	if magnitude > 0 {
+		places = max(12-int(math.Ceil(math.Log10(magnitude))), 0)
+	}
+	formatted, _ := normalizeDecimal(strconv.FormatFloat(f, 'f', places, 64), -1)
+	return formatted
+}
+
+// unitPreprocessor converts quantities such as "1,2 t" or "1200g" to a
+// number in a target unit, such as "1200" or "1.2" for kg. Bare numbers are
+// taken to be in the target unit and are left as they are, as are values
+// in units of another dimension or that are not quantities.
+type unitPreprocessor struct {
+	symbol string
+	target unitDef
+	format *numberFormat // nil to accept "." or "," as the decimal mark
+}
+
+// newUnitPreprocessor creates a new unit preprocessor converting to the
+// unit with the symbol, and false if the unit is unknown
+func newUnitPreprocessor(symbol string) (*unitPreprocessor, bool) {
+	target, ok := units[symbol]
+	if !ok {
+		return nil, false
+	}
+	return &unitPreprocessor{symbol: symbol, target: target}, true
+}
+
+// withFormat returns a copy of the preprocessor that reads numbers in the
+// format, for WithLocale
+func (p *unitPreprocessor) withFormat(format numberFormat) *unitPreprocessor {
+	return &unitPreprocessor{symbol: p.symbol, target: p.target, format: &format}
+}
+
+// Process converts a quantity to the target unit
+func (p *unitPreprocessor) Process(value string) string {
+	number, symbol := splitQuantity(value)
+	if symbol == "" {
+		return value
+	}
+	from, ok := lookupUnit(symbol)
+	if !ok || from.dimension != p.target.dimension {
+		return value
+	}
+	f, ok := parseQuantityNumber(number, p.format)
+	if !ok {
+		return value
+	}
+	base := f*from.factor + from.offset
+	magnitude := max(math.Abs(f*from.factor), from.offset, p.target.offset) / p.target.factor
+	return formatQuantity((base-p.target.offset)/p.target.factor, magnitude)
+}
+
+// Name returns the preprocessor name
+func (p *unitPreprocessor) Name() string {
+	return unitTagValue
+}
+
+// unitValidator validates that values are quantities in one of a set of
+// units, such as "1.2 kg" or "1200g". Bare numbers are accepted, so the
+// validator can follow the unit prep tag.
+type unitValidator struct {
+	symbols []string
+	format  *numberFormat
+	errMsg  string
+}
+
+// newUnitValidator creates a new unit validator for the units with the
+// symbols
+func newUnitValidator(symbols []string) *unitValidator {
+	return &unitValidator{
+		symbols: symbols,
+		errMsg:  "value must be a quantity in " + strings.Join(symbols, ", "),
+	}
+}
+
+// withFormat returns a copy of the validator that reads numbers in the
+// format, for WithLocale
+func (v *unitValidator) withFormat(format numberFormat) *unitValidator {
+	return &unitValidator{symbols: v.symbols, format: &format, errMsg: v.errMsg}
+}
+
+// Validate checks that the value is a number with one of the units, or
+// without a unit
+func (v *unitValidator) Validate(value string) string {
+	number, symbol := splitQuantity(value)
+	if _, ok := parseQuantityNumber(number, v.format); !ok {
+		return v.errMsg
+	}
+	if symbol == "" {
+		return ""
+	}
+	for _, s := range v.symbols {
+		if symbol == s {
+			return ""
+		}
+		if u, ok := lookupUnit(symbol); ok && u == units[s] {
+			return ""
+		}
+	}
+	return v.errMsg
+}
+
+// Name returns the validator name
+func (v *unitValidator) Name() string {
+	return unitTagValue
+}
+
+// buildUnitValidator builds a unit validator from a space-separated list of
+// unit symbols, such as "kg g t".
+func buildUnitValidator(value string, strict bool) (Validator, error) {
+	symbols := strings.Fields(value)
+	valid := len(symbols) > 0
+	for _, s := range symbols {
+		if _, ok := units[s]; !ok {
+			valid = false
+		}
+	}
+	if !valid {
+		if strict {
+			return nil, fmt.Errorf("%w: unit requires a space-separated list of known units such as \"kg g\", got %q", ErrInvalidTagFormat, value)
+		}
+		return nil, nil //nolint:nilnil // non-strict mode silently ignores invalid args
+	}
+	return newUnitValidator(slices.Clip(symbols)), nil
+}
@@ -0,0 +1,126 @@
+package fileprep
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestUnitPreprocessor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		target string
+		locale string
+		input  string
+		want   string
+	}{
+		{name: "tonnes with decimal comma", target: "kg", input: "1,2 t", want: "1200"},
+		{name: "grams without space", target: "kg", input: "1200g", want: "1.2"},
+		{name: "same unit", target: "kg", input: " 2.50 kg ", want: "2.5"},
+		{name: "case-insensitive unit", target: "kg", input: "3 KG", want: "3"},
+		{name: "pounds", target: "kg", input: "1 lb", want: "0.45359237"},
+		{name: "negative", target: "m", input: "-15 cm", want: "-0.15"},
+		{name: "cubic meters", target: "l", input: "1.5 m3", want: "1500"},
+		{name: "cubic meters superscript", target: "l", input: "2m³", want: "2000"},
+		{name: "celsius to fahrenheit", target: "°F", input: "100 °C", want: "212"},
+		{name: "fahrenheit to celsius", target: "°C", input: "32°F", want: "0"},
+		{name: "minutes", target: "s", input: "1.5 min", want: "90"},
+		{name: "energy", target: "kWh", input: "1500 Wh", want: "1.5"},
+		{name: "bare number", target: "kg", input: "12", want: "12"},
+		{name: "other dimension", target: "kg", input: "5 m", want: "5 m"},
+		{name: "unknown unit", target: "kg", input: "5 stone", want: "5 stone"},
+		{name: "not a number", target: "kg", input: "heavy kg", want: "heavy kg"},
+		{name: "empty", target: "kg", input: "", want: ""},
+		{name: "English locale groups", target: "kg", locale: "en", input: "1,200 g", want: "1.2"},
+		{name: "German locale", target: "kg", locale: "de", input: "1.234,5 g", want: "1.2345"},
+		{name: "locale rejects other format", target: "kg", locale: "de", input: "1,200.5 g", want: "1,200.5 g"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p, ok := newUnitPreprocessor(tt.target)
+			if !ok {
+				t.Fatalf("newUnitPreprocessor(%q) failed", tt.target)
+			}
+			if tt.locale != "" {
+				p = p.withFormat(numberFormatFor(language.MustParse(tt.locale)))
+			}
+			if got := p.Process(tt.input); got != tt.want {
+				t.Errorf("unit=%s Process(%q) = %q, want %q", tt.target, tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "listed unit", input: "1.2 kg"},
+		{name: "listed unit without space", input: "1200g"},
+		{name: "listed unit other case", input: "1 T"},
+		{name: "decimal comma", input: "1,5 kg"},
+		{name: "bare number", input: "12"},
+		{name: "unlisted unit", input: "3 lb", wantErr: true},
+		{name: "unknown unit", input: "3 stone", wantErr: true},
+		{name: "not a number", input: "many kg", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	v, err := buildUnitValidator("kg g t", true)
+	if err != nil {
+		t.Fatalf("buildUnitValidator() error = %v", err)
+	}
+	if v.Name() != unitTagValue {
+		t.Errorf("Name() = %q, want %q", v.Name(), unitTagValue)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := v.Validate(tt.input); (got != "") != tt.wantErr {
+				t.Errorf("Validate(%q) = %q, wantErr %v", tt.input, got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnit_Processor(t *testing.T) {
+	t.Parallel()
+
+	type Shipment struct {
+		Weight string `prep:"unit=kg" validate:"unit=kg"`
+		Volume string `validate:"unit=l ml"`
+	}
+
+	input := "weight,volume\n\"1,2 t\",500 ml\n1200g,2 m3\n3 stone,1 l\n"
+	var shipments []Shipment
+	output, result, err := NewProcessor(FileTypeCSV).Process(strings.NewReader(input), &shipments)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	var got []string
+	for _, e := range result.ValidationErrors() {
+		got = append(got, e.Column+": "+e.Message())
+	}
+	want := "volume: value must be a quantity in l, ml|weight: value must be a quantity in kg"
+	if strings.Join(got, "|") != want {
+		t.Errorf("errors = %v, want %s", got, want)
+	}
+	data, err := io.ReadAll(output)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+	if want := "weight,volume\n1200,500 ml\n1.2,2 m3\n3 stone,1 l\n"; string(data) != want {
+		t.Errorf("output = %q, want %q", string(data), want)
+	}
+}
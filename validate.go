@@ -1,10 +1,15 @@
 package fileprep
 
 import (
+	"cmp"
 	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
 	"net"
 	"net/url"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -96,22 +101,39 @@ type Validator interface {
 // validators is a slice of Validator
 type validators []Validator
 
-// Validate applies all validators and returns the first error message.
+// Validate applies all validators and returns the first failing validator
+// together with its error message.
 // If omitempty is present and the value is empty, subsequent validators are skipped.
-// Returns empty string if all validations pass.
-func (vs validators) Validate(value string) (string, string) {
+// Returns nil and an empty string if all validations pass.
+func (vs validators) Validate(value string) (Validator, string) {
 	for _, v := range vs {
 		if v.Name() == omitemptyTagValue {
 			if value == "" {
-				return "", ""
+				return nil, ""
 			}
 			continue
 		}
 		if msg := v.Validate(value); msg != "" {
-			return v.Name(), msg
+			return v, msg
 		}
 	}
-	return "", ""
+	return nil, ""
+}
+
+// paramValidator attaches the raw tag parameter (e.g. "0" for min=0) to a
+// validator so that it can be reported in ValidationError.Param.
+type paramValidator struct {
+	Validator
+	param string
+}
+
+// validatorParam returns the tag parameter of v, or an empty string if the
+// validator was declared without one.
+func validatorParam(v Validator) string {
+	if pv, ok := v.(*paramValidator); ok {
+		return pv.param
+	}
+	return ""
 }
 
 // omitemptyValidator is a sentinel validator that signals empty values should be skipped.
@@ -128,6 +150,40 @@ func (v *omitemptyValidator) Name() string {
 	return omitemptyTagValue
 }
 
+// orclearValidator is a sentinel validator that signals invalid values should
+// be cleared rather than reported. It does not perform validation itself;
+// its presence is detected by validators.clearsInvalid().
+type orclearValidator struct{}
+
+// Validate always returns empty (the orclear logic is handled by the processor)
+func (v *orclearValidator) Validate(_ string) string {
+	return ""
+}
+
+// Name returns the validator name
+func (v *orclearValidator) Name() string {
+	return orclearTagValue
+}
+
+// clearsInvalid reports whether vs contains orclear.
+func (vs validators) clearsInvalid() bool {
+	return slices.ContainsFunc(vs, func(v Validator) bool { return v.Name() == orclearTagValue })
+}
+
+// validateCleared checks a value cleared by orclear. Only required can
+// reject it, since the other validators are the ones that cleared it.
+func (vs validators) validateCleared() (Validator, string) {
+	for _, v := range vs {
+		if v.Name() != requiredTagValue {
+			continue
+		}
+		if msg := v.Validate(""); msg != "" {
+			return v, msg
+		}
+	}
+	return nil, ""
+}
+
 // =============================================================================
 // Basic Validators
 // =============================================================================
@@ -351,11 +407,15 @@ func (v *alphanumericUnicodeValidator) Name() string {
 // equalValidator validates that a value equals the threshold
 type equalValidator struct {
 	threshold float64
+	errMsg    string // pre-built error message
 }
 
 // newEqualValidator creates a new equal validator
 func newEqualValidator(threshold float64) *equalValidator {
-	return &equalValidator{threshold: threshold}
+	return &equalValidator{
+		threshold: threshold,
+		errMsg:    "value must equal " + strconv.FormatFloat(threshold, 'f', -1, 64),
+	}
 }
 
 // Validate checks if the value equals the threshold
@@ -365,7 +425,7 @@ func (v *equalValidator) Validate(value string) string {
 		return errMsgValidNumber
 	}
 	if f != v.threshold {
-		return "value must equal " + strconv.FormatFloat(v.threshold, 'f', -1, 64)
+		return v.errMsg
 	}
 	return ""
 }
@@ -378,11 +438,15 @@ func (v *equalValidator) Name() string {
 // notEqualValidator validates that a value does not equal the threshold
 type notEqualValidator struct {
 	threshold float64
+	errMsg    string // pre-built error message
 }
 
 // newNotEqualValidator creates a new not equal validator
 func newNotEqualValidator(threshold float64) *notEqualValidator {
-	return &notEqualValidator{threshold: threshold}
+	return &notEqualValidator{
+		threshold: threshold,
+		errMsg:    "value must not equal " + strconv.FormatFloat(threshold, 'f', -1, 64),
+	}
 }
 
 // Validate checks if the value does not equal the threshold
@@ -392,7 +456,7 @@ func (v *notEqualValidator) Validate(value string) string {
 		return errMsgValidNumber
 	}
 	if f == v.threshold {
-		return "value must not equal " + strconv.FormatFloat(v.threshold, 'f', -1, 64)
+		return v.errMsg
 	}
 	return ""
 }
@@ -405,11 +469,15 @@ func (v *notEqualValidator) Name() string {
 // greaterThanValidator validates that a value is greater than the threshold
 type greaterThanValidator struct {
 	threshold float64
+	errMsg    string // pre-built error message
 }
 
 // newGreaterThanValidator creates a new greater than validator
 func newGreaterThanValidator(threshold float64) *greaterThanValidator {
-	return &greaterThanValidator{threshold: threshold}
+	return &greaterThanValidator{
+		threshold: threshold,
+		errMsg:    "value must be greater than " + strconv.FormatFloat(threshold, 'f', -1, 64),
+	}
 }
 
 // Validate checks if the value is greater than the threshold
@@ -419,7 +487,7 @@ func (v *greaterThanValidator) Validate(value string) string {
 		return errMsgValidNumber
 	}
 	if f <= v.threshold {
-		return "value must be greater than " + strconv.FormatFloat(v.threshold, 'f', -1, 64)
+		return v.errMsg
 	}
 	return ""
 }
@@ -432,11 +500,15 @@ func (v *greaterThanValidator) Name() string {
 // greaterThanEqualValidator validates that a value is greater than or equal to the threshold
 type greaterThanEqualValidator struct {
 	threshold float64
+	errMsg    string // pre-built error message
 }
 
 // newGreaterThanEqualValidator creates a new greater than or equal validator
 func newGreaterThanEqualValidator(threshold float64) *greaterThanEqualValidator {
-	return &greaterThanEqualValidator{threshold: threshold}
+	return &greaterThanEqualValidator{
+		threshold: threshold,
+		errMsg:    "value must be greater than or equal to " + strconv.FormatFloat(threshold, 'f', -1, 64),
+	}
 }
 
 // Validate checks if the value is greater than or equal to the threshold
@@ -446,7 +518,7 @@ func (v *greaterThanEqualValidator) Validate(value string) string {
 		return errMsgValidNumber
 	}
 	if f < v.threshold {
-		return "value must be greater than or equal to " + strconv.FormatFloat(v.threshold, 'f', -1, 64)
+		return v.errMsg
 	}
 	return ""
 }
@@ -459,11 +531,15 @@ func (v *greaterThanEqualValidator) Name() string {
 // lessThanValidator validates that a value is less than the threshold
 type lessThanValidator struct {
 	threshold float64
+	errMsg    string // pre-built error message
 }
 
 // newLessThanValidator creates a new less than validator
 func newLessThanValidator(threshold float64) *lessThanValidator {
-	return &lessThanValidator{threshold: threshold}
+	return &lessThanValidator{
+		threshold: threshold,
+		errMsg:    "value must be less than " + strconv.FormatFloat(threshold, 'f', -1, 64),
+	}
 }
 
 // Validate checks if the value is less than the threshold
@@ -473,7 +549,7 @@ func (v *lessThanValidator) Validate(value string) string {
 		return errMsgValidNumber
 	}
 	if f >= v.threshold {
-		return "value must be less than " + strconv.FormatFloat(v.threshold, 'f', -1, 64)
+		return v.errMsg
 	}
 	return ""
 }
@@ -486,11 +562,15 @@ func (v *lessThanValidator) Name() string {
 // lessThanEqualValidator validates that a value is less than or equal to the threshold
 type lessThanEqualValidator struct {
 	threshold float64
+	errMsg    string // pre-built error message
 }
 
 // newLessThanEqualValidator creates a new less than or equal validator
 func newLessThanEqualValidator(threshold float64) *lessThanEqualValidator {
-	return &lessThanEqualValidator{threshold: threshold}
+	return &lessThanEqualValidator{
+		threshold: threshold,
+		errMsg:    "value must be less than or equal to " + strconv.FormatFloat(threshold, 'f', -1, 64),
+	}
 }
 
 // Validate checks if the value is less than or equal to the threshold
@@ -500,7 +580,7 @@ func (v *lessThanEqualValidator) Validate(value string) string {
 		return errMsgValidNumber
 	}
 	if f > v.threshold {
-		return "value must be less than or equal to " + strconv.FormatFloat(v.threshold, 'f', -1, 64)
+		return v.errMsg
 	}
 	return ""
 }
@@ -513,11 +593,15 @@ func (v *lessThanEqualValidator) Name() string {
 // minValidator validates that a value is at least the minimum
 type minValidator struct {
 	threshold float64
+	errMsg    string // pre-built error message
 }
 
 // newMinValidator creates a new min validator
 func newMinValidator(threshold float64) *minValidator {
-	return &minValidator{threshold: threshold}
+	return &minValidator{
+		threshold: threshold,
+		errMsg:    "value must be at least " + strconv.FormatFloat(threshold, 'f', -1, 64),
+	}
 }
 
 // Validate checks if the value is at least the minimum
@@ -527,7 +611,7 @@ func (v *minValidator) Validate(value string) string {
 		return errMsgValidNumber
 	}
 	if f < v.threshold {
-		return "value must be at least " + strconv.FormatFloat(v.threshold, 'f', -1, 64)
+		return v.errMsg
 	}
 	return ""
 }
@@ -540,11 +624,15 @@ func (v *minValidator) Name() string {
 // maxValidator validates that a value is at most the maximum
 type maxValidator struct {
 	threshold float64
+	errMsg    string // pre-built error message
 }
 
 // newMaxValidator creates a new max validator
 func newMaxValidator(threshold float64) *maxValidator {
-	return &maxValidator{threshold: threshold}
+	return &maxValidator{
+		threshold: threshold,
+		errMsg:    "value must be at most " + strconv.FormatFloat(threshold, 'f', -1, 64),
+	}
 }
 
 // Validate checks if the value is at most the maximum
@@ -554,7 +642,7 @@ func (v *maxValidator) Validate(value string) string {
 		return errMsgValidNumber
 	}
 	if f > v.threshold {
-		return "value must be at most " + strconv.FormatFloat(v.threshold, 'f', -1, 64)
+		return v.errMsg
 	}
 	return ""
 }
@@ -567,18 +655,22 @@ func (v *maxValidator) Name() string {
 // lengthValidator validates that a value has exactly the specified length
 type lengthValidator struct {
 	length int
+	errMsg string // pre-built error message
 }
 
 // newLengthValidator creates a new length validator
 func newLengthValidator(length int) *lengthValidator {
-	return &lengthValidator{length: length}
+	return &lengthValidator{
+		length: length,
+		errMsg: "value must have exactly " + strconv.Itoa(length) + " characters",
+	}
 }
 
 // Validate checks if the value has exactly the specified length (grapheme clusters)
 func (v *lengthValidator) Validate(value string) string {
 	count := utf8.RuneCountInString(value)
 	if count != v.length {
-		return "value must have exactly " + strconv.Itoa(v.length) + " characters"
+		return v.errMsg
 	}
 	return ""
 }
@@ -594,6 +686,7 @@ func (v *lengthValidator) Name() string {
 
 // oneOfValidator validates that a value is one of the allowed values
 type oneOfValidator struct {
+	allowed    []string            // allowed values in tag order, for closest
 	allowedSet map[string]struct{} // O(1) lookup instead of O(n) linear search
 	errMsg     string              // pre-built error message
 }
@@ -605,19 +698,75 @@ func newOneOfValidator(allowed []string) *oneOfValidator {
 		allowedSet[s] = struct{}{}
 	}
 	return &oneOfValidator{
+		allowed:    allowed,
 		allowedSet: allowedSet,
 		errMsg:     "value must be one of: " + strings.Join(allowed, ", "),
 	}
 }
 
-// Validate checks if the value is one of the allowed values
+// Validate checks if the value is one of the allowed values. The message
+// names the closest allowed value when the value looks like a misspelling
+// of it.
 func (v *oneOfValidator) Validate(value string) string {
 	if _, ok := v.allowedSet[value]; ok {
 		return ""
 	}
+	if closest, ok := v.closest(value); ok {
+		return v.errMsg + " (did you mean " + strconv.Quote(closest) + "?)"
+	}
 	return v.errMsg
 }
 
+// oneOfMaxSuggestRunes bounds the length of values closest compares, since
+// the edit distance is quadratic in it.
+const oneOfMaxSuggestRunes = 64
+
+// closest returns the allowed value with the smallest edit distance to
+// value, ignoring case, and false when even that one differs in more than a
+// third of its characters (at least one). Ties go to the value listed first.
+func (v *oneOfValidator) closest(value string) (string, bool) {
+	if value == "" || utf8.RuneCountInString(value) > oneOfMaxSuggestRunes {
+		return "", false
+	}
+	folded := []rune(strings.ToLower(value))
+	best, bestDist := "", -1
+	for _, candidate := range v.allowed {
+		if candidate == "" || utf8.RuneCountInString(candidate) > oneOfMaxSuggestRunes {
+			continue
+		}
+		dist := levenshtein(folded, []rune(strings.ToLower(candidate)))
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = candidate, dist
+		}
+	}
+	if bestDist < 0 || bestDist > max(1, utf8.RuneCountInString(best)/3) {
+		return "", false
+	}
+	return best, true
+}
+
+// levenshtein returns the number of rune insertions, deletions, and
+// substitutions that turn a into b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := range a {
+		curr[0] = i + 1
+		for j := range b {
+			cost := 1
+			if a[i] == b[j] {
+				cost = 0
+			}
+			curr[j+1] = min(prev[j+1]+1, curr[j]+1, prev[j]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
 // Name returns the validator name
 func (v *oneOfValidator) Name() string {
 	return oneOfTagValue
@@ -1225,17 +1374,21 @@ func (v *hostnamePortValidator) Name() string {
 // startsWithValidator validates that a value starts with the prefix
 type startsWithValidator struct {
 	prefix string
+	errMsg string // pre-built error message
 }
 
 // newStartsWithValidator creates a new startsWith validator
 func newStartsWithValidator(prefix string) *startsWithValidator {
-	return &startsWithValidator{prefix: prefix}
+	return &startsWithValidator{
+		prefix: prefix,
+		errMsg: "value must start with '" + prefix + "'",
+	}
 }
 
 // Validate checks if the value starts with the prefix
 func (v *startsWithValidator) Validate(value string) string {
 	if !strings.HasPrefix(value, v.prefix) {
-		return "value must start with '" + v.prefix + "'"
+		return v.errMsg
 	}
 	return ""
 }
@@ -1248,17 +1401,21 @@ func (v *startsWithValidator) Name() string {
 // startsNotWithValidator validates that a value does not start with the prefix
 type startsNotWithValidator struct {
 	prefix string
+	errMsg string // pre-built error message
 }
 
 // newStartsNotWithValidator creates a new startsNotWith validator
 func newStartsNotWithValidator(prefix string) *startsNotWithValidator {
-	return &startsNotWithValidator{prefix: prefix}
+	return &startsNotWithValidator{
+		prefix: prefix,
+		errMsg: "value must not start with '" + prefix + "'",
+	}
 }
 
 // Validate checks if the value does not start with the prefix
 func (v *startsNotWithValidator) Validate(value string) string {
 	if strings.HasPrefix(value, v.prefix) {
-		return "value must not start with '" + v.prefix + "'"
+		return v.errMsg
 	}
 	return ""
 }
@@ -1271,17 +1428,21 @@ func (v *startsNotWithValidator) Name() string {
 // endsWithValidator validates that a value ends with the suffix
 type endsWithValidator struct {
 	suffix string
+	errMsg string // pre-built error message
 }
 
 // newEndsWithValidator creates a new endsWith validator
 func newEndsWithValidator(suffix string) *endsWithValidator {
-	return &endsWithValidator{suffix: suffix}
+	return &endsWithValidator{
+		suffix: suffix,
+		errMsg: "value must end with '" + suffix + "'",
+	}
 }
 
 // Validate checks if the value ends with the suffix
 func (v *endsWithValidator) Validate(value string) string {
 	if !strings.HasSuffix(value, v.suffix) {
-		return "value must end with '" + v.suffix + "'"
+		return v.errMsg
 	}
 	return ""
 }
@@ -1294,17 +1455,21 @@ func (v *endsWithValidator) Name() string {
 // endsNotWithValidator validates that a value does not end with the suffix
 type endsNotWithValidator struct {
 	suffix string
+	errMsg string // pre-built error message
 }
 
 // newEndsNotWithValidator creates a new endsNotWith validator
 func newEndsNotWithValidator(suffix string) *endsNotWithValidator {
-	return &endsNotWithValidator{suffix: suffix}
+	return &endsNotWithValidator{
+		suffix: suffix,
+		errMsg: "value must not end with '" + suffix + "'",
+	}
 }
 
 // Validate checks if the value does not end with the suffix
 func (v *endsNotWithValidator) Validate(value string) string {
 	if strings.HasSuffix(value, v.suffix) {
-		return "value must not end with '" + v.suffix + "'"
+		return v.errMsg
 	}
 	return ""
 }
@@ -1317,17 +1482,21 @@ func (v *endsNotWithValidator) Name() string {
 // containsValidator validates that a value contains the substring
 type containsValidator struct {
 	substr string
+	errMsg string // pre-built error message
 }
 
 // newContainsValidator creates a new contains validator
 func newContainsValidator(substr string) *containsValidator {
-	return &containsValidator{substr: substr}
+	return &containsValidator{
+		substr: substr,
+		errMsg: "value must contain '" + substr + "'",
+	}
 }
 
 // Validate checks if the value contains the substring
 func (v *containsValidator) Validate(value string) string {
 	if !strings.Contains(value, v.substr) {
-		return "value must contain '" + v.substr + "'"
+		return v.errMsg
 	}
 	return ""
 }
@@ -1340,24 +1509,28 @@ func (v *containsValidator) Name() string {
 // containsAnyValidator validates that a value contains any of the specified characters.
 // This is symmetric with excludesAllValidator and uses strings.ContainsAny for per-character checking.
 type containsAnyValidator struct {
-	chars string
+	chars  string
+	errMsg string // pre-built error message
 }
 
 // newContainsAnyValidator creates a new containsAny validator.
 // Each character in chars is checked individually (e.g., "abc" checks for 'a', 'b', or 'c').
 func newContainsAnyValidator(chars string) *containsAnyValidator {
-	return &containsAnyValidator{chars: chars}
+	return &containsAnyValidator{
+		chars:  chars,
+		errMsg: "value must contain any of: " + chars,
+	}
 }
 
 // Validate checks if the value contains any of the specified characters
 func (v *containsAnyValidator) Validate(value string) string {
 	if value == "" || v.chars == "" {
-		return "value must contain any of: " + v.chars
+		return v.errMsg
 	}
 	if strings.ContainsAny(value, v.chars) {
 		return ""
 	}
-	return "value must contain any of: " + v.chars
+	return v.errMsg
 }
 
 // Name returns the validator name
@@ -1367,18 +1540,22 @@ func (v *containsAnyValidator) Name() string {
 
 // containsRuneValidator validates that a value contains the rune
 type containsRuneValidator struct {
-	r rune
+	r      rune
+	errMsg string // pre-built error message
 }
 
 // newContainsRuneValidator creates a new containsRune validator
 func newContainsRuneValidator(r rune) *containsRuneValidator {
-	return &containsRuneValidator{r: r}
+	return &containsRuneValidator{
+		r:      r,
+		errMsg: "value must contain character '" + string(r) + "'",
+	}
 }
 
 // Validate checks if the value contains the rune
 func (v *containsRuneValidator) Validate(value string) string {
 	if !strings.ContainsRune(value, v.r) {
-		return "value must contain character '" + string(v.r) + "'"
+		return v.errMsg
 	}
 	return ""
 }
@@ -1395,17 +1572,21 @@ func (v *containsRuneValidator) Name() string {
 // excludesValidator validates that a value does not contain the substring
 type excludesValidator struct {
 	substr string
+	errMsg string // pre-built error message
 }
 
 // newExcludesValidator creates a new excludes validator
 func newExcludesValidator(substr string) *excludesValidator {
-	return &excludesValidator{substr: substr}
+	return &excludesValidator{
+		substr: substr,
+		errMsg: "value must not contain '" + substr + "'",
+	}
 }
 
 // Validate checks if the value does not contain the substring
 func (v *excludesValidator) Validate(value string) string {
 	if strings.Contains(value, v.substr) {
-		return "value must not contain '" + v.substr + "'"
+		return v.errMsg
 	}
 	return ""
 }
@@ -1417,12 +1598,16 @@ func (v *excludesValidator) Name() string {
 
 // excludesAllValidator validates that a value does not contain any of the runes
 type excludesAllValidator struct {
-	chars string
+	chars  string
+	errMsg string // pre-built error message
 }
 
 // newExcludesAllValidator creates a new excludesAll validator
 func newExcludesAllValidator(chars string) *excludesAllValidator {
-	return &excludesAllValidator{chars: chars}
+	return &excludesAllValidator{
+		chars:  chars,
+		errMsg: "value must not contain any of: " + chars,
+	}
 }
 
 // Validate checks if the value does not contain any of the specified characters
@@ -1431,7 +1616,7 @@ func (v *excludesAllValidator) Validate(value string) string {
 		return ""
 	}
 	if strings.ContainsAny(value, v.chars) {
-		return "value must not contain any of: " + v.chars
+		return v.errMsg
 	}
 	return ""
 }
@@ -1443,18 +1628,22 @@ func (v *excludesAllValidator) Name() string {
 
 // excludesRuneValidator validates that a value does not contain the rune
 type excludesRuneValidator struct {
-	r rune
+	r      rune
+	errMsg string // pre-built error message
 }
 
 // newExcludesRuneValidator creates a new excludesRune validator
 func newExcludesRuneValidator(r rune) *excludesRuneValidator {
-	return &excludesRuneValidator{r: r}
+	return &excludesRuneValidator{
+		r:      r,
+		errMsg: "value must not contain character '" + string(r) + "'",
+	}
 }
 
 // Validate checks if the value does not contain the rune
 func (v *excludesRuneValidator) Validate(value string) string {
 	if strings.ContainsRune(value, v.r) {
-		return "value must not contain character '" + string(v.r) + "'"
+		return v.errMsg
 	}
 	return ""
 }
@@ -1489,20 +1678,281 @@ func (v *multibyteValidator) Name() string {
 	return multibyteTagValue
 }
 
+// noEmojiValidator validates that a value contains no emoji
+type noEmojiValidator struct{}
+
+// newNoEmojiValidator creates a new no emoji validator
+func newNoEmojiValidator() *noEmojiValidator {
+	return &noEmojiValidator{}
+}
+
+// Validate checks that the value contains no emoji
+func (v *noEmojiValidator) Validate(value string) string {
+	if containsEmoji(value) {
+		return "value must not contain emoji"
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *noEmojiValidator) Name() string {
+	return noEmojiTagValue
+}
+
+//nolint:gochecknoglobals // charset name tables
+var (
+	// charsetTables maps the lowercased names of Unicode scripts and general
+	// categories, such as "latin", "han", and "lu", to their tables.
+	charsetTables = func() map[string]*unicode.RangeTable {
+		tables := make(map[string]*unicode.RangeTable, len(unicode.Scripts)+len(unicode.Categories))
+		for name, table := range unicode.Categories {
+			tables[strings.ToLower(name)] = table
+		}
+		for name, table := range unicode.Scripts {
+			tables[strings.ToLower(name)] = table
+		}
+		return tables
+	}()
+	// charsetAliases are charset names that combine several tables.
+	charsetAliases = map[string][]*unicode.RangeTable{
+		"ascii": {{R16: []unicode.Range16{{Lo: 0x00, Hi: 0x7F, Stride: 1}}, LatinOffset: 1}},
+		// Kana includes the prolonged sound mark and the voicing marks, which
+		// belong to the Common and Inherited scripts
+		"kana": {unicode.Hiragana, unicode.Katakana, {R16: []unicode.Range16{
+			{Lo: 0x3099, Hi: 0x309C, Stride: 1},
+			{Lo: 0x30FC, Hi: 0x30FC, Stride: 1},
+		}}},
+	}
+)
+
+// parseCharsets returns the tables of the space-separated charset names in
+// value, or an error naming the first unknown one or reporting none.
+func parseCharsets(value string) ([]*unicode.RangeTable, error) {
+	var tables []*unicode.RangeTable
+	for _, name := range strings.Fields(strings.ToLower(value)) {
+		if alias, ok := charsetAliases[name]; ok {
+			tables = append(tables, alias...)
+			continue
+		}
+		table, ok := charsetTables[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown Unicode script or category %q", name)
+		}
+		tables = append(tables, table)
+	}
+	if len(tables) == 0 {
+		return nil, errors.New("no Unicode script or category given")
+	}
+	return tables, nil
+}
+
+// charsetValidator validates that every rune of a value belongs to one of
+// the allowed Unicode scripts or categories, or with exclude, that none does
+type charsetValidator struct {
+	tables  []*unicode.RangeTable
+	exclude bool
+	errMsg  string // pre-built error message
+}
+
+// newCharsetValidator creates a new charset or excludescharset validator
+func newCharsetValidator(tables []*unicode.RangeTable, names string, exclude bool) *charsetValidator {
+	errMsg := "value must contain only characters from " + strings.Join(strings.Fields(names), ", ")
+	if exclude {
+		errMsg = "value must not contain characters from " + strings.Join(strings.Fields(names), ", ")
+	}
+	return &charsetValidator{tables: tables, exclude: exclude, errMsg: errMsg}
+}
+
+// Validate checks the script or category of every rune
+func (v *charsetValidator) Validate(value string) string {
+	for _, r := range value {
+		if unicode.IsOneOf(v.tables, r) == v.exclude {
+			return v.errMsg
+		}
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *charsetValidator) Name() string {
+	if v.exclude {
+		return excludesCharsetTagValue
+	}
+	return charsetTagValue
+}
+
+// redactedValue replaces the value of errors from validators of secrets,
+// such as password, so reports and logs do not leak them.
+const redactedValue = "[redacted]"
+
+// secretValidator is implemented by validators of secret values. Their
+// errors report redactedValue instead of the value.
+type secretValidator interface {
+	secret()
+}
+
+// reportedValue returns the value to report in an error from v.
+func reportedValue(v Validator, value string) string {
+	if pv, ok := v.(*paramValidator); ok {
+		v = pv.Validator
+	}
+	if _, ok := v.(secretValidator); ok {
+		return redactedValue
+	}
+	return value
+}
+
+// passwordPolicy is the policy of a password tag, such as
+// "min8 upper lower digit symbol".
+type passwordPolicy struct {
+	minLength int
+	maxLength int // 0 means no limit
+	upper     bool
+	lower     bool
+	digit     bool
+	symbol    bool
+	classes   int // how many of the four character classes must appear
+}
+
+// defaultPasswordPolicy is the policy of a password tag without a value.
+const defaultPasswordPolicy = "min8 upper lower digit symbol"
+
+// parsePasswordPolicy parses space-separated password rules: minN, maxN,
+// upper, lower, digit, symbol, and classesN.
+func parsePasswordPolicy(value string) (passwordPolicy, error) {
+	if strings.TrimSpace(value) == "" {
+		value = defaultPasswordPolicy
+	}
+	var policy passwordPolicy
+	for _, rule := range strings.Fields(strings.ToLower(value)) {
+		switch rule {
+		case "upper":
+			policy.upper = true
+		case "lower":
+			policy.lower = true
+		case "digit":
+			policy.digit = true
+		case "symbol":
+			policy.symbol = true
+		default:
+			var target *int
+			var number string
+			switch {
+			case strings.HasPrefix(rule, "min"):
+				target, number = &policy.minLength, rule[len("min"):]
+			case strings.HasPrefix(rule, "max"):
+				target, number = &policy.maxLength, rule[len("max"):]
+			case strings.HasPrefix(rule, "classes"):
+				target, number = &policy.classes, rule[len("classes"):]
+			default:
+				return policy, fmt.Errorf("unknown password rule %q", rule)
+			}
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 1 {
+				return policy, fmt.Errorf("password rule %q needs a positive number", rule)
+			}
+			*target = n
+		}
+	}
+	if policy.classes > 4 {
+		return policy, fmt.Errorf("password rule classes%d exceeds the 4 character classes", policy.classes)
+	}
+	if policy.maxLength > 0 && policy.maxLength < policy.minLength {
+		return policy, fmt.Errorf("password rule max%d is below min%d", policy.maxLength, policy.minLength)
+	}
+	return policy, nil
+}
+
+// passwordValidator validates that a value meets a password policy. Its
+// errors do not include the value.
+type passwordValidator struct {
+	policy passwordPolicy
+}
+
+// newPasswordValidator creates a new password validator
+func newPasswordValidator(policy passwordPolicy) *passwordValidator {
+	return &passwordValidator{policy: policy}
+}
+
+// Validate checks the length and character classes of the value and lists
+// every unmet rule
+func (v *passwordValidator) Validate(value string) string {
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range value {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+
+	p := v.policy
+	var missing []string
+	if length := utf8.RuneCountInString(value); length < p.minLength {
+		missing = append(missing, "at least "+strconv.Itoa(p.minLength)+" characters")
+	} else if p.maxLength > 0 && length > p.maxLength {
+		missing = append(missing, "at most "+strconv.Itoa(p.maxLength)+" characters")
+	}
+	for _, class := range []struct {
+		required, present bool
+		name              string
+	}{
+		{p.upper, hasUpper, "an uppercase letter"},
+		{p.lower, hasLower, "a lowercase letter"},
+		{p.digit, hasDigit, "a digit"},
+		{p.symbol, hasSymbol, "a symbol"},
+	} {
+		if class.required && !class.present {
+			missing = append(missing, class.name)
+		}
+	}
+	present := 0
+	for _, ok := range []bool{hasUpper, hasLower, hasDigit, hasSymbol} {
+		if ok {
+			present++
+		}
+	}
+	if present < p.classes {
+		missing = append(missing, "characters of "+strconv.Itoa(p.classes)+" kinds (uppercase, lowercase, digits, symbols)")
+	}
+
+	if len(missing) == 0 {
+		return ""
+	}
+	return "password must have " + strings.Join(missing, ", ")
+}
+
+// Name returns the validator name
+func (v *passwordValidator) Name() string {
+	return passwordTagValue
+}
+
+// secret marks password values as secrets
+func (v *passwordValidator) secret() {}
+
 // equalIgnoreCaseValidator validates that a value equals the expected value (case insensitive)
 type equalIgnoreCaseValidator struct {
 	expected string
+	errMsg   string // pre-built error message
 }
 
 // newEqualIgnoreCaseValidator creates a new equalIgnoreCase validator
 func newEqualIgnoreCaseValidator(expected string) *equalIgnoreCaseValidator {
-	return &equalIgnoreCaseValidator{expected: expected}
+	return &equalIgnoreCaseValidator{
+		expected: expected,
+		errMsg:   "value must equal '" + expected + "' (case insensitive)",
+	}
 }
 
 // Validate checks if the value equals the expected value (case insensitive)
 func (v *equalIgnoreCaseValidator) Validate(value string) string {
 	if !strings.EqualFold(value, v.expected) {
-		return "value must equal '" + v.expected + "' (case insensitive)"
+		return v.errMsg
 	}
 	return ""
 }
@@ -1515,17 +1965,21 @@ func (v *equalIgnoreCaseValidator) Name() string {
 // notEqualIgnoreCaseValidator validates that a value does not equal the expected value (case insensitive)
 type notEqualIgnoreCaseValidator struct {
 	expected string
+	errMsg   string // pre-built error message
 }
 
 // newNotEqualIgnoreCaseValidator creates a new notEqualIgnoreCase validator
 func newNotEqualIgnoreCaseValidator(expected string) *notEqualIgnoreCaseValidator {
-	return &notEqualIgnoreCaseValidator{expected: expected}
+	return &notEqualIgnoreCaseValidator{
+		expected: expected,
+		errMsg:   "value must not equal '" + expected + "' (case insensitive)",
+	}
 }
 
 // Validate checks if the value does not equal the expected value (case insensitive)
 func (v *notEqualIgnoreCaseValidator) Validate(value string) string {
 	if strings.EqualFold(value, v.expected) {
-		return "value must not equal '" + v.expected + "' (case insensitive)"
+		return v.errMsg
 	}
 	return ""
 }
@@ -1542,11 +1996,15 @@ func (v *notEqualIgnoreCaseValidator) Name() string {
 // datetimeValidator validates that a value matches the specified datetime layout
 type datetimeValidator struct {
 	layout string
+	errMsg string // pre-built error message
 }
 
 // newDatetimeValidator creates a new datetime validator with the specified layout
 func newDatetimeValidator(layout string) *datetimeValidator {
-	return &datetimeValidator{layout: layout}
+	return &datetimeValidator{
+		layout: layout,
+		errMsg: "value must be a valid datetime in format: " + layout,
+	}
 }
 
 // Validate checks if the value matches the datetime layout
@@ -1555,7 +2013,7 @@ func (v *datetimeValidator) Validate(value string) string {
 		return ""
 	}
 	if err := parseDateTimeImpl(value, v.layout); err != nil {
-		return "value must be a valid datetime in format: " + v.layout
+		return v.errMsg
 	}
 	return ""
 }
@@ -1914,3 +2372,322 @@ func (v *macValidator) Validate(value string) string {
 func (v *macValidator) Name() string {
 	return macTagValue
 }
+
+// =============================================================================
+// Column Statistics Validators
+// =============================================================================
+
+// columnStatsValidator is a Validator whose result depends on the whole
+// column. Before any row is validated, Process passes the preprocessed values
+// of the column to withColumnValues and validates rows with the returned
+// validator, so the tag-parsed instance stays unchanged.
+type columnStatsValidator interface {
+	Validator
+	withColumnValues(values []string) Validator
+}
+
+// columnFloats returns the numeric entries of values, skipping empty and
+// non-numeric ones.
+func columnFloats(values []string) []float64 {
+	nums := make([]float64, 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			nums = append(nums, f)
+		}
+	}
+	return nums
+}
+
+// formatStat formats a computed statistic for error messages
+func formatStat(f float64) string {
+	return strconv.FormatFloat(f, 'g', 6, 64)
+}
+
+// outlierValidator flags values more than k standard deviations from the column mean
+type outlierValidator struct {
+	k      float64
+	mean   float64
+	stddev float64
+	ready  bool   // true once column statistics are set
+	errMsg string // pre-built error message
+}
+
+// newOutlierValidator creates a new outlier validator
+func newOutlierValidator(k float64) *outlierValidator {
+	return &outlierValidator{k: k}
+}
+
+// withColumnValues returns a validator bound to the mean and population
+// standard deviation of the column
+func (v *outlierValidator) withColumnValues(values []string) Validator {
+	nums := columnFloats(values)
+	if len(nums) == 0 {
+		return &outlierValidator{k: v.k}
+	}
+
+	var sum float64
+	for _, f := range nums {
+		sum += f
+	}
+	mean := sum / float64(len(nums))
+	var sqDiff float64
+	for _, f := range nums {
+		sqDiff += (f - mean) * (f - mean)
+	}
+	stddev := math.Sqrt(sqDiff / float64(len(nums)))
+
+	return &outlierValidator{
+		k:      v.k,
+		mean:   mean,
+		stddev: stddev,
+		ready:  true,
+		errMsg: "value must be within " + formatStat(v.k) + " standard deviations of the column mean (" +
+			formatStat(mean) + " ± " + formatStat(stddev) + ")",
+	}
+}
+
+// Validate checks if the value is within k standard deviations of the column mean
+func (v *outlierValidator) Validate(value string) string {
+	if value == "" || !v.ready {
+		return ""
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return errMsgValidNumber
+	}
+	if math.Abs(f-v.mean) > v.k*v.stddev {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *outlierValidator) Name() string {
+	return outlierTagValue
+}
+
+// percentileValidator flags values outside the given percentile range of the column
+type percentileValidator struct {
+	low    float64 // lower percentile (0-100)
+	high   float64 // upper percentile (0-100)
+	minVal float64
+	maxVal float64
+	ready  bool   // true once column statistics are set
+	errMsg string // pre-built error message
+}
+
+// newPercentileValidator creates a new percentile validator
+func newPercentileValidator(low, high float64) *percentileValidator {
+	return &percentileValidator{low: low, high: high}
+}
+
+// withColumnValues returns a validator bound to the column's values at the
+// low and high percentiles, using linear interpolation between ranks
+func (v *percentileValidator) withColumnValues(values []string) Validator {
+	nums := columnFloats(values)
+	if len(nums) == 0 {
+		return &percentileValidator{low: v.low, high: v.high}
+	}
+	slices.Sort(nums)
+
+	minVal := percentileOf(nums, v.low)
+	maxVal := percentileOf(nums, v.high)
+	return &percentileValidator{
+		low:    v.low,
+		high:   v.high,
+		minVal: minVal,
+		maxVal: maxVal,
+		ready:  true,
+		errMsg: "value must be within percentiles " + formatStat(v.low) + "-" + formatStat(v.high) +
+			" of the column (" + formatStat(minVal) + " to " + formatStat(maxVal) + ")",
+	}
+}
+
+// percentileOf returns the p-th percentile (0-100) of sorted values
+func percentileOf(sorted []float64, p float64) float64 {
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// Validate checks if the value is within the column's percentile range
+func (v *percentileValidator) Validate(value string) string {
+	if value == "" || !v.ready {
+		return ""
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return errMsgValidNumber
+	}
+	if f < v.minVal || f > v.maxVal {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *percentileValidator) Name() string {
+	return percentileTagValue
+}
+
+// monotonicValidator checks that a column never goes backwards across rows.
+// Values are compared numerically when both parse as numbers and
+// lexically otherwise, which orders ISO 8601 timestamps correctly.
+// Only the first offending row is reported.
+//
+// The bound validator keeps the previous value, so it relies on rows being
+// validated once each in input order, as Process does. Empty values and
+// values rejected by an earlier validator in the chain are skipped.
+type monotonicValidator struct {
+	strict bool   // true for increasing, false for nondecreasing
+	prev   string // last accepted value
+	seen   bool   // true once prev is set
+	failed bool   // true once an offending row was reported
+}
+
+// newMonotonicValidator creates a new monotonic validator
+func newMonotonicValidator(strict bool) *monotonicValidator {
+	return &monotonicValidator{strict: strict}
+}
+
+// withColumnValues returns a validator with fresh state for one run.
+// The column values are not needed up front.
+func (v *monotonicValidator) withColumnValues(_ []string) Validator {
+	return &monotonicValidator{strict: v.strict}
+}
+
+// Validate checks that the value does not go backwards from the previous row
+func (v *monotonicValidator) Validate(value string) string {
+	if value == "" || v.failed {
+		return ""
+	}
+	if !v.seen {
+		v.prev, v.seen = value, true
+		return ""
+	}
+
+	order := compareOrdered(value, v.prev)
+	if order < 0 || (v.strict && order == 0) {
+		v.failed = true
+		if v.strict {
+			return "value must be greater than the previous value (" + v.prev + ")"
+		}
+		return "value must not be less than the previous value (" + v.prev + ")"
+	}
+	v.prev = value
+	return ""
+}
+
+// compareOrdered compares a and b as numbers when both parse as float64,
+// otherwise as strings.
+func compareOrdered(a, b string) int {
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		return cmp.Compare(fa, fb)
+	}
+	return strings.Compare(a, b)
+}
+
+// Name returns the validator name
+func (v *monotonicValidator) Name() string {
+	if v.strict {
+		return increasingTagValue
+	}
+	return nondecreasingTagValue
+}
+
+// =============================================================================
+// Group Validators
+// =============================================================================
+
+// orValidator passes when at least one member passes
+type orValidator struct {
+	members validators
+	errMsg  string // pre-built error message
+}
+
+// newOrValidator creates a new or validator. labels are the member tags,
+// used in the error message.
+func newOrValidator(members validators, labels []string) *orValidator {
+	return &orValidator{
+		members: members,
+		errMsg:  "value must satisfy at least one of: " + strings.Join(labels, ", "),
+	}
+}
+
+// Validate checks if any member accepts the value
+func (v *orValidator) Validate(value string) string {
+	for _, m := range v.members {
+		if m.Validate(value) == "" {
+			return ""
+		}
+	}
+	return v.errMsg
+}
+
+// Name returns the validator name
+func (v *orValidator) Name() string {
+	return orTagValue
+}
+
+// andValidator passes when every member passes. It is mainly useful inside
+// or() and not(), since top-level validators are already combined with AND.
+type andValidator struct {
+	members validators
+}
+
+// newAndValidator creates a new and validator
+func newAndValidator(members validators) *andValidator {
+	return &andValidator{members: members}
+}
+
+// Validate returns the message of the first member that rejects the value
+func (v *andValidator) Validate(value string) string {
+	for _, m := range v.members {
+		if msg := m.Validate(value); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *andValidator) Name() string {
+	return andTagValue
+}
+
+// notValidator passes when its member fails
+type notValidator struct {
+	member Validator
+	errMsg string // pre-built error message
+}
+
+// newNotValidator creates a new not validator. label is the member tag,
+// used in the error message.
+func newNotValidator(member Validator, label string) *notValidator {
+	return &notValidator{
+		member: member,
+		errMsg: "value must not satisfy " + label,
+	}
+}
+
+// Validate checks if the member rejects the value
+func (v *notValidator) Validate(value string) string {
+	if v.member.Validate(value) == "" {
+		return v.errMsg
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *notValidator) Name() string {
+	return notTagValue
+}
@@ -1,8 +1,11 @@
 package fileprep
 
 import (
+	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestOmitemptyValidator(t *testing.T) {
@@ -11,17 +14,17 @@ func TestOmitemptyValidator(t *testing.T) {
 	t.Run("omitempty with email skips validation on empty value", func(t *testing.T) {
 		t.Parallel()
 		vs := validators{&omitemptyValidator{}, newEmailValidator()}
-		tag, msg := vs.Validate("")
-		if tag != "" || msg != "" {
-			t.Errorf("expected empty value to pass with omitempty, got tag=%q msg=%q", tag, msg)
+		v, msg := vs.Validate("")
+		if v != nil || msg != "" {
+			t.Errorf("expected empty value to pass with omitempty, got validator=%v msg=%q", v, msg)
 		}
 	})
 
 	t.Run("omitempty with email validates non-empty value", func(t *testing.T) {
 		t.Parallel()
 		vs := validators{&omitemptyValidator{}, newEmailValidator()}
-		tag, msg := vs.Validate("invalid")
-		if tag == "" || msg == "" {
+		v, msg := vs.Validate("invalid")
+		if v == nil || msg == "" {
 			t.Error("expected validation error for invalid email with omitempty")
 		}
 	})
@@ -29,18 +32,18 @@ func TestOmitemptyValidator(t *testing.T) {
 	t.Run("omitempty with email passes valid non-empty value", func(t *testing.T) {
 		t.Parallel()
 		vs := validators{&omitemptyValidator{}, newEmailValidator()}
-		tag, msg := vs.Validate("user@example.com")
-		if tag != "" || msg != "" {
-			t.Errorf("expected valid email to pass with omitempty, got tag=%q msg=%q", tag, msg)
+		v, msg := vs.Validate("user@example.com")
+		if v != nil || msg != "" {
+			t.Errorf("expected valid email to pass with omitempty, got validator=%v msg=%q", v, msg)
 		}
 	})
 
 	t.Run("required before omitempty still catches empty value", func(t *testing.T) {
 		t.Parallel()
 		vs := validators{newRequiredValidator(), &omitemptyValidator{}, newEmailValidator()}
-		tag, msg := vs.Validate("")
-		if tag != requiredTagValue {
-			t.Errorf("expected required to catch empty value before omitempty, got tag=%q", tag)
+		v, msg := vs.Validate("")
+		if v == nil || v.Name() != requiredTagValue {
+			t.Errorf("expected required to catch empty value before omitempty, got validator=%v", v)
 		}
 		if msg == "" {
 			t.Error("expected error message for required validation failure")
@@ -672,6 +675,53 @@ func TestOneOfValidator_ErrorMessagePreservesOrder(t *testing.T) {
 	}
 }
 
+func TestOneOfValidator_ClosestMatch(t *testing.T) {
+	t.Parallel()
+
+	v := newOneOfValidator([]string{"active", "inactive", "pending"})
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"actve", `value must be one of: active, inactive, pending (did you mean "active"?)`},
+		{"Pending", `value must be one of: active, inactive, pending (did you mean "pending"?)`},
+		{"inactiv", `value must be one of: active, inactive, pending (did you mean "inactive"?)`},
+		{"closed", "value must be one of: active, inactive, pending"},
+		{"", "value must be one of: active, inactive, pending"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			if got := v.Validate(tt.input); got != tt.want {
+				t.Errorf("Validate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"日本語", "日本", 1},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein([]rune(tt.a), []rune(tt.b)); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
 func TestOneOfValidator_EdgeCases(t *testing.T) {
 	t.Parallel()
 
@@ -1722,6 +1772,128 @@ func TestMultibyteValidator(t *testing.T) {
 	}
 }
 
+func TestNoEmojiValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"hello", false},
+		{"日本語", false},
+		{"©2024", false},
+		{"", false},
+		{"nice 😀", true},
+		{"☀", true},
+		{"🇯🇵", true},
+	}
+
+	v := newNoEmojiValidator()
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			msg := v.Validate(tt.input)
+			hasErr := msg != ""
+			if hasErr != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.input, msg, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCharsetValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		tag     string
+		input   string
+		wantErr bool
+	}{
+		{"ascii only", "charset=ascii", "Hello, world!", false},
+		{"ascii rejects accents", "charset=ascii", "café", true},
+		{"latin script", "charset=latin", "café", false},
+		{"latin script rejects spaces", "charset=latin", "café au lait", true},
+		{"latin with space separators", "charset=latin zs", "café au lait", false},
+		{"japanese", "charset=han kana", "東京タワー", false},
+		{"kana includes voicing marks", "charset=kana", "か\u3099", false},
+		{"japanese rejects latin", "charset=han kana", "東京Tower", true},
+		{"category names are case-insensitive", "charset=Lu Nd", "AB12", false},
+		{"category rejects lowercase", "charset=lu nd", "Ab12", true},
+		{"empty value passes", "charset=han", "", false},
+		{"excludes cyrillic", "excludescharset=cyrillic", "Paypal", false},
+		{"excludes cyrillic homoglyph", "excludescharset=cyrillic", "Pаypal", true},
+		{"excludes control characters", "excludescharset=cc", "line\nbreak", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			vals, _, err := parseValidateTag(tt.tag, true)
+			if err != nil {
+				t.Fatalf("parseValidateTag(%q) error = %v", tt.tag, err)
+			}
+			_, msg := vals.Validate(tt.input)
+			if (msg != "") != tt.wantErr {
+				t.Errorf("Validate(%q) error = %q, wantErr %v", tt.input, msg, tt.wantErr)
+			}
+		})
+	}
+
+	if v := newCharsetValidator(nil, "han kana", false); v.Validate("x") != "value must contain only characters from han, kana" || v.Name() != "charset" {
+		t.Errorf("charset message = %q, name = %q", v.Validate("x"), v.Name())
+	}
+}
+
+func TestPasswordValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		policy string
+		input  string
+		want   string
+	}{
+		{"default policy met", "", "Secr3t!pw", ""},
+		{"default policy lists every unmet rule", "", "secret", "password must have at least 8 characters, an uppercase letter, a digit, a symbol"},
+		{"length only", "min12", "Secr3t!pw", "password must have at least 12 characters"},
+		{"maximum length", "min4 max8", "Secr3t!password", "password must have at most 8 characters"},
+		{"unicode letters count", "upper lower", "Ärger", ""},
+		{"classes met", "min8 classes3", "secret12!", ""},
+		{"classes unmet", "min8 classes3", "secret123", "password must have characters of 3 kinds (uppercase, lowercase, digits, symbols)"},
+		{"rules are case-insensitive", "MIN2 Digit", "a1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			policy, err := parsePasswordPolicy(tt.policy)
+			if err != nil {
+				t.Fatalf("parsePasswordPolicy(%q) error = %v", tt.policy, err)
+			}
+			v := newPasswordValidator(policy)
+			if got := v.Validate(tt.input); got != tt.want {
+				t.Errorf("Validate(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	for _, policy := range []string{"min", "min0", "length8", "classes5", "min12 max8"} {
+		if _, err := parsePasswordPolicy(policy); err == nil {
+			t.Errorf("parsePasswordPolicy(%q) error = nil, want error", policy)
+		}
+	}
+
+	v := newPasswordValidator(passwordPolicy{})
+	if v.Name() != "password" || reportedValue(&paramValidator{Validator: v, param: "min8"}, "hunter2") != "[redacted]" {
+		t.Errorf("Name() = %q, password values must be redacted", v.Name())
+	}
+	if got := reportedValue(newMultibyteValidator(), "value"); got != "value" {
+		t.Errorf("reportedValue() = %q, want the value", got)
+	}
+}
+
 func TestEqualIgnoreCaseValidator(t *testing.T) {
 	t.Parallel()
 
@@ -1798,8 +1970,12 @@ func TestValidators_Validate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			tag, msg := vals.Validate(tt.input)
+			v, msg := vals.Validate(tt.input)
 			hasErr := msg != ""
+			tag := ""
+			if v != nil {
+				tag = v.Name()
+			}
 			if hasErr != tt.wantErr {
 				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.input, msg, tt.wantErr)
 			}
@@ -1888,6 +2064,7 @@ func TestValidatorNames(t *testing.T) {
 
 		// Misc validators
 		{"multibyte", func() Validator { return newMultibyteValidator() }, "multibyte"},
+		{"no_emoji", func() Validator { return newNoEmojiValidator() }, "no_emoji"},
 		{"eq_ignore_case", func() Validator { return newEqualIgnoreCaseValidator("test") }, "eq_ignore_case"},
 		{"ne_ignore_case", func() Validator { return newNotEqualIgnoreCaseValidator("test") }, "ne_ignore_case"},
 	}
@@ -2414,3 +2591,263 @@ func TestMACValidator(t *testing.T) {
 		t.Errorf("Name() = %q, want %q", v.Name(), "mac")
 	}
 }
+
+func TestOutlierValidator(t *testing.T) {
+	t.Parallel()
+
+	// mean 10, population stddev 2
+	column := []string{"8", "12", "8", "12", "", "n/a"}
+	v := newOutlierValidator(2).withColumnValues(column)
+
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"10", false},
+		{"14", false}, // exactly 2 sigma
+		{"6", false},
+		{"14.5", true},
+		{"5", true},
+		{"", false}, // empty is valid
+		{"abc", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			msg := v.Validate(tt.input)
+			hasErr := msg != ""
+			if hasErr != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.input, msg, tt.wantErr)
+			}
+		})
+	}
+
+	if got, want := v.Validate("20"), "value must be within 2 standard deviations of the column mean (10 ± 2)"; got != want {
+		t.Errorf("Validate(%q) = %q, want %q", "20", got, want)
+	}
+	if v.Name() != "outlier" {
+		t.Errorf("Name() = %q, want %q", v.Name(), "outlier")
+	}
+
+	t.Run("unbound or empty column accepts everything", func(t *testing.T) {
+		t.Parallel()
+		for _, v := range []Validator{newOutlierValidator(3), newOutlierValidator(3).withColumnValues([]string{"", "x"})} {
+			if msg := v.Validate("1e9"); msg != "" {
+				t.Errorf("Validate() = %q, want no error", msg)
+			}
+		}
+	})
+}
+
+func TestPercentileValidator(t *testing.T) {
+	t.Parallel()
+
+	// 0..100 in steps of 1, so percentile p is exactly p
+	column := make([]string, 101)
+	for i := range column {
+		column[i] = strconv.Itoa(i)
+	}
+	v := newPercentileValidator(5, 95).withColumnValues(column)
+
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{"50", false},
+		{"5", false},
+		{"95", false},
+		{"4.9", true},
+		{"95.1", true},
+		{"", false}, // empty is valid
+		{"abc", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			msg := v.Validate(tt.input)
+			hasErr := msg != ""
+			if hasErr != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v, wantErr %v", tt.input, msg, tt.wantErr)
+			}
+		})
+	}
+
+	if v.Name() != "percentile" {
+		t.Errorf("Name() = %q, want %q", v.Name(), "percentile")
+	}
+
+	t.Run("interpolates between ranks", func(t *testing.T) {
+		t.Parallel()
+		if got := percentileOf([]float64{10, 20}, 25); got != 12.5 {
+			t.Errorf("percentileOf() = %v, want 12.5", got)
+		}
+	})
+}
+
+func TestMonotonicValidator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		strict bool
+		values []string
+		want   []string
+	}{
+		{
+			name:   "increasing numbers",
+			strict: true,
+			values: []string{"1", "2", "10", "", "11"},
+			want:   []string{"", "", "", "", ""},
+		},
+		{
+			name:   "increasing rejects repeat and reports only first",
+			strict: true,
+			values: []string{"1", "2", "2", "1", "0"},
+			want:   []string{"", "", "value must be greater than the previous value (2)", "", ""},
+		},
+		{
+			name:   "nondecreasing allows repeat",
+			strict: false,
+			values: []string{"1", "1", "2", "1.5"},
+			want:   []string{"", "", "", "value must not be less than the previous value (2)"},
+		},
+		{
+			name:   "timestamps compare lexically",
+			strict: true,
+			values: []string{"2024-01-01T00:00:00Z", "2024-01-02T00:00:00Z", "2023-12-31T23:59:59Z"},
+			want:   []string{"", "", "value must be greater than the previous value (2024-01-02T00:00:00Z)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			v := newMonotonicValidator(tt.strict).withColumnValues(tt.values)
+			got := make([]string, len(tt.values))
+			for i, value := range tt.values {
+				got[i] = v.Validate(value)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if got := newMonotonicValidator(true).Name(); got != "increasing" {
+		t.Errorf("Name() = %q, want %q", got, "increasing")
+	}
+	if got := newMonotonicValidator(false).Name(); got != "nondecreasing" {
+		t.Errorf("Name() = %q, want %q", got, "nondecreasing")
+	}
+}
+
+func TestGroupValidators(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		tag     string
+		input   string
+		wantMsg string
+	}{
+		{"or accepts first", "or(email|e164)", "user@example.com", ""},
+		{"or accepts second", "or(email|e164)", "+14155552671", ""},
+		{"or rejects", "or(email|e164)", "call me", "value must satisfy at least one of: email, e164"},
+		{"or with params", "or(len=0|min=10)", "5", "value must satisfy at least one of: len=0, min=10"},
+		{"not accepts", "not(contains=test)", "production", ""},
+		{"not rejects", "not(contains=test)", "test-user", "value must not satisfy contains=test"},
+		{"and reports failing member", "or(and(numeric|len=5)|uuid)", "123", "value must satisfy at least one of: and(numeric|len=5), uuid"},
+		{"nested and accepts", "or(and(numeric|len=5)|uuid)", "12345", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			vals, _, err := parseValidateTag(tt.tag, true)
+			if err != nil {
+				t.Fatalf("parseValidateTag(%q) error = %v", tt.tag, err)
+			}
+			_, msg := vals.Validate(tt.input)
+			if msg != tt.wantMsg {
+				t.Errorf("Validate(%q) = %q, want %q", tt.input, msg, tt.wantMsg)
+			}
+		})
+	}
+
+	t.Run("and returns first member message", func(t *testing.T) {
+		t.Parallel()
+		v := newAndValidator(validators{newNumericValidator(), newEmailValidator()})
+		if got := v.Validate("abc"); got != newNumericValidator().Validate("abc") {
+			t.Errorf("Validate() = %q, want numeric message", got)
+		}
+	})
+}
+
+// TestValidators_EmptyValueDefaults pins how each validator treats an empty
+// value without omitempty, as documented in the README.
+func TestValidators_EmptyValueDefaults(t *testing.T) {
+	t.Parallel()
+
+	acceptEmpty := []string{
+		"alpha", "alphanumeric", "alphanumunicode", "alphaspace", "alphaunicode", "ascii",
+		"datetime=2006-01-02", "e164", "endsnotwith=a", "excludes=a", "excludesall=a", "excludesrune=a",
+		"hexadecimal", "hexcolor", "hsl", "hsla", "lang=ja", "latitude", "longitude", "lowercase", "mac",
+		"ne_ignore_case=a", "no_emoji", "not_in_list=a", "numeric", "printascii", "rgb", "rgba", "startsnotwith=a", "uppercase", "url_encoded",
+	}
+	rejectEmpty := []string{
+		"boolean", "cidr", "cidrv4", "cidrv6", "contains=a", "containsany=a", "containsrune=a", "datauri",
+		"email", "endswith=a", "eq=1", "eq_ignore_case=a", "fqdn", "gt=1", "gte=1", "hostname",
+		"hostname_port", "hostname_rfc1123", "http_url", "https_url", "ip4_addr", "ip6_addr", "ip_addr",
+		"len=1", "lt=1", "lte=1", "max=1", "min=1", "multibyte", "ne=1", "number", "oneof=a b", "oneof_file=a",
+		"required", "startswith=a", "ulid", "uri", "url", "uuid", "uuid3", "uuid4", "uuid5",
+	}
+
+	check := func(tags []string, wantErr bool) {
+		for _, tag := range tags {
+			vals, _, err := parseValidateTag(tag, true)
+			if err != nil {
+				t.Errorf("parseValidateTag(%q) error = %v", tag, err)
+				continue
+			}
+			if _, msg := vals.Validate(""); (msg != "") != wantErr {
+				t.Errorf("%s: Validate(\"\") = %q, want error %v", tag, msg, wantErr)
+			}
+		}
+	}
+	check(acceptEmpty, false)
+	check(rejectEmpty, true)
+}
+
+func TestOmitEmptyValidators(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		tag  string
+		want []string
+	}{
+		{"adds omitempty", "email", []string{"omitempty", "email"}},
+		{"keeps required first", "email,required,len=10", []string{"required", "omitempty", "email", "len"}},
+		{"deduplicates omitempty", "omitempty,email", []string{"omitempty", "email"}},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			vals, _, err := parseValidateTag(tt.tag, true)
+			if err != nil {
+				t.Fatalf("parseValidateTag() error = %v", err)
+			}
+			var got []string
+			for _, v := range omitEmptyValidators(vals) {
+				got = append(got, v.Name())
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
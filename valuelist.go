@@ -0,0 +1,272 @@
+package fileprep
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// readValueList reads a value list: one value per line, with surrounding
+// whitespace trimmed. Blank lines and lines starting with # are skipped.
+func readValueList(r io.Reader) ([]string, error) {
+	if r == nil {
+		return nil, ErrNilReader
+	}
+	var values []string
+	scanner := bufio.NewScanner(r)
+	for first := true; scanner.Scan(); first = false {
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\uFEFF")
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		values = append(values, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// valueListValidator is a Validator that checks values against a list
+// loaded with WithValueList. Before any row is validated, Process passes
+// the list named by listName to withValues and validates rows with the
+// returned validator, so the tag-parsed instance stays unchanged.
+type valueListValidator interface {
+	Validator
+	listName() string
+	withValues(values []string) Validator
+}
+
+// withValueLists returns a copy of the struct info in which every value
+// list validator, including those inside or, and, and not groups, is bound
+// to its list in lists. It returns an error wrapping ErrInvalidOption for a
+// list that was not loaded, and si itself when no field uses a value list.
+func (si *structInfo) withValueLists(lists map[string][]string) (*structInfo, error) {
+	var bound *structInfo
+	for i, fi := range si.Fields {
+		vs, changed, err := bindValueLists(fi.Validators, lists)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", fi.Name, err)
+		}
+		warn, warnChanged, err := bindValueLists(fi.WarnValidators, lists)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", fi.Name, err)
+		}
+		if !changed && !warnChanged {
+			continue
+		}
+		if bound == nil {
+			bound = &structInfo{Fields: slices.Clone(si.Fields)}
+		}
+		bound.Fields[i].Validators = vs
+		bound.Fields[i].WarnValidators = warn
+	}
+	if bound == nil {
+		return si, nil
+	}
+	return bound, nil
+}
+
+// bindValueLists returns a copy of vs with every value list validator bound
+// to its list, and whether any was.
+func bindValueLists(vs validators, lists map[string][]string) (validators, bool, error) {
+	var out validators
+	for i, v := range vs {
+		b, changed, err := bindValueList(v, lists)
+		if err != nil {
+			return nil, false, err
+		}
+		if !changed {
+			continue
+		}
+		if out == nil {
+			out = slices.Clone(vs)
+		}
+		out[i] = b
+	}
+	if out == nil {
+		return vs, false, nil
+	}
+	return out, true, nil
+}
+
+// bindValueList binds v, or the members of a group v, to their lists.
+func bindValueList(v Validator, lists map[string][]string) (Validator, bool, error) {
+	switch v := v.(type) {
+	case *paramValidator:
+		b, changed, err := bindValueList(v.Validator, lists)
+		if !changed || err != nil {
+			return v, false, err
+		}
+		return &paramValidator{Validator: b, param: v.param}, true, nil
+	case valueListValidator:
+		values, ok := lists[v.listName()]
+		if !ok {
+			return nil, false, fmt.Errorf("%w: %s=%s needs a list loaded with WithValueList(%q, ...)", ErrInvalidOption, v.Name(), v.listName(), v.listName())
+		}
+		return v.withValues(values), true, nil
+	case *orValidator:
+		members, changed, err := bindValueLists(v.members, lists)
+		if !changed || err != nil {
+			return v, false, err
+		}
+		return &orValidator{members: members, errMsg: v.errMsg}, true, nil
+	case *andValidator:
+		members, changed, err := bindValueLists(v.members, lists)
+		if !changed || err != nil {
+			return v, false, err
+		}
+		return &andValidator{members: members}, true, nil
+	case *notValidator:
+		member, changed, err := bindValueList(v.member, lists)
+		if !changed || err != nil {
+			return v, false, err
+		}
+		return &notValidator{member: member, errMsg: v.errMsg}, true, nil
+	default:
+		return v, false, nil
+	}
+}
+
+// notInListValidator validates that a value contains none of the words and
+// phrases of a denylist. Words match whole words, ignoring case, so a
+// listed "ass" does not reject "class". Entries with characters of scripts
+// written without spaces between words, such as Han, kana, and Thai, match
+// anywhere in the value.
+type notInListValidator struct {
+	list       string
+	words      map[string]bool // single-word entries
+	phrases    []string        // multi-word entries, words joined by one space
+	substrings []string        // entries in scripts written without spaces
+	errMsg     string          // pre-built error message
+}
+
+// newNotInListValidator creates a new not_in_list validator for the list
+// named list. It passes every value until withValues binds it to the list.
+func newNotInListValidator(list string) *notInListValidator {
+	return &notInListValidator{
+		list:   list,
+		errMsg: "value must not contain words from " + list,
+	}
+}
+
+// listName returns the name of the denylist
+func (v *notInListValidator) listName() string {
+	return v.list
+}
+
+// withValues returns a copy of the validator that rejects the entries of
+// values
+func (v *notInListValidator) withValues(values []string) Validator {
+	bound := &notInListValidator{list: v.list, words: make(map[string]bool), errMsg: v.errMsg}
+	for _, entry := range values {
+		entry = strings.ToLower(entry)
+		if strings.IndexFunc(entry, isUnspacedScript) >= 0 {
+			bound.substrings = append(bound.substrings, entry)
+			continue
+		}
+		switch words := listWords(entry); len(words) {
+		case 0:
+		case 1:
+			bound.words[words[0]] = true
+		default:
+			bound.phrases = append(bound.phrases, strings.Join(words, " "))
+		}
+	}
+	return bound
+}
+
+// Validate checks that no word, phrase, or substring of the list is in the
+// value
+func (v *notInListValidator) Validate(value string) string {
+	lower := strings.ToLower(value)
+	for _, s := range v.substrings {
+		if strings.Contains(lower, s) {
+			return v.errMsg
+		}
+	}
+	words := listWords(lower)
+	for _, w := range words {
+		if v.words[w] {
+			return v.errMsg
+		}
+	}
+	if len(v.phrases) > 0 {
+		joined := " " + strings.Join(words, " ") + " "
+		for _, phrase := range v.phrases {
+			if strings.Contains(joined, " "+phrase+" ") {
+				return v.errMsg
+			}
+		}
+	}
+	return ""
+}
+
+// Name returns the validator name
+func (v *notInListValidator) Name() string {
+	return notInListTagValue
+}
+
+// listWords splits s into words: runs of letters, digits, and marks.
+func listWords(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+}
+
+// isUnspacedScript reports whether r belongs to a script written without
+// spaces between words.
+func isUnspacedScript(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai)
+}
+
+// oneOfFileValidator validates that a value is one of the values of a list,
+// like oneof for enumerations too large for a struct tag
+type oneOfFileValidator struct {
+	list  string
+	oneOf *oneOfValidator // nil until withValues binds the list
+}
+
+// newOneOfFileValidator creates a new oneof_file validator for the list
+// named list. It rejects every value until withValues binds it to the list.
+func newOneOfFileValidator(list string) *oneOfFileValidator {
+	return &oneOfFileValidator{list: list}
+}
+
+// listName returns the name of the list of allowed values
+func (v *oneOfFileValidator) listName() string {
+	return v.list
+}
+
+// withValues returns a copy of the validator that accepts the values. The
+// message names the list rather than its values, which may be many.
+func (v *oneOfFileValidator) withValues(values []string) Validator {
+	oneOf := newOneOfValidator(values)
+	oneOf.errMsg = v.errMsg()
+	return &oneOfFileValidator{list: v.list, oneOf: oneOf}
+}
+
+// errMsg returns the error message
+func (v *oneOfFileValidator) errMsg() string {
+	return "value must be one of the values in " + v.list
+}
+
+// Validate checks if the value is one of the listed values
+func (v *oneOfFileValidator) Validate(value string) string {
+	if v.oneOf == nil {
+		return v.errMsg()
+	}
+	return v.oneOf.Validate(value)
+}
+
+// Name returns the validator name
+func (v *oneOfFileValidator) Name() string {
+	return oneOfFileTagValue
+}
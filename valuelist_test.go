@@ -0,0 +1,174 @@
+package fileprep
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileparser"
+)
+
+func TestReadValueList(t *testing.T) {
+	t.Parallel()
+
+	got, err := readValueList(strings.NewReader("\uFEFF# comment\n  darn \n\nheck\r\n#not a value\nbad word\n"))
+	if err != nil {
+		t.Fatalf("readValueList() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"darn", "heck", "bad word"}, got); diff != "" {
+		t.Errorf("readValueList() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNotInListValidator(t *testing.T) {
+	t.Parallel()
+
+	v := newNotInListValidator("badwords.txt").withValues([]string{"Darn", "bad word", "ばか"})
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"clean text", "What a lovely day", false},
+		{"listed word", "Well, darn it", true},
+		{"case is ignored", "DARN!", true},
+		{"word inside another word", "darned socks", false},
+		{"phrase", "That is a bad   word.", true},
+		{"phrase words apart", "a bad day for a word", false},
+		{"unspaced script", "このばかやろう", true},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if msg := v.Validate(tt.input); (msg != "") != tt.wantErr {
+				t.Errorf("Validate(%q) = %q, wantErr %v", tt.input, msg, tt.wantErr)
+			}
+		})
+	}
+
+	if msg := v.Validate("darn"); msg != "value must not contain words from badwords.txt" {
+		t.Errorf("message = %q", msg)
+	}
+	if msg := newNotInListValidator("badwords.txt").Validate("darn"); msg != "" {
+		t.Errorf("unbound Validate() = %q, want pass", msg)
+	}
+}
+
+func TestWithValueList(t *testing.T) {
+	t.Parallel()
+
+	const badwords = "# moderation list\ndarn\nheck\n"
+
+	t.Run("rejects listed words", func(t *testing.T) {
+		t.Parallel()
+
+		type comment struct {
+			Body  string `validate:"not_in_list=badwords.txt"`
+			Title string `validate:"or(not_in_list=badwords.txt|eq_ignore_case=heck)"`
+		}
+		var comments []comment
+		_, result, err := NewProcessor(fileparser.CSV, WithValueList("badwords.txt", strings.NewReader(badwords))).
+			Process(strings.NewReader("body,title\nnice post,hello\ndarn it,Heck\nfine,oh heck\n"), &comments)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		var got [][2]any
+		for _, e := range result.ValidationErrors() {
+			got = append(got, [2]any{e.Row, e.Column})
+		}
+		want := [][2]any{{2, "body"}, {3, "title"}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("errors mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("missing list", func(t *testing.T) {
+		t.Parallel()
+
+		type comment struct {
+			Body string `validate:"not_in_list=badwords.txt"`
+		}
+		var comments []comment
+		_, _, err := NewProcessor(fileparser.CSV).Process(strings.NewReader("body\nhi\n"), &comments)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("Process() error = %v, want ErrInvalidOption", err)
+		}
+	})
+
+	t.Run("unreadable list", func(t *testing.T) {
+		t.Parallel()
+
+		type comment struct {
+			Body string
+		}
+		readErr := errors.New("disk error")
+		var comments []comment
+		_, _, err := NewProcessor(fileparser.CSV, WithValueList("badwords.txt", iotest.ErrReader(readErr))).
+			Process(strings.NewReader("body\nhi\n"), &comments)
+		if !errors.Is(err, ErrInvalidOption) || !errors.Is(err, readErr) {
+			t.Errorf("Process() error = %v, want ErrInvalidOption wrapping the read error", err)
+		}
+	})
+}
+
+func TestOneOfFileValidator(t *testing.T) {
+	t.Parallel()
+
+	const prefectures = "# 47 prefectures, shortened\nHokkaido\nTokyo\nOsaka\nOkinawa\n"
+
+	type address struct {
+		Prefecture string `validate:"oneof_file=prefectures.txt"`
+	}
+
+	t.Run("accepts listed values", func(t *testing.T) {
+		t.Parallel()
+
+		var addresses []address
+		_, result, err := NewProcessor(fileparser.CSV,
+			WithValueList("prefectures.txt", strings.NewReader(prefectures)), WithFixSuggestions()).
+			Process(strings.NewReader("prefecture,city\nTokyo,Shibuya\nOsak,Kita\nMars,\n,Naha\n"), &addresses)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		var got []string
+		for _, e := range result.ValidationErrors() {
+			got = append(got, e.Value+": "+e.Message())
+		}
+		want := []string{
+			`Osak: value must be one of the values in prefectures.txt (did you mean "Osaka"?)`,
+			"Mars: value must be one of the values in prefectures.txt",
+			": value must be one of the values in prefectures.txt",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("errors mismatch (-want +got):\n%s", diff)
+		}
+		wantSuggestions := []FixSuggestion{{Row: 2, Column: "prefecture", Field: "Prefecture", Value: "Osak", Suggested: "Osaka", Rule: FixClosestMatch}}
+		if diff := cmp.Diff(wantSuggestions, result.Suggestions); diff != "" {
+			t.Errorf("Suggestions mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("missing list", func(t *testing.T) {
+		t.Parallel()
+
+		var addresses []address
+		_, _, err := NewProcessor(fileparser.CSV, WithValueList("badwords.txt", strings.NewReader("darn\n"))).
+			Process(strings.NewReader("prefecture\nTokyo\n"), &addresses)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("Process() error = %v, want ErrInvalidOption", err)
+		}
+	})
+
+	t.Run("unbound rejects every value", func(t *testing.T) {
+		t.Parallel()
+
+		if msg := newOneOfFileValidator("prefectures.txt").Validate("Tokyo"); msg == "" {
+			t.Error("unbound Validate() passed")
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package fileprep
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// workerBatchRows is the number of consecutive rows a WithWorkers goroutine
+// takes at a time.
+const workerBatchRows = 256
+
+// concurrentRow is a row whose first pass ran in a WithWorkers goroutine.
+// Its errors, warnings, suggestions, and changes are entries from to to of
+// the result of its batch, so they can be merged in input order.
+type concurrentRow struct {
+	rowPass
+	filtered bool
+	value    reflect.Value
+	batch    *ProcessResult
+	from, to resultMark
+}
+
+// resultMark is the length of the ordered slices of a ProcessResult.
+type resultMark struct {
+	errors, warnings, suggestions, changes int
+}
+
+// mark returns the current lengths of the ordered slices of r.
+func (r *ProcessResult) mark() resultMark {
+	return resultMark{
+		errors:      len(r.Errors),
+		warnings:    len(r.Warnings),
+		suggestions: len(r.Suggestions),
+		changes:     len(r.changes),
+	}
+}
+
+// appendRow appends the entries from to to of batch to r.
+func (r *ProcessResult) appendRow(batch *ProcessResult, from, to resultMark) {
+	r.Errors = append(r.Errors, batch.Errors[from.errors:to.errors]...)
+	r.Warnings = append(r.Warnings, batch.Warnings[from.warnings:to.warnings]...)
+	r.Suggestions = append(r.Suggestions, batch.Suggestions[from.suggestions:to.suggestions]...)
+	r.changes = append(r.changes, batch.changes[from.changes:to.changes]...)
+}
+
+// firstPassConcurrently filters and pads records, then runs the first pass
+// over the remaining rows with p.workers goroutines. Each batch of rows has
+// its own result, whose repair counts are added to result here; each row
+// has its own struct value.
+func (p *Processor) firstPassConcurrently(
+	run *processRun,
+	records [][]string,
+	firstRowIdx int,
+	result *ProcessResult,
+	out *runOutput,
+) []concurrentRow {
+	rows := make([]concurrentRow, len(records))
+	for rowIdx := range records {
+		rows[rowIdx].filtered = !p.prepareRow(run, records, rowIdx, result, out)
+	}
+
+	batches := make([]ProcessResult, (len(records)+workerBatchRows-1)/workerBatchRows)
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(p.workers, len(batches)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range next {
+				batch := &batches[b]
+				for rowIdx := b * workerBatchRows; rowIdx < min((b+1)*workerBatchRows, len(records)); rowIdx++ {
+					row := &rows[rowIdx]
+					if row.filtered {
+						continue
+					}
+					row.batch, row.from = batch, batch.mark()
+					row.value = reflect.New(run.structType).Elem()
+					row.rowPass = p.firstPass(run, records[rowIdx], firstRowIdx+rowIdx+1, row.value, batch)
+					row.to = batch.mark()
+				}
+			}
+		}()
+	}
+	for b := range batches {
+		next <- b
+	}
+	close(next)
+	wg.Wait()
+
+	for _, batch := range batches {
+		for column, counts := range batch.Repairs {
+			result.repair(column, func(c *ColumnRepairs) {
+				c.Padded += counts.Padded
+				c.Truncated += counts.Truncated
+				c.Clamped += counts.Clamped
+				c.Cleared += counts.Cleared
+				c.ConversionFallbacks += counts.ConversionFallbacks
+			})
+		}
+	}
+	return rows
+}
+
+// validatesInOrder reports whether a validator of the struct info compares a
+// row with the rows before it, so rows must be validated one at a time.
+func (si *structInfo) validatesInOrder() bool {
+	for _, fi := range si.Fields {
+		if slices.ContainsFunc(fi.Validators, dependsOnRowOrder) ||
+			slices.ContainsFunc(fi.WarnValidators, dependsOnRowOrder) {
+			return true
+		}
+	}
+	return false
+}
+
+// dependsOnRowOrder reports whether v, or a member of a group v, keeps state
+// from one row to the next.
+func dependsOnRowOrder(v Validator) bool {
+	switch v := v.(type) {
+	case *paramValidator:
+		return dependsOnRowOrder(v.Validator)
+	case *monotonicValidator:
+		return true
+	case *orValidator:
+		return slices.ContainsFunc(v.members, dependsOnRowOrder)
+	case *andValidator:
+		return slices.ContainsFunc(v.members, dependsOnRowOrder)
+	case *notValidator:
+		return dependsOnRowOrder(v.member)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,328 @@
+package fileprep
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nao1215/fileparser"
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxParseOptions controls how XLSX input is parsed.
+// The zero value matches fileparser, which reads the cached value of every
+// cell of the first sheet.
+type xlsxParseOptions struct {
+	formulasAsEmpty bool             // replace formula cells with empty strings
+	errorPolicy     ExcelErrorPolicy // how to treat error cells such as #N/A
+	fillMergedCells bool             // copy the value of a merged range into every cell
+	dateLayout      string           // Go time layout for date-formatted cells
+}
+
+// isDefault reports whether no parse option was set.
+func (o xlsxParseOptions) isDefault() bool {
+	return o == xlsxParseOptions{}
+}
+
+// excelErrorMode selects how ExcelErrorPolicy treats error cells.
+type excelErrorMode int
+
+const (
+	excelErrorKeep excelErrorMode = iota
+	excelErrorEmpty
+	excelErrorValue
+	excelErrorInvalid
+)
+
+// ExcelErrorPolicy selects how XLSX error cells (#N/A, #DIV/0!, #REF!, ...)
+// are read. The zero value is ExcelErrorKeep.
+type ExcelErrorPolicy struct {
+	mode  excelErrorMode
+	value string
+}
+
+// ExcelErrorKeep reads error cells as their error text, such as "#N/A".
+// This is the default.
+func ExcelErrorKeep() ExcelErrorPolicy {
+	return ExcelErrorPolicy{}
+}
+
+// ExcelErrorEmpty reads error cells as empty strings, so they behave like
+// blank cells for prep and validate tags.
+func ExcelErrorEmpty() ExcelErrorPolicy {
+	return ExcelErrorPolicy{mode: excelErrorEmpty}
+}
+
+// ExcelErrorValue reads error cells as the given sentinel value.
+func ExcelErrorValue(value string) ExcelErrorPolicy {
+	return ExcelErrorPolicy{mode: excelErrorValue, value: value}
+}
+
+// ExcelErrorInvalid reads error cells as empty strings and reports each one
+// as a ValidationError with the tag "excel_error", which marks its row
+// invalid. The error's Value is the error text.
+func ExcelErrorInvalid() ExcelErrorPolicy {
+	return ExcelErrorPolicy{mode: excelErrorInvalid}
+}
+
+// excelErrorTagName is the validation tag reported for error cells under
+// ExcelErrorInvalid.
+const excelErrorTagName = "excel_error"
+
+// excelErrorCell is an error cell reported under ExcelErrorInvalid.
+type excelErrorCell struct {
+	row    int // 1-based data row number (excluding header)
+	column string
+	value  string
+}
+
+// parseXLSX parses the first sheet of an XLSX workbook with the given
+// options. The first row is the header, whose column names must be unique
+// unless allowDuplicateHeaders is set. Under ExcelErrorInvalid it also
+// returns the error cells of the data rows.
+func parseXLSX(data []byte, opts xlsxParseOptions, allowDuplicateHeaders bool) (*fileparser.TableData, []excelErrorCell, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open XLSX: %w", err)
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, nil, fmt.Errorf("%w: XLSX has no sheets", ErrNoHeader)
+	}
+	sheet := sheets[0]
+	reader, err := newXLSXCellReader(f, sheet, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read XLSX sheet %q: %w", sheet, err)
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("%w: empty XLSX data", ErrNoHeader)
+	}
+
+	var errorCells []excelErrorCell
+	for i, record := range rows[1:] {
+		for j := range record {
+			cellName, err := excelize.CoordinatesToCellName(j+1, i+2)
+			if err != nil {
+				return nil, nil, err
+			}
+			value, isError, err := reader.read(cellName, record[j])
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read XLSX cell %s: %w", cellName, err)
+			}
+			record[j] = value
+			if isError && opts.errorPolicy.mode == excelErrorInvalid {
+				column := ""
+				if j < len(rows[0]) {
+					column = rows[0][j]
+				}
+				errorCells = append(errorCells, excelErrorCell{row: i + 1, column: column, value: value})
+				record[j] = ""
+			}
+		}
+	}
+
+	if opts.fillMergedCells {
+		if rows, err = fillMergedCells(f, sheet, rows); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	headers := rows[0]
+	if !allowDuplicateHeaders {
+		if err := validateHeaderNames(headers); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &fileparser.TableData{
+		Headers: headers,
+		Records: rows[1:],
+	}, errorCells, nil
+}
+
+// xlsxCellReader applies the parse options to the cells of one sheet.
+type xlsxCellReader struct {
+	f        *excelize.File
+	sheet    string
+	opts     xlsxParseOptions
+	date1904 bool         // the workbook counts dates from 1904-01-01
+	isDate   map[int]bool // whether a style index has a date number format
+}
+
+// newXLSXCellReader returns a cell reader for sheet.
+func newXLSXCellReader(f *excelize.File, sheet string, opts xlsxParseOptions) (*xlsxCellReader, error) {
+	r := &xlsxCellReader{f: f, sheet: sheet, opts: opts, isDate: make(map[int]bool)}
+	if opts.dateLayout != "" {
+		props, err := f.GetWorkbookProps()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read XLSX workbook properties: %w", err)
+		}
+		r.date1904 = props.Date1904 != nil && *props.Date1904
+	}
+	return r, nil
+}
+
+// read applies the formula, error, and date options to the formatted value
+// of one cell. It reports whether the cell holds an error.
+func (r *xlsxCellReader) read(cellName, value string) (string, bool, error) {
+	if r.opts.formulasAsEmpty {
+		formula, err := r.f.GetCellFormula(r.sheet, cellName)
+		if err != nil {
+			return "", false, err
+		}
+		if formula != "" {
+			return "", false, nil
+		}
+	}
+
+	if r.opts.errorPolicy.mode != excelErrorKeep {
+		cellType, err := r.f.GetCellType(r.sheet, cellName)
+		if err != nil {
+			return "", false, err
+		}
+		if cellType == excelize.CellTypeError {
+			switch r.opts.errorPolicy.mode {
+			case excelErrorEmpty:
+				return "", true, nil
+			case excelErrorValue:
+				return r.opts.errorPolicy.value, true, nil
+			default:
+				return value, true, nil
+			}
+		}
+	}
+
+	if r.opts.dateLayout != "" {
+		return r.formatDate(cellName, value)
+	}
+	return value, false, nil
+}
+
+// formatDate formats a cell with a date number format with the configured
+// layout, reading its serial number in the workbook's date system. Other
+// cells keep their formatted value.
+func (r *xlsxCellReader) formatDate(cellName, value string) (string, bool, error) {
+	styleIdx, err := r.f.GetCellStyle(r.sheet, cellName)
+	if err != nil {
+		return "", false, err
+	}
+	isDate, err := r.isDateStyle(styleIdx)
+	if err != nil || !isDate {
+		return value, false, err
+	}
+
+	raw, err := r.f.GetCellValue(r.sheet, cellName, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return "", false, err
+	}
+	serial, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return value, false, nil //nolint:nilerr // text in a date-formatted cell is kept as is
+	}
+	t, err := excelize.ExcelDateToTime(serial, r.date1904)
+	if err != nil {
+		return value, false, nil //nolint:nilerr // serials outside the date range are kept as is
+	}
+	return t.Format(r.opts.dateLayout), false, nil
+}
+
+// isDateStyle reports whether the number format of a style shows a date or
+// time. Style 0 is the workbook default, General.
+func (r *xlsxCellReader) isDateStyle(styleIdx int) (bool, error) {
+	if styleIdx == 0 {
+		return false, nil
+	}
+	if isDate, ok := r.isDate[styleIdx]; ok {
+		return isDate, nil
+	}
+	style, err := r.f.GetStyle(styleIdx)
+	if err != nil {
+		return false, err
+	}
+	isDate := isDateNumFmt(style.NumFmt)
+	if style.CustomNumFmt != nil {
+		isDate = isDateFormatCode(*style.CustomNumFmt)
+	}
+	r.isDate[styleIdx] = isDate
+	return isDate, nil
+}
+
+// isDateNumFmt reports whether a built-in number format ID is a date or
+// time format.
+func isDateNumFmt(id int) bool {
+	return (id >= 14 && id <= 22) || (id >= 27 && id <= 36) || (id >= 45 && id <= 47) || (id >= 50 && id <= 58)
+}
+
+// isDateFormatCode reports whether a custom number format code shows a date
+// or time: it has a y, m, d, h, or s token outside quoted text, brackets
+// such as [Red] or [$-409], and escaped characters.
+func isDateFormatCode(code string) bool {
+	inQuotes, inBrackets := false, false
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case inQuotes:
+			inQuotes = c != '"'
+		case inBrackets:
+			inBrackets = c != ']'
+		case c == '"':
+			inQuotes = true
+		case c == '[':
+			inBrackets = true
+		case c == '\\' || c == '_' || c == '*':
+			i++ // skip the escaped or padding character
+		case strings.ContainsRune("yYmMdDhHsS", rune(c)):
+			return true
+		}
+	}
+	return false
+}
+
+// fillMergedCells copies the value of the top-left cell of every merged
+// range into all the cells the range covers, growing rows as needed.
+func fillMergedCells(f *excelize.File, sheet string, rows [][]string) ([][]string, error) {
+	merged, err := f.GetMergeCells(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XLSX merged cells: %w", err)
+	}
+	for _, mc := range merged {
+		startCol, startRow, err := excelize.CellNameToCoordinates(mc.GetStartAxis())
+		if err != nil {
+			return nil, err
+		}
+		endCol, endRow, err := excelize.CellNameToCoordinates(mc.GetEndAxis())
+		if err != nil {
+			return nil, err
+		}
+
+		value := cell(rowAt(rows, startRow-1), startCol-1)
+		for len(rows) < endRow {
+			rows = append(rows, nil)
+		}
+		for r := startRow - 1; r < endRow; r++ {
+			if len(rows[r]) < endCol {
+				rows[r] = append(rows[r], make([]string, endCol-len(rows[r]))...)
+			}
+			for c := startCol - 1; c < endCol; c++ {
+				rows[r][c] = value
+			}
+		}
+	}
+	return rows, nil
+}
+
+// rowAt returns row i of rows, or nil if there is no such row.
+func rowAt(rows [][]string, i int) []string {
+	if i < len(rows) {
+		return rows[i]
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package fileprep
+
+import (
+	"archive/zip"
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testXLSX describes a one-sheet workbook for tests. sheetData is the
+// content of the sheetData element; cells use inline strings
+// (t="inlineStr"), numbers, formulas with cached values (<f> and <v>), and
+// errors (t="e").
+type testXLSX struct {
+	sheetData string
+	mergeRefs []string // merged ranges such as "A2:A3"
+	date1904  bool     // use the 1904 date system
+	dateStyle string   // custom number format of style 1, e.g. "yyyy/mm/dd"
+}
+
+// newTestXLSX builds a one-sheet workbook from the XML of the sheetData
+// element's content.
+func newTestXLSX(t *testing.T, sheetData string, mergeRefs ...string) []byte {
+	t.Helper()
+	return testXLSX{sheetData: sheetData, mergeRefs: mergeRefs}.build(t)
+}
+
+// build returns the workbook as XLSX bytes.
+func (x testXLSX) build(t *testing.T) []byte {
+	t.Helper()
+
+	mergeCells := ""
+	if len(x.mergeRefs) > 0 {
+		mergeCells = `<mergeCells count="` + strconv.Itoa(len(x.mergeRefs)) + `">`
+		for _, ref := range x.mergeRefs {
+			mergeCells += `<mergeCell ref="` + ref + `"/>`
+		}
+		mergeCells += `</mergeCells>`
+	}
+	workbookPr := ""
+	if x.date1904 {
+		workbookPr = `<workbookPr date1904="1"/>`
+	}
+
+	files := map[string]string{
+		"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`,
+		"_rels/.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`,
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+` + workbookPr + `<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`,
+		"xl/styles.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="1"><numFmt numFmtId="164" formatCode="` + x.dateStyle + `"/></numFmts>
+<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="1"><fill><patternFill patternType="none"/></fill></fills>
+<borders count="1"><border/></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>
+</styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<sheetData>` + x.sheetData + `</sheetData>` + mergeCells + `
+</worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip Create(%s) error = %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("zip Write(%s) error = %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// formulaAndErrorSheet has a header row and two data rows. B2 is a formula
+// with a cached value, B3 a formula that evaluates to #DIV/0!, and C2 a
+// literal #N/A error.
+const formulaAndErrorSheet = `<row r="1"><c r="A1" t="inlineStr"><is><t>id</t></is></c><c r="B1" t="inlineStr"><is><t>total</t></is></c><c r="C1" t="inlineStr"><is><t>rate</t></is></c></row>
+<row r="2"><c r="A2"><v>1</v></c><c r="B2"><f>A2*10</f><v>10</v></c><c r="C2" t="e"><v>#N/A</v></c></row>
+<row r="3"><c r="A3"><v>2</v></c><c r="B3" t="e"><f>A3/0</f><v>#DIV/0!</v></c><c r="C3"><v>0.5</v></c></row>`
+
+func TestParseXLSX(t *testing.T) {
+	t.Parallel()
+
+	data := newTestXLSX(t, formulaAndErrorSheet)
+
+	tests := []struct {
+		name           string
+		opts           xlsxParseOptions
+		wantRecords    [][]string
+		wantErrorCells []excelErrorCell
+	}{
+		{
+			name:        "formulas as empty",
+			opts:        xlsxParseOptions{formulasAsEmpty: true},
+			wantRecords: [][]string{{"1", "", "#N/A"}, {"2", "", "0.5"}},
+		},
+		{
+			name:        "errors as empty",
+			opts:        xlsxParseOptions{errorPolicy: ExcelErrorEmpty()},
+			wantRecords: [][]string{{"1", "10", ""}, {"2", "", "0.5"}},
+		},
+		{
+			name:        "errors as sentinel",
+			opts:        xlsxParseOptions{errorPolicy: ExcelErrorValue("ERR")},
+			wantRecords: [][]string{{"1", "10", "ERR"}, {"2", "ERR", "0.5"}},
+		},
+		{
+			name:        "errors as validation errors",
+			opts:        xlsxParseOptions{errorPolicy: ExcelErrorInvalid()},
+			wantRecords: [][]string{{"1", "10", ""}, {"2", "", "0.5"}},
+			wantErrorCells: []excelErrorCell{
+				{row: 1, column: "rate", value: "#N/A"},
+				{row: 2, column: "total", value: "#DIV/0!"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tableData, errorCells, err := parseXLSX(data, tt.opts, false)
+			if err != nil {
+				t.Fatalf("parseXLSX() error = %v", err)
+			}
+			if diff := cmp.Diff([]string{"id", "total", "rate"}, tableData.Headers); diff != "" {
+				t.Errorf("headers mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantRecords, tableData.Records); diff != "" {
+				t.Errorf("records mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantErrorCells, errorCells, cmp.AllowUnexported(excelErrorCell{})); diff != "" {
+				t.Errorf("error cells mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseXLSX_FillMergedCells(t *testing.T) {
+	t.Parallel()
+
+	// Region spans A2:A3, and the Q1 header spans B1:C1
+	data := newTestXLSX(t, `<row r="1"><c r="A1" t="inlineStr"><is><t>region</t></is></c><c r="B1" t="inlineStr"><is><t>Q1</t></is></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><t>East</t></is></c><c r="B2"><v>1</v></c><c r="C2"><v>2</v></c></row>
+<row r="3"><c r="B3"><v>3</v></c><c r="C3"><v>4</v></c></row>`, "A2:A3", "B1:C1")
+
+	if _, _, err := parseXLSX(data, xlsxParseOptions{fillMergedCells: true}, false); err == nil {
+		t.Error("parseXLSX() with a merged header and no duplicate headers allowed: error = nil")
+	}
+
+	tableData, _, err := parseXLSX(data, xlsxParseOptions{fillMergedCells: true}, true)
+	if err != nil {
+		t.Fatalf("parseXLSX() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"region", "Q1", "Q1"}, tableData.Headers); diff != "" {
+		t.Errorf("headers mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([][]string{{"East", "1", "2"}, {"East", "3", "4"}}, tableData.Records); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseXLSX_DateLayout(t *testing.T) {
+	t.Parallel()
+
+	// 45000 is 2023-03-15 in the 1900 system and 2027-03-16 in the 1904
+	// system; the text cell and the plain number are not dates
+	sheetData := `<row r="1"><c r="A1" t="inlineStr"><is><t>day</t></is></c><c r="B1" t="inlineStr"><is><t>qty</t></is></c></row>
+<row r="2"><c r="A2" s="1"><v>45000</v></c><c r="B2"><v>45000</v></c></row>
+<row r="3"><c r="A3" s="1" t="inlineStr"><is><t>unknown</t></is></c><c r="B3"><v>1</v></c></row>`
+
+	tests := []struct {
+		name     string
+		workbook testXLSX
+		want     [][]string
+	}{
+		{
+			name:     "1900 date system",
+			workbook: testXLSX{sheetData: sheetData, dateStyle: "dd/mm/yyyy"},
+			want:     [][]string{{"2023-03-15", "45000"}, {"unknown", "1"}},
+		},
+		{
+			name:     "1904 date system",
+			workbook: testXLSX{sheetData: sheetData, dateStyle: "dd/mm/yyyy", date1904: true},
+			want:     [][]string{{"2027-03-16", "45000"}, {"unknown", "1"}},
+		},
+		{
+			name:     "number format is not a date",
+			workbook: testXLSX{sheetData: sheetData, dateStyle: "0.00"},
+			want:     [][]string{{"45000.00", "45000"}, {"unknown", "1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tableData, _, err := parseXLSX(tt.workbook.build(t), xlsxParseOptions{dateLayout: "2006-01-02"}, false)
+			if err != nil {
+				t.Fatalf("parseXLSX() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, tableData.Records); diff != "" {
+				t.Errorf("records mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIsDateFormatCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{code: "yyyy-mm-dd", want: true},
+		{code: "h:mm AM/PM", want: true},
+		{code: "[$-409]d-mmm-yy;@", want: true},
+		{code: "#,##0.00", want: false},
+		{code: "[Red]0.00", want: false},
+		{code: `0" days"`, want: false},
+		{code: `\d0`, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isDateFormatCode(tt.code); got != tt.want {
+			t.Errorf("isDateFormatCode(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package fileprep
+
+import (
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// xlsxDataSheet and xlsxErrorsSheet are the sheet names of XLSX output.
+	xlsxDataSheet   = "Data"
+	xlsxErrorsSheet = "Errors"
+	// xlsxErrorFill is the background color of cells with errors.
+	xlsxErrorFill = "FFC7CE"
+)
+
+// xlsxErrorSheet holds the errors that WithXLSXErrorSheet writes to XLSX
+// output: the highlighted cells of the data sheet and the Errors sheet rows.
+type xlsxErrorSheet struct {
+	issues []reportIssue
+	cells  map[[2]int]bool // output record index and column index of cells with errors
+}
+
+// newXLSXErrorSheet locates the errors in the output records. rowNums holds
+// the input row number of each record, or is nil when the records are
+// contiguous starting at firstRow. Errors of rows that are not in the
+// output are listed but not highlighted.
+func newXLSXErrorSheet(errs []error, headers []string, rowNums []int, firstRow, recordCount int) *xlsxErrorSheet {
+	recordOf := make(map[int]int, recordCount)
+	for i := range recordCount {
+		if rowNums != nil {
+			recordOf[rowNums[i]] = i
+		} else {
+			recordOf[firstRow+i] = i
+		}
+	}
+	colIdx := columnIndexes(headers)
+
+	sheet := &xlsxErrorSheet{cells: make(map[[2]int]bool)}
+	for _, err := range errs {
+		issue, ok := newReportIssue(err)
+		if !ok {
+			continue
+		}
+		sheet.issues = append(sheet.issues, issue)
+		record, inOutput := recordOf[issue.Row]
+		col, hasColumn := colIdx[issue.Column]
+		if inOutput && hasColumn {
+			sheet.cells[[2]int{record, col}] = true
+		}
+	}
+	return sheet
+}
+
+// writeXLSX writes a workbook with headers in bold and records as text on
+// the Data sheet. With errSheet, the cells with errors are highlighted and
+// the errors are listed on an Errors sheet.
+func writeXLSX(w io.Writer, headers []string, records [][]string, errSheet *xlsxErrorSheet) (err error) {
+	f := excelize.NewFile()
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}()
+	if err := f.SetSheetName("Sheet1", xlsxDataSheet); err != nil {
+		return err
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	errorStyle, err := f.NewStyle(&excelize.Style{
+		Fill: excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{xlsxErrorFill}},
+	})
+	if err != nil {
+		return err
+	}
+
+	sw, err := f.NewStreamWriter(xlsxDataSheet)
+	if err != nil {
+		return err
+	}
+	if err := writeXLSXRow(sw, 1, headers, func(int) int { return headerStyle }); err != nil {
+		return err
+	}
+	for i, record := range records {
+		row := record
+		if len(row) < len(headers) {
+			row = make([]string, len(headers))
+			copy(row, record)
+		}
+		style := func(int) int { return 0 }
+		if errSheet != nil {
+			style = func(col int) int {
+				if errSheet.cells[[2]int{i, col}] {
+					return errorStyle
+				}
+				return 0
+			}
+		}
+		if err := writeXLSXRow(sw, i+2, row, style); err != nil {
+			return err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+
+	if errSheet != nil {
+		if err := writeXLSXErrors(f, errSheet.issues, headerStyle); err != nil {
+			return err
+		}
+	}
+	return f.Write(w)
+}
+
+// writeXLSXRow writes values as text cells of the 1-based row, with the
+// style that style returns for each column index.
+func writeXLSXRow(sw *excelize.StreamWriter, row int, values []string, style func(col int) int) error {
+	cells := make([]any, len(values))
+	for i, value := range values {
+		cells[i] = excelize.Cell{StyleID: style(i), Value: value}
+	}
+	cell, err := excelize.CoordinatesToCellName(1, row)
+	if err != nil {
+		return err
+	}
+	return sw.SetRow(cell, cells)
+}
+
+// writeXLSXErrors adds the Errors sheet listing issues.
+func writeXLSXErrors(f *excelize.File, issues []reportIssue, headerStyle int) error {
+	if _, err := f.NewSheet(xlsxErrorsSheet); err != nil {
+		return err
+	}
+	sw, err := f.NewStreamWriter(xlsxErrorsSheet)
+	if err != nil {
+		return err
+	}
+	header := []string{"row", "column", "tag", "value", "message"}
+	if err := writeXLSXRow(sw, 1, header, func(int) int { return headerStyle }); err != nil {
+		return err
+	}
+	for i, issue := range issues {
+		cells := []any{issue.Row, issue.Column, issue.Tag, issue.Value, issue.Message}
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := sw.SetRow(cell, cells); err != nil {
+			return err
+		}
+	}
+	return sw.Flush()
+}
@@ -0,0 +1,199 @@
+package fileprep
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/nao1215/fileparser"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestProcessor_OutputFormatXLSX(t *testing.T) {
+	t.Parallel()
+
+	type order struct {
+		ID    string `validate:"required"`
+		Email string `prep:"trim" validate:"email"`
+	}
+	input := "id,email\n1, a@example.com \n,not-an-email\n3,c@example.com\n"
+
+	t.Run("data sheet with error sheet", func(t *testing.T) {
+		t.Parallel()
+
+		var orders []order
+		output, result, err := NewProcessor(fileparser.CSV,
+			WithOutputFormat(fileparser.XLSX), WithXLSXErrorSheet()).Process(strings.NewReader(input), &orders)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		stream, ok := output.(Stream)
+		if !ok {
+			t.Fatalf("output is %T, want Stream", output)
+		}
+		if stream.Format() != fileparser.XLSX || stream.Name() != "data.xlsx" {
+			t.Errorf("Format() = %v, Name() = %q, want XLSX and data.xlsx", stream.Format(), stream.Name())
+		}
+		if offsets := stream.RowOffsets(); len(offsets) != 0 {
+			t.Errorf("RowOffsets() = %v, want none", offsets)
+		}
+		if len(result.Errors) != 2 {
+			t.Fatalf("Errors = %v, want 2 errors", result.Errors)
+		}
+
+		f, err := excelize.OpenReader(output)
+		if err != nil {
+			t.Fatalf("excelize.OpenReader() error = %v", err)
+		}
+		defer f.Close()
+
+		if diff := cmp.Diff([]string{"Data", "Errors"}, f.GetSheetList()); diff != "" {
+			t.Errorf("sheets mismatch (-want +got):\n%s", diff)
+		}
+		rows, err := f.GetRows("Data")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantRows := [][]string{
+			{"id", "email"},
+			{"1", "a@example.com"},
+			{"", "not-an-email"},
+			{"3", "c@example.com"},
+		}
+		if diff := cmp.Diff(wantRows, rows); diff != "" {
+			t.Errorf("Data rows mismatch (-want +got):\n%s", diff)
+		}
+
+		// The invalid cells A3 and B3 are highlighted, the others are not
+		for cell, wantFill := range map[string]bool{"A3": true, "B3": true, "A2": false, "B4": false} {
+			styleID, err := f.GetCellStyle("Data", cell)
+			if err != nil {
+				t.Fatal(err)
+			}
+			style, err := f.GetStyle(styleID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if filled := len(style.Fill.Color) > 0; filled != wantFill {
+				t.Errorf("cell %s filled = %v, want %v", cell, filled, wantFill)
+			}
+		}
+
+		errorRows, err := f.GetRows("Errors")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantErrors := [][]string{
+			{"row", "column", "tag", "value", "message"},
+			{"2", "id", "required", "", result.Errors[0].(*ValidationError).Message()},
+			{"2", "email", "email", "not-an-email", result.Errors[1].(*ValidationError).Message()},
+		}
+		if diff := cmp.Diff(wantErrors, errorRows); diff != "" {
+			t.Errorf("Errors rows mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("valid rows only without error sheet", func(t *testing.T) {
+		t.Parallel()
+
+		var orders []order
+		output, _, err := NewProcessor(fileparser.CSV,
+			WithOutputFormat(fileparser.XLSX), WithValidRowsOnly()).Process(strings.NewReader(input), &orders)
+		if err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		f, err := excelize.OpenReader(output)
+		if err != nil {
+			t.Fatalf("excelize.OpenReader() error = %v", err)
+		}
+		defer f.Close()
+
+		if diff := cmp.Diff([]string{"Data"}, f.GetSheetList()); diff != "" {
+			t.Errorf("sheets mismatch (-want +got):\n%s", diff)
+		}
+		rows, err := f.GetRows("Data")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantRows := [][]string{{"id", "email"}, {"1", "a@example.com"}, {"3", "c@example.com"}}
+		if diff := cmp.Diff(wantRows, rows); diff != "" {
+			t.Errorf("Data rows mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestWithOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	type row struct {
+		Name string `prep:"trim"`
+	}
+
+	tests := []struct {
+		name       string
+		fileType   FileType
+		input      string
+		outputType FileType
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "unchanged CSV to TSV",
+			fileType:   fileparser.CSV,
+			input:      "name,city\nalice,Tokyo\n",
+			outputType: fileparser.TSV,
+			want:       "name\tcity\nalice\tTokyo\n",
+		},
+		{
+			name:       "TSV to LTSV",
+			fileType:   fileparser.TSV,
+			input:      "name\tcity\n alice \tTokyo\n",
+			outputType: fileparser.LTSV,
+			want:       "name:alice\tcity:Tokyo\n",
+		},
+		{
+			name:       "JSONL output",
+			fileType:   fileparser.CSV,
+			input:      "name\nalice\n",
+			outputType: fileparser.JSONL,
+			wantErr:    ErrInvalidOption,
+		},
+		{
+			name:       "JSON input",
+			fileType:   fileparser.JSONL,
+			input:      "{\"name\":\"alice\"}\n",
+			outputType: fileparser.CSV,
+			wantErr:    ErrUnsupportedFileType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var rows []row
+			output, _, err := NewProcessor(tt.fileType, WithOutputFormat(tt.outputType)).Process(strings.NewReader(tt.input), &rows)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Process() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			data, err := io.ReadAll(output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, string(data)); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+			if got := output.(Stream).Format(); got != tt.outputType {
+				t.Errorf("Format() = %v, want %v", got, tt.outputType)
+			}
+		})
+	}
+}